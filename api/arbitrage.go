@@ -0,0 +1,62 @@
+// Copyright (c) 2025 BVK Chaitanya
+
+package api
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/shopspring/decimal"
+)
+
+const ArbitragePath = "/trader/arbitrage"
+
+type ArbitrageRequest struct {
+	ExchangeA string
+	ProductA  string
+	FeePctA   decimal.Decimal
+
+	ExchangeB string
+	ProductB  string
+	FeePctB   decimal.Decimal
+
+	// ThresholdPct is the minimum spread percentage above the fees on both
+	// exchanges for alerts and trades.
+	ThresholdPct decimal.Decimal
+
+	// TradeSize is the size for paired orders. Zero value only monitors the
+	// spreads.
+	TradeSize decimal.Decimal
+
+	// MaxTrades limits the number of paired trades. Zero value means no limit.
+	MaxTrades int
+}
+
+type ArbitrageResponse struct {
+	UID string
+}
+
+func (r *ArbitrageRequest) Check() error {
+	if len(r.ExchangeA) == 0 || len(r.ExchangeB) == 0 {
+		return fmt.Errorf("exchange names cannot be empty")
+	}
+	if len(r.ProductA) == 0 || len(r.ProductB) == 0 {
+		return fmt.Errorf("product ids cannot be empty")
+	}
+	if strings.EqualFold(r.ExchangeA, r.ExchangeB) {
+		return fmt.Errorf("exchange names must be different")
+	}
+	if r.FeePctA.IsNegative() || r.FeePctB.IsNegative() {
+		return fmt.Errorf("fee percentages cannot be negative")
+	}
+	if r.ThresholdPct.IsNegative() {
+		return fmt.Errorf("threshold percentage cannot be negative")
+	}
+	if r.TradeSize.IsNegative() {
+		return fmt.Errorf("trade size cannot be negative")
+	}
+	if r.MaxTrades < 0 {
+		return fmt.Errorf("max trades cannot be negative")
+	}
+	return nil
+}
@@ -0,0 +1,30 @@
+// Copyright (c) 2025 BVK Chaitanya
+
+package api
+
+import "github.com/shopspring/decimal"
+
+const BudgetPath = "/trader/budget"
+
+type BudgetRequest struct {
+}
+
+// BudgetResponseItem holds the capital committed by all running jobs and the
+// balances for a quote currency in an exchange. Total balance includes the
+// balance held for the open orders. HasBalance is false when server hasn't
+// received the balance from the exchange yet.
+type BudgetResponseItem struct {
+	ExchangeName string
+	Currency     string
+
+	NumJobs   int
+	Committed decimal.Decimal
+
+	HasBalance bool
+	Available  decimal.Decimal
+	Total      decimal.Decimal
+}
+
+type BudgetResponse struct {
+	Items []*BudgetResponseItem
+}
@@ -0,0 +1,71 @@
+// Copyright (c) 2025 BVK Chaitanya
+
+package api
+
+import (
+	"github.com/shopspring/decimal"
+)
+
+// DryRunReport describes the exchange-facing effects of a new job without
+// creating the job.
+type DryRunReport struct {
+	ExchangeName string
+	ProductID    string
+
+	// TickerPrice is the current product price used to determine the orders
+	// that are placed immediately. It is zero when price is not known.
+	TickerPrice decimal.Decimal
+
+	BaseMinSize    decimal.Decimal
+	BaseIncrement  decimal.Decimal
+	QuoteMinSize   decimal.Decimal
+	QuoteIncrement decimal.Decimal
+
+	Points []*DryRunPoint
+
+	BaseCurrency  string
+	QuoteCurrency string
+
+	// BaseNow and QuoteNow are the balances required for the orders that are
+	// placed immediately at the current ticker price.
+	BaseNow  decimal.Decimal
+	QuoteNow decimal.Decimal
+
+	// BaseWorstCase and QuoteWorstCase are the balances required when all
+	// orders of the job are placed.
+	BaseWorstCase  decimal.Decimal
+	QuoteWorstCase decimal.Decimal
+
+	// BaseAvailable and QuoteAvailable are the last known available balances
+	// when the corresponding HasBaseBalance or HasQuoteBalance flag is true.
+	BaseAvailable   decimal.Decimal
+	QuoteAvailable  decimal.Decimal
+	HasBaseBalance  bool
+	HasQuoteBalance bool
+
+	// QuoteCommitted is the quote currency budget already committed to the
+	// running jobs.
+	QuoteCommitted decimal.Decimal
+
+	// NumViolations is the number of points that violate the exchange limits.
+	NumViolations int
+}
+
+// DryRunPoint describes a single buy or sell point of a new job.
+type DryRunPoint struct {
+	// Pair is the index of the buy/sell pair for the point. It is -1 for
+	// limiter jobs.
+	Pair int
+
+	Side   string
+	Size   decimal.Decimal
+	Price  decimal.Decimal
+	Cancel decimal.Decimal
+
+	// PlacedNow is true if an order is placed for the point immediately at the
+	// current ticker price.
+	PlacedNow bool
+
+	// Violations holds the exchange limits violated by the point.
+	Violations []string
+}
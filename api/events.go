@@ -0,0 +1,149 @@
+// Copyright (c) 2025 BVK Chaitanya
+
+package api
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/bvk/tradebot/gobs"
+	"github.com/shopspring/decimal"
+)
+
+// EventsPath is a server-sent events endpoint that streams the trader events
+// as they happen. Unlike other endpoints, it is accessed with a GET request
+// so that it is compatible with the EventSource clients. Stream is configured
+// with the following query parameters:
+//
+//   - types: comma separated event types to stream; all types except price
+//     are streamed by default.
+//   - products: comma separated exchange:product names to limit the order,
+//     fill and price events; price events require at least one product.
+//
+// Every event is sent with the event type as the SSE event name and the Event
+// object in json format as the SSE data.
+const EventsPath = "/trader/events"
+
+// Event types streamed by the events endpoint.
+const (
+	OrderEventType   = "order"
+	FillEventType    = "fill"
+	JobEventType     = "job"
+	BalanceEventType = "balance"
+	PriceEventType   = "price"
+)
+
+// EventTypes holds all supported event types.
+var EventTypes = []string{OrderEventType, FillEventType, JobEventType, BalanceEventType, PriceEventType}
+
+// DefaultEventTypes holds the event types streamed when request doesn't
+// select any types.
+var DefaultEventTypes = []string{OrderEventType, FillEventType, JobEventType, BalanceEventType}
+
+type Event struct {
+	Type string
+	Time time.Time
+
+	// ExchangeName is set for all event types except the job events.
+	ExchangeName string
+
+	// ProductID is set for the order, fill and price events.
+	ProductID string
+
+	Order   *OrderEvent
+	Fill    *FillEvent
+	Job     *JobStateEvent
+	Balance *BalanceEvent
+	Price   *PriceEvent
+}
+
+type OrderEvent struct {
+	ServerID string
+	ClientID string
+
+	ExecutedFee   decimal.Decimal
+	ExecutedSize  decimal.Decimal
+	ExecutedValue decimal.Decimal
+
+	Status string
+	Done   bool
+}
+
+// FillEvent is derived from the order updates and holds the incremental
+// executed size, value and fee since the previous update of an order. Orders
+// are tracked only after the stream begins, so the first fill of an order that
+// was partially filled earlier includes the earlier executions.
+type FillEvent struct {
+	ServerID string
+	ClientID string
+
+	Size  decimal.Decimal
+	Value decimal.Decimal
+	Fee   decimal.Decimal
+}
+
+type JobStateEvent struct {
+	UID   string
+	Name  string
+	Kind  string
+	State gobs.State
+
+	Reason string
+}
+
+type BalanceEvent struct {
+	Currency string
+	Amount   decimal.Decimal
+}
+
+type PriceEvent struct {
+	Price decimal.Decimal
+}
+
+// EventsQuery holds the parsed query parameters for the events endpoint.
+type EventsQuery struct {
+	Types []string
+
+	// Products holds exchange:product names.
+	Products []string
+}
+
+// ParseEventsQuery parses and validates the events endpoint query parameters.
+func ParseEventsQuery(types, products string) (*EventsQuery, error) {
+	q := new(EventsQuery)
+	for _, t := range strings.Split(types, ",") {
+		if t = strings.ToLower(strings.TrimSpace(t)); len(t) == 0 {
+			continue
+		}
+		if !slices.Contains(EventTypes, t) {
+			return nil, fmt.Errorf("unsupported event type %q", t)
+		}
+		if !slices.Contains(q.Types, t) {
+			q.Types = append(q.Types, t)
+		}
+	}
+	if len(q.Types) == 0 {
+		q.Types = slices.Clone(DefaultEventTypes)
+	}
+	for _, p := range strings.Split(products, ",") {
+		if p = strings.TrimSpace(p); len(p) == 0 {
+			continue
+		}
+		ename, pid, ok := strings.Cut(p, ":")
+		if !ok || len(ename) == 0 || len(pid) == 0 {
+			return nil, fmt.Errorf("product %q must be of the form exchange:product", p)
+		}
+		q.Products = append(q.Products, p)
+	}
+	if slices.Contains(q.Types, PriceEventType) && len(q.Products) == 0 {
+		return nil, fmt.Errorf("price events require at least one product")
+	}
+	return q, nil
+}
+
+// Has returns true if the input event type is selected.
+func (q *EventsQuery) Has(typ string) bool {
+	return slices.Contains(q.Types, typ)
+}
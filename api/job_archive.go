@@ -0,0 +1,48 @@
+// Copyright (c) 2025 BVK Chaitanya
+
+package api
+
+import (
+	"fmt"
+)
+
+const JobArchivePath = "/trader/job/archive"
+
+// JobArchiveRequest moves finished jobs out of the main keyspaces into a
+// compressed archive. Archived jobs remain visible in the summary and history
+// queries and can be restored later.
+type JobArchiveRequest struct {
+	UID string
+
+	// Selector, when non-empty, archives all jobs matching the label selector
+	// instead of UID.
+	Selector string
+}
+
+type JobArchiveResponse struct {
+	FinalState string
+
+	// Jobs holds the per-job results when request uses a selector.
+	Jobs []*JobResult
+}
+
+func (r *JobArchiveRequest) Check() error {
+	if len(r.UID) == 0 && len(r.Selector) == 0 {
+		return fmt.Errorf("job uid or selector is required")
+	}
+	if len(r.UID) != 0 && len(r.Selector) != 0 {
+		return fmt.Errorf("job uid and selector cannot be used together")
+	}
+	return nil
+}
+
+const JobRestorePath = "/trader/job/restore"
+
+// JobRestoreRequest moves an archived job back into the main keyspaces.
+type JobRestoreRequest struct {
+	UID string
+}
+
+type JobRestoreResponse struct {
+	FinalState string
+}
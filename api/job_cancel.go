@@ -6,7 +6,14 @@ const JobCancelPath = "/trader/job/cancel"
 
 type JobCancelRequest struct {
 	UID string
+
+	// Selector, when non-empty, applies the operation to all jobs matching the
+	// label selector of the form "key1=value1,key2=value2" instead of UID.
+	Selector string
 }
 type JobCancelResponse struct {
 	FinalState string
+
+	// Jobs holds the per-job results when request uses a selector.
+	Jobs []*JobResult
 }
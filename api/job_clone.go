@@ -0,0 +1,58 @@
+// Copyright (c) 2025 BVK Chaitanya
+
+package api
+
+import (
+	"fmt"
+
+	"github.com/shopspring/decimal"
+)
+
+const JobClonePath = "/trader/job/clone"
+
+// JobCloneRequest creates a new paused job with the definition of an existing
+// limiter, looper or waller job. Buy/sell points of the new job are derived
+// from the original job's points with the optional overrides.
+type JobCloneRequest struct {
+	UID string
+
+	// Name, when non-empty, is assigned as the name for the new job.
+	Name string
+
+	// ProductID, when non-empty, replaces the product of the new job. Exchange
+	// of the new job is always the same as the original job.
+	ProductID string
+
+	// PriceShift, when non-zero, is added to all buy, sell and cancel prices.
+	PriceShift decimal.Decimal
+
+	// PriceScale, when non-zero, multiplies all buy, sell and cancel prices. For
+	// example, 0.9 moves all prices 10% lower.
+	PriceScale decimal.Decimal
+
+	// SizeScale, when non-zero, multiplies all buy and sell sizes.
+	SizeScale decimal.Decimal
+}
+
+type JobCloneResponse struct {
+	// UID is the id of the new job.
+	UID string
+
+	Type string
+}
+
+func (r *JobCloneRequest) Check() error {
+	if len(r.UID) == 0 {
+		return fmt.Errorf("job uid cannot be empty")
+	}
+	if !r.PriceShift.IsZero() && !r.PriceScale.IsZero() {
+		return fmt.Errorf("price shift and price scale cannot be used together")
+	}
+	if r.PriceScale.IsNegative() {
+		return fmt.Errorf("price scale cannot be negative")
+	}
+	if r.SizeScale.IsNegative() {
+		return fmt.Errorf("size scale cannot be negative")
+	}
+	return nil
+}
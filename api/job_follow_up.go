@@ -0,0 +1,80 @@
+// Copyright (c) 2025 BVK Chaitanya
+
+package api
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/bvk/tradebot/point"
+	"github.com/shopspring/decimal"
+)
+
+const JobFollowUpPath = "/trader/job/follow-up"
+
+// JobFollowUpRequest declares a job to be created when an existing job
+// completes successfully. Zero sizes in the buy/sell points are replaced with
+// the base size filled by the parent job.
+type JobFollowUpRequest struct {
+	UID string
+
+	// Typename must be one of Limiter or Looper.
+	Typename string
+
+	Buy  *point.Point
+	Sell *point.Point
+
+	// Remove when true, removes an existing follow-up declaration.
+	Remove bool
+}
+
+type JobFollowUpResponse struct {
+}
+
+func (r *JobFollowUpRequest) Check() error {
+	if len(r.UID) == 0 {
+		return fmt.Errorf("job uid cannot be empty")
+	}
+	if r.Remove {
+		return nil
+	}
+	check := func(p *point.Point, side string) error {
+		if p == nil {
+			return nil
+		}
+		if p.Size.IsNegative() {
+			return fmt.Errorf("%s size cannot be negative", side)
+		}
+		// Zero sizes are derived from the parent job later.
+		tmp := *p
+		if tmp.Size.IsZero() {
+			tmp.Size = decimal.NewFromInt(1)
+		}
+		if err := tmp.Check(); err != nil {
+			return fmt.Errorf("invalid %s point: %w", side, err)
+		}
+		if !strings.EqualFold(tmp.Side(), side) {
+			return fmt.Errorf("invalid %s point side", side)
+		}
+		return nil
+	}
+	if err := check(r.Buy, "buy"); err != nil {
+		return err
+	}
+	if err := check(r.Sell, "sell"); err != nil {
+		return err
+	}
+	switch {
+	case strings.EqualFold(r.Typename, "limiter"):
+		if (r.Buy == nil) == (r.Sell == nil) {
+			return fmt.Errorf("follow-up limiter needs exactly one of buy or sell points")
+		}
+	case strings.EqualFold(r.Typename, "looper"):
+		if r.Buy == nil || r.Sell == nil {
+			return fmt.Errorf("follow-up looper needs both buy and sell points")
+		}
+	default:
+		return fmt.Errorf("follow-up job type must be one of limiter or looper")
+	}
+	return nil
+}
@@ -0,0 +1,22 @@
+// Copyright (c) 2025 BVK Chaitanya
+
+package api
+
+import (
+	"github.com/bvk/tradebot/gobs"
+)
+
+const JobHistoryPath = "/trader/job/history"
+
+type JobHistoryRequest struct {
+	UID string
+}
+
+type JobHistoryResponse struct {
+	UID  string
+	Name string
+	Type string
+
+	// Events holds the job events in the time order.
+	Events []*gobs.JobEvent
+}
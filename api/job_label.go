@@ -0,0 +1,45 @@
+// Copyright (c) 2025 BVK Chaitanya
+
+package api
+
+import (
+	"fmt"
+)
+
+const JobLabelPath = "/trader/job/label"
+
+// JobLabelRequest adds or updates the Labels and removes the labels with keys
+// in Remove on an existing job.
+type JobLabelRequest struct {
+	UID string
+
+	Labels map[string]string
+	Remove []string
+}
+
+type JobLabelResponse struct {
+	// Labels holds the updated labels of the job.
+	Labels map[string]string
+}
+
+func (r *JobLabelRequest) Check() error {
+	if len(r.UID) == 0 {
+		return fmt.Errorf("job uid cannot be empty")
+	}
+	if len(r.Labels) == 0 && len(r.Remove) == 0 {
+		return fmt.Errorf("at least one label must be added or removed")
+	}
+	return nil
+}
+
+// JobResult holds the result of an operation on one of the jobs matched by a
+// label selector.
+type JobResult struct {
+	UID  string
+	Name string
+
+	FinalState string
+
+	// Error is non-empty if the operation has failed on the job.
+	Error string
+}
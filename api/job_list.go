@@ -2,9 +2,14 @@
 
 package api
 
+import "time"
+
 const JobListPath = "/trader/job/list"
 
 type JobListRequest struct {
+	// Selector, when non-empty, limits the response to the jobs matching the
+	// label selector.
+	Selector string
 }
 
 type JobListResponseItem struct {
@@ -14,6 +19,19 @@ type JobListResponseItem struct {
 	Name  string
 
 	ManualFlag bool
+
+	Labels map[string]string
+
+	// Armed is true if job is waiting for it's trigger to fire. Trigger
+	// describes the condition and TriggerDistance describes how far the trigger
+	// is from firing.
+	Armed           bool
+	Trigger         string
+	TriggerDistance string
+
+	// RestartAt is non-zero when the job has failed and is waiting to be
+	// restarted automatically.
+	RestartAt time.Time
 }
 
 type JobListResponse struct {
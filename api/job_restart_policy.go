@@ -0,0 +1,38 @@
+// Copyright (c) 2025 BVK Chaitanya
+
+package api
+
+import (
+	"fmt"
+)
+
+const JobRestartPolicyPath = "/trader/job/restart-policy"
+
+// JobRestartPolicyRequest updates the automatic restart policy of a job.
+// Policy is a string of the form "never" or
+// "on-failure:max-attempts=5,window=1h,backoff=1m,max-backoff=30m" where
+// parameters are optional.
+type JobRestartPolicyRequest struct {
+	UID string
+
+	Policy string
+
+	// Remove when true, removes the job specific policy, so that the default
+	// policy for the job type is used.
+	Remove bool
+}
+
+type JobRestartPolicyResponse struct {
+	// Policy holds the updated policy with all parameters.
+	Policy string
+}
+
+func (r *JobRestartPolicyRequest) Check() error {
+	if len(r.UID) == 0 {
+		return fmt.Errorf("job uid cannot be empty")
+	}
+	if !r.Remove && len(r.Policy) == 0 {
+		return fmt.Errorf("restart policy cannot be empty")
+	}
+	return nil
+}
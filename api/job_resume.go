@@ -6,7 +6,14 @@ const JobResumePath = "/trader/job/resume"
 
 type JobResumeRequest struct {
 	UID string
+
+	// Selector, when non-empty, applies the operation to all jobs matching the
+	// label selector of the form "key1=value1,key2=value2" instead of UID.
+	Selector string
 }
 type JobResumeResponse struct {
 	FinalState string
+
+	// Jobs holds the per-job results when request uses a selector.
+	Jobs []*JobResult
 }
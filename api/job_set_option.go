@@ -9,16 +9,26 @@ const JobSetOptionPath = "/trader/job/set-option"
 type JobSetOptionRequest struct {
 	UID string
 
+	// Selector, when non-empty, updates the option on all jobs matching the
+	// label selector instead of UID. Option is updated on all matching jobs or
+	// none of them.
+	Selector string
+
 	OptionKey   string
 	OptionValue string
 }
 
 type JobSetOptionResponse struct {
+	// Jobs holds the per-job results when request uses a selector.
+	Jobs []*JobResult
 }
 
 func (req *JobSetOptionRequest) Check() error {
-	if len(req.UID) == 0 {
-		return fmt.Errorf("job uid cannot be empty")
+	if len(req.UID) == 0 && len(req.Selector) == 0 {
+		return fmt.Errorf("job uid or selector is required")
+	}
+	if len(req.UID) != 0 && len(req.Selector) != 0 {
+		return fmt.Errorf("job uid and selector cannot be used together")
 	}
 	if len(req.OptionKey) == 0 {
 		return fmt.Errorf("option key cannot be empty")
@@ -5,7 +5,9 @@ package api
 import (
 	"fmt"
 
+	"github.com/bvk/tradebot/gobs"
 	"github.com/bvk/tradebot/point"
+	"github.com/bvk/tradebot/trigger"
 )
 
 const LimitPath = "/trader/limit"
@@ -16,10 +18,34 @@ type LimitRequest struct {
 	ProductID string
 
 	Point *point.Point
+
+	// Trigger, when non-nil, creates the job in armed state where it begins
+	// trading only after the trigger fires.
+	Trigger *trigger.Trigger
+
+	// Options holds the limiter options to set on the new job, for example,
+	// execution=iceberg and size-limit=0.1 options.
+	Options map[string]string
+
+	// Labels holds the user-defined labels for selecting the new job in bulk
+	// operations.
+	Labels map[string]string
+
+	// Expiry, when non-nil, sets a deadline for the job and the fallback
+	// action to take if the job is not complete by the deadline.
+	Expiry *gobs.LimiterExpiry
+
+	Pause bool
+
+	// DryRun when true, validates the request and returns a report of the
+	// exchange-facing effects without creating the job.
+	DryRun bool
 }
 
 type LimitResponse struct {
 	UID string
+
+	DryRun *DryRunReport
 }
 
 func (r *LimitRequest) Check() error {
@@ -32,5 +58,16 @@ func (r *LimitRequest) Check() error {
 	if err := r.Point.Check(); err != nil {
 		return fmt.Errorf("invalid trade point: %w", err)
 	}
+	if r.Trigger != nil {
+		if err := r.Trigger.Check(); err != nil {
+			return fmt.Errorf("invalid trigger: %w", err)
+		}
+	}
+	if mode := r.Options["execution"]; mode != "" && mode != "limit" && r.Options["size-limit"] == "" {
+		return fmt.Errorf("execution mode %q requires the size-limit option", mode)
+	}
+	if r.Expiry != nil && r.Expiry.Deadline.IsZero() {
+		return fmt.Errorf("expiry deadline cannot be zero")
+	}
 	return nil
 }
@@ -6,6 +6,7 @@ import (
 	"fmt"
 
 	"github.com/bvk/tradebot/point"
+	"github.com/bvk/tradebot/trigger"
 )
 
 const LoopPath = "/trader/loop"
@@ -18,11 +19,29 @@ type LoopRequest struct {
 	Buy  *point.Point
 	Sell *point.Point
 
+	// Trigger, when non-nil, creates the job in armed state where it begins
+	// trading only after the trigger fires.
+	Trigger *trigger.Trigger
+
+	// Options holds the job options to set on the new job, for example,
+	// max-cycles, profit-target and end-date options.
+	Options map[string]string
+
+	// Labels holds the user-defined labels for selecting the new job in bulk
+	// operations.
+	Labels map[string]string
+
 	Pause bool
+
+	// DryRun when true, validates the request and returns a report of the
+	// exchange-facing effects without creating the job.
+	DryRun bool
 }
 
 type LoopResponse struct {
 	UID string
+
+	DryRun *DryRunReport
 }
 
 func (r *LoopRequest) Check() error {
@@ -44,5 +63,10 @@ func (r *LoopRequest) Check() error {
 	if r.Sell.Side() != "SELL" {
 		return fmt.Errorf("invalid sell point side")
 	}
+	if r.Trigger != nil {
+		if err := r.Trigger.Check(); err != nil {
+			return fmt.Errorf("invalid trigger: %w", err)
+		}
+	}
 	return nil
 }
@@ -0,0 +1,55 @@
+// Copyright (c) 2025 BVK Chaitanya
+
+package api
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/bvk/tradebot/gobs"
+	"github.com/bvk/tradebot/trader"
+	"github.com/shopspring/decimal"
+)
+
+const StatusPath = "/trader/status"
+
+// StatusRequest computes the trade statuses of all or selected jobs that
+// support status reports. Statuses cover the lifetime of the jobs when
+// BeginTime and EndTime are zero.
+type StatusRequest struct {
+	// Jobs holds the names or uids of the selected jobs. All jobs are included
+	// when it is empty.
+	Jobs []string
+
+	BeginTime time.Time
+	EndTime   time.Time
+
+	// PricesFrom holds the exchange name to fetch the current product prices.
+	PricesFrom string
+}
+
+type StatusResponse struct {
+	Jobs []*JobStatus
+
+	// Prices holds the current product prices from the PricesFrom exchange
+	// keyed by the product ids.
+	Prices map[string]decimal.Decimal
+
+	// Accounts holds the last known coinbase account balances.
+	Accounts []*gobs.Account
+}
+
+type JobStatus struct {
+	UID   string
+	Name  string
+	State gobs.State
+
+	Status *trader.Status
+}
+
+func (r *StatusRequest) Check() error {
+	if !r.BeginTime.IsZero() && !r.EndTime.IsZero() && !r.BeginTime.Before(r.EndTime) {
+		return fmt.Errorf("begin time must be before the end time")
+	}
+	return nil
+}
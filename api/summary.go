@@ -0,0 +1,68 @@
+// Copyright (c) 2025 BVK Chaitanya
+
+package api
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/bvk/tradebot/gobs"
+	"github.com/shopspring/decimal"
+)
+
+const SummaryPath = "/trader/summary"
+
+// SummaryRequest computes the per-job and aggregate summaries of the looper,
+// waller and watcher jobs including the archived jobs. Summaries cover the
+// lifetime of the jobs when BeginTime and EndTime are zero. Zero EndTime with
+// a non-zero BeginTime covers the time period till now.
+type SummaryRequest struct {
+	// Jobs holds the names or uids of the selected jobs. All jobs are
+	// summarized when it is empty.
+	Jobs []string
+
+	BeginTime time.Time
+	EndTime   time.Time
+
+	// Recalculate when true recomputes the summaries from the job data instead
+	// of using the cached summaries.
+	Recalculate bool
+
+	// IgnoreJobTypes holds the lowercase job types that are not included in the
+	// aggregate summaries.
+	IgnoreJobTypes []string
+}
+
+type SummaryResponse struct {
+	Jobs []*JobSummary
+
+	// Total and Running hold the aggregate summaries of all jobs and the
+	// running jobs, respectively.
+	Total   *gobs.Summary
+	Running *gobs.Summary
+
+	// Prices holds the current product prices keyed by the exchange names and
+	// product ids.
+	Prices map[string]map[string]decimal.Decimal
+
+	// Accounts holds the last known coinbase account balances.
+	Accounts []*gobs.Account
+}
+
+type JobSummary struct {
+	UID   string
+	Name  string
+	Type  string
+	State gobs.State
+
+	Archived bool
+
+	Summary *gobs.Summary
+}
+
+func (r *SummaryRequest) Check() error {
+	if !r.BeginTime.IsZero() && !r.EndTime.IsZero() && !r.BeginTime.Before(r.EndTime) {
+		return fmt.Errorf("begin time must be before the end time")
+	}
+	return nil
+}
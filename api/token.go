@@ -0,0 +1,71 @@
+// Copyright (c) 2025 BVK Chaitanya
+
+package api
+
+import (
+	"fmt"
+	"time"
+)
+
+const (
+	TokenCreatePath = "/trader/token/create"
+	TokenListPath   = "/trader/token/list"
+	TokenRevokePath = "/trader/token/revoke"
+)
+
+// TokenCreateRequest creates a new API token with one of the read-only, trade
+// or admin scopes. Zero ExpiresAt creates a token that never expires.
+type TokenCreateRequest struct {
+	Name  string
+	Scope string
+
+	ExpiresAt time.Time
+}
+
+type TokenCreateResponse struct {
+	ID string
+
+	// Token holds the token string to be used by the clients. It is not saved
+	// by the server, so it cannot be retrieved again.
+	Token string
+}
+
+func (r *TokenCreateRequest) Check() error {
+	if len(r.Name) == 0 {
+		return fmt.Errorf("token name cannot be empty")
+	}
+	if len(r.Scope) == 0 {
+		return fmt.Errorf("token scope cannot be empty")
+	}
+	return nil
+}
+
+type TokenListRequest struct {
+}
+
+type TokenListResponse struct {
+	Tokens []*TokenInfo
+}
+
+type TokenInfo struct {
+	ID    string
+	Name  string
+	Scope string
+
+	CreatedAt time.Time
+	ExpiresAt time.Time
+}
+
+type TokenRevokeRequest struct {
+	ID string
+}
+
+type TokenRevokeResponse struct {
+}
+
+func (r *TokenRevokeRequest) Check() error {
+	if len(r.ID) == 0 {
+		return fmt.Errorf("token id cannot be empty")
+	}
+	return nil
+}
@@ -6,6 +6,7 @@ import (
 	"fmt"
 
 	"github.com/bvk/tradebot/point"
+	"github.com/bvk/tradebot/trigger"
 )
 
 const WallPath = "/trader/wall"
@@ -16,10 +17,30 @@ type WallRequest struct {
 	ProductID string
 
 	Pairs []*point.Pair
+
+	// Trigger, when non-nil, creates the job in armed state where it begins
+	// trading only after the trigger fires.
+	Trigger *trigger.Trigger
+
+	// Options holds the job options to set on the new job, for example,
+	// max-cycles, profit-target and end-date options.
+	Options map[string]string
+
+	// Labels holds the user-defined labels for selecting the new job in bulk
+	// operations.
+	Labels map[string]string
+
+	Pause bool
+
+	// DryRun when true, validates the request and returns a report of the
+	// exchange-facing effects without creating the job.
+	DryRun bool
 }
 
 type WallResponse struct {
 	UID string
+
+	DryRun *DryRunReport
 }
 
 func (r *WallRequest) Check() error {
@@ -37,5 +58,10 @@ func (r *WallRequest) Check() error {
 			return fmt.Errorf("invalid buy/sell pair %d: %w", i, err)
 		}
 	}
+	if r.Trigger != nil {
+		if err := r.Trigger.Check(); err != nil {
+			return fmt.Errorf("invalid trigger: %w", err)
+		}
+	}
 	return nil
 }
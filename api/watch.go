@@ -5,6 +5,7 @@ package api
 import (
 	"fmt"
 
+	"github.com/bvk/tradebot/gobs"
 	"github.com/bvk/tradebot/point"
 	"github.com/shopspring/decimal"
 )
@@ -19,6 +20,10 @@ type WatchRequest struct {
 	FeePct decimal.Decimal
 
 	Pairs []*point.Pair
+
+	// FillModel when non-nil, selects the conditions for the simulated buys
+	// and sells.
+	FillModel *gobs.WatcherFillModel
 }
 
 type WatchResponse struct {
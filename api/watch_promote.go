@@ -0,0 +1,48 @@
+// Copyright (c) 2025 BVK Chaitanya
+
+package api
+
+import (
+	"fmt"
+
+	"github.com/bvk/tradebot/gobs"
+	"github.com/shopspring/decimal"
+)
+
+const WatchPromotePath = "/trader/watch/promote"
+
+type WatchPromoteRequest struct {
+	UID string
+
+	// MinPrice and MaxPrice when non-zero, select only the pairs with buy
+	// prices in the [MinPrice, MaxPrice] range.
+	MinPrice decimal.Decimal
+	MaxPrice decimal.Decimal
+
+	// Force when true, allows promoting an already superseded watcher.
+	Force bool
+}
+
+type WatchPromoteResponse struct {
+	// UID is the id of the new waller job.
+	UID string
+
+	NumPairs int
+
+	// WatcherSummary holds the simulated summary of the watcher at the time
+	// of promotion.
+	WatcherSummary *gobs.Summary
+}
+
+func (r *WatchPromoteRequest) Check() error {
+	if len(r.UID) == 0 {
+		return fmt.Errorf("watcher uid cannot be empty")
+	}
+	if r.MinPrice.IsNegative() || r.MaxPrice.IsNegative() {
+		return fmt.Errorf("price range cannot be negative")
+	}
+	if !r.MaxPrice.IsZero() && r.MaxPrice.LessThan(r.MinPrice) {
+		return fmt.Errorf("max price cannot be less than the min price")
+	}
+	return nil
+}
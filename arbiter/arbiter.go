@@ -0,0 +1,380 @@
+// Copyright (c) 2025 BVK Chaitanya
+
+// Package arbiter implements a job that monitors the price spread for an asset
+// between two exchanges and optionally trades the spread with paired buy and
+// sell orders using the balances held on each exchange.
+package arbiter
+
+import (
+	"bytes"
+	"context"
+	"encoding/gob"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"path"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/bvk/tradebot/gobs"
+	"github.com/bvk/tradebot/idgen"
+	"github.com/bvk/tradebot/kvutil"
+	"github.com/bvk/tradebot/timerange"
+	"github.com/bvk/tradebot/trader"
+	"github.com/bvkgo/kv"
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+)
+
+const DefaultKeyspace = "/arbiters/"
+
+// Leg is one side of the arbitrage.
+type Leg struct {
+	ExchangeName string
+	ProductID    string
+	FeePct       decimal.Decimal
+}
+
+type Arbiter struct {
+	runtimeLock sync.Mutex
+
+	uid string
+
+	idgen *idgen.Generator
+
+	state *gobs.ArbiterState
+}
+
+var _ trader.Trader = &Arbiter{}
+
+func New(uid string, a, b *Leg, thresholdPct, tradeSize decimal.Decimal, maxTrades int) (*Arbiter, error) {
+	v := &Arbiter{
+		uid:   uid,
+		idgen: idgen.New(uid, 0),
+		state: &gobs.ArbiterState{
+			ExchangeA:    a.ExchangeName,
+			ProductA:     a.ProductID,
+			FeePctA:      a.FeePct,
+			ExchangeB:    b.ExchangeName,
+			ProductB:     b.ProductID,
+			FeePctB:      b.FeePct,
+			ThresholdPct: thresholdPct,
+			TradeSize:    tradeSize,
+			MaxTrades:    maxTrades,
+			ClientIDSeed: uid,
+		},
+	}
+	if err := v.check(); err != nil {
+		return nil, err
+	}
+	return v, nil
+}
+
+func checkUID(uid string) error {
+	fs := strings.Split(uid, "/")
+	if len(fs) == 0 {
+		return fmt.Errorf("uid cannot be empty")
+	}
+	if _, err := uuid.Parse(fs[0]); err != nil {
+		return fmt.Errorf("uid %q doesn't start with an uuid: %w", uid, err)
+	}
+	return nil
+}
+
+func (v *Arbiter) check() error {
+	if err := checkUID(v.uid); err != nil {
+		return err
+	}
+	s := v.state
+	if s.ExchangeA == "" || s.ProductA == "" || s.ExchangeB == "" || s.ProductB == "" {
+		return fmt.Errorf("exchange names and product ids cannot be empty")
+	}
+	if strings.EqualFold(s.ExchangeA, s.ExchangeB) {
+		return fmt.Errorf("arbitrage requires two different exchanges")
+	}
+	if err := CheckProducts(s.ProductA, s.ProductB); err != nil {
+		return err
+	}
+	if s.FeePctA.IsNegative() || s.FeePctB.IsNegative() {
+		return fmt.Errorf("fee percentages cannot be negative")
+	}
+	if s.ThresholdPct.IsNegative() {
+		return fmt.Errorf("threshold percentage cannot be negative")
+	}
+	if s.TradeSize.IsNegative() {
+		return fmt.Errorf("trade size cannot be negative")
+	}
+	if s.MaxTrades < 0 {
+		return fmt.Errorf("max trades cannot be negative")
+	}
+	return nil
+}
+
+// stableQuotes are quote currencies that are treated as equivalent.
+var stableQuotes = []string{"USD", "USDC", "USDT"}
+
+// Normalize returns the base currency and the normalized quote currency for a
+// product id. Stable coin quotes USDC and USDT are normalized to USD.
+func Normalize(productID string) (base, quote string) {
+	id := strings.ToUpper(productID)
+	if b, q, ok := strings.Cut(id, "-"); ok {
+		base, quote = b, q
+	} else {
+		for _, q := range []string{"USDT", "USDC", "USD", "BTC", "ETH"} {
+			if strings.HasSuffix(id, q) && len(id) > len(q) {
+				base, quote = strings.TrimSuffix(id, q), q
+				break
+			}
+		}
+	}
+	for _, q := range stableQuotes {
+		if quote == q {
+			return base, "USD"
+		}
+	}
+	return base, quote
+}
+
+// CheckProducts returns an error if the products are not the same asset after
+// normalizing their quote currencies.
+func CheckProducts(a, b string) error {
+	baseA, quoteA := Normalize(a)
+	baseB, quoteB := Normalize(b)
+	if baseA == "" || quoteA == "" || baseB == "" || quoteB == "" {
+		return fmt.Errorf("could not determine base and quote currencies for %q and %q", a, b)
+	}
+	if baseA != baseB || quoteA != quoteB {
+		return fmt.Errorf("products %q and %q are not the same asset (%s-%s vs %s-%s)", a, b, baseA, quoteA, baseB, quoteB)
+	}
+	return nil
+}
+
+func (v *Arbiter) String() string {
+	return "arbiter:" + v.uid
+}
+
+func (v *Arbiter) LogValue() slog.Value {
+	return slog.StringValue(v.uid)
+}
+
+func (v *Arbiter) UID() string {
+	return v.uid
+}
+
+func (v *Arbiter) ProductID() string {
+	return v.state.ProductA
+}
+
+func (v *Arbiter) ExchangeName() string {
+	return v.state.ExchangeA
+}
+
+// OtherExchangeName returns the exchange name for the second leg.
+func (v *Arbiter) OtherExchangeName() string {
+	return v.state.ExchangeB
+}
+
+// OtherProductID returns the product id for the second leg.
+func (v *Arbiter) OtherProductID() string {
+	return v.state.ProductB
+}
+
+func (v *Arbiter) SetOption(opt, val string) (string, error) {
+	value := strings.TrimPrefix(val, "undo:")
+	switch key := strings.ToLower(opt); key {
+	case "threshold-pct", "trade-size":
+		d, err := decimal.NewFromString(value)
+		if err != nil || d.IsNegative() {
+			return "", fmt.Errorf("invalid value %q for the %s option", val, key)
+		}
+		field := &v.state.ThresholdPct
+		if key == "trade-size" {
+			field = &v.state.TradeSize
+		}
+		current := field.String()
+		*field = d
+		return "undo:" + current, nil
+	case "max-trades":
+		n, err := strconv.Atoi(value)
+		if err != nil || n < 0 {
+			return "", fmt.Errorf("invalid value %q for the %s option", val, key)
+		}
+		current := strconv.Itoa(v.state.MaxTrades)
+		v.state.MaxTrades = n
+		return "undo:" + current, nil
+	default:
+		return "", fmt.Errorf("invalid/unsupported arbiter option %q", key)
+	}
+}
+
+func (v *Arbiter) Actions() []*gobs.Action {
+	var actions []*gobs.Action
+	for _, t := range v.state.Trades {
+		var orders []*gobs.Order
+		for _, o := range []*gobs.Order{t.Buy, t.Sell} {
+			if o != nil && o.Done && o.FilledSize.IsPositive() {
+				orders = append(orders, o)
+			}
+		}
+		if len(orders) == 0 {
+			continue
+		}
+		price := orders[0].FilledPrice
+		actions = append(actions, &gobs.Action{
+			UID:    v.uid,
+			Point:  gobs.Point{Size: orders[0].FilledSize, Price: price, Cancel: price},
+			Orders: orders,
+		})
+	}
+	return actions
+}
+
+// BudgetAt returns the value required for one paired trade at the last
+// observed ask price.
+func (v *Arbiter) BudgetAt(feePct decimal.Decimal) decimal.Decimal {
+	s := v.state
+	if !s.TradeSize.IsPositive() || len(s.Spreads) == 0 {
+		return decimal.Zero
+	}
+	last := s.Spreads[len(s.Spreads)-1]
+	value := s.TradeSize.Mul(decimal.Max(last.AskA, last.AskB))
+	return value.Add(value.Mul(feePct).Div(decimal.NewFromInt(100)))
+}
+
+func (v *Arbiter) GetSummary(r *timerange.Range) *gobs.Summary {
+	s := &gobs.Summary{
+		Exchange:  v.state.ExchangeA,
+		ProductID: v.state.ProductA,
+		Budget:    v.BudgetAt(decimal.Zero),
+	}
+	for _, t := range v.state.Trades {
+		if r != nil && !r.InRange(t.Time) {
+			continue
+		}
+		if s.BeginAt.IsZero() || t.Time.Before(s.BeginAt) {
+			s.BeginAt = t.Time
+		}
+		if t.Time.After(s.EndAt) {
+			s.EndAt = t.Time
+		}
+		if b := t.Buy; b != nil && b.FilledSize.IsPositive() {
+			s.NumBuys = s.NumBuys.Add(decimal.NewFromInt(1))
+			s.BoughtFees = s.BoughtFees.Add(b.FilledFee)
+			s.BoughtSize = s.BoughtSize.Add(b.FilledSize)
+			s.BoughtValue = s.BoughtValue.Add(b.FilledSize.Mul(b.FilledPrice))
+		}
+		if x := t.Sell; x != nil && x.FilledSize.IsPositive() {
+			s.NumSells = s.NumSells.Add(decimal.NewFromInt(1))
+			s.SoldFees = s.SoldFees.Add(x.FilledFee)
+			s.SoldSize = s.SoldSize.Add(x.FilledSize)
+			s.SoldValue = s.SoldValue.Add(x.FilledSize.Mul(x.FilledPrice))
+		}
+	}
+	return s
+}
+
+// Spreads returns the recorded spread history.
+func (v *Arbiter) Spreads() []*gobs.ArbiterSpread {
+	return v.state.Spreads
+}
+
+// Trades returns the paired trades performed by the job.
+func (v *Arbiter) Trades() []*gobs.ArbiterTrade {
+	return v.state.Trades
+}
+
+func (v *Arbiter) Save(ctx context.Context, rw kv.ReadWriter) error {
+	v.state.ClientIDOffset = v.idgen.Offset()
+	v.state.LifetimeSummary = v.GetSummary(nil)
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(v.state); err != nil {
+		return fmt.Errorf("could not encode arbiter state: %w", err)
+	}
+	key := path.Join(DefaultKeyspace, v.uid)
+	if err := rw.Set(ctx, key, &buf); err != nil {
+		return fmt.Errorf("could not save arbiter state: %w", err)
+	}
+	return nil
+}
+
+func Load(ctx context.Context, uid string, r kv.Reader) (*Arbiter, error) {
+	if err := checkUID(uid); err != nil {
+		return nil, err
+	}
+	key := path.Join(DefaultKeyspace, uid)
+	state, err := kvutil.Get[gobs.ArbiterState](ctx, r, key)
+	if err != nil {
+		return nil, fmt.Errorf("could not load arbiter state: %w", err)
+	}
+	seed := uid
+	if len(state.ClientIDSeed) > 0 {
+		seed = state.ClientIDSeed
+	}
+	v := &Arbiter{
+		uid:   uid,
+		state: state,
+		idgen: idgen.New(seed, state.ClientIDOffset+SaveClientIDOffsetSize),
+	}
+	if err := v.check(); err != nil {
+		return nil, err
+	}
+	return v, nil
+}
+
+func LoadFunc(ctx context.Context, r kv.Reader, pickf func(string) bool) ([]*Arbiter, error) {
+	const MinUUID = "00000000-0000-0000-0000-000000000000"
+	const MaxUUID = "ffffffff-ffff-ffff-ffff-ffffffffffff"
+
+	begin := path.Join(DefaultKeyspace, MinUUID)
+	end := path.Join(DefaultKeyspace, MaxUUID)
+
+	it, err := r.Ascend(ctx, begin, end)
+	if err != nil {
+		return nil, err
+	}
+	defer kv.Close(it)
+
+	var arbiters []*Arbiter
+	for k, _, err := it.Fetch(ctx, false); err == nil; k, _, err = it.Fetch(ctx, true) {
+		if pickf != nil && !pickf(k) {
+			continue
+		}
+		uid := strings.TrimPrefix(k, DefaultKeyspace)
+		v, err := Load(ctx, uid, r)
+		if err != nil {
+			return nil, err
+		}
+		arbiters = append(arbiters, v)
+	}
+
+	if _, _, err := it.Fetch(ctx, false); err != nil && !errors.Is(err, io.EOF) {
+		return nil, err
+	}
+	return arbiters, nil
+}
+
+// spreadPct returns the spread percentage for buying at the ask price and
+// selling at the bid price.
+func spreadPct(ask, bid decimal.Decimal) decimal.Decimal {
+	if !ask.IsPositive() {
+		return decimal.Zero
+	}
+	return bid.Sub(ask).Div(ask).Mul(decimal.NewFromInt(100))
+}
+
+// SpreadPcts returns the spread percentages for buying on exchange A and
+// selling on exchange B and vice versa.
+func SpreadPcts(s *gobs.ArbiterSpread) (ab, ba decimal.Decimal) {
+	return spreadPct(s.AskA, s.BidB), spreadPct(s.AskB, s.BidA)
+}
+
+// lastSpreadAt returns the time of the last spread sample.
+func (v *Arbiter) lastSpreadAt() time.Time {
+	if n := len(v.state.Spreads); n > 0 {
+		return v.state.Spreads[n-1].Time
+	}
+	return time.Time{}
+}
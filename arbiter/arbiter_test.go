@@ -0,0 +1,242 @@
+// Copyright (c) 2025 BVK Chaitanya
+
+package arbiter
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/bvk/tradebot/exchange/exchangetest"
+	"github.com/bvk/tradebot/trader"
+	"github.com/bvkgo/kv"
+	"github.com/bvkgo/kv/kvmemdb"
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+)
+
+type testMessenger struct {
+	mu       sync.Mutex
+	messages []string
+}
+
+func (m *testMessenger) SendMessage(_ context.Context, _ time.Time, format string, args ...interface{}) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.messages = append(m.messages, fmt.Sprintf(format, args...))
+}
+
+func (m *testMessenger) count() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return len(m.messages)
+}
+
+type testSetup struct {
+	db   kv.Database
+	msgr *testMessenger
+	a, b *exchangetest.Product
+	rt   *trader.Runtime
+}
+
+func newTestSetup() *testSetup {
+	d := decimal.NewFromFloat
+	s := &testSetup{
+		db:   kvmemdb.New(),
+		msgr: new(testMessenger),
+		a:    exchangetest.NewProduct("exa", "BTC-USD", d(0.01), d(0.1)),
+		b:    exchangetest.NewProduct("exb", "BTCUSDT", d(0.01), d(0.1)),
+	}
+	runtime := func(p *exchangetest.Product) *trader.Runtime {
+		return &trader.Runtime{
+			Exchange:  exchangetest.NewExchange(p.ExchangeName(), p),
+			Database:  s.db,
+			Product:   p,
+			Messenger: s.msgr,
+		}
+	}
+	rtA, rtB := runtime(s.a), runtime(s.b)
+	rtA.RuntimeFor = func(ctx context.Context, exchangeName, productID string) (*trader.Runtime, error) {
+		if exchangeName != s.b.ExchangeName() || productID != s.b.ProductID() {
+			return nil, fmt.Errorf("unexpected product %s on %s", productID, exchangeName)
+		}
+		return rtB, nil
+	}
+	s.rt = rtA
+	return s
+}
+
+func (s *testSetup) newArbiter(t *testing.T, tradeSize int64) *Arbiter {
+	d := decimal.NewFromFloat
+	a := &Leg{ExchangeName: "exa", ProductID: "BTC-USD", FeePct: d(0.1)}
+	b := &Leg{ExchangeName: "exb", ProductID: "BTCUSDT", FeePct: d(0.1)}
+	v, err := New(uuid.New().String(), a, b, d(1), decimal.NewFromInt(tradeSize), 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return v
+}
+
+func (s *testSetup) run(ctx context.Context, v *Arbiter) <-chan error {
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- v.Run(ctx, s.rt)
+	}()
+	return errCh
+}
+
+func (s *testSetup) load(t *testing.T, uid string) *Arbiter {
+	var v *Arbiter
+	if err := kv.WithReader(context.Background(), s.db, func(ctx context.Context, r kv.Reader) (err error) {
+		v, err = Load(ctx, uid, r)
+		return err
+	}); err != nil {
+		t.Fatal(err)
+	}
+	return v
+}
+
+// tickUntil sets the ticker prices on both products till the condition is
+// true.
+func (s *testSetup) tickUntil(t *testing.T, priceA, priceB int64, cond func() bool) {
+	timeout := time.After(10 * time.Second)
+	for !cond() {
+		select {
+		case <-timeout:
+			t.Fatalf("timed out ticking prices %d and %d", priceA, priceB)
+		case <-time.After(5 * time.Millisecond):
+			s.a.SetPrice(decimal.NewFromInt(priceA))
+			s.b.SetPrice(decimal.NewFromInt(priceB))
+		}
+	}
+}
+
+func TestNormalize(t *testing.T) {
+	tests := []struct {
+		product     string
+		base, quote string
+	}{
+		{"BTC-USD", "BTC", "USD"},
+		{"BTC-USDC", "BTC", "USD"},
+		{"BTCUSDT", "BTC", "USD"},
+		{"ethbtc", "ETH", "BTC"},
+	}
+	for _, test := range tests {
+		if base, quote := Normalize(test.product); base != test.base || quote != test.quote {
+			t.Fatalf("%s: wanted %s-%s, got %s-%s", test.product, test.base, test.quote, base, quote)
+		}
+	}
+
+	if err := CheckProducts("BTC-USD", "BTCUSDT"); err != nil {
+		t.Fatal(err)
+	}
+	if err := CheckProducts("BTC-USD", "ETHUSDT"); err == nil {
+		t.Fatalf("wanted an error for different assets")
+	}
+}
+
+func TestSpreadMonitor(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	s := newTestSetup()
+	v := s.newArbiter(t, 0 /* tradeSize */)
+	errCh := s.run(ctx, v)
+
+	// Spread of 10% is above the fees and threshold, so it must be alerted,
+	// but must not be traded without a trade size.
+	s.tickUntil(t, 100, 110, func() bool { return s.msgr.count() > 0 })
+	cancel()
+	<-errCh
+
+	if n := s.a.OpenOrders() + s.b.OpenOrders(); n != 0 {
+		t.Fatalf("wanted no orders from the spread monitor, got %d", n)
+	}
+
+	loaded := s.load(t, v.UID())
+	spreads := loaded.Spreads()
+	if len(spreads) != 1 {
+		t.Fatalf("wanted one spread sample within the sample interval, got %d", len(spreads))
+	}
+	ab, ba := SpreadPcts(spreads[0])
+	if !ab.Equal(decimal.NewFromInt(10)) || ba.IsPositive() {
+		t.Fatalf("wanted spreads 10 and negative, got %s and %s", ab, ba)
+	}
+}
+
+func TestArbiterTrade(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	s := newTestSetup()
+	v := s.newArbiter(t, 1 /* tradeSize */)
+	errCh := s.run(ctx, v)
+
+	// Buy on exa at 100 and sell on exb at 110 must both be filled.
+	s.tickUntil(t, 100, 110, func() bool {
+		return s.a.Fills("BUY").IsPositive() && s.b.Fills("SELL").IsPositive()
+	})
+	s.tickUntil(t, 100, 110, func() bool { return s.msgr.count() >= 2 })
+	cancel()
+	<-errCh
+
+	trades := s.load(t, v.UID()).Trades()
+	if len(trades) != 1 {
+		t.Fatalf("wanted one paired trade, got %d", len(trades))
+	}
+	trade := trades[0]
+	if trade.BuyExchange != "exa" || trade.SellExchange != "exb" {
+		t.Fatalf("wanted buy on exa and sell on exb, got %s and %s", trade.BuyExchange, trade.SellExchange)
+	}
+	if !trade.Buy.FilledSize.Equal(decimal.NewFromInt(1)) || !trade.Sell.FilledSize.Equal(decimal.NewFromInt(1)) {
+		t.Fatalf("wanted both legs to be filled, got %s and %s", trade.Buy.FilledSize, trade.Sell.FilledSize)
+	}
+}
+
+func TestArbiterTradeCanceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	s := newTestSetup()
+	v := s.newArbiter(t, 1 /* tradeSize */)
+	errCh := s.run(ctx, v)
+
+	// Place the paired orders with a single tick on both products.
+	s.a.SetPrice(decimal.NewFromInt(100))
+	s.b.SetPrice(decimal.NewFromInt(110))
+	for i := 0; s.b.OpenOrders() == 0; i++ {
+		if i == 1000 {
+			t.Fatalf("paired orders are not placed in time")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	// Fill the buy leg at 100 but keep the sell leg on the book by ticking exb
+	// below the sell price.
+	s.tickUntil(t, 100, 105, func() bool { return s.a.Fills("BUY").IsPositive() })
+
+	// Canceling the job must cancel the sell leg and record both legs.
+	cancel()
+	select {
+	case <-errCh:
+	case <-time.After(10 * time.Second):
+		t.Fatalf("arbiter did not stop after cancel")
+	}
+	if n := s.b.OpenOrders(); n != 0 {
+		t.Fatalf("wanted the sell leg to be canceled, got %d open orders", n)
+	}
+
+	trades := s.load(t, v.UID()).Trades()
+	if len(trades) != 1 {
+		t.Fatalf("wanted one paired trade, got %d", len(trades))
+	}
+	trade := trades[0]
+	if trade.Buy == nil || !trade.Buy.Done || !trade.Buy.FilledSize.Equal(decimal.NewFromInt(1)) {
+		t.Fatalf("wanted the buy leg to be filled, got %v", trade.Buy)
+	}
+	if trade.Sell == nil || !trade.Sell.Done || !trade.Sell.FilledSize.IsZero() {
+		t.Fatalf("wanted the sell leg to be canceled, got %v", trade.Sell)
+	}
+}
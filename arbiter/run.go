@@ -0,0 +1,307 @@
+// Copyright (c) 2025 BVK Chaitanya
+
+package arbiter
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/bvk/tradebot/exchange"
+	"github.com/bvk/tradebot/gobs"
+	"github.com/bvk/tradebot/trader"
+	"github.com/bvkgo/kv"
+	"github.com/shopspring/decimal"
+	"github.com/visvasity/topic"
+)
+
+const (
+	SaveClientIDOffsetSize = 10
+
+	// SpreadSampleInterval is the minimum interval between the spread samples
+	// in the spread history.
+	SpreadSampleInterval = 5 * time.Minute
+
+	// MaxSpreadSamples limits the spread history to about three days. Spread
+	// history is saved with the job state, so it must be kept small.
+	MaxSpreadSamples = 3 * 24 * 12
+
+	// AlertInterval is the minimum interval between the spread alerts.
+	AlertInterval = 15 * time.Minute
+
+	// TradeInterval is the minimum interval between the paired trades.
+	TradeInterval = time.Minute
+
+	// TradeTimeout is the max time paired orders are kept on the book before
+	// they are canceled.
+	TradeTimeout = 30 * time.Second
+)
+
+// settleRetryInterval is the interval between the order status checks while
+// settling the paired orders.
+var settleRetryInterval = time.Second
+
+// quote holds the best bid and ask prices for a leg.
+type quote struct {
+	bid, ask decimal.Decimal
+}
+
+func quoteFrom(update exchange.PriceUpdate) quote {
+	if v, ok := update.(exchange.BidAskUpdate); ok {
+		if bid, ask := v.BidAsk(); bid.IsPositive() && ask.IsPositive() {
+			return quote{bid: bid, ask: ask}
+		}
+	}
+	price, _ := update.PricePoint()
+	return quote{bid: price, ask: price}
+}
+
+func (v *Arbiter) Run(ctx context.Context, rt *trader.Runtime) error {
+	v.runtimeLock.Lock()
+	defer v.runtimeLock.Unlock()
+
+	if rt.Product.ProductID() != v.state.ProductA || rt.Product.ExchangeName() != v.state.ExchangeA {
+		return fmt.Errorf("runtime product must be %s on %s", v.state.ProductA, v.state.ExchangeA)
+	}
+	if rt.RuntimeFor == nil {
+		return fmt.Errorf("arbiter job requires runtime support for multiple products")
+	}
+	rtB, err := rt.RuntimeFor(ctx, v.state.ExchangeB, v.state.ProductB)
+	if err != nil {
+		return fmt.Errorf("could not open product %s on %s: %w", v.state.ProductB, v.state.ExchangeB, err)
+	}
+	if size := v.state.TradeSize; size.IsPositive() {
+		if size.LessThan(rt.Product.BaseMinSize()) || size.LessThan(rtB.Product.BaseMinSize()) {
+			return fmt.Errorf("trade size %s is below the minimum size on one of the exchanges", size)
+		}
+	}
+
+	updatesA, err := rt.Product.GetPriceUpdates()
+	if err != nil {
+		return err
+	}
+	defer updatesA.Close()
+
+	tickerA, err := topic.ReceiveCh(updatesA)
+	if err != nil {
+		return err
+	}
+
+	updatesB, err := rtB.Product.GetPriceUpdates()
+	if err != nil {
+		return err
+	}
+	defer updatesB.Close()
+
+	tickerB, err := topic.ReceiveCh(updatesB)
+	if err != nil {
+		return err
+	}
+
+	var a, b quote
+	var lastAlertAt, nextTradeAt time.Time
+
+	dirty := 0
+	flushCh := time.After(time.Minute)
+
+	for {
+		select {
+		case <-ctx.Done():
+			if dirty > 0 {
+				if err := kv.WithReadWriter(context.Background(), rt.Database, v.Save); err != nil {
+					slog.Error("could not save arbiter state before quitting (ignored)", "arbiter", v, "err", err)
+				}
+			}
+			return context.Cause(ctx)
+
+		case <-flushCh:
+			if dirty > 0 {
+				if err := kv.WithReadWriter(ctx, rt.Database, v.Save); err != nil {
+					slog.Error("could not save arbiter state (will retry)", "arbiter", v, "err", err)
+				} else {
+					dirty = 0
+				}
+			}
+			flushCh = time.After(time.Minute)
+			continue
+
+		case update := <-tickerA:
+			a = quoteFrom(update)
+		case update := <-tickerB:
+			b = quoteFrom(update)
+		}
+
+		if !a.ask.IsPositive() || !b.ask.IsPositive() {
+			continue
+		}
+
+		now := time.Now()
+		sample := &gobs.ArbiterSpread{Time: now, BidA: a.bid, AskA: a.ask, BidB: b.bid, AskB: b.ask}
+		if now.Sub(v.lastSpreadAt()) >= SpreadSampleInterval {
+			v.state.Spreads = append(v.state.Spreads, sample)
+			if n := len(v.state.Spreads); n > MaxSpreadSamples {
+				v.state.Spreads = v.state.Spreads[n-MaxSpreadSamples:]
+			}
+			dirty++
+		}
+
+		// Pick the more profitable direction.
+		fees := v.state.FeePctA.Add(v.state.FeePctB)
+		ab, ba := SpreadPcts(sample)
+		buyRT, sellRT, buyPrice, sellPrice, net := rt, rtB, a.ask, b.bid, ab.Sub(fees)
+		if ba.GreaterThan(ab) {
+			buyRT, sellRT, buyPrice, sellPrice, net = rtB, rt, b.ask, a.bid, ba.Sub(fees)
+		}
+		if net.LessThanOrEqual(v.state.ThresholdPct) {
+			continue
+		}
+
+		buyName, sellName := buyRT.Product.ExchangeName(), sellRT.Product.ExchangeName()
+		if now.Sub(lastAlertAt) >= AlertInterval {
+			lastAlertAt = now
+			slog.Info("arbitrage spread is above the threshold", "arbiter", v, "buy-exchange", buyName, "sell-exchange", sellName, "net-spread-pct", net)
+			if rt.Messenger != nil {
+				rt.Messenger.SendMessage(ctx, now, "Arbitrage spread for %s is %s%% above fees (buy on %s at %s, sell on %s at %s).", v.state.ProductA, net.StringFixed(3), buyName, buyPrice, sellName, sellPrice)
+			}
+		}
+
+		if !v.state.TradeSize.IsPositive() || now.Before(nextTradeAt) {
+			continue
+		}
+		if v.state.MaxTrades > 0 && len(v.state.Trades) >= v.state.MaxTrades {
+			continue
+		}
+		if buyRT.IsTripped() || sellRT.IsTripped() {
+			continue
+		}
+		nextTradeAt = now.Add(TradeInterval)
+
+		trade, err := v.trade(ctx, rt, buyRT, sellRT, buyPrice, sellPrice)
+		if trade == nil {
+			slog.Error("could not place paired arbitrage orders", "arbiter", v, "err", err)
+			continue
+		}
+		trade.NetSpreadPct = net
+		v.state.Trades = append(v.state.Trades, trade)
+		if err := kv.WithReadWriter(ctx, rt.Database, v.Save); err != nil {
+			slog.Error("could not save arbiter state after a trade (will retry)", "arbiter", v, "err", err)
+			dirty++
+		}
+		if rt.Messenger != nil {
+			rt.Messenger.SendMessage(ctx, now, "Arbitrage trade for %s: bought %s on %s, sold %s on %s (%s).", v.state.ProductA, filled(trade.Buy), buyName, filled(trade.Sell), sellName, errString(err))
+		}
+	}
+}
+
+func filled(order *gobs.Order) string {
+	if order == nil {
+		return "none"
+	}
+	return fmt.Sprintf("%s at %s", order.FilledSize, order.FilledPrice.StringFixed(5))
+}
+
+func errString(err error) string {
+	if err != nil {
+		return err.Error()
+	}
+	return "ok"
+}
+
+// trade places the paired buy and sell orders and waits for them to complete.
+// Orders that are not filled before the timeout are canceled. It returns a nil
+// trade if no orders could be placed.
+func (v *Arbiter) trade(ctx context.Context, rt, buyRT, sellRT *trader.Runtime, buyPrice, sellPrice decimal.Decimal) (*gobs.ArbiterTrade, error) {
+	// Client ids must be persisted before they are used.
+	if err := kv.WithReadWriter(ctx, rt.Database, v.Save); err != nil {
+		return nil, err
+	}
+
+	size := v.state.TradeSize
+	buy, err := buyRT.Product.LimitBuy(ctx, v.idgen.NextID(), size, buyPrice)
+	if err != nil {
+		return nil, fmt.Errorf("could not create buy order on %s: %w", buyRT.Product.ExchangeName(), err)
+	}
+	trade := &gobs.ArbiterTrade{
+		Time:         time.Now(),
+		BuyExchange:  buyRT.Product.ExchangeName(),
+		SellExchange: sellRT.Product.ExchangeName(),
+	}
+
+	var sell exchange.Order
+	var sellErr error
+	sell, sellErr = sellRT.Product.LimitSell(ctx, v.idgen.NextID(), size, sellPrice)
+	if sellErr != nil {
+		sellErr = fmt.Errorf("could not create sell order on %s (buy side is unhedged): %w", sellRT.Product.ExchangeName(), sellErr)
+	}
+
+	// Both legs must always be settled, so settle errors are reported only
+	// after both orders are complete.
+	var buyErr error
+	trade.Buy, buyErr = v.settle(ctx, buyRT.Product, buy.ServerID())
+	if sell != nil {
+		trade.Sell, sellErr = v.settle(ctx, sellRT.Product, sell.ServerID())
+	}
+	if buyErr != nil {
+		return trade, buyErr
+	}
+	if sellErr != nil {
+		return trade, sellErr
+	}
+	if !trade.Buy.FilledSize.Equal(trade.Sell.FilledSize) {
+		return trade, fmt.Errorf("paired orders are unbalanced")
+	}
+	return trade, nil
+}
+
+// settle waits for an order to complete till the trade timeout and cancels it
+// if necessary. Order is canceled immediately if the input context is
+// canceled, but settle still waits for the order to complete. It returns the
+// final order state.
+func (v *Arbiter) settle(ctx context.Context, product exchange.Product, serverID string) (*gobs.Order, error) {
+	// Order must be tracked till it's complete even if ctx is canceled.
+	bg := context.WithoutCancel(ctx)
+
+	deadline := time.Now().Add(TradeTimeout)
+	canceled := false
+	for {
+		detail, err := product.Get(bg, serverID)
+		if err == nil && detail.IsDone() {
+			order, err := exchange.NewSimpleOrderFromOrderDetail(detail)
+			if err != nil {
+				return nil, err
+			}
+			return &gobs.Order{
+				ServerOrderID: order.ServerOrderID,
+				ClientOrderID: order.ClientUUID.String(),
+				CreateTime:    order.CreateTime,
+				FinishTime:    order.FinishTime,
+				Side:          order.Side,
+				Status:        order.Status,
+				FilledFee:     order.Fee,
+				FilledSize:    order.FilledSize,
+				FilledPrice:   order.FilledPrice,
+				Done:          order.Done,
+				DoneReason:    order.DoneReason,
+			}, nil
+		}
+		if err != nil {
+			slog.Warn("could not fetch arbitrage order (will retry)", "arbiter", v, "order-id", serverID, "err", err)
+		}
+		if !canceled && (ctx.Err() != nil || time.Now().After(deadline)) {
+			if err := product.Cancel(bg, serverID); err != nil {
+				slog.Error("could not cancel arbitrage order (will retry)", "arbiter", v, "order-id", serverID, "err", err)
+			} else {
+				canceled = true
+			}
+		}
+		if ctx.Err() != nil {
+			time.Sleep(settleRetryInterval)
+			continue
+		}
+		select {
+		case <-ctx.Done():
+		case <-time.After(settleRetryInterval):
+		}
+	}
+}
@@ -0,0 +1,121 @@
+// Copyright (c) 2025 BVK Chaitanya
+
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/bvk/tradebot/gobs"
+	"github.com/bvkgo/kv"
+)
+
+// errUnauthenticated is returned when a request doesn't carry a valid token.
+var errUnauthenticated = errors.New("unauthenticated")
+
+type Options struct {
+	// AuthLoopback when true requires tokens for the requests from loopback
+	// addresses too. Loopback requests are trusted by default, so that local
+	// tools work without tokens and the first admin token can be created.
+	AuthLoopback bool
+
+	// RequireClientCert when true rejects the requests that are not made over
+	// TLS with a verified client certificate.
+	RequireClientCert bool
+}
+
+// Authenticator checks API tokens in the http requests.
+type Authenticator struct {
+	db   kv.Database
+	opts Options
+}
+
+// New creates an authenticator that verifies tokens from the input database.
+func New(db kv.Database, opts *Options) *Authenticator {
+	if opts == nil {
+		opts = new(Options)
+	}
+	return &Authenticator{db: db, opts: *opts}
+}
+
+// BearerToken returns the token from the http authorization header.
+func BearerToken(r *http.Request) string {
+	v := r.Header.Get("authorization")
+	if prefix := "bearer "; len(v) > len(prefix) && strings.EqualFold(v[:len(prefix)], prefix) {
+		return strings.TrimSpace(v[len(prefix):])
+	}
+	return ""
+}
+
+func isLoopback(r *http.Request) bool {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return false
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
+// Check verifies that the request is allowed to perform operations that
+// require the input scope. It returns a nil token when the request is trusted
+// without a token.
+func (a *Authenticator) Check(ctx context.Context, r *http.Request, scope Scope) (*gobs.APIToken, error) {
+	if a.opts.RequireClientCert {
+		if r.TLS == nil || len(r.TLS.VerifiedChains) == 0 {
+			return nil, fmt.Errorf("verified client certificate is required: %w", os.ErrPermission)
+		}
+	}
+
+	token := BearerToken(r)
+	if len(token) == 0 {
+		if !a.opts.AuthLoopback && isLoopback(r) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("api token is required: %w: %w", errUnauthenticated, os.ErrPermission)
+	}
+
+	var t *gobs.APIToken
+	verify := func(ctx context.Context, r kv.Reader) (err error) {
+		t, err = Verify(ctx, r, token, time.Now())
+		return err
+	}
+	if err := kv.WithReader(ctx, a.db, verify); err != nil {
+		if errors.Is(err, os.ErrPermission) {
+			return nil, fmt.Errorf("%w: %w", errUnauthenticated, err)
+		}
+		return nil, err
+	}
+	if !Scope(t.Scope).Allows(scope) {
+		return nil, fmt.Errorf("token %q with scope %q cannot perform %q operations: %w", t.ID, t.Scope, scope, os.ErrPermission)
+	}
+	return t, nil
+}
+
+// Handler returns a http handler that invokes the next handler only if the
+// request is allowed to perform operations that require the input scope.
+func (a *Authenticator) Handler(scope Scope, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if _, err := a.Check(r.Context(), r, scope); err != nil {
+			slog.Warn("rejected unauthorized api request", "path", r.URL.Path, "remote", r.RemoteAddr, "err", err)
+			if !errors.Is(err, os.ErrPermission) {
+				http.Error(w, "could not verify api token", http.StatusInternalServerError)
+				return
+			}
+			if errors.Is(err, errUnauthenticated) {
+				w.Header().Set("www-authenticate", "Bearer")
+				http.Error(w, err.Error(), http.StatusUnauthorized)
+				return
+			}
+			http.Error(w, err.Error(), http.StatusForbidden)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
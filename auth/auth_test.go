@@ -0,0 +1,89 @@
+// Copyright (c) 2025 BVK Chaitanya
+
+package auth
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/bvkgo/kv"
+	"github.com/bvkgo/kv/kvmemdb"
+)
+
+func TestScopeAllows(t *testing.T) {
+	if !Admin.Allows(ReadOnly) || !Admin.Allows(Trade) || !Trade.Allows(ReadOnly) {
+		t.Fatalf("higher scopes must allow lower scope operations")
+	}
+	if ReadOnly.Allows(Trade) || Trade.Allows(Admin) {
+		t.Fatalf("lower scopes must not allow higher scope operations")
+	}
+	if Scope("bad").Allows(ReadOnly) || Admin.Allows(Scope("bad")) {
+		t.Fatalf("unknown scopes must not be allowed")
+	}
+}
+
+func TestTokens(t *testing.T) {
+	ctx := context.Background()
+	db := kvmemdb.New()
+
+	var token, expired string
+	if err := kv.WithReadWriter(ctx, db, func(ctx context.Context, rw kv.ReadWriter) (err error) {
+		if token, _, err = Create(ctx, rw, "ci", Trade, time.Time{}); err != nil {
+			return err
+		}
+		expired, _, err = Create(ctx, rw, "old", Admin, time.Now().Add(-time.Minute))
+		return err
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := kv.WithReader(ctx, db, func(ctx context.Context, r kv.Reader) error {
+		if v, err := Verify(ctx, r, token, time.Now()); err != nil {
+			return err
+		} else if v.Name != "ci" || v.Scope != string(Trade) {
+			t.Fatalf("wanted ci token with trade scope, got %s/%s", v.Name, v.Scope)
+		}
+		if _, err := Verify(ctx, r, token+"x", time.Now()); !errors.Is(err, os.ErrPermission) {
+			t.Fatalf("wanted ErrPermission for bad secret, got %v", err)
+		}
+		if _, err := Verify(ctx, r, expired, time.Now()); !errors.Is(err, os.ErrPermission) {
+			t.Fatalf("wanted ErrPermission for expired token, got %v", err)
+		}
+		return nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	a := New(db, &Options{AuthLoopback: true})
+	handler := a.Handler(Admin, http.HandlerFunc(func(http.ResponseWriter, *http.Request) {}))
+	serve := func(token string) int {
+		r := httptest.NewRequest(http.MethodPost, "/db/", nil)
+		if len(token) > 0 {
+			r.Header.Set("authorization", "Bearer "+token)
+		}
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, r)
+		return w.Code
+	}
+	if code := serve(""); code != http.StatusUnauthorized {
+		t.Fatalf("wanted %d without token, got %d", http.StatusUnauthorized, code)
+	}
+	if code := serve(token); code != http.StatusForbidden {
+		t.Fatalf("wanted %d for trade token on admin path, got %d", http.StatusForbidden, code)
+	}
+
+	if err := kv.WithReadWriter(ctx, db, func(ctx context.Context, rw kv.ReadWriter) error {
+		id := token[:16]
+		return Revoke(ctx, rw, id)
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if code := serve(token); code != http.StatusUnauthorized {
+		t.Fatalf("wanted %d for revoked token, got %d", http.StatusUnauthorized, code)
+	}
+}
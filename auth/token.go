@@ -0,0 +1,151 @@
+// Copyright (c) 2025 BVK Chaitanya
+
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/bvk/tradebot/gobs"
+	"github.com/bvk/tradebot/kvutil"
+	"github.com/bvkgo/kv"
+)
+
+// Keyspace holds the API tokens keyed by the token ids.
+const Keyspace = "/api-tokens/"
+
+// Scope determines the API operations allowed for a token. Scopes are ordered
+// so that a scope allows all operations of the lower scopes.
+type Scope string
+
+const (
+	// ReadOnly scope allows the queries that do not modify the trader state.
+	ReadOnly Scope = "read-only"
+
+	// Trade scope allows creating and controlling the trading jobs.
+	Trade Scope = "trade"
+
+	// Admin scope allows all operations including the raw database access,
+	// configuration changes and token management.
+	Admin Scope = "admin"
+)
+
+// Scopes holds all scopes in the increasing order of privileges.
+var Scopes = []Scope{ReadOnly, Trade, Admin}
+
+// ParseScope returns the scope with the input name.
+func ParseScope(s string) (Scope, error) {
+	v := Scope(strings.ToLower(strings.TrimSpace(s)))
+	if !slices.Contains(Scopes, v) {
+		return "", fmt.Errorf("invalid scope %q: %w", s, os.ErrInvalid)
+	}
+	return v, nil
+}
+
+// Allows returns true if the scope permits operations that require the input
+// scope.
+func (s Scope) Allows(want Scope) bool {
+	have, need := slices.Index(Scopes, s), slices.Index(Scopes, want)
+	return have >= 0 && need >= 0 && have >= need
+}
+
+// Create adds a new token with the input name and scope within the input
+// transaction and returns the token string. Token string is not saved in the
+// database, so it must be recorded by the caller. Zero expiresAt value creates
+// a token that never expires.
+func Create(ctx context.Context, rw kv.ReadWriter, name string, scope Scope, expiresAt time.Time) (string, *gobs.APIToken, error) {
+	if !slices.Contains(Scopes, scope) {
+		return "", nil, fmt.Errorf("invalid scope %q: %w", scope, os.ErrInvalid)
+	}
+
+	idBytes := make([]byte, 8)
+	if _, err := rand.Read(idBytes); err != nil {
+		return "", nil, fmt.Errorf("could not generate token id: %w", err)
+	}
+	secretBytes := make([]byte, 32)
+	if _, err := rand.Read(secretBytes); err != nil {
+		return "", nil, fmt.Errorf("could not generate token secret: %w", err)
+	}
+	id := hex.EncodeToString(idBytes)
+	secret := base64.RawURLEncoding.EncodeToString(secretBytes)
+	hash := sha256.Sum256([]byte(secret))
+
+	t := &gobs.APIToken{
+		ID:         id,
+		Name:       name,
+		Scope:      string(scope),
+		SecretHash: hash[:],
+		CreatedAt:  time.Now(),
+		ExpiresAt:  expiresAt,
+	}
+	key := path.Join(Keyspace, id)
+	if err := kvutil.Set(ctx, rw, key, t); err != nil {
+		return "", nil, fmt.Errorf("could not save token %q: %w", id, err)
+	}
+	return id + "." + secret, t, nil
+}
+
+// List returns all tokens in the database.
+func List(ctx context.Context, r kv.Reader) ([]*gobs.APIToken, error) {
+	var tokens []*gobs.APIToken
+	collect := func(ctx context.Context, r kv.Reader, key string, value *gobs.APIToken) error {
+		tokens = append(tokens, value)
+		return nil
+	}
+	begin, end := kvutil.PathRange(Keyspace)
+	if err := kvutil.Ascend(ctx, r, begin, end, collect); err != nil {
+		return nil, fmt.Errorf("could not scan tokens: %w", err)
+	}
+	return tokens, nil
+}
+
+// Revoke removes a token from the database within the input transaction.
+func Revoke(ctx context.Context, rw kv.ReadWriter, id string) error {
+	key := path.Join(Keyspace, id)
+	if _, err := kvutil.Get[gobs.APIToken](ctx, rw, key); err != nil {
+		return fmt.Errorf("could not read token %q: %w", id, err)
+	}
+	if err := rw.Delete(ctx, key); err != nil {
+		return fmt.Errorf("could not delete token %q: %w", id, err)
+	}
+	return nil
+}
+
+// Verify returns the token data for a valid, unexpired token string.
+func Verify(ctx context.Context, r kv.Reader, token string, now time.Time) (*gobs.APIToken, error) {
+	id, secret, ok := strings.Cut(token, ".")
+	if !ok || len(id) == 0 || len(secret) == 0 {
+		return nil, fmt.Errorf("malformed token: %w", os.ErrPermission)
+	}
+	if _, err := hex.DecodeString(id); err != nil {
+		return nil, fmt.Errorf("malformed token: %w", os.ErrPermission)
+	}
+
+	key := path.Join(Keyspace, id)
+	t, err := kvutil.Get[gobs.APIToken](ctx, r, key)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, fmt.Errorf("unknown token: %w", os.ErrPermission)
+		}
+		return nil, fmt.Errorf("could not read token %q: %w", id, err)
+	}
+	hash := sha256.Sum256([]byte(secret))
+	if subtle.ConstantTimeCompare(hash[:], t.SecretHash) != 1 {
+		return nil, fmt.Errorf("invalid token: %w", os.ErrPermission)
+	}
+	if !t.ExpiresAt.IsZero() && !now.Before(t.ExpiresAt) {
+		return nil, fmt.Errorf("token %q has expired: %w", id, os.ErrPermission)
+	}
+	return t, nil
+}
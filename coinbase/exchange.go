@@ -313,6 +313,7 @@ func (ex *Exchange) goRunBackgroundTasks(ctx context.Context) {
 				ServerTime:  stime,
 				Symbol:      a.Currency,
 				FreeBalance: newAvail,
+				Hold:        newHold,
 			}
 			ex.balanceUpdatesTopic.Send(bupdate)
 		}
@@ -76,6 +76,10 @@ func (p *Product) BaseMinSize() decimal.Decimal {
 	return p.productData.BaseMinSize.Decimal
 }
 
+func (p *Product) BaseIncrement() decimal.Decimal {
+	return p.productData.BaseIncrement.Decimal
+}
+
 func (p *Product) GetPriceUpdates() (*topic.Receiver[exchange.PriceUpdate], error) {
 	convert := func(v *advanced.TickerEvent) exchange.PriceUpdate { return v }
 	return topic.SubscribeFunc(p.prodTickerTopic, convert, 1, true /* includeLast */)
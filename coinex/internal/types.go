@@ -256,10 +256,18 @@ func (v *BalanceUpdate) Balance() (string, decimal.Decimal) {
 	return v.Currency, v.Available
 }
 
+func (v *BalanceUpdate) HoldBalance() decimal.Decimal {
+	return v.Frozen
+}
+
 var _ exchange.PriceUpdate = &BBOUpdate{}
 
 var d2 = decimal.NewFromInt(2)
 
+func (v *BBOUpdate) BidAsk() (decimal.Decimal, decimal.Decimal) {
+	return v.BestBidPrice, v.BestAskPrice
+}
+
 func (v *BBOUpdate) PricePoint() (decimal.Decimal, gobs.RemoteTime) {
 	price := v.BestBidPrice.Add(v.BestAskPrice).Div(d2)
 	return price, gobs.RemoteTime{Time: time.UnixMilli(v.UpdatedAt)}
@@ -158,6 +158,10 @@ func (p *Product) BaseMinSize() decimal.Decimal {
 	return p.mstatus.MinAmount
 }
 
+func (p *Product) BaseIncrement() decimal.Decimal {
+	return decimal.New(1, -int32(p.mstatus.BasePrecision))
+}
+
 func (p *Product) GetOrderUpdates() (*topic.Receiver[exchange.OrderUpdate], error) {
 	fn := func(x *internal.Order) exchange.OrderUpdate { return x }
 	return topic.SubscribeFunc(p.client.getMarketOrdersTopic(p.market), fn, 0, true)
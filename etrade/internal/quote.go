@@ -94,6 +94,11 @@ func NewQuoteFromAPI(a *APIQuoteData) *Quote {
 	}
 }
 
+// BidAsk returns the current best bid and ask prices.
+func (q *Quote) BidAsk() (decimal.Decimal, decimal.Decimal) {
+	return q.Bid, q.Ask
+}
+
 // PricePoint returns the mid-price of the current best bid and ask, which is
 // the most accurate real-time price signal for limit order placement. Falls
 // back to lastTrade if bid or ask is zero (e.g. outside market hours).
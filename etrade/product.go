@@ -329,6 +329,12 @@ func (p *Product) BaseMinSize() decimal.Decimal {
 	return decimal.NewFromInt(1)
 }
 
+// BaseIncrement returns the order size increment for US equity symbols: 1
+// share.
+func (p *Product) BaseIncrement() decimal.Decimal {
+	return decimal.NewFromInt(1)
+}
+
 func (p *Product) GetOrderUpdates() (*topic.Receiver[exchange.OrderUpdate], error) {
 	fn := func(o *internal.Order) exchange.OrderUpdate {
 		if o.ClientUUID != uuid.Nil {
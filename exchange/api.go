@@ -46,16 +46,29 @@ type PriceUpdate interface {
 	PricePoint() (decimal.Decimal, gobs.RemoteTime)
 }
 
+// BidAskUpdate is an optional interface for the price updates that also carry
+// the best bid and ask prices.
+type BidAskUpdate interface {
+	BidAsk() (bid, ask decimal.Decimal)
+}
+
 type BalanceUpdate interface {
 	Balance() (string, decimal.Decimal)
 }
 
+// HoldBalanceUpdate is an optional interface for the balance updates that also
+// carry the balance held for the open orders.
+type HoldBalanceUpdate interface {
+	HoldBalance() decimal.Decimal
+}
+
 type Product interface {
 	io.Closer
 
 	ProductID() string
 	ExchangeName() string
 	BaseMinSize() decimal.Decimal
+	BaseIncrement() decimal.Decimal
 
 	GetPriceUpdates() (*topic.Receiver[PriceUpdate], error)
 	GetOrderUpdates() (*topic.Receiver[OrderUpdate], error)
@@ -0,0 +1,359 @@
+// Copyright (c) 2025 BVK Chaitanya
+
+// Package exchangetest implements an in-memory exchange product for testing
+// the trader jobs. Limit orders are filled completely at their limit price
+// when the ticker price set by the tests crosses the limit price.
+package exchangetest
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/bvk/tradebot/exchange"
+	"github.com/bvk/tradebot/gobs"
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"github.com/visvasity/topic"
+)
+
+type Product struct {
+	exchangeName string
+	productID    string
+
+	minSize   decimal.Decimal
+	increment decimal.Decimal
+	feePct    decimal.Decimal
+
+	priceTopic *topic.Topic[exchange.PriceUpdate]
+	orderTopic *topic.Topic[exchange.OrderUpdate]
+
+	mu sync.Mutex
+
+	price decimal.Decimal
+
+	nextID int
+
+	orders map[string]*exchange.SimpleOrder
+
+	// sizes and prices hold the requested size and limit price of the orders.
+	sizes  map[string]decimal.Decimal
+	prices map[string]decimal.Decimal
+}
+
+var _ exchange.Product = &Product{}
+
+// NewProduct creates a test product with the input minimum order size, which
+// is also used as the order size increment. Fee percentage is charged on the
+// filled value of every order.
+func NewProduct(exchangeName, productID string, minSize, feePct decimal.Decimal) *Product {
+	return &Product{
+		exchangeName: exchangeName,
+		productID:    productID,
+		minSize:      minSize,
+		increment:    minSize,
+		feePct:       feePct,
+		priceTopic:   topic.New[exchange.PriceUpdate](),
+		orderTopic:   topic.New[exchange.OrderUpdate](),
+		orders:       make(map[string]*exchange.SimpleOrder),
+		sizes:        make(map[string]decimal.Decimal),
+		prices:       make(map[string]decimal.Decimal),
+	}
+}
+
+func (p *Product) Close() error {
+	p.priceTopic.Close()
+	p.orderTopic.Close()
+	return nil
+}
+
+func (p *Product) ProductID() string {
+	return p.productID
+}
+
+func (p *Product) ExchangeName() string {
+	return p.exchangeName
+}
+
+func (p *Product) BaseMinSize() decimal.Decimal {
+	return p.minSize
+}
+
+func (p *Product) BaseIncrement() decimal.Decimal {
+	return p.increment
+}
+
+// SetBaseIncrement updates the order size increment. It must be called before
+// the product is used.
+func (p *Product) SetBaseIncrement(increment decimal.Decimal) {
+	p.increment = increment
+}
+
+func (p *Product) GetPriceUpdates() (*topic.Receiver[exchange.PriceUpdate], error) {
+	return topic.Subscribe(p.priceTopic, 1, true /* includeLast */)
+}
+
+func (p *Product) GetOrderUpdates() (*topic.Receiver[exchange.OrderUpdate], error) {
+	return topic.Subscribe(p.orderTopic, 0, false /* includeLast */)
+}
+
+// SetPrice updates the ticker price and fills all open orders that are
+// crossed by the new price.
+func (p *Product) SetPrice(price decimal.Decimal) {
+	p.mu.Lock()
+	p.price = price
+	now := time.Now()
+	var updates []*exchange.SimpleOrder
+	for id, order := range p.orders {
+		if order.Done {
+			continue
+		}
+		limit := p.prices[id]
+		if order.Side == "BUY" && price.GreaterThan(limit) {
+			continue
+		}
+		if order.Side == "SELL" && price.LessThan(limit) {
+			continue
+		}
+		size := p.sizes[id]
+		order.FilledSize = size
+		order.FilledPrice = limit
+		order.Fee = size.Mul(limit).Mul(p.feePct).Div(decimal.NewFromInt(100))
+		order.FinishTime = gobs.RemoteTime{Time: now}
+		order.Status = "FILLED"
+		order.Done = true
+		updates = append(updates, clone(order))
+	}
+	p.mu.Unlock()
+
+	for _, u := range updates {
+		p.orderTopic.Send(u)
+	}
+	p.priceTopic.Send(&exchange.SimpleTicker{
+		ServerTime: exchange.RemoteTime{Time: now},
+		Price:      price,
+	})
+}
+
+// PartialFill fills the open orders with the input side partially to the
+// input filled size at their limit prices. Orders remain open.
+func (p *Product) PartialFill(side string, size decimal.Decimal) {
+	p.mu.Lock()
+	var updates []*exchange.SimpleOrder
+	for id, order := range p.orders {
+		if order.Done || !strings.EqualFold(order.Side, side) || !size.LessThan(p.sizes[id]) {
+			continue
+		}
+		limit := p.prices[id]
+		order.FilledSize = size
+		order.FilledPrice = limit
+		order.Fee = size.Mul(limit).Mul(p.feePct).Div(decimal.NewFromInt(100))
+		updates = append(updates, clone(order))
+	}
+	p.mu.Unlock()
+
+	for _, u := range updates {
+		p.orderTopic.Send(u)
+	}
+}
+
+// Price returns the last ticker price.
+func (p *Product) Price() decimal.Decimal {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	return p.price
+}
+
+// OpenOrders returns the number of orders that are not done yet.
+func (p *Product) OpenOrders() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	n := 0
+	for _, order := range p.orders {
+		if !order.Done {
+			n++
+		}
+	}
+	return n
+}
+
+// Fills returns the total filled size of the orders with the input side.
+func (p *Product) Fills(side string) decimal.Decimal {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	var sum decimal.Decimal
+	for _, order := range p.orders {
+		if strings.EqualFold(order.Side, side) {
+			sum = sum.Add(order.FilledSize)
+		}
+	}
+	return sum
+}
+
+// Sizes returns the requested sizes of all orders with the input side in the
+// order of their creation.
+func (p *Product) Sizes(side string) []decimal.Decimal {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	var sizes []decimal.Decimal
+	for i := 1; i <= p.nextID; i++ {
+		id := fmt.Sprintf("order-%d", i)
+		if order, ok := p.orders[id]; ok && strings.EqualFold(order.Side, side) {
+			sizes = append(sizes, p.sizes[id])
+		}
+	}
+	return sizes
+}
+
+// Prices returns the limit prices of all orders with the input side in the
+// order of their creation.
+func (p *Product) Prices(side string) []decimal.Decimal {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	var prices []decimal.Decimal
+	for i := 1; i <= p.nextID; i++ {
+		id := fmt.Sprintf("order-%d", i)
+		if order, ok := p.orders[id]; ok && strings.EqualFold(order.Side, side) {
+			prices = append(prices, p.prices[id])
+		}
+	}
+	return prices
+}
+
+func (p *Product) LimitBuy(ctx context.Context, clientID uuid.UUID, size, price decimal.Decimal) (exchange.Order, error) {
+	return p.limit(clientID, "BUY", size, price)
+}
+
+func (p *Product) LimitSell(ctx context.Context, clientID uuid.UUID, size, price decimal.Decimal) (exchange.Order, error) {
+	return p.limit(clientID, "SELL", size, price)
+}
+
+func (p *Product) limit(clientID uuid.UUID, side string, size, price decimal.Decimal) (exchange.Order, error) {
+	if size.LessThan(p.minSize) {
+		return nil, fmt.Errorf("order size %s is below the min size %s: %w", size, p.minSize, os.ErrInvalid)
+	}
+	if p.increment.IsPositive() && !size.Mod(p.increment).IsZero() {
+		return nil, fmt.Errorf("order size %s is not a multiple of the size increment %s: %w", size, p.increment, os.ErrInvalid)
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.nextID++
+	id := fmt.Sprintf("order-%d", p.nextID)
+	order, err := exchange.NewSimpleOrder(id, clientID, side)
+	if err != nil {
+		return nil, err
+	}
+	order.CreateTime = gobs.RemoteTime{Time: time.Now()}
+	order.Status = "OPEN"
+	p.orders[id] = order
+	p.sizes[id] = size
+	p.prices[id] = price
+	return clone(order), nil
+}
+
+func (p *Product) Get(ctx context.Context, serverID string) (exchange.OrderDetail, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	order, ok := p.orders[serverID]
+	if !ok {
+		return nil, fmt.Errorf("order %q not found: %w", serverID, os.ErrNotExist)
+	}
+	return clone(order), nil
+}
+
+func (p *Product) Cancel(ctx context.Context, serverID string) error {
+	p.mu.Lock()
+	order, ok := p.orders[serverID]
+	if !ok {
+		p.mu.Unlock()
+		return fmt.Errorf("order %q not found: %w", serverID, os.ErrNotExist)
+	}
+	if order.Done {
+		p.mu.Unlock()
+		return nil
+	}
+	order.FinishTime = gobs.RemoteTime{Time: time.Now()}
+	order.Status = "CANCELLED"
+	order.Done = true
+	order.DoneReason = "CANCELLED"
+	update := clone(order)
+	p.mu.Unlock()
+
+	p.orderTopic.Send(update)
+	return nil
+}
+
+func clone(order *exchange.SimpleOrder) *exchange.SimpleOrder {
+	v := *order
+	return &v
+}
+
+// Exchange is an in-memory exchange that serves the test products.
+type Exchange struct {
+	name string
+
+	products map[string]*Product
+}
+
+var _ exchange.Exchange = &Exchange{}
+
+// NewExchange creates a test exchange with the input products.
+func NewExchange(name string, products ...*Product) *Exchange {
+	v := &Exchange{name: name, products: make(map[string]*Product)}
+	for _, p := range products {
+		v.products[p.ProductID()] = p
+	}
+	return v
+}
+
+func (v *Exchange) Close() error {
+	return nil
+}
+
+func (v *Exchange) ExchangeName() string {
+	return v.name
+}
+
+func (v *Exchange) GetBalanceUpdates() (*topic.Receiver[exchange.BalanceUpdate], error) {
+	return topic.Subscribe(topic.New[exchange.BalanceUpdate](), 1, false)
+}
+
+func (v *Exchange) CanDedupOnClientUUID() bool {
+	return false
+}
+
+func (v *Exchange) OpenSpotProduct(ctx context.Context, productID string) (exchange.Product, error) {
+	p, ok := v.products[productID]
+	if !ok {
+		return nil, fmt.Errorf("product %q not found: %w", productID, os.ErrNotExist)
+	}
+	return p, nil
+}
+
+func (v *Exchange) GetSpotProduct(ctx context.Context, base, quote string) (*gobs.Product, error) {
+	productID := base + "-" + quote
+	if _, ok := v.products[productID]; !ok {
+		return nil, fmt.Errorf("product %q not found: %w", productID, os.ErrNotExist)
+	}
+	return &gobs.Product{ProductID: productID, BaseCurrencyID: base, QuoteCurrencyID: quote}, nil
+}
+
+func (v *Exchange) GetOrder(ctx context.Context, productID string, serverID string) (exchange.OrderDetail, error) {
+	for _, p := range v.products {
+		if d, err := p.Get(ctx, serverID); err == nil {
+			return d, nil
+		}
+	}
+	return nil, fmt.Errorf("order %q not found: %w", serverID, os.ErrNotExist)
+}
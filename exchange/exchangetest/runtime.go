@@ -0,0 +1,74 @@
+// Copyright (c) 2025 BVK Chaitanya
+
+package exchangetest
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/bvk/tradebot/trader"
+	"github.com/bvkgo/kv/kvmemdb"
+	"github.com/shopspring/decimal"
+)
+
+type discardMessenger struct{}
+
+func (discardMessenger) SendMessage(context.Context, time.Time, string, ...interface{}) {}
+
+// NewRuntime returns a trader runtime for the product with an in-memory
+// database. Messages sent by the jobs are discarded.
+func NewRuntime(p *Product) *trader.Runtime {
+	return &trader.Runtime{
+		Exchange:  NewExchange(p.ExchangeName(), p),
+		Database:  kvmemdb.New(),
+		Product:   p,
+		Messenger: discardMessenger{},
+	}
+}
+
+// Start runs the job in the background and returns a channel that receives
+// the job's return value.
+func Start(ctx context.Context, job trader.Trader, rt *trader.Runtime) <-chan error {
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- job.Run(ctx, rt)
+	}()
+	return errCh
+}
+
+// TickUntil sets the ticker prices in a round-robin order till the condition
+// is true. Test is failed if the condition is not true within ten seconds.
+func TickUntil(t testing.TB, p *Product, prices []int64, cond func() bool) {
+	t.Helper()
+
+	timeout := time.After(10 * time.Second)
+	for i := 0; !cond(); i++ {
+		select {
+		case <-timeout:
+			t.Fatalf("timed out ticking prices %v", prices)
+		case <-time.After(5 * time.Millisecond):
+			p.SetPrice(decimal.NewFromInt(prices[i%len(prices)]))
+		}
+	}
+}
+
+// TickUntilDone sets the ticker prices in a round-robin order till the job
+// started with Start returns. Test is failed if the job returns a non-nil
+// error.
+func TickUntilDone(t testing.TB, p *Product, prices []int64, errCh <-chan error) {
+	t.Helper()
+
+	var status error
+	TickUntil(t, p, prices, func() bool {
+		select {
+		case status = <-errCh:
+			return true
+		default:
+			return false
+		}
+	})
+	if status != nil {
+		t.Fatalf("wanted job to complete, got %v", status)
+	}
+}
@@ -10,8 +10,15 @@ type SimpleBalance struct {
 	ServerTime  RemoteTime
 	Symbol      string
 	FreeBalance decimal.Decimal
+
+	// Hold is the balance held for the open orders.
+	Hold decimal.Decimal
 }
 
 func (v *SimpleBalance) Balance() (string, decimal.Decimal) {
 	return v.Symbol, v.FreeBalance
 }
+
+func (v *SimpleBalance) HoldBalance() decimal.Decimal {
+	return v.Hold
+}
@@ -0,0 +1,59 @@
+// Copyright (c) 2025 BVK Chaitanya
+
+package gobs
+
+import (
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+type ArbiterState struct {
+	ExchangeA string
+	ProductA  string
+	FeePctA   decimal.Decimal
+
+	ExchangeB string
+	ProductB  string
+	FeePctB   decimal.Decimal
+
+	// ThresholdPct is the minimum spread percentage above the fees for alerts
+	// and trades.
+	ThresholdPct decimal.Decimal
+
+	// TradeSize is the size for the paired buy and sell orders. Zero value
+	// disables the trades, i.e., job only monitors the spread.
+	TradeSize decimal.Decimal
+
+	// MaxTrades is the max number of paired trades. Zero value means no limit.
+	MaxTrades int
+
+	ClientIDSeed   string
+	ClientIDOffset uint64
+
+	Spreads []*ArbiterSpread
+	Trades  []*ArbiterTrade
+
+	LifetimeSummary *Summary
+}
+
+// ArbiterSpread is a sample of best bid and ask prices on both exchanges.
+type ArbiterSpread struct {
+	Time time.Time
+
+	BidA, AskA decimal.Decimal
+	BidB, AskB decimal.Decimal
+}
+
+// ArbiterTrade is a pair of buy and sell orders placed on different exchanges.
+type ArbiterTrade struct {
+	Time time.Time
+
+	BuyExchange  string
+	SellExchange string
+
+	NetSpreadPct decimal.Decimal
+
+	Buy  *Order
+	Sell *Order
+}
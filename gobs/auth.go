@@ -0,0 +1,21 @@
+// Copyright (c) 2025 BVK Chaitanya
+
+package gobs
+
+import (
+	"time"
+)
+
+// APIToken holds an access token for the HTTP API. Only the SHA-256 hash of
+// the token secret is saved, so tokens cannot be recovered from the database.
+// Zero ExpiresAt value indicates that token never expires.
+type APIToken struct {
+	ID    string
+	Name  string
+	Scope string
+
+	SecretHash []byte
+
+	CreatedAt time.Time
+	ExpiresAt time.Time
+}
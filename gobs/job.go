@@ -2,6 +2,12 @@
 
 package gobs
 
+import (
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
 type State string
 
 const (
@@ -32,8 +38,9 @@ type JobExportData struct {
 	Name     string
 	Typename string
 
-	JobFlags uint64
-	JobState State
+	JobFlags  uint64
+	JobState  State
+	JobLabels map[string]string
 
 	KeyValues []*KeyValue
 }
@@ -44,4 +51,107 @@ type JobData struct {
 	Flags    uint64
 
 	State State
+
+	// Trigger is non-nil if job must wait for a price condition before it can
+	// begin trading.
+	Trigger *JobTrigger
+
+	// FollowUp is non-nil if a new job must be created when this job is
+	// completed successfully.
+	FollowUp *JobFollowUp
+
+	// RestartPolicy if non-nil overrides the default restart policy for the
+	// job type.
+	RestartPolicy *JobRestartPolicy
+
+	// RestartAttempts holds the times of automatic restart attempts within
+	// the restart policy window.
+	RestartAttempts []time.Time
+
+	// RestartAt is non-zero when a failed job is waiting to be restarted
+	// automatically.
+	RestartAt time.Time
+
+	// Labels holds user-defined key-value pairs for selecting jobs in bulk
+	// operations.
+	Labels map[string]string
+}
+
+// JobRestartPolicy determines if and when a failed job is restarted
+// automatically. Mode must be one of "never" or "on-failure". Restarts are
+// delayed with an exponential backoff starting from Backoff and limited by
+// MaxBackoff. Job is left in FAILED state when MaxAttempts restarts are
+// already performed within the Window duration.
+type JobRestartPolicy struct {
+	Mode string
+
+	MaxAttempts int
+	Window      time.Duration
+
+	Backoff    time.Duration
+	MaxBackoff time.Duration
+}
+
+// JobFollowUp declares a job to be created when it's parent job completes
+// successfully. Zero sizes in the buy/sell points are replaced with the base
+// size filled by the parent job. ChildID is set to the follow-up job's id when
+// it is created.
+type JobFollowUp struct {
+	// Typename is the follow-up job type, which is one of Limiter or Looper.
+	Typename string
+
+	Buy  *Point
+	Sell *Point
+
+	ChildID string
+
+	// Error holds the reason when follow-up job could not be created.
+	Error string
+}
+
+// JobTrigger holds a price condition for a job. Job is armed till the trigger
+// fires, which is recorded in the FiredAt field.
+type JobTrigger struct {
+	Kind   string
+	Price  decimal.Decimal
+	Window time.Duration
+
+	FiredAt time.Time
+}
+
+// IsArmed returns true if job has a trigger that is not fired yet.
+func (v *JobData) IsArmed() bool {
+	return v.Trigger != nil && v.Trigger.FiredAt.IsZero()
+}
+
+// JobEvent records a change to a job, like a state transition or an update
+// to the job options. Reason holds the additional details, which is the error
+// message for FAILED jobs.
+type JobEvent struct {
+	Time time.Time
+	Kind string
+
+	// State is the job state after the event.
+	State State
+
+	Reason string
+}
+
+// JobArchive holds a finished job that is moved out of the main keyspaces.
+// Data holds the gzip compressed, gob encoded JobExportData with all
+// key-values of the job, so that the job can be restored later. Summary holds
+// the job's lifetime summary computed when it was archived.
+type JobArchive struct {
+	UID      string
+	Name     string
+	Typename string
+
+	State  State
+	Labels map[string]string
+
+	ArchivedAt time.Time
+
+	Summary *Summary
+
+	Data []byte
 }
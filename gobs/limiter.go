@@ -2,6 +2,12 @@
 
 package gobs
 
+import (
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
 type LimiterState struct {
 	V2 *LimiterStateV2
 }
@@ -15,6 +21,42 @@ type LimiterStateV2 struct {
 	ClientIDOffset   uint64
 	TradePoint       Point
 	ServerIDOrderMap map[string]*Order
+
+	// Expiry when non-nil holds the deadline for the limiter and the fallback
+	// action to take if the limiter is not complete by the deadline.
+	Expiry *LimiterExpiry
+}
+
+type LimiterExpiry struct {
+	Deadline time.Time
+
+	// Action is one of "complete", "reprice" or "aggressive". Complete action
+	// finishes the limiter with a partial fill, reprice action moves the limit
+	// price toward the ticker price in steps and aggressive action places the
+	// remaining size at a price that crosses the spread.
+	Action string
+
+	// RepriceStepPct is the percentage of the limit price moved toward the
+	// ticker price every RepriceInterval in the reprice action.
+	RepriceStepPct  decimal.Decimal
+	RepriceInterval time.Duration
+
+	// AggressivePct is the percentage above the ask price (for buys) or below
+	// the bid price (for sells) used by the aggressive action.
+	AggressivePct decimal.Decimal
+
+	// OriginalPrice is the limit price before any fallback action is applied.
+	OriginalPrice decimal.Decimal
+
+	// Price is the limit price set by the reprice or aggressive actions. It is
+	// zero till a fallback action updates the limit price. Limiter's point is
+	// never changed by the fallback actions.
+	Price decimal.Decimal
+
+	// ExpiredAt is the time when the fallback action has started.
+	ExpiredAt time.Time
+
+	NumReprices int
 }
 
 func (v *LimiterState) Upgrade() {
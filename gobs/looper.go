@@ -2,6 +2,8 @@
 
 package gobs
 
+import "time"
+
 type LooperState struct {
 	V2 *LooperStateV2
 }
@@ -15,6 +17,11 @@ type LooperStateV2 struct {
 	TradePair    Pair
 
 	LifetimeSummary *Summary
+
+	// StopLossTime is non-zero if the looper was stopped out. StopLossLimiterID
+	// is the limiter that sells the inventory held at that time, if any.
+	StopLossTime      time.Time
+	StopLossLimiterID string
 }
 
 func (v *LooperState) Upgrade() {
@@ -11,6 +11,10 @@ func NewByTypename(typename string) (any, error) {
 	switch typename {
 	case "JobData":
 		v = new(JobData)
+	case "JobEvent":
+		v = new(JobEvent)
+	case "JobArchive":
+		v = new(JobArchive)
 	case "LimiterState":
 		v = new(LimiterState)
 	case "LooperState":
@@ -19,6 +23,8 @@ func NewByTypename(typename string) (any, error) {
 		v = new(WallerState)
 	case "WatcherState":
 		v = new(WatcherState)
+	case "ArbiterState":
+		v = new(ArbiterState)
 	case "KeyValue":
 		v = new(KeyValue)
 	case "NameData":
@@ -39,6 +45,8 @@ func NewByTypename(typename string) (any, error) {
 		v = new(CoinbaseProducts)
 	case "TelegramState":
 		v = new(TelegramState)
+	case "APIToken":
+		v = new(APIToken)
 	default:
 		return nil, fmt.Errorf("unsupported type name %q", typename)
 	}
@@ -2,7 +2,11 @@
 
 package gobs
 
-import "github.com/shopspring/decimal"
+import (
+	"time"
+
+	"github.com/shopspring/decimal"
+)
 
 type ServerExchangeState struct {
 	EnabledProductIDs []string
@@ -16,8 +20,64 @@ type AlertsConfig struct {
 	LowBalanceLimits map[string]decimal.Decimal
 }
 
+// RiskLimits holds inventory risk limits. Zero values are not enforced.
+// MaxUnsoldSize is only enforced for the per-product limits.
+type RiskLimits struct {
+	MaxUnsoldSize    decimal.Decimal
+	MaxUnsoldValue   decimal.Decimal
+	MaxHeldPositions int
+}
+
+type RiskLimitsConfig struct {
+	// ReleasePct is the percentage of the limits below which the inventory must
+	// fall before frozen buys are released. Defaults to 80 when zero.
+	ReleasePct decimal.Decimal
+
+	// PerExchangeLimits are keyed by the exchange name and PerProductLimits are
+	// keyed by the exchange-name/product-id strings.
+	PerExchangeLimits map[string]*RiskLimits
+	PerProductLimits  map[string]*RiskLimits
+}
+
+// RiskState holds the jobs frozen by the server due to risk limits. Frozen is
+// keyed by the exchange or exchange/product scope and holds the ids of the
+// jobs frozen for the scope. Jobs maps the frozen job ids to their freeze
+// records, which are shared by all scopes holding a freeze on the job.
+type RiskState struct {
+	Frozen map[string][]string
+
+	Jobs map[string]*RiskFreeze
+}
+
+// RiskFreeze holds the undo value for a frozen job's freeze option and the
+// number of scopes holding a freeze on the job. Undo value is restored only
+// when the last scope releases the job.
+type RiskFreeze struct {
+	Undo   string
+	Scopes int
+}
+
+// CircuitBreakerConfig configures the market crash circuit breaker. Breaker
+// trips when price moves more than MovePct percent within the Window duration
+// or when bid-ask spread is above MaxSpreadPct percent of the price. Zero
+// values disable the respective checks. Tripped breakers are reset after the
+// CoolDown duration or when ManualReset is true, only by the operator.
+type CircuitBreakerConfig struct {
+	MovePct decimal.Decimal
+	Window  time.Duration
+
+	MaxSpreadPct decimal.Decimal
+
+	CoolDown    time.Duration
+	ManualReset bool
+}
+
 type ServerState struct {
 	AlertsConfig *AlertsConfig
 
+	CircuitBreaker *CircuitBreakerConfig
+
+	RiskLimits *RiskLimitsConfig
+
 	ExchangeMap map[string]*ServerExchangeState
 }
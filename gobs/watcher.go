@@ -17,6 +17,44 @@ type WatcherState struct {
 	TradeLoops []*WatcherLoop
 
 	LifetimeSummary *Summary
+
+	// SupersededBy holds the uid of the waller job created from this watcher
+	// and SupersededAt holds the time when the waller is created.
+	SupersededBy string
+	SupersededAt time.Time
+
+	// FillModel when non-nil, selects the conditions for simulated buys and
+	// sells. Nil value uses the original behavior where buys are recorded when
+	// consecutive ticks cross the buy price and sells are recorded when ticker
+	// is above the sell price.
+	FillModel *WatcherFillModel
+}
+
+// WatcherFillModel holds the conditions for the simulated fills. Products only
+// publish ticker updates, so price range in between two consecutive tickers is
+// used as the candle low and high. Orders are always filled completely;
+// partial fills are not modeled.
+type WatcherFillModel struct {
+	// Mode is one of "touch" or "cross". Touch mode fills an order when price
+	// reaches the order price and cross mode fills an order only when price
+	// moves beyond the order price by more than CrossTicks ticks.
+	Mode string
+
+	CrossTicks int
+
+	// TickSize is the price increment for the product. Zero value uses the
+	// smallest increment in the order price.
+	TickSize decimal.Decimal
+
+	// MinTimeAtPrice is the minimum duration price must stay at or beyond the
+	// order price before the order is filled, which models the queue of other
+	// orders ahead at the same price.
+	MinTimeAtPrice time.Duration
+
+	// SimulateCancel when true, considers orders as open only when ticker is
+	// in between the order price and cancel price like a limiter job does, so
+	// that price gaps beyond the cancel price are not filled.
+	SimulateCancel bool
 }
 
 type WatcherLoop struct {
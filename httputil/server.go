@@ -4,6 +4,7 @@ package httputil
 
 import (
 	"context"
+	"crypto/tls"
 	"errors"
 	"fmt"
 	"log"
@@ -177,6 +178,25 @@ func (s *Server) StartUnix(ctx context.Context, addr *net.UnixAddr) (id int64, s
 }
 
 func (s *Server) StartTCP(ctx context.Context, addr *net.TCPAddr) (id int64, status error) {
+	return s.startTCP(ctx, addr, nil)
+}
+
+// StartTLS starts a https server on the input address. Client certificates
+// are verified as per the input config, but servers that need mandatory
+// client certificates must use tls.VerifyClientCertIfGiven and reject the
+// requests without verified certificates in the handlers, so that server
+// initialization check, which doesn't use a client certificate, can succeed.
+func (s *Server) StartTLS(ctx context.Context, addr *net.TCPAddr, config *tls.Config) (id int64, status error) {
+	if config == nil {
+		return -1, fmt.Errorf("tls config cannot be nil: %w", os.ErrInvalid)
+	}
+	if config.ClientAuth == tls.RequireAnyClientCert || config.ClientAuth == tls.RequireAndVerifyClientCert {
+		return -1, fmt.Errorf("tls client auth must be optional for the server check: %w", os.ErrInvalid)
+	}
+	return s.startTCP(ctx, addr, config)
+}
+
+func (s *Server) startTCP(ctx context.Context, addr *net.TCPAddr, config *tls.Config) (id int64, status error) {
 	l, err := net.Listen("tcp", addr.String())
 	if err != nil {
 		return -1, err
@@ -202,6 +222,12 @@ func (s *Server) StartTCP(ctx context.Context, addr *net.TCPAddr) (id int64, sta
 	s.AddHandler(testPath, testHandler)
 	defer s.RemoveHandler(testPath)
 
+	scheme := "http"
+	if config != nil {
+		l = tls.NewListener(l, config)
+		scheme = "https"
+	}
+
 	server := &http.Server{
 		Handler: s,
 		BaseContext: func(net.Listener) context.Context {
@@ -229,8 +255,15 @@ func (s *Server) StartTCP(ctx context.Context, addr *net.TCPAddr) (id int64, sta
 	c := http.Client{
 		Timeout: s.opts.ServerCheckTimeout,
 	}
+	if config != nil {
+		// Server check only verifies that our own listener is serving, so server
+		// certificate is not verified.
+		c.Transport = &http.Transport{
+			TLSClientConfig: &tls.Config{InsecureSkipVerify: true},
+		}
+	}
 	u := url.URL{
-		Scheme: "http",
+		Scheme: scheme,
 		Host:   l.Addr().String(),
 		Path:   testPath,
 	}
@@ -0,0 +1,230 @@
+// Copyright (c) 2025 BVK Chaitanya
+
+package job
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/gob"
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"time"
+
+	"github.com/bvk/tradebot/gobs"
+	"github.com/bvk/tradebot/kvutil"
+	"github.com/bvkgo/kv"
+)
+
+// ArchiveKeyspace holds the finished jobs that are moved out of the main
+// keyspaces.
+const ArchiveKeyspace = "/job-archive/"
+
+// Event kinds recorded for the archival operations.
+const (
+	ArchivedEvent = "archived"
+	RestoredEvent = "restored"
+)
+
+// DecodeArchive returns the job export data from an archived job.
+func DecodeArchive(a *gobs.JobArchive) (*gobs.JobExportData, error) {
+	zr, err := gzip.NewReader(bytes.NewReader(a.Data))
+	if err != nil {
+		return nil, fmt.Errorf("could not open compressed archive data: %w", err)
+	}
+	defer zr.Close()
+
+	export := new(gobs.JobExportData)
+	if err := gob.NewDecoder(zr).Decode(export); err != nil {
+		return nil, fmt.Errorf("could not decode archive data: %w", err)
+	}
+	return export, nil
+}
+
+func encodeArchive(export *gobs.JobExportData) ([]byte, error) {
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	if err := gob.NewEncoder(zw).Encode(export); err != nil {
+		return nil, fmt.Errorf("could not encode archive data: %w", err)
+	}
+	if err := zw.Close(); err != nil {
+		return nil, fmt.Errorf("could not compress archive data: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// Archive moves a finished job into the archive keyspace. Export must hold all
+// key-values of the job, which are removed from the database along with the
+// job data. Job history and name are retained. If writer is non-nil, then job
+// is archived within the input writer's transaction.
+func (r *Runner) Archive(ctx context.Context, writer kv.ReadWriter, export *gobs.JobExportData, summary *gobs.Summary) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	uid := export.UID
+	if _, ok := r.jobMap[uid]; ok {
+		return fmt.Errorf("running job %q cannot be archived", uid)
+	}
+
+	archive := func(ctx context.Context, rw kv.ReadWriter) error {
+		key := path.Join(Keyspace, uid)
+		jd, err := kvutil.Get[gobs.JobData](ctx, rw, key)
+		if err != nil {
+			return fmt.Errorf("could not read job data from db: %w", err)
+		}
+		if !jd.State.IsDone() {
+			return fmt.Errorf("job %q is not finished yet (%s): %w", uid, jd.State, os.ErrInvalid)
+		}
+
+		export.Typename = jd.Typename
+		export.JobFlags = jd.Flags
+		export.JobState = jd.State
+		export.JobLabels = jd.Labels
+		data, err := encodeArchive(export)
+		if err != nil {
+			return err
+		}
+
+		a := &gobs.JobArchive{
+			UID:        uid,
+			Name:       export.Name,
+			Typename:   jd.Typename,
+			State:      jd.State,
+			Labels:     jd.Labels,
+			ArchivedAt: time.Now(),
+			Summary:    summary,
+			Data:       data,
+		}
+		akey := path.Join(ArchiveKeyspace, uid)
+		if err := kvutil.Set(ctx, rw, akey, a); err != nil {
+			return fmt.Errorf("could not save archive for job %q: %w", uid, err)
+		}
+
+		for _, kv := range export.KeyValues {
+			if err := rw.Delete(ctx, kv.Key); err != nil {
+				return fmt.Errorf("could not delete key %q: %w", kv.Key, err)
+			}
+		}
+		if err := rw.Delete(ctx, key); err != nil {
+			return fmt.Errorf("could not delete key %q: %w", key, err)
+		}
+
+		event := &gobs.JobEvent{
+			Kind:   ArchivedEvent,
+			State:  jd.State,
+			Reason: fmt.Sprintf("%d keys, %d compressed bytes", len(export.KeyValues), len(data)),
+		}
+		return AppendEvent(ctx, rw, uid, event)
+	}
+	if writer != nil {
+		return archive(ctx, writer)
+	}
+	return kv.WithReadWriter(ctx, r.db, archive)
+}
+
+// Restore moves an archived job back into the main keyspaces in it's final
+// state. It returns the restored job's export data. If writer is non-nil,
+// then job is restored within the input writer's transaction.
+func (r *Runner) Restore(ctx context.Context, writer kv.ReadWriter, uid string) (*gobs.JobExportData, error) {
+	var export *gobs.JobExportData
+	restore := func(ctx context.Context, rw kv.ReadWriter) error {
+		akey := path.Join(ArchiveKeyspace, uid)
+		a, err := kvutil.Get[gobs.JobArchive](ctx, rw, akey)
+		if err != nil {
+			return fmt.Errorf("could not read archive for job %q: %w", uid, err)
+		}
+		v, err := DecodeArchive(a)
+		if err != nil {
+			return err
+		}
+
+		key := path.Join(Keyspace, uid)
+		if _, err := rw.Get(ctx, key); err == nil {
+			return fmt.Errorf("job with uid %q already exists: %w", uid, os.ErrExist)
+		} else if !errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("could not check for job %q: %w", uid, err)
+		}
+		for _, kv := range v.KeyValues {
+			if _, err := rw.Get(ctx, kv.Key); err == nil {
+				return fmt.Errorf("data key %q already exists: %w", kv.Key, os.ErrExist)
+			} else if !errors.Is(err, os.ErrNotExist) {
+				return fmt.Errorf("could not check for data key %q: %w", kv.Key, err)
+			}
+			if err := rw.Set(ctx, kv.Key, bytes.NewReader(kv.Value)); err != nil {
+				return fmt.Errorf("could not restore key %q: %w", kv.Key, err)
+			}
+		}
+
+		jd := &gobs.JobData{
+			ID:       uid,
+			Typename: v.Typename,
+			Flags:    v.JobFlags,
+			State:    v.JobState,
+			Labels:   v.JobLabels,
+		}
+		if err := kvutil.Set(ctx, rw, key, jd); err != nil {
+			return err
+		}
+		if err := rw.Delete(ctx, akey); err != nil {
+			return fmt.Errorf("could not delete key %q: %w", akey, err)
+		}
+		if err := AppendEvent(ctx, rw, uid, &gobs.JobEvent{Kind: RestoredEvent, State: jd.State}); err != nil {
+			return err
+		}
+		export = v
+		return nil
+	}
+	if writer != nil {
+		if err := restore(ctx, writer); err != nil {
+			return nil, err
+		}
+		return export, nil
+	}
+	if err := kv.WithReadWriter(ctx, r.db, restore); err != nil {
+		return nil, err
+	}
+	return export, nil
+}
+
+// GetArchive returns an archived job. If the reader is nil, then a new
+// snapshot will be used for reading the database.
+func (r *Runner) GetArchive(ctx context.Context, reader kv.Reader, uid string) (*gobs.JobArchive, error) {
+	if reader == nil {
+		snap, err := r.db.NewSnapshot(ctx)
+		if err != nil {
+			return nil, err
+		}
+		defer snap.Discard(ctx)
+
+		reader = snap
+	}
+
+	key := path.Join(ArchiveKeyspace, uid)
+	a, err := kvutil.Get[gobs.JobArchive](ctx, reader, key)
+	if err != nil {
+		return nil, fmt.Errorf("could not read archive for job %q: %w", uid, err)
+	}
+	return a, nil
+}
+
+// ScanArchive invokes the callback function with all archived jobs. If the
+// reader is nil, then a new snapshot will be used for reading the database.
+func (r *Runner) ScanArchive(ctx context.Context, reader kv.Reader, fn func(ctx context.Context, r kv.Reader, item *gobs.JobArchive) error) error {
+	if reader == nil {
+		snap, err := r.db.NewSnapshot(ctx)
+		if err != nil {
+			return err
+		}
+		defer snap.Discard(ctx)
+
+		reader = snap
+	}
+
+	begin, end := kvutil.PathRange(ArchiveKeyspace)
+	cb := func(ctx context.Context, reader kv.Reader, key string, value *gobs.JobArchive) error {
+		return fn(ctx, reader, value)
+	}
+	return kvutil.Ascend(ctx, reader, begin, end, cb)
+}
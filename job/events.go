@@ -0,0 +1,27 @@
+// Copyright (c) 2025 BVK Chaitanya
+
+package job
+
+import (
+	"github.com/bvk/tradebot/gobs"
+	"github.com/visvasity/topic"
+)
+
+// StateChange describes a job state transition performed by the runner.
+type StateChange struct {
+	UID   string
+	Event *gobs.JobEvent
+}
+
+// StateChanges returns a receiver for the job state transitions performed by
+// the runner. State changes are published only after they are committed to
+// the database, so changes made within caller's transactions, like adding a
+// new job with a non-nil writer, are not published. Callers must close the
+// receiver when it is no longer necessary.
+func (r *Runner) StateChanges() (*topic.Receiver[*StateChange], error) {
+	return topic.Subscribe(r.stateTopic, 0, false)
+}
+
+func (r *Runner) publish(uid string, event *gobs.JobEvent) {
+	r.stateTopic.Send(&StateChange{UID: uid, Event: event})
+}
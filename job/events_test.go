@@ -0,0 +1,68 @@
+// Copyright (c) 2025 BVK Chaitanya
+
+package job
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/bvk/tradebot/gobs"
+	"github.com/bvkgo/kv/kvmemdb"
+)
+
+func TestRunnerStateChanges(t *testing.T) {
+	ctx := context.Background()
+	db := kvmemdb.New()
+
+	runner := NewRunner(db)
+	defer runner.PauseAll(ctx)
+
+	changes, err := runner.StateChanges()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer changes.Close()
+
+	if err := runner.Add(ctx, nil, "1", "JobOne"); err != nil {
+		t.Fatal(err)
+	}
+	jobFunc := func(ctx context.Context) error {
+		<-ctx.Done()
+		return context.Cause(ctx)
+	}
+	if err := runner.Resume(ctx, "1", jobFunc, ctx); err != nil {
+		t.Fatal(err)
+	}
+	if err := runner.Pause(ctx, "1"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := runner.Cancel(ctx, "1"); err != nil {
+		t.Fatal(err)
+	}
+
+	wants := []struct {
+		kind  string
+		state gobs.State
+	}{
+		{CreatedEvent, gobs.PAUSED},
+		{ResumedEvent, gobs.RUNNING},
+		{PausedEvent, gobs.PAUSED},
+		{CanceledEvent, gobs.CANCELED},
+	}
+	for _, want := range wants {
+		rctx, cancel := context.WithTimeout(ctx, time.Second)
+		var change *StateChange
+		for v := range changes.All(rctx, &err) {
+			change = v
+			break
+		}
+		cancel()
+		if change == nil {
+			t.Fatalf("wanted %s event, got none (%v)", want.kind, err)
+		}
+		if change.UID != "1" || change.Event.Kind != want.kind || change.Event.State != want.state {
+			t.Fatalf("wanted %s/%s event, got %s/%s for %q", want.kind, want.state, change.Event.Kind, change.Event.State, change.UID)
+		}
+	}
+}
@@ -0,0 +1,165 @@
+// Copyright (c) 2025 BVK Chaitanya
+
+package job
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"strconv"
+	"time"
+
+	"github.com/bvk/tradebot/gobs"
+	"github.com/bvk/tradebot/kvutil"
+	"github.com/bvkgo/kv"
+)
+
+// HistoryKeyspace holds the event history for all jobs. Events of a job are
+// stored under a per-job directory with keys ordered by the event time.
+const HistoryKeyspace = "/job-history/"
+
+// Event kinds recorded in the job history.
+const (
+	CreatedEvent   = "created"
+	ResumedEvent   = "resumed"
+	PausedEvent    = "paused"
+	FailedEvent    = "failed"
+	CompletedEvent = "completed"
+	CanceledEvent  = "canceled"
+	OptionEvent    = "option-changed"
+	RenamedEvent   = "renamed"
+	ClonedEvent    = "cloned"
+)
+
+// eventKind returns the event kind for a job state transition.
+func eventKind(state gobs.State) string {
+	switch state {
+	case gobs.RUNNING:
+		return ResumedEvent
+	case gobs.COMPLETED:
+		return CompletedEvent
+	case gobs.CANCELED:
+		return CanceledEvent
+	case gobs.FAILED:
+		return FailedEvent
+	default:
+		return PausedEvent
+	}
+}
+
+// AppendEvent adds an event to the history of a job within the input
+// transaction. Event keys are derived from the event time, but are adjusted
+// to be unique and increasing when multiple events share the same timestamp.
+func AppendEvent(ctx context.Context, rw kv.ReadWriter, uid string, event *gobs.JobEvent) error {
+	if event.Time.IsZero() {
+		event.Time = time.Now()
+	}
+
+	seq := event.Time.UnixNano()
+	begin, end := kvutil.PathRange(path.Join(HistoryKeyspace, uid))
+	last, _, err := kvutil.Last[gobs.JobEvent](ctx, rw, begin, end)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("could not read last event of job %q: %w", uid, err)
+	}
+	if last != "" {
+		n, err := strconv.ParseInt(path.Base(last), 10, 64)
+		if err != nil {
+			return fmt.Errorf("could not parse event key %q: %w", last, err)
+		}
+		if seq <= n {
+			seq = n + 1
+		}
+	}
+
+	key := path.Join(HistoryKeyspace, uid, fmt.Sprintf("%020d", seq))
+	if err := kvutil.Set(ctx, rw, key, event); err != nil {
+		return fmt.Errorf("could not save event of job %q: %w", uid, err)
+	}
+	return nil
+}
+
+// History returns all events of a job in the time order.
+func History(ctx context.Context, r kv.Reader, uid string) ([]*gobs.JobEvent, error) {
+	var events []*gobs.JobEvent
+	collect := func(ctx context.Context, r kv.Reader, key string, value *gobs.JobEvent) error {
+		events = append(events, value)
+		return nil
+	}
+	begin, end := kvutil.PathRange(path.Join(HistoryKeyspace, uid))
+	if err := kvutil.Ascend(ctx, r, begin, end, collect); err != nil {
+		return nil, fmt.Errorf("could not scan history of job %q: %w", uid, err)
+	}
+	return events, nil
+}
+
+// deleteHistory removes all events of a job within the input transaction.
+func deleteHistory(ctx context.Context, rw kv.ReadWriter, uid string) error {
+	var keys []string
+	collect := func(ctx context.Context, r kv.Reader, key string, value *gobs.JobEvent) error {
+		keys = append(keys, key)
+		return nil
+	}
+	begin, end := kvutil.PathRange(path.Join(HistoryKeyspace, uid))
+	if err := kvutil.Ascend(ctx, rw, begin, end, collect); err != nil {
+		return fmt.Errorf("could not scan history of job %q: %w", uid, err)
+	}
+	for _, key := range keys {
+		if err := rw.Delete(ctx, key); err != nil {
+			return fmt.Errorf("could not delete key %q: %w", key, err)
+		}
+	}
+	return nil
+}
+
+// History returns the event history of a job. If the reader is nil, then a
+// new snapshot will be used for reading the database.
+func (r *Runner) History(ctx context.Context, reader kv.Reader, uid string) ([]*gobs.JobEvent, error) {
+	if reader == nil {
+		snap, err := r.db.NewSnapshot(ctx)
+		if err != nil {
+			return nil, err
+		}
+		defer snap.Discard(ctx)
+
+		reader = snap
+	}
+
+	// History is also available for the archived jobs.
+	key := path.Join(Keyspace, uid)
+	if _, err := kvutil.Get[gobs.JobData](ctx, reader, key); err != nil {
+		if !errors.Is(err, os.ErrNotExist) {
+			return nil, fmt.Errorf("could not read job data from db: %w", err)
+		}
+		akey := path.Join(ArchiveKeyspace, uid)
+		if _, err := kvutil.Get[gobs.JobArchive](ctx, reader, akey); err != nil {
+			return nil, fmt.Errorf("could not read job data from db: %w", err)
+		}
+	}
+	return History(ctx, reader, uid)
+}
+
+// AddEvent records a job event with the job's current state. Events for job
+// changes that are not performed by the runner, like option updates, are
+// recorded through this method. If writer is non-nil, then the event is added
+// within the input writer's transaction.
+func (r *Runner) AddEvent(ctx context.Context, writer kv.ReadWriter, uid, kind, reason string) error {
+	add := func(ctx context.Context, rw kv.ReadWriter) error {
+		key := path.Join(Keyspace, uid)
+		jd, err := kvutil.Get[gobs.JobData](ctx, rw, key)
+		if err != nil {
+			return fmt.Errorf("could not read job data from db: %w", err)
+		}
+		event := &gobs.JobEvent{
+			Kind:   kind,
+			State:  jd.State,
+			Reason: reason,
+		}
+		return AppendEvent(ctx, rw, uid, event)
+	}
+	if writer != nil {
+		return add(ctx, writer)
+	}
+	return kv.WithReadWriter(ctx, r.db, add)
+}
@@ -25,8 +25,8 @@ func TestPause(t *testing.T) {
 	if j1.state() != gobs.PAUSED {
 		t.Fatalf("j1 must be paused")
 	}
-	if !errors.Is(j1.Err(), errPause) {
-		t.Fatalf("want errPause, got %v", j1.Err())
+	if !errors.Is(j1.Err(), ErrPause) {
+		t.Fatalf("want ErrPause, got %v", j1.Err())
 	}
 }
 
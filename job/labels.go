@@ -0,0 +1,127 @@
+// Copyright (c) 2025 BVK Chaitanya
+
+package job
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"path"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/bvk/tradebot/gobs"
+	"github.com/bvk/tradebot/kvutil"
+	"github.com/bvkgo/kv"
+)
+
+// ReservedLabels are derived from the job data for selecting jobs and cannot
+// be set by the users.
+var ReservedLabels = []string{"uid", "name", "type", "state", "product", "exchange"}
+
+// LabelsEvent is recorded in the job history when labels are updated.
+const LabelsEvent = "labels-changed"
+
+var labelKeyRe = regexp.MustCompile(`^[a-zA-Z0-9][a-zA-Z0-9._-]*$`)
+
+// ParseLabels parses labels or a label selector from a string of the form
+// "key1=value1,key2=value2". Empty string returns a nil map.
+func ParseLabels(s string) (map[string]string, error) {
+	if s = strings.TrimSpace(s); len(s) == 0 {
+		return nil, nil
+	}
+	labels := make(map[string]string)
+	for _, item := range strings.Split(s, ",") {
+		key, value, ok := strings.Cut(item, "=")
+		if !ok {
+			return nil, fmt.Errorf("label %q must be of the form key=value", item)
+		}
+		key, value = strings.TrimSpace(key), strings.TrimSpace(value)
+		if !labelKeyRe.MatchString(key) {
+			return nil, fmt.Errorf("label key %q is invalid", key)
+		}
+		if _, ok := labels[key]; ok {
+			return nil, fmt.Errorf("label key %q is repeated", key)
+		}
+		labels[key] = value
+	}
+	return labels, nil
+}
+
+// LabelsString returns the labels in the format accepted by ParseLabels with
+// keys in sorted order.
+func LabelsString(labels map[string]string) string {
+	keys := slices.Collect(maps.Keys(labels))
+	sort.Strings(keys)
+	var items []string
+	for _, k := range keys {
+		items = append(items, k+"="+labels[k])
+	}
+	return strings.Join(items, ",")
+}
+
+// CheckLabels validates user-defined labels.
+func CheckLabels(labels map[string]string) error {
+	for k, v := range labels {
+		if !labelKeyRe.MatchString(k) {
+			return fmt.Errorf("label key %q is invalid", k)
+		}
+		if slices.Contains(ReservedLabels, strings.ToLower(k)) {
+			return fmt.Errorf("label key %q is reserved", k)
+		}
+		if strings.ContainsAny(v, ",=") {
+			return fmt.Errorf("label value %q for key %q cannot contain comma or equal characters", v, k)
+		}
+	}
+	return nil
+}
+
+// MatchLabels returns true if all key-value pairs in the selector are present
+// in the labels. Values are compared case-insensitively.
+func MatchLabels(selector, labels map[string]string) bool {
+	for k, v := range selector {
+		lv, ok := labels[k]
+		if !ok || !strings.EqualFold(lv, v) {
+			return false
+		}
+	}
+	return true
+}
+
+// UpdateLabels adds or updates the input labels and removes the labels with
+// the input keys on an existing job. If writer is non-nil, then labels are
+// updated within the input writer's transaction.
+func (r *Runner) UpdateLabels(ctx context.Context, writer kv.ReadWriter, uid string, labels map[string]string, remove []string) error {
+	if err := CheckLabels(labels); err != nil {
+		return err
+	}
+
+	update := func(ctx context.Context, rw kv.ReadWriter) error {
+		key := path.Join(Keyspace, uid)
+		jd, err := kvutil.Get[gobs.JobData](ctx, rw, key)
+		if err != nil {
+			return fmt.Errorf("could not read job data from db: %w", err)
+		}
+		if jd.Labels == nil {
+			jd.Labels = make(map[string]string)
+		}
+		for _, k := range remove {
+			delete(jd.Labels, k)
+		}
+		maps.Copy(jd.Labels, labels)
+		if len(jd.Labels) == 0 {
+			jd.Labels = nil
+		}
+		if err := kvutil.Set(ctx, rw, key, jd); err != nil {
+			return fmt.Errorf("could not update labels for job %q: %w", uid, err)
+		}
+		event := &gobs.JobEvent{Kind: LabelsEvent, State: jd.State, Reason: LabelsString(jd.Labels)}
+		return AppendEvent(ctx, rw, uid, event)
+	}
+	if writer != nil {
+		return update(ctx, writer)
+	}
+	return kv.WithReadWriter(ctx, r.db, update)
+}
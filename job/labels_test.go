@@ -0,0 +1,57 @@
+// Copyright (c) 2025 BVK Chaitanya
+
+package job
+
+import (
+	"context"
+	"testing"
+
+	"github.com/bvkgo/kv/kvmemdb"
+)
+
+func TestLabels(t *testing.T) {
+	ctx := context.Background()
+	db := kvmemdb.New()
+
+	runner := NewRunner(db)
+	defer runner.PauseAll(ctx)
+
+	if _, err := ParseLabels("strategy"); err == nil {
+		t.Fatalf("wanted parse error for label without a value")
+	}
+	if _, err := ParseLabels("a=1,a=2"); err == nil {
+		t.Fatalf("wanted parse error for repeated label")
+	}
+	labels, err := ParseLabels("strategy=grid, owner=alice")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := CheckLabels(map[string]string{"product": "BTC-USD"}); err == nil {
+		t.Fatalf("wanted error for reserved label")
+	}
+
+	if err := runner.Add(ctx, nil, "1", "Waller"); err != nil {
+		t.Fatal(err)
+	}
+	if err := runner.UpdateLabels(ctx, nil, "1", labels, nil); err != nil {
+		t.Fatal(err)
+	}
+	if err := runner.UpdateLabels(ctx, nil, "1", map[string]string{"tier": "1"}, []string{"owner"}); err != nil {
+		t.Fatal(err)
+	}
+
+	jd, err := runner.Get(ctx, nil, "1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s := LabelsString(jd.Labels); s != "strategy=grid,tier=1" {
+		t.Fatalf("wanted strategy=grid,tier=1, got %q", s)
+	}
+
+	if !MatchLabels(map[string]string{"strategy": "GRID"}, jd.Labels) {
+		t.Fatalf("wanted case-insensitive selector match")
+	}
+	if MatchLabels(map[string]string{"strategy": "grid", "owner": "alice"}, jd.Labels) {
+		t.Fatalf("wanted no match for removed label")
+	}
+}
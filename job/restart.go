@@ -0,0 +1,284 @@
+// Copyright (c) 2025 BVK Chaitanya
+
+package job
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"path"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/bvk/tradebot/gobs"
+	"github.com/bvk/tradebot/kvutil"
+	"github.com/bvkgo/kv"
+)
+
+var RestartModes = []string{"never", "on-failure"}
+
+const (
+	DefaultRestartMaxAttempts = 5
+	DefaultRestartWindow      = time.Hour
+	DefaultRestartBackoff     = time.Minute
+	DefaultRestartMaxBackoff  = 30 * time.Minute
+)
+
+// Event kinds recorded for the automatic restarts.
+const (
+	RestartScheduledEvent = "restart-scheduled"
+	RestartExhaustedEvent = "restart-exhausted"
+)
+
+// EscalateFunc is invoked when a failed job cannot be restarted because the
+// restart policy's attempts are exhausted.
+type EscalateFunc func(uid string, attempts int, status error)
+
+// ParseRestartPolicy parses a restart policy from a string of the form
+// "on-failure:max-attempts=5,window=1h,backoff=1m,max-backoff=30m". Only the
+// mode is required and other parameters take the default values.
+func ParseRestartPolicy(s string) (*gobs.JobRestartPolicy, error) {
+	mode, params, _ := strings.Cut(strings.TrimSpace(s), ":")
+	p := &gobs.JobRestartPolicy{
+		Mode:        strings.ToLower(mode),
+		MaxAttempts: DefaultRestartMaxAttempts,
+		Window:      DefaultRestartWindow,
+		Backoff:     DefaultRestartBackoff,
+		MaxBackoff:  DefaultRestartMaxBackoff,
+	}
+	if len(params) != 0 {
+		for _, kv := range strings.Split(params, ",") {
+			key, val, ok := strings.Cut(kv, "=")
+			if !ok {
+				return nil, fmt.Errorf("restart policy parameter %q must be of the form key=value", kv)
+			}
+			switch key = strings.ToLower(strings.TrimSpace(key)); key {
+			case "max-attempts":
+				v, err := strconv.Atoi(strings.TrimSpace(val))
+				if err != nil {
+					return nil, fmt.Errorf("could not parse max-attempts value %q: %w", val, err)
+				}
+				p.MaxAttempts = v
+			case "window", "backoff", "max-backoff":
+				v, err := time.ParseDuration(strings.TrimSpace(val))
+				if err != nil {
+					return nil, fmt.Errorf("could not parse %s value %q: %w", key, val, err)
+				}
+				switch key {
+				case "window":
+					p.Window = v
+				case "backoff":
+					p.Backoff = v
+				case "max-backoff":
+					p.MaxBackoff = v
+				}
+			default:
+				return nil, fmt.Errorf("invalid restart policy parameter %q", key)
+			}
+		}
+	}
+	if err := CheckRestartPolicy(p); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// CheckRestartPolicy validates a restart policy.
+func CheckRestartPolicy(p *gobs.JobRestartPolicy) error {
+	if !slices.Contains(RestartModes, p.Mode) {
+		return fmt.Errorf("restart mode %q must be one of %s", p.Mode, strings.Join(RestartModes, ", "))
+	}
+	if p.Mode == "never" {
+		return nil
+	}
+	if p.MaxAttempts <= 0 {
+		return fmt.Errorf("restart max-attempts must be positive")
+	}
+	if p.Window <= 0 {
+		return fmt.Errorf("restart window must be positive")
+	}
+	if p.Backoff <= 0 || p.MaxBackoff < p.Backoff {
+		return fmt.Errorf("restart backoff must be positive and cannot exceed the max-backoff")
+	}
+	return nil
+}
+
+// RestartPolicyString returns the input policy in the format accepted by
+// ParseRestartPolicy.
+func RestartPolicyString(p *gobs.JobRestartPolicy) string {
+	if p == nil {
+		return ""
+	}
+	if p.Mode == "never" {
+		return p.Mode
+	}
+	return fmt.Sprintf("%s:max-attempts=%d,window=%s,backoff=%s,max-backoff=%s", p.Mode, p.MaxAttempts, p.Window, p.Backoff, p.MaxBackoff)
+}
+
+// nextRestart returns the time for the next restart attempt of a failed job.
+// Restart attempts outside the policy window are dropped from the job
+// data. It returns false if the restart attempts are exhausted.
+func nextRestart(p *gobs.JobRestartPolicy, jd *gobs.JobData, now time.Time) (time.Time, bool) {
+	jd.RestartAttempts = slices.DeleteFunc(jd.RestartAttempts, func(t time.Time) bool {
+		return now.Sub(t) >= p.Window
+	})
+	n := len(jd.RestartAttempts)
+	if n >= p.MaxAttempts {
+		return time.Time{}, false
+	}
+	delay := p.Backoff
+	for i := 0; i < n && delay < p.MaxBackoff; i++ {
+		delay *= 2
+	}
+	return now.Add(min(delay, p.MaxBackoff)), true
+}
+
+// SetRestartFuncs configures the functions to restart a failed job and to
+// escalate when a failed job's restart attempts are exhausted.
+func (r *Runner) SetRestartFuncs(restart func(uid string), escalate EscalateFunc) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.restart, r.escalate = restart, escalate
+}
+
+// SetDefaultRestartPolicy configures the restart policy for all jobs of a
+// type that do not have a job specific restart policy. A nil policy removes
+// the default.
+func (r *Runner) SetDefaultRestartPolicy(typename string, p *gobs.JobRestartPolicy) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	key := strings.ToLower(typename)
+	if p == nil {
+		delete(r.restartPolicyMap, key)
+		return
+	}
+	r.restartPolicyMap[key] = p
+}
+
+// restartPolicy returns the restart policy for a job. It returns nil if job
+// must not be restarted.
+func (r *Runner) restartPolicy(jd *gobs.JobData) *gobs.JobRestartPolicy {
+	p := jd.RestartPolicy
+	if p == nil {
+		r.mu.Lock()
+		p = r.restartPolicyMap[strings.ToLower(jd.Typename)]
+		r.mu.Unlock()
+	}
+	if p == nil || p.Mode != "on-failure" {
+		return nil
+	}
+	return p
+}
+
+// SetRestartPolicy updates the restart policy of an existing job. A nil
+// policy removes the job specific policy, so that the default policy for the
+// job type is used.
+func (r *Runner) SetRestartPolicy(ctx context.Context, writer kv.ReadWriter, uid string, p *gobs.JobRestartPolicy) error {
+	if p != nil {
+		if err := CheckRestartPolicy(p); err != nil {
+			return fmt.Errorf("invalid restart policy: %w", err)
+		}
+	}
+
+	update := func(ctx context.Context, rw kv.ReadWriter) error {
+		key := path.Join(Keyspace, uid)
+		jd, err := kvutil.Get[gobs.JobData](ctx, rw, key)
+		if err != nil {
+			return fmt.Errorf("could not read job data from db: %w", err)
+		}
+		if jd.State.IsDone() {
+			return fmt.Errorf("job %q is already complete", uid)
+		}
+		jd.RestartPolicy = p
+		if err := kvutil.Set(ctx, rw, key, jd); err != nil {
+			return fmt.Errorf("could not update restart policy for job %q: %w", uid, err)
+		}
+		return nil
+	}
+	if writer != nil {
+		return update(ctx, writer)
+	}
+	return kv.WithReadWriter(ctx, r.db, update)
+}
+
+// scheduleRestart arranges for a failed job to be restarted at the input
+// time.
+func (r *Runner) scheduleRestart(uid string, at time.Time) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if t, ok := r.restartTimerMap[uid]; ok {
+		t.Stop()
+	}
+	r.restartTimerMap[uid] = time.AfterFunc(time.Until(at), func() {
+		r.restartJob(uid, at)
+	})
+}
+
+// restartJob restarts a failed job if it is still waiting for the restart
+// scheduled at the input time. Jobs that are paused, resumed or canceled in
+// the meantime are not restarted.
+func (r *Runner) restartJob(uid string, at time.Time) {
+	r.mu.Lock()
+	delete(r.restartTimerMap, uid)
+	restart := r.restart
+	r.mu.Unlock()
+
+	if restart == nil {
+		return
+	}
+
+	jd, err := r.Get(context.Background(), nil, uid)
+	if err != nil {
+		slog.Error("could not read job data for restart (ignored)", "job", uid, "err", err)
+		return
+	}
+	if jd.State != gobs.PAUSED || !jd.RestartAt.Equal(at) {
+		slog.Info("job restart is skipped because job is updated", "job", uid, "state", jd.State)
+		return
+	}
+	restart(uid)
+}
+
+// ScheduleRestarts schedules the restarts for all failed jobs that are waiting
+// to be restarted in the database. It must be invoked when the jobs are loaded
+// from the database, because scheduled restarts are not persistent across
+// process restarts.
+func (r *Runner) ScheduleRestarts(ctx context.Context) error {
+	var pending []*gobs.JobData
+	collect := func(ctx context.Context, _ kv.Reader, jd *gobs.JobData) error {
+		if IsWaitingForRestart(jd) {
+			pending = append(pending, jd)
+		}
+		return nil
+	}
+	if err := r.Scan(ctx, nil /* reader */, collect); err != nil {
+		return fmt.Errorf("could not scan jobs waiting for restart: %w", err)
+	}
+	for _, jd := range pending {
+		slog.Info("failed job is scheduled to restart", "job", jd.ID, "restart-at", jd.RestartAt)
+		r.scheduleRestart(jd.ID, jd.RestartAt)
+	}
+	return nil
+}
+
+// IsWaitingForRestart returns true if job has failed and is waiting for an
+// automatic restart.
+func IsWaitingForRestart(jd *gobs.JobData) bool {
+	return jd.State == gobs.PAUSED && !jd.RestartAt.IsZero()
+}
+
+// stopRestarts cancels all scheduled restarts.
+func (r *Runner) stopRestarts() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for uid, t := range r.restartTimerMap {
+		t.Stop()
+		delete(r.restartTimerMap, uid)
+	}
+}
@@ -13,10 +13,12 @@ import (
 	"path"
 	"slices"
 	"sync"
+	"time"
 
 	"github.com/bvk/tradebot/gobs"
 	"github.com/bvk/tradebot/kvutil"
 	"github.com/bvkgo/kv"
+	"github.com/visvasity/topic"
 )
 
 const Keyspace = "/jobs/"
@@ -28,19 +30,56 @@ type Runner struct {
 
 	// jobMap holds running jobs.
 	jobMap map[string]*Job
+
+	createFollowUp CreateFollowUpFunc
+	startFollowUp  func(uid string)
+
+	restart  func(uid string)
+	escalate EscalateFunc
+
+	// restartPolicyMap holds the default restart policies per job type.
+	restartPolicyMap map[string]*gobs.JobRestartPolicy
+
+	// restartTimerMap holds the timers for failed jobs waiting for restart.
+	restartTimerMap map[string]*time.Timer
+
+	// stateTopic publishes the committed job state transitions.
+	stateTopic *topic.Topic[*StateChange]
 }
 
+// CreateFollowUpFunc prepares the follow-up job declared by a parent job and
+// returns the new job's uid, typename and a function to save the new job. It
+// must not write to the database; save function is invoked by the runner only
+// after all checks are successful, so that a failed follow-up writes nothing.
+type CreateFollowUpFunc func(ctx context.Context, r kv.Reader, parent *gobs.JobData) (uid, typename string, save func(context.Context, kv.ReadWriter) error, err error)
+
 // NewRunner creates a job runner that uses the input database for persistency.
 func NewRunner(db kv.Database) *Runner {
 	return &Runner{
-		db:     db,
-		jobMap: make(map[string]*Job),
+		db:               db,
+		jobMap:           make(map[string]*Job),
+		restartPolicyMap: make(map[string]*gobs.JobRestartPolicy),
+		restartTimerMap:  make(map[string]*time.Timer),
+		stateTopic:       topic.New[*StateChange](),
 	}
 }
 
+// SetFollowUpFuncs configures the functions to create and start the follow-up
+// jobs when a job with a follow-up declaration is completed. Follow-up jobs are
+// created in the same transaction that marks the parent job as COMPLETED and
+// are started after the transaction is committed.
+func (r *Runner) SetFollowUpFuncs(create CreateFollowUpFunc, start func(uid string)) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.createFollowUp, r.startFollowUp = create, start
+}
+
 // PauseAll pauses all running jobs and waits for the goroutines to complete or
 // the input context to expire.
 func (r *Runner) PauseAll(ctx context.Context) error {
+	r.stopRestarts()
+
 	r.mu.Lock()
 	jobs := slices.Collect(maps.Values(r.jobMap))
 	r.mu.Unlock()
@@ -86,7 +125,7 @@ func (r *Runner) Resume(ctx context.Context, uid string, fn Func, fctx context.C
 			jd.State = gobs.FAILED
 		case status == nil:
 			jd.State = gobs.COMPLETED
-		case errors.Is(status, errPause):
+		case errors.Is(status, ErrPause):
 			jd.State = gobs.PAUSED
 		case errors.Is(status, errCancel):
 			jd.State = gobs.CANCELED
@@ -96,10 +135,65 @@ func (r *Runner) Resume(ctx context.Context, uid string, fn Func, fctx context.C
 			jd.State = gobs.COMPLETED
 		}
 
+		// Failed jobs are restarted automatically as per the restart policy.
+		failed := jd.State == gobs.FAILED
+		attempts, exhausted := 0, false
+		if failed {
+			if p := r.restartPolicy(jd); p != nil {
+				now := time.Now()
+				if at, ok := nextRestart(p, jd, now); ok {
+					jd.State = gobs.PAUSED
+					jd.RestartAt = at.Round(0)
+					jd.RestartAttempts = append(jd.RestartAttempts, now)
+				} else {
+					exhausted = true
+				}
+				attempts = len(jd.RestartAttempts)
+			}
+		}
+
+		childID := ""
+		if jd.State == gobs.COMPLETED && jd.FollowUp != nil && jd.FollowUp.ChildID == "" {
+			id, err := r.addFollowUp(ctx, tx, jd)
+			if err != nil {
+				slog.Error("could not create follow-up job (ignored)", "job", uid, "err", err)
+				jd.FollowUp.Error = err.Error()
+			}
+			jd.FollowUp.ChildID, childID = id, id
+		}
+
 		if err := kvutil.Set(ctx, tx, key, jd); err != nil {
 			return err
 		}
 
+		event := &gobs.JobEvent{Kind: eventKind(jd.State), State: jd.State}
+		if failed {
+			event.Kind, event.Reason = FailedEvent, status.Error()
+		}
+		if err := AppendEvent(ctx, tx, uid, event); err != nil {
+			return err
+		}
+		switch {
+		case failed && !jd.RestartAt.IsZero():
+			event := &gobs.JobEvent{
+				Kind:   RestartScheduledEvent,
+				State:  jd.State,
+				Reason: fmt.Sprintf("attempt %d at %s", attempts, jd.RestartAt.Format(time.RFC3339)),
+			}
+			if err := AppendEvent(ctx, tx, uid, event); err != nil {
+				return err
+			}
+		case exhausted:
+			event := &gobs.JobEvent{
+				Kind:   RestartExhaustedEvent,
+				State:  jd.State,
+				Reason: fmt.Sprintf("%d restart attempts are exhausted", attempts),
+			}
+			if err := AppendEvent(ctx, tx, uid, event); err != nil {
+				return err
+			}
+		}
+
 		r.mu.Lock()
 		defer r.mu.Unlock()
 
@@ -110,6 +204,20 @@ func (r *Runner) Resume(ctx context.Context, uid string, fn Func, fctx context.C
 		// Remove the job only after its final state is successfully written to
 		// database.
 		delete(r.jobMap, uid)
+		r.publish(uid, event)
+
+		// Follow-up job must be started asynchronously cause it needs the lock.
+		if childID != "" && r.startFollowUp != nil {
+			go r.startFollowUp(childID)
+		}
+
+		if at := jd.RestartAt; failed && !at.IsZero() {
+			log.Printf("job %q is scheduled to restart at %s", uid, at)
+			go r.scheduleRestart(uid, at)
+		}
+		if exhausted && r.escalate != nil {
+			go r.escalate(uid, attempts, status)
+		}
 		return errDone
 	}
 
@@ -139,17 +247,110 @@ func (r *Runner) Resume(ctx context.Context, uid string, fn Func, fctx context.C
 	}
 
 	jd.State = gobs.RUNNING
+	jd.RestartAt = time.Time{}
 	if err := kvutil.Set(ctx, tx, key, jd); err != nil {
 		return err
 	}
+	resumed := &gobs.JobEvent{Kind: ResumedEvent, State: jd.State}
+	if err := AppendEvent(ctx, tx, uid, resumed); err != nil {
+		return err
+	}
 	if err := tx.Commit(ctx); err != nil {
 		return err
 	}
+	r.publish(uid, resumed)
 
 	r.jobMap[uid] = Run(wrapper, fctx)
 	return nil
 }
 
+// addFollowUp creates the follow-up job of a completed job and adds it as a
+// PAUSED job within the input transaction.
+func (r *Runner) addFollowUp(ctx context.Context, rw kv.ReadWriter, parent *gobs.JobData) (string, error) {
+	r.mu.Lock()
+	create := r.createFollowUp
+	r.mu.Unlock()
+
+	if create == nil {
+		return "", fmt.Errorf("follow-up jobs are not supported: %w", os.ErrInvalid)
+	}
+
+	uid, typename, save, err := create(ctx, rw, parent)
+	if err != nil {
+		return "", err
+	}
+
+	key := path.Join(Keyspace, uid)
+	if _, err := kvutil.Get[gobs.JobData](ctx, rw, key); err == nil || !errors.Is(err, os.ErrNotExist) {
+		if err == nil {
+			return "", fmt.Errorf("follow-up job with uid %q already exists: %w", uid, os.ErrExist)
+		}
+		return "", fmt.Errorf("could not read job data from db: %w", err)
+	}
+
+	if err := save(ctx, rw); err != nil {
+		return "", fmt.Errorf("could not save follow-up job: %w", err)
+	}
+
+	jd := &gobs.JobData{
+		ID:       uid,
+		Typename: typename,
+		State:    gobs.PAUSED,
+	}
+	if err := kvutil.Set(ctx, rw, key, jd); err != nil {
+		return "", err
+	}
+	event := &gobs.JobEvent{
+		Kind:   CreatedEvent,
+		State:  jd.State,
+		Reason: fmt.Sprintf("follow-up of job %s", parent.ID),
+	}
+	if err := AppendEvent(ctx, rw, uid, event); err != nil {
+		return "", err
+	}
+	return uid, nil
+}
+
+// SetFollowUp declares a follow-up job for an existing job. A nil follow-up
+// removes the declaration.
+func (r *Runner) SetFollowUp(ctx context.Context, writer kv.ReadWriter, uid string, followUp *gobs.JobFollowUp) error {
+	rw := writer
+	var tx kv.Transaction
+	if writer == nil {
+		tmp, err := r.db.NewTransaction(ctx)
+		if err != nil {
+			return err
+		}
+		defer tmp.Rollback(ctx)
+
+		tx, rw = tmp, tmp
+	}
+
+	key := path.Join(Keyspace, uid)
+	jd, err := kvutil.Get[gobs.JobData](ctx, rw, key)
+	if err != nil {
+		return fmt.Errorf("could not read job data from db: %w", err)
+	}
+	if jd.State.IsDone() {
+		return fmt.Errorf("job %q is already complete", uid)
+	}
+	if jd.FollowUp != nil && jd.FollowUp.ChildID != "" {
+		return fmt.Errorf("follow-up job for %q is already created", uid)
+	}
+
+	jd.FollowUp = followUp
+	if err := kvutil.Set(ctx, rw, key, jd); err != nil {
+		return fmt.Errorf("could not update follow-up for job %q: %w", uid, err)
+	}
+
+	if writer == nil {
+		if err := tx.Commit(ctx); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 // Get returns an existing job's information.
 func (r *Runner) Get(ctx context.Context, reader kv.Reader, uid string) (*gobs.JobData, error) {
 	if reader == nil {
@@ -206,6 +407,72 @@ func (r *Runner) UpdateFlags(ctx context.Context, writer kv.ReadWriter, uid stri
 	return nil
 }
 
+// SetTrigger updates the price trigger of an existing job. Jobs with a trigger
+// are armed and job functions are expected to wait till the trigger fires
+// before trading. A nil trigger disarms the job.
+func (r *Runner) SetTrigger(ctx context.Context, writer kv.ReadWriter, uid string, trigger *gobs.JobTrigger) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.jobMap[uid]; ok {
+		return fmt.Errorf("trigger cannot be updated on running job %q", uid)
+	}
+
+	rw := writer
+	var tx kv.Transaction
+	if writer == nil {
+		tmp, err := r.db.NewTransaction(ctx)
+		if err != nil {
+			return err
+		}
+		defer tmp.Rollback(ctx)
+
+		tx, rw = tmp, tmp
+	}
+
+	key := path.Join(Keyspace, uid)
+	jd, err := kvutil.Get[gobs.JobData](ctx, rw, key)
+	if err != nil {
+		return fmt.Errorf("could not read job data from db: %w", err)
+	}
+	if jd.State.IsDone() {
+		return fmt.Errorf("job %q is already complete", uid)
+	}
+
+	jd.Trigger = trigger
+	if err := kvutil.Set(ctx, rw, key, jd); err != nil {
+		return fmt.Errorf("could not update trigger for job %q: %w", uid, err)
+	}
+
+	if writer == nil {
+		if err := tx.Commit(ctx); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// FireTrigger records that the trigger of an armed job has fired, so that job
+// doesn't wait for the trigger again after a restart.
+func (r *Runner) FireTrigger(ctx context.Context, uid string, at time.Time) error {
+	fire := func(ctx context.Context, rw kv.ReadWriter) error {
+		key := path.Join(Keyspace, uid)
+		jd, err := kvutil.Get[gobs.JobData](ctx, rw, key)
+		if err != nil {
+			return fmt.Errorf("could not read job data from db: %w", err)
+		}
+		if jd.Trigger == nil {
+			return fmt.Errorf("job %q has no trigger: %w", uid, os.ErrInvalid)
+		}
+		if !jd.Trigger.FiredAt.IsZero() {
+			return nil
+		}
+		jd.Trigger.FiredAt = at
+		return kvutil.Set(ctx, rw, key, jd)
+	}
+	return kv.WithReadWriter(ctx, r.db, fire)
+}
+
 // Add creates a new job in the database. Jobs are created in PAUSED state and
 // must be resumed to begin execution. If writer is non-nil, then job is added
 // to the database within the input writer's transaction and database update
@@ -247,11 +514,16 @@ func (r *Runner) Add(ctx context.Context, writer kv.ReadWriter, uid, typename st
 	if err := kvutil.Set(ctx, rw, key, jd); err != nil {
 		return err
 	}
+	created := &gobs.JobEvent{Kind: CreatedEvent, State: jd.State}
+	if err := AppendEvent(ctx, rw, uid, created); err != nil {
+		return err
+	}
 
 	if writer == nil {
 		if err := tx.Commit(ctx); err != nil {
 			return err
 		}
+		r.publish(uid, created)
 	}
 	return nil
 }
@@ -283,6 +555,9 @@ func (r *Runner) Remove(ctx context.Context, writer kv.ReadWriter, uid string) e
 	if err := rw.Delete(ctx, key); err != nil {
 		return fmt.Errorf("could not delete key %q: %w", key, err)
 	}
+	if err := deleteHistory(ctx, rw, uid); err != nil {
+		return err
+	}
 
 	if writer == nil {
 		if err := tx.Commit(ctx); err != nil {
@@ -352,13 +627,19 @@ func (r *Runner) Pause(ctx context.Context, uid string) error {
 	}
 
 	jd.State = gobs.PAUSED
+	jd.RestartAt = time.Time{}
 	if err := kvutil.Set(ctx, tx, key, jd); err != nil {
 		return fmt.Errorf("could not mark job %q as paused: %w", uid, err)
 	}
+	paused := &gobs.JobEvent{Kind: PausedEvent, State: jd.State}
+	if err := AppendEvent(ctx, tx, uid, paused); err != nil {
+		return err
+	}
 
 	if err := tx.Commit(ctx); err != nil {
 		return err
 	}
+	r.publish(uid, paused)
 	return nil
 }
 
@@ -401,9 +682,14 @@ func (r *Runner) Cancel(ctx context.Context, uid string) (*gobs.JobData, error)
 	if err := kvutil.Set(ctx, tx, key, jd); err != nil {
 		return nil, fmt.Errorf("could not mark job %q as canceled: %w", uid, err)
 	}
+	canceled := &gobs.JobEvent{Kind: CanceledEvent, State: jd.State}
+	if err := AppendEvent(ctx, tx, uid, canceled); err != nil {
+		return nil, err
+	}
 
 	if err := tx.Commit(ctx); err != nil {
 		return nil, err
 	}
+	r.publish(uid, canceled)
 	return jd, nil
 }
@@ -6,10 +6,15 @@ import (
 	"context"
 	"errors"
 	"os"
+	"slices"
 	"testing"
+	"time"
 
 	"github.com/bvk/tradebot/gobs"
+	"github.com/bvk/tradebot/kvutil"
+	"github.com/bvkgo/kv"
 	"github.com/bvkgo/kv/kvmemdb"
+	"github.com/shopspring/decimal"
 )
 
 func TestRunner1(t *testing.T) {
@@ -216,3 +221,399 @@ func TestRunner3(t *testing.T) {
 		t.Fatalf("wanted non-nil, got %v", err)
 	}
 }
+
+func TestRunnerTrigger(t *testing.T) {
+	ctx := context.Background()
+	db := kvmemdb.New()
+
+	runner := NewRunner(db)
+	defer runner.PauseAll(ctx)
+
+	if err := runner.Add(ctx, nil, "1", "JobOne"); err != nil {
+		t.Fatal(err)
+	}
+	if err := runner.FireTrigger(ctx, "1", time.Now()); err == nil || !errors.Is(err, os.ErrInvalid) {
+		t.Fatalf("wanted ErrInvalid, got %v", err)
+	}
+
+	trigger := &gobs.JobTrigger{Kind: "below", Price: decimal.NewFromInt(100)}
+	if err := runner.SetTrigger(ctx, nil, "1", trigger); err != nil {
+		t.Fatal(err)
+	}
+	if jd, err := runner.Get(ctx, nil, "1"); err != nil {
+		t.Fatal(err)
+	} else if !jd.IsArmed() {
+		t.Fatalf("wanted job to be armed")
+	}
+
+	if err := runner.FireTrigger(ctx, "1", time.Now()); err != nil {
+		t.Fatal(err)
+	}
+	if jd, err := runner.Get(ctx, nil, "1"); err != nil {
+		t.Fatal(err)
+	} else if jd.IsArmed() {
+		t.Fatalf("wanted job to be not armed after the trigger fired")
+	} else if jd.State != gobs.PAUSED {
+		t.Fatalf("wanted PAUSED, got %v", jd.State)
+	}
+}
+
+func TestRunnerFollowUp(t *testing.T) {
+	ctx := context.Background()
+	db := kvmemdb.New()
+
+	runner := NewRunner(db)
+	defer runner.PauseAll(ctx)
+
+	startCh := make(chan string, 1)
+	create := func(ctx context.Context, r kv.Reader, parent *gobs.JobData) (string, string, func(context.Context, kv.ReadWriter) error, error) {
+		if parent.FollowUp.Typename != "JobTwo" {
+			return "", "", nil, os.ErrInvalid
+		}
+		return "2", "JobTwo", func(context.Context, kv.ReadWriter) error { return nil }, nil
+	}
+	runner.SetFollowUpFuncs(create, func(uid string) { startCh <- uid })
+
+	if err := runner.Add(ctx, nil, "1", "JobOne"); err != nil {
+		t.Fatal(err)
+	}
+	if err := runner.SetFollowUp(ctx, nil, "1", &gobs.JobFollowUp{Typename: "JobTwo"}); err != nil {
+		t.Fatal(err)
+	}
+
+	done := func(ctx context.Context) error { return nil }
+	if err := runner.Resume(ctx, "1", done, ctx); err != nil {
+		t.Fatal(err)
+	}
+	if uid := <-startCh; uid != "2" {
+		t.Fatalf("wanted follow-up job 2 to start, got %q", uid)
+	}
+
+	if jd, err := runner.Get(ctx, nil, "1"); err != nil {
+		t.Fatal(err)
+	} else if jd.State != gobs.COMPLETED {
+		t.Fatalf("wanted COMPLETED, got %v", jd.State)
+	} else if jd.FollowUp.ChildID != "2" {
+		t.Fatalf("wanted follow-up child id 2, got %q", jd.FollowUp.ChildID)
+	}
+
+	if jd, err := runner.Get(ctx, nil, "2"); err != nil {
+		t.Fatal(err)
+	} else if jd.State != gobs.PAUSED || jd.Typename != "JobTwo" {
+		t.Fatalf("wanted PAUSED JobTwo, got %v %v", jd.State, jd.Typename)
+	}
+}
+
+func TestRunnerFollowUpExists(t *testing.T) {
+	ctx := context.Background()
+	db := kvmemdb.New()
+
+	runner := NewRunner(db)
+	defer runner.PauseAll(ctx)
+
+	changes, err := runner.StateChanges()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer changes.Close()
+
+	// Follow-up job uid is already used by an existing job, so the follow-up
+	// must fail without saving the new job.
+	const childKey = "/test/2"
+	create := func(ctx context.Context, r kv.Reader, parent *gobs.JobData) (string, string, func(context.Context, kv.ReadWriter) error, error) {
+		save := func(ctx context.Context, rw kv.ReadWriter) error {
+			return kvutil.Set(ctx, rw, childKey, &gobs.JobData{ID: "2"})
+		}
+		return "2", "JobTwo", save, nil
+	}
+	runner.SetFollowUpFuncs(create, func(uid string) { t.Errorf("unexpected follow-up job %q is started", uid) })
+
+	if err := runner.Add(ctx, nil, "1", "JobOne"); err != nil {
+		t.Fatal(err)
+	}
+	if err := runner.Add(ctx, nil, "2", "JobOne"); err != nil {
+		t.Fatal(err)
+	}
+	if err := runner.SetFollowUp(ctx, nil, "1", &gobs.JobFollowUp{Typename: "JobTwo"}); err != nil {
+		t.Fatal(err)
+	}
+
+	done := func(ctx context.Context) error { return nil }
+	if err := runner.Resume(ctx, "1", done, ctx); err != nil {
+		t.Fatal(err)
+	}
+	rctx, cancel := context.WithTimeout(ctx, time.Second)
+	defer cancel()
+	for v := range changes.All(rctx, &err) {
+		if v.UID == "1" && v.Event.State == gobs.COMPLETED {
+			break
+		}
+	}
+	if err := rctx.Err(); err != nil {
+		t.Fatalf("wanted job to complete: %v", err)
+	}
+
+	if jd, err := runner.Get(ctx, nil, "1"); err != nil {
+		t.Fatal(err)
+	} else if jd.State != gobs.COMPLETED {
+		t.Fatalf("wanted COMPLETED, got %v", jd.State)
+	} else if jd.FollowUp.ChildID != "" || jd.FollowUp.Error == "" {
+		t.Fatalf("wanted follow-up error and no child id, got %+v", jd.FollowUp)
+	}
+	if _, err := kvutil.GetDB[gobs.JobData](ctx, db, childKey); !errors.Is(err, os.ErrNotExist) {
+		t.Fatalf("wanted follow-up job to be not saved, got %v", err)
+	}
+}
+
+func TestRunnerHistory(t *testing.T) {
+	ctx := context.Background()
+	db := kvmemdb.New()
+
+	runner := NewRunner(db)
+	defer runner.PauseAll(ctx)
+
+	if err := runner.Add(ctx, nil, "1", "JobOne"); err != nil {
+		t.Fatal(err)
+	}
+
+	ch := make(chan error)
+	jobFunc := func(ctx context.Context) error {
+		select {
+		case <-ctx.Done():
+			return context.Cause(ctx)
+		case err := <-ch:
+			return err
+		}
+	}
+
+	if err := runner.Resume(ctx, "1", jobFunc, ctx); err != nil {
+		t.Fatal(err)
+	}
+	if err := runner.Pause(ctx, "1"); err != nil {
+		t.Fatal(err)
+	}
+	if err := runner.AddEvent(ctx, nil, "1", OptionEvent, "retire=true"); err != nil {
+		t.Fatal(err)
+	}
+	if err := runner.Resume(ctx, "1", jobFunc, ctx); err != nil {
+		t.Fatal(err)
+	}
+	ch <- errors.New("some failure")
+
+	var events []*gobs.JobEvent
+	for i := 0; i < 100; i++ {
+		if jd, err := runner.Get(ctx, nil, "1"); err != nil {
+			t.Fatal(err)
+		} else if jd.State == gobs.FAILED {
+			v, err := runner.History(ctx, nil, "1")
+			if err != nil {
+				t.Fatal(err)
+			}
+			events = v
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	wants := []string{CreatedEvent, ResumedEvent, PausedEvent, OptionEvent, ResumedEvent, FailedEvent}
+	if len(events) != len(wants) {
+		t.Fatalf("wanted %d events, got %d", len(wants), len(events))
+	}
+	for i, want := range wants {
+		if events[i].Kind != want {
+			t.Fatalf("event %d: wanted %q, got %q", i, want, events[i].Kind)
+		}
+		if i > 0 && events[i].Time.Before(events[i-1].Time) {
+			t.Fatalf("event %d is out of order", i)
+		}
+	}
+	if last := events[len(events)-1]; last.State != gobs.FAILED || last.Reason != "some failure" {
+		t.Fatalf("wanted FAILED event with the error reason, got %v %q", last.State, last.Reason)
+	}
+
+	if err := runner.Remove(ctx, nil, "1"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := runner.History(ctx, nil, "1"); err == nil {
+		t.Fatalf("wanted an error for removed job")
+	}
+	var left []*gobs.JobEvent
+	if err := kv.WithReader(ctx, db, func(ctx context.Context, r kv.Reader) (err error) {
+		left, err = History(ctx, r, "1")
+		return err
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if len(left) != 0 {
+		t.Fatalf("wanted empty history for removed job, got %d events", len(left))
+	}
+}
+
+func TestRunnerRestart(t *testing.T) {
+	ctx := context.Background()
+	db := kvmemdb.New()
+
+	runner := NewRunner(db)
+	defer runner.PauseAll(ctx)
+
+	policy, err := ParseRestartPolicy("on-failure:max-attempts=2,window=1h,backoff=10ms,max-backoff=20ms")
+	if err != nil {
+		t.Fatal(err)
+	}
+	runner.SetDefaultRestartPolicy("JobOne", policy)
+
+	var runs int
+	jobFunc := func(ctx context.Context) error {
+		runs++
+		return errors.New("some failure")
+	}
+	restart := func(uid string) {
+		if err := runner.Resume(ctx, uid, jobFunc, ctx); err != nil {
+			t.Errorf("could not restart job: %v", err)
+		}
+	}
+	escalateCh := make(chan int, 1)
+	escalate := func(uid string, attempts int, status error) {
+		escalateCh <- attempts
+	}
+	runner.SetRestartFuncs(restart, escalate)
+
+	if err := runner.Add(ctx, nil, "1", "JobOne"); err != nil {
+		t.Fatal(err)
+	}
+	if err := runner.Resume(ctx, "1", jobFunc, ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case attempts := <-escalateCh:
+		if attempts != 2 {
+			t.Fatalf("wanted 2 restart attempts, got %d", attempts)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatalf("job restart attempts are not exhausted in time")
+	}
+
+	for i := 0; i < 100; i++ {
+		jd, err := runner.Get(ctx, nil, "1")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if jd.State == gobs.FAILED {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if runs != 3 {
+		t.Fatalf("wanted 3 runs, got %d", runs)
+	}
+
+	events, err := runner.History(ctx, nil, "1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var kinds []string
+	for _, e := range events {
+		kinds = append(kinds, e.Kind)
+	}
+	wants := []string{
+		CreatedEvent,
+		ResumedEvent, FailedEvent, RestartScheduledEvent,
+		ResumedEvent, FailedEvent, RestartScheduledEvent,
+		ResumedEvent, FailedEvent, RestartExhaustedEvent,
+	}
+	if !slices.Equal(kinds, wants) {
+		t.Fatalf("wanted events %v, got %v", wants, kinds)
+	}
+
+	// Job specific policy overrides the default policy.
+	if err := runner.Add(ctx, nil, "2", "JobOne"); err != nil {
+		t.Fatal(err)
+	}
+	if err := runner.SetRestartPolicy(ctx, nil, "2", &gobs.JobRestartPolicy{Mode: "never"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := runner.Resume(ctx, "2", jobFunc, ctx); err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 100; i++ {
+		jd, err := runner.Get(ctx, nil, "2")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if jd.State == gobs.FAILED {
+			if len(jd.RestartAttempts) != 0 || !jd.RestartAt.IsZero() {
+				t.Fatalf("wanted no restarts for the job")
+			}
+			return
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatalf("job with never restart policy is not failed in time")
+}
+
+func TestRunnerScheduleRestarts(t *testing.T) {
+	ctx := context.Background()
+	db := kvmemdb.New()
+
+	policy, err := ParseRestartPolicy("on-failure:max-attempts=2,window=1h,backoff=200ms,max-backoff=200ms")
+	if err != nil {
+		t.Fatal(err)
+	}
+	jobFunc := func(ctx context.Context) error {
+		return errors.New("some failure")
+	}
+
+	runner := NewRunner(db)
+	runner.SetDefaultRestartPolicy("JobOne", policy)
+	runner.SetRestartFuncs(func(uid string) {}, nil)
+
+	if err := runner.Add(ctx, nil, "1", "JobOne"); err != nil {
+		t.Fatal(err)
+	}
+	if err := runner.Resume(ctx, "1", jobFunc, ctx); err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 100; i++ {
+		jd, err := runner.Get(ctx, nil, "1")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if IsWaitingForRestart(jd) {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	// Stopping the runner drops the scheduled restart, but job must remain
+	// waiting for the restart in the database.
+	if err := runner.PauseAll(ctx); err != nil {
+		t.Fatal(err)
+	}
+	jd, err := runner.Get(ctx, nil, "1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !IsWaitingForRestart(jd) {
+		t.Fatalf("wanted job to be waiting for restart, got state %s", jd.State)
+	}
+
+	// A new runner over the same database must schedule the pending restart.
+	restartCh := make(chan string, 1)
+	runner2 := NewRunner(db)
+	defer runner2.PauseAll(ctx)
+	runner2.SetDefaultRestartPolicy("JobOne", policy)
+	runner2.SetRestartFuncs(func(uid string) { restartCh <- uid }, nil)
+	if err := runner2.ScheduleRestarts(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case uid := <-restartCh:
+		if uid != "1" {
+			t.Fatalf("wanted job 1 to be restarted, got %q", uid)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatalf("pending job restart is not rescheduled")
+	}
+}
@@ -27,6 +27,7 @@ func (r *Runner) Import(ctx context.Context, writer kv.ReadWriter, export *gobs.
 		Typename: export.Typename,
 		Flags:    export.JobFlags,
 		State:    export.JobState,
+		Labels:   export.JobLabels,
 	}
 	key := path.Join(Keyspace, export.UID)
 	if err := kvutil.Set(ctx, writer, key, jd); err != nil {
@@ -54,6 +55,7 @@ func (r *Runner) Export(ctx context.Context, reader kv.Reader, uid string, expor
 	export.JobFlags = jd.Flags
 	export.Typename = jd.Typename
 	export.JobState = jd.State
+	export.JobLabels = jd.Labels
 	return nil
 }
 
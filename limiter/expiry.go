@@ -0,0 +1,179 @@
+// Copyright (c) 2025 BVK Chaitanya
+
+package limiter
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/bvk/tradebot/exchange"
+	"github.com/bvk/tradebot/gobs"
+	"github.com/bvk/tradebot/point"
+	"github.com/shopspring/decimal"
+)
+
+// ExpiryActions are the supported fallback actions when a limiter is not
+// complete by it's deadline.
+var ExpiryActions = []string{"complete", "reprice", "aggressive"}
+
+const DefaultRepriceInterval = time.Minute
+
+var (
+	DefaultRepriceStepPct = decimal.NewFromFloat(0.1)
+	DefaultAggressivePct  = decimal.NewFromFloat(0.5)
+)
+
+// CheckExpiry returns a non-nil error if the expiry parameters are invalid.
+func CheckExpiry(e *gobs.LimiterExpiry) error {
+	if e.Deadline.IsZero() {
+		return fmt.Errorf("expiry deadline cannot be zero")
+	}
+	if !slices.Contains(ExpiryActions, e.Action) {
+		return fmt.Errorf("expiry action %q is invalid (must be one of %s)", e.Action, strings.Join(ExpiryActions, ", "))
+	}
+	if e.RepriceStepPct.IsNegative() || e.RepriceStepPct.GreaterThanOrEqual(decimal.NewFromInt(100)) {
+		return fmt.Errorf("reprice step percentage must be in between 0-100")
+	}
+	if e.RepriceInterval < 0 {
+		return fmt.Errorf("reprice interval cannot be negative")
+	}
+	if e.AggressivePct.IsNegative() || e.AggressivePct.GreaterThanOrEqual(decimal.NewFromInt(100)) {
+		return fmt.Errorf("aggressive percentage must be in between 0-100")
+	}
+	return nil
+}
+
+// SetExpiry sets a deadline and the fallback action for the limiter. Zero
+// values for the action parameters are replaced with the defaults.
+func (v *Limiter) SetExpiry(e *gobs.LimiterExpiry) error {
+	if err := CheckExpiry(e); err != nil {
+		return err
+	}
+	expiry := *e
+	if expiry.RepriceStepPct.IsZero() {
+		expiry.RepriceStepPct = DefaultRepriceStepPct
+	}
+	if expiry.RepriceInterval == 0 {
+		expiry.RepriceInterval = DefaultRepriceInterval
+	}
+	if expiry.AggressivePct.IsZero() {
+		expiry.AggressivePct = DefaultAggressivePct
+	}
+	expiry.OriginalPrice = v.point.Price
+	expiry.Price = decimal.Zero
+	expiry.ExpiredAt = time.Time{}
+	expiry.NumReprices = 0
+	v.expiry.Store(&expiry)
+	return nil
+}
+
+// Expiry returns a copy of the limiter expiry parameters or nil.
+func (v *Limiter) Expiry() *gobs.LimiterExpiry {
+	e := v.expiry.Load()
+	if e == nil {
+		return nil
+	}
+	c := *e
+	return &c
+}
+
+// updateExpiry replaces the expiry parameters with an updated copy, so that
+// readers from other goroutines never see a partial update. It must only be
+// called from the Run goroutine.
+func (v *Limiter) updateExpiry(update func(e *gobs.LimiterExpiry)) {
+	if e := v.expiry.Load(); e != nil {
+		c := *e
+		update(&c)
+		v.expiry.Store(&c)
+	}
+}
+
+func (v *Limiter) expiryAction() string {
+	e := v.expiry.Load()
+	if e == nil || e.ExpiredAt.IsZero() {
+		return ""
+	}
+	return e.Action
+}
+
+// expire marks the limiter as expired, which activates the fallback action.
+func (v *Limiter) expire(now time.Time) {
+	v.updateExpiry(func(e *gobs.LimiterExpiry) {
+		if e.ExpiredAt.IsZero() {
+			e.ExpiredAt = now
+		}
+	})
+}
+
+// pricePlaces returns the number of decimal places used for the adjusted
+// limit prices.
+func (v *Limiter) pricePlaces(e *gobs.LimiterExpiry) int32 {
+	return max(-e.OriginalPrice.Exponent(), 2)
+}
+
+// orderPoint returns the point used for the exchange orders. It is the
+// limiter's point with the limit price and the cancel price moved together
+// to the fallback action's price, so that the cancel offset is preserved.
+func (v *Limiter) orderPoint() point.Point {
+	p := v.point
+	if e := v.expiry.Load(); e != nil && e.Price.IsPositive() {
+		offset := p.Cancel.Sub(p.Price)
+		p.Price = e.Price
+		p.Cancel = p.Price.Add(offset)
+	}
+	return p
+}
+
+// reprice moves the limit price one step toward the ticker price. Returns
+// false if the limit price is already at or beyond the ticker price.
+func (v *Limiter) reprice(ticker decimal.Decimal) bool {
+	e := v.expiry.Load()
+	current := v.orderPoint().Price
+	step := e.OriginalPrice.Mul(e.RepriceStepPct).Div(decimal.NewFromInt(100))
+	var price decimal.Decimal
+	if v.IsBuy() {
+		if current.GreaterThanOrEqual(ticker) {
+			return false
+		}
+		price = decimal.Min(current.Add(step), ticker)
+	} else {
+		if current.LessThanOrEqual(ticker) {
+			return false
+		}
+		price = decimal.Max(current.Sub(step), ticker)
+	}
+	price = price.Round(v.pricePlaces(e))
+	if price.Equal(current) || !price.IsPositive() {
+		return false
+	}
+	v.updateExpiry(func(e *gobs.LimiterExpiry) {
+		e.Price = price
+		e.NumReprices++
+	})
+	return true
+}
+
+// setAggressivePrice updates the limit price so that the order crosses the
+// spread and executes immediately.
+func (v *Limiter) setAggressivePrice(ticker exchange.PriceUpdate) {
+	price, _ := ticker.PricePoint()
+	bid, ask := price, price
+	if x, ok := ticker.(exchange.BidAskUpdate); ok {
+		if b, a := x.BidAsk(); b.IsPositive() && a.IsPositive() {
+			bid, ask = b, a
+		}
+	}
+	e := v.expiry.Load()
+	pct := e.AggressivePct.Div(decimal.NewFromInt(100))
+	if v.IsBuy() {
+		price = ask.Add(ask.Mul(pct))
+	} else {
+		price = bid.Sub(bid.Mul(pct))
+	}
+	price = price.Round(v.pricePlaces(e))
+	v.updateExpiry(func(e *gobs.LimiterExpiry) {
+		e.Price = price
+	})
+}
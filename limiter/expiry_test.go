@@ -0,0 +1,184 @@
+// Copyright (c) 2025 BVK Chaitanya
+
+package limiter
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/bvk/tradebot/exchange/exchangetest"
+	"github.com/bvk/tradebot/gobs"
+	"github.com/bvk/tradebot/trader"
+	"github.com/bvkgo/kv"
+	"github.com/bvkgo/kv/kvmemdb"
+	"github.com/shopspring/decimal"
+)
+
+func newExpiryLimiter(t *testing.T, expiry *gobs.LimiterExpiry) *Limiter {
+	v := newTestLimiter(t, nil)
+	if err := v.SetExpiry(expiry); err != nil {
+		t.Fatal(err)
+	}
+	return v
+}
+
+// checkPoint verifies that limiter's point is not changed by the fallback
+// actions, both in memory and in the database.
+func checkPoint(t *testing.T, v *Limiter, rt *trader.Runtime) {
+	var loaded *Limiter
+	if err := kv.WithReader(context.Background(), rt.Database, func(ctx context.Context, r kv.Reader) (err error) {
+		loaded, err = Load(ctx, v.UID(), r)
+		return err
+	}); err != nil {
+		t.Fatal(err)
+	}
+	for _, p := range []*Limiter{v, loaded} {
+		if pt := p.Point(); !pt.Price.Equal(decimal.NewFromInt(100)) || !pt.Cancel.Equal(decimal.NewFromInt(105)) {
+			t.Fatalf("wanted limiter point to be unchanged, got %v", pt)
+		}
+	}
+	if got, want := loaded.Expiry().Price, v.Expiry().Price; !got.Equal(want) {
+		t.Fatalf("wanted fallback price %s in the database, got %s", want, got)
+	}
+}
+
+func TestExpiryComplete(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	p := newTestProduct(decimal.NewFromFloat(0.01))
+	rt := exchangetest.NewRuntime(p)
+	v := newExpiryLimiter(t, &gobs.LimiterExpiry{
+		Deadline: time.Now().Add(500 * time.Millisecond),
+		Action:   "complete",
+	})
+	errCh := exchangetest.Start(ctx, v, rt)
+
+	// Ticker at 101 places the buy at 100, but never fills it, so the limiter
+	// must complete at the deadline with only the partial fill.
+	exchangetest.TickUntil(t, p, []int64{101}, func() bool { return p.OpenOrders() > 0 })
+	half := decimal.NewFromFloat(0.5)
+	p.PartialFill("BUY", half)
+	exchangetest.TickUntilDone(t, p, []int64{101}, errCh)
+
+	if got := p.Fills("BUY"); !got.Equal(half) {
+		t.Fatalf("wanted %s bought, got %s", half, got)
+	}
+	if n := p.OpenOrders(); n != 0 {
+		t.Fatalf("wanted the open order to be canceled at the deadline, got %d open orders", n)
+	}
+	if !v.PendingSize().IsZero() {
+		t.Fatalf("wanted no pending size after the deadline, got %s", v.PendingSize())
+	}
+	if e := v.Expiry(); e.ExpiredAt.IsZero() || !e.Price.IsZero() {
+		t.Fatalf("wanted expired limiter without a fallback price, got %+v", e)
+	}
+	checkPoint(t, v, rt)
+}
+
+func TestExpiryReprice(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	p := newTestProduct(decimal.NewFromFloat(0.01))
+	rt := exchangetest.NewRuntime(p)
+	v := newExpiryLimiter(t, &gobs.LimiterExpiry{
+		Deadline:        time.Now().Add(200 * time.Millisecond),
+		Action:          "reprice",
+		RepriceStepPct:  decimal.NewFromInt(1),
+		RepriceInterval: 10 * time.Millisecond,
+	})
+	errCh := exchangetest.Start(ctx, v, rt)
+
+	// Ticker at 102 never fills the buy at 100, so the limit price must be
+	// moved by 1 in every reprice till the buy is filled at 102.
+	exchangetest.TickUntilDone(t, p, []int64{102}, errCh)
+
+	if got := p.Fills("BUY"); !got.Equal(decimal.NewFromInt(1)) {
+		t.Fatalf("wanted 1 bought, got %s", got)
+	}
+	prices := p.Prices("BUY")
+	if len(prices) != 3 || !prices[0].Equal(decimal.NewFromInt(100)) || !prices[1].Equal(decimal.NewFromInt(101)) || !prices[2].Equal(decimal.NewFromInt(102)) {
+		t.Fatalf("wanted buy orders at 100, 101 and 102, got %v", prices)
+	}
+	if e := v.Expiry(); !e.Price.Equal(decimal.NewFromInt(102)) || e.NumReprices != 2 {
+		t.Fatalf("wanted fallback price 102 after two reprices, got %+v", e)
+	}
+	checkPoint(t, v, rt)
+}
+
+func TestExpiryAggressive(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	p := newTestProduct(decimal.NewFromFloat(0.01))
+	rt := exchangetest.NewRuntime(p)
+	v := newExpiryLimiter(t, &gobs.LimiterExpiry{
+		Deadline:      time.Now().Add(200 * time.Millisecond),
+		Action:        "aggressive",
+		AggressivePct: decimal.NewFromFloat(0.5),
+	})
+	errCh := exchangetest.Start(ctx, v, rt)
+
+	// Ticker at 110 is above the cancel price, so the buy is placed only at
+	// the deadline and crosses the ticker by 0.5%.
+	exchangetest.TickUntilDone(t, p, []int64{110}, errCh)
+
+	want := decimal.NewFromFloat(110.55)
+	if got := p.Fills("BUY"); !got.Equal(decimal.NewFromInt(1)) {
+		t.Fatalf("wanted 1 bought, got %s", got)
+	}
+	if prices := p.Prices("BUY"); len(prices) != 1 || !prices[0].Equal(want) {
+		t.Fatalf("wanted one buy order at %s, got %v", want, prices)
+	}
+	if e := v.Expiry(); !e.Price.Equal(want) {
+		t.Fatalf("wanted fallback price %s, got %s", want, e.Price)
+	}
+	checkPoint(t, v, rt)
+}
+
+func TestExpiryRace(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	p := newTestProduct(decimal.NewFromFloat(0.01))
+	rt := exchangetest.NewRuntime(p)
+	v := newExpiryLimiter(t, &gobs.LimiterExpiry{
+		Deadline:        time.Now().Add(50 * time.Millisecond),
+		Action:          "reprice",
+		RepriceStepPct:  decimal.NewFromFloat(0.05),
+		RepriceInterval: time.Millisecond,
+	})
+	errCh := exchangetest.Start(ctx, v, rt)
+
+	// Save and read the limiter state while the limit price is repriced. State
+	// is saved into a separate database to avoid conflicts with the job's own
+	// transactions.
+	db := kvmemdb.New()
+	done := make(chan struct{})
+	saved := make(chan struct{})
+	go func() {
+		defer close(saved)
+		for {
+			select {
+			case <-done:
+				return
+			default:
+			}
+			if err := kv.WithReadWriter(ctx, db, v.Save); err != nil {
+				t.Error(err)
+				return
+			}
+			v.PendingSize()
+			v.Expiry()
+		}
+	}()
+	exchangetest.TickUntilDone(t, p, []int64{102}, errCh)
+	close(done)
+	<-saved
+
+	if e := v.Expiry(); e.NumReprices == 0 {
+		t.Fatalf("wanted reprices while the state is saved, got %+v", e)
+	}
+}
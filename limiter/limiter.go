@@ -61,6 +61,23 @@ type Limiter struct {
 	// orders. It's value is typically less than the total size so that large
 	// orders can be avoided.
 	sizeLimitOpt atomic.Pointer[decimal.Decimal]
+
+	// executionOpt when set and non-empty, is one of "iceberg" or "twap"
+	// execution modes, which split the pending size into multiple child
+	// orders. This option can be updated while job is running, so it needs to
+	// be an atomic.
+	executionOpt atomic.Pointer[string]
+
+	// twapIntervalOpt when non-zero, is the average delay (in nanoseconds)
+	// between the child orders in the twap execution mode. This option can be
+	// updated while job is running, so it needs to be an atomic.
+	twapIntervalOpt atomic.Int64
+
+	// expiry when non-nil, holds the deadline for the limiter and the fallback
+	// action to take after the deadline. Fallback actions update the expiry
+	// state while job is running, so it is replaced atomically with an updated
+	// copy.
+	expiry atomic.Pointer[gobs.LimiterExpiry]
 }
 
 var _ trader.Trader = &Limiter{}
@@ -113,6 +130,18 @@ func (v *Limiter) ExchangeName() string {
 	return v.exchangeName
 }
 
+// Point returns a copy of the limiter's buy or sell point.
+func (v *Limiter) Point() *point.Point {
+	p := v.point
+	return &p
+}
+
+// Options returns the options set on the limiter in the format accepted by
+// SetOption.
+func (v *Limiter) Options() map[string]string {
+	return v.options()
+}
+
 func (v *Limiter) BudgetAt(feePct decimal.Decimal) decimal.Decimal {
 	return v.point.Value().Add(v.point.FeeAt(feePct))
 }
@@ -277,6 +306,9 @@ func (v *Limiter) FilledValue() decimal.Decimal {
 }
 
 func (v *Limiter) PendingSize() decimal.Decimal {
+	if v.expiryAction() == "complete" {
+		return decimal.Zero
+	}
 	size := v.point.Size.Sub(v.FilledSize())
 	if size.LessThanOrEqual(decimal.Zero) {
 		return decimal.Zero
@@ -298,19 +330,25 @@ func (v *Limiter) compactOrderMap() {
 }
 
 func (v *Limiter) updateOrderMap(update exchange.OrderUpdate) (*exchange.SimpleOrder, error) {
-	if current, ok := v.orderMap.Load(update.ServerID()); ok {
-		if _, err := current.AddUpdate(update); err != nil {
-			return nil, err
-		}
-		return current, nil
+	current, ok := v.orderMap.Load(update.ServerID())
+	if !ok {
+		return nil, os.ErrNotExist
+	}
+	// Orders are read by Save from other goroutines, so updates are applied to
+	// a copy that replaces the current order.
+	order := *current
+	if _, err := order.AddUpdate(update); err != nil {
+		return nil, err
 	}
-	return nil, os.ErrNotExist
+	v.orderMap.Store(update.ServerID(), &order)
+	return &order, nil
 }
 
 func (v *Limiter) Save(ctx context.Context, rw kv.ReadWriter) error {
 	v.compactOrderMap()
 	gv := &gobs.LimiterState{
 		V2: &gobs.LimiterStateV2{
+			Options:        v.options(),
 			ProductID:      v.productID,
 			ExchangeName:   v.exchangeName,
 			ClientIDSeed:   v.idgen.Seed(),
@@ -321,6 +359,7 @@ func (v *Limiter) Save(ctx context.Context, rw kv.ReadWriter) error {
 				Cancel: v.point.Cancel,
 			},
 			ServerIDOrderMap: make(map[string]*gobs.Order),
+			Expiry:           v.Expiry(),
 		},
 	}
 	for k, v := range v.dupOrderMap() {
@@ -394,6 +433,7 @@ func Load(ctx context.Context, uid string, r kv.Reader) (*Limiter, error) {
 			Cancel: gv.V2.TradePoint.Cancel,
 		},
 	}
+	v.expiry.Store(gv.V2.Expiry)
 	for kk, vv := range gv.V2.ServerIDOrderMap {
 		cuuid, err := uuid.Parse(vv.ClientOrderID)
 		if err != nil {
@@ -2,8 +2,180 @@
 
 package limiter
 
-import "fmt"
+import (
+	"fmt"
+	"math/rand/v2"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// ExecutionModes are the supported values for the "execution" option. Mode
+// "limit" places the whole pending size as a single order. Mode "iceberg"
+// keeps only a clip of the size-limit option on the book and places the next
+// clip after the current clip is filled. Mode "twap" is similar to iceberg,
+// but also waits for about twap-interval duration between the clips.
+var ExecutionModes = []string{"limit", "iceberg", "twap"}
+
+const DefaultTWAPInterval = 5 * time.Minute
+
+// clipJitterPct is the maximum random variation in the clip sizes and twap
+// intervals, so that child orders are not easily recognized on the book.
+const clipJitterPct = 20
 
 func (v *Limiter) SetOption(opt, value string) (string, error) {
-	return "", fmt.Errorf("limiter option %q is invalid", opt)
+	switch key := strings.ToLower(opt); key {
+	case "execution":
+		return v.setExecutionOption(key, value)
+	case "size-limit":
+		return v.setSizeLimitOption(key, value)
+	case "twap-interval":
+		return v.setTWAPIntervalOption(key, value)
+	default:
+		return "", fmt.Errorf("limiter option %q is invalid", opt)
+	}
+}
+
+func (v *Limiter) setExecutionOption(opt, val string) (string, error) {
+	current := v.executionMode()
+	value := strings.TrimPrefix(strings.ToLower(val), "undo:")
+	if value == "" {
+		value = "limit"
+	}
+	if !slices.Contains(ExecutionModes, value) {
+		return "", fmt.Errorf("invalid value %q for the %s option (must be one of %s)", val, opt, strings.Join(ExecutionModes, ", "))
+	}
+	if value == "limit" {
+		v.executionOpt.Store(nil)
+		return "undo:" + current, nil
+	}
+	v.executionOpt.Store(&value)
+	return "undo:" + current, nil
+}
+
+func (v *Limiter) setSizeLimitOption(opt, val string) (string, error) {
+	current := ""
+	if p := v.sizeLimitOpt.Load(); p != nil {
+		current = p.String()
+	}
+	value := strings.TrimPrefix(val, "undo:")
+	if value == "" {
+		v.sizeLimitOpt.Store(nil)
+		return "undo:" + current, nil
+	}
+	size, err := decimal.NewFromString(value)
+	if err != nil {
+		return "", fmt.Errorf("could not parse %s option value %q: %w", opt, val, err)
+	}
+	if !size.IsPositive() {
+		return "", fmt.Errorf("%s option value must be positive", opt)
+	}
+	v.sizeLimitOpt.Store(&size)
+	return "undo:" + current, nil
+}
+
+func (v *Limiter) setTWAPIntervalOption(opt, val string) (string, error) {
+	current := ""
+	if d := v.twapInterval(); d != 0 {
+		current = d.String()
+	}
+	value := strings.TrimPrefix(val, "undo:")
+	if value == "" {
+		v.twapIntervalOpt.Store(0)
+		return "undo:" + current, nil
+	}
+	d, err := time.ParseDuration(value)
+	if err != nil {
+		return "", fmt.Errorf("could not parse %s option value %q: %w", opt, val, err)
+	}
+	if d <= 0 {
+		return "", fmt.Errorf("%s option value must be positive", opt)
+	}
+	v.twapIntervalOpt.Store(int64(d))
+	return "undo:" + current, nil
+}
+
+func (v *Limiter) executionMode() string {
+	if p := v.executionOpt.Load(); p != nil && *p != "" {
+		return *p
+	}
+	return "limit"
+}
+
+func (v *Limiter) twapInterval() time.Duration {
+	return time.Duration(v.twapIntervalOpt.Load())
+}
+
+func (v *Limiter) options() map[string]string {
+	opts := make(map[string]string)
+	if mode := v.executionMode(); mode != "limit" {
+		opts["execution"] = mode
+	}
+	if p := v.sizeLimitOpt.Load(); p != nil {
+		opts["size-limit"] = p.String()
+	}
+	if d := v.twapInterval(); d != 0 {
+		opts["twap-interval"] = d.String()
+	}
+	return opts
+}
+
+func (v *Limiter) checkOptions() error {
+	mode := v.executionMode()
+	if mode == "limit" {
+		return nil
+	}
+	if p := v.sizeLimitOpt.Load(); p == nil || !p.IsPositive() {
+		return fmt.Errorf("execution mode %q requires a positive size-limit option", mode)
+	}
+	return nil
+}
+
+// jitter returns a random multiplier in the [1-clipJitterPct%, 1+clipJitterPct%]
+// range.
+func jitter() decimal.Decimal {
+	pct := (2*rand.Float64() - 1) * clipJitterPct
+	return decimal.NewFromFloat(1 + pct/100)
+}
+
+// orderSize returns the size for the next exchange order. In the iceberg and
+// twap modes, pending size is split into randomized clips around the
+// size-limit, which are rounded to the size increment, but remainders smaller
+// than the minimum size are merged into the last clip. Clips are never
+// smaller than the minimum size or the size increment, so that a small
+// size-limit doesn't place the whole pending size at once.
+func (v *Limiter) orderSize(pending, minSize, increment decimal.Decimal) decimal.Decimal {
+	size := pending
+	if p := v.sizeLimitOpt.Load(); v.executionMode() != "limit" && p != nil && p.IsPositive() {
+		clip := p.Mul(jitter()).Round(8)
+		smallest := minSize
+		if increment.IsPositive() {
+			clip = clip.Div(increment).Round(0).Mul(increment)
+			smallest = decimal.Max(minSize.Div(increment).Ceil().Mul(increment), increment)
+		}
+		clip = decimal.Max(clip, smallest)
+		if clip.IsPositive() && clip.LessThan(pending) && pending.Sub(clip).GreaterThanOrEqual(minSize) {
+			size = clip
+		}
+	}
+	if size.LessThan(minSize) {
+		size = minSize
+	}
+	return size
+}
+
+// nextClipDelay returns the randomized delay before placing the next clip
+// after a clip is filled.
+func (v *Limiter) nextClipDelay() time.Duration {
+	if v.executionMode() != "twap" {
+		return 0
+	}
+	interval := v.twapInterval()
+	if interval == 0 {
+		interval = DefaultTWAPInterval
+	}
+	f, _ := jitter().Float64()
+	return time.Duration(float64(interval) * f)
 }
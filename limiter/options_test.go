@@ -0,0 +1,137 @@
+// Copyright (c) 2025 BVK Chaitanya
+
+package limiter
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/bvk/tradebot/exchange/exchangetest"
+	"github.com/bvk/tradebot/point"
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+)
+
+func newTestProduct(increment decimal.Decimal) *exchangetest.Product {
+	p := exchangetest.NewProduct("test", "BTC-USD", decimal.NewFromFloat(0.01), decimal.NewFromFloat(0.1))
+	p.SetBaseIncrement(increment)
+	return p
+}
+
+func newTestLimiter(t *testing.T, opts map[string]string) *Limiter {
+	d := decimal.NewFromInt
+	v, err := New(uuid.New().String(), "test", "BTC-USD", &point.Point{Size: d(1), Price: d(100), Cancel: d(105)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for opt, val := range opts {
+		if _, err := v.SetOption(opt, val); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return v
+}
+
+func TestOrderSize(t *testing.T) {
+	d := decimal.NewFromFloat
+	minSize, increment := d(0.01), d(0.25)
+
+	v := newTestLimiter(t, nil)
+	if got := v.orderSize(d(1), minSize, increment); !got.Equal(d(1)) {
+		t.Fatalf("wanted full pending size in the limit mode, got %s", got)
+	}
+
+	v = newTestLimiter(t, map[string]string{"execution": "iceberg", "size-limit": "0.3"})
+	for i := 0; i < 100; i++ {
+		// Clips are randomized around 0.3, but must be rounded to the increment.
+		if got := v.orderSize(d(1), minSize, increment); !got.Equal(d(0.25)) {
+			t.Fatalf("wanted clip of 0.25, got %s", got)
+		}
+		// Clips must not leave remainders smaller than the min size.
+		if got := v.orderSize(d(0.25), minSize, increment); !got.Equal(d(0.25)) {
+			t.Fatalf("wanted last clip of 0.25, got %s", got)
+		}
+	}
+
+	// Size limits below the size increment or min size must not place the whole
+	// pending size.
+	v = newTestLimiter(t, map[string]string{"execution": "iceberg", "size-limit": "0.1"})
+	for i := 0; i < 100; i++ {
+		if got := v.orderSize(d(1), minSize, increment); !got.Equal(d(0.25)) {
+			t.Fatalf("wanted smallest clip of 0.25, got %s", got)
+		}
+		if got := v.orderSize(d(1), d(0.3), increment); !got.Equal(d(0.5)) {
+			t.Fatalf("wanted smallest clip of 0.5 for min size 0.3, got %s", got)
+		}
+	}
+
+	// Size limit is used as is when size increment is unknown.
+	v = newTestLimiter(t, map[string]string{"execution": "iceberg", "size-limit": "0.3"})
+	for i := 0; i < 100; i++ {
+		got := v.orderSize(d(1), minSize, decimal.Zero)
+		if got.LessThan(d(0.24)) || got.GreaterThan(d(0.36)) {
+			t.Fatalf("wanted clip within 20%% of 0.3, got %s", got)
+		}
+	}
+}
+
+func testClips(t *testing.T, opts map[string]string) time.Duration {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	increment := decimal.NewFromFloat(0.25)
+	p := newTestProduct(increment)
+	rt := exchangetest.NewRuntime(p)
+	v := newTestLimiter(t, opts)
+
+	start := time.Now()
+	errCh := exchangetest.Start(ctx, v, rt)
+	exchangetest.TickUntilDone(t, p, []int64{101, 100}, errCh)
+	elapsed := time.Since(start)
+
+	if got := p.Fills("BUY"); !got.Equal(decimal.NewFromInt(1)) {
+		t.Fatalf("wanted 1 bought, got %s", got)
+	}
+	sizes := p.Sizes("BUY")
+	if len(sizes) != 4 {
+		t.Fatalf("wanted 4 clips, got %v", sizes)
+	}
+	for _, size := range sizes {
+		if !size.Equal(increment) {
+			t.Fatalf("wanted all clips to be %s, got %v", increment, sizes)
+		}
+	}
+	return elapsed
+}
+
+func TestIcebergClips(t *testing.T) {
+	testClips(t, map[string]string{"execution": "iceberg", "size-limit": "0.3"})
+}
+
+func TestTWAPClips(t *testing.T) {
+	elapsed := testClips(t, map[string]string{"execution": "twap", "size-limit": "0.3", "twap-interval": "100ms"})
+	// Three delays between four clips are at least 80ms each.
+	if elapsed < 240*time.Millisecond {
+		t.Fatalf("wanted twap clips to be delayed, but all clips completed in %s", elapsed)
+	}
+}
+
+func TestOptionsRace(t *testing.T) {
+	v := newTestLimiter(t, map[string]string{"execution": "twap", "size-limit": "0.3"})
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < 100; i++ {
+			v.SetOption("execution", []string{"iceberg", "twap"}[i%2])
+			v.SetOption("twap-interval", []string{"1s", "2s"}[i%2])
+		}
+	}()
+	for i := 0; i < 100; i++ {
+		v.options()
+		v.nextClipDelay()
+		v.orderSize(decimal.NewFromInt(1), decimal.NewFromFloat(0.01), decimal.NewFromFloat(0.01))
+	}
+	<-done
+}
@@ -27,6 +27,9 @@ func (v *Limiter) Run(ctx context.Context, rt *trader.Runtime) error {
 	if rt.Product.ProductID() != v.productID {
 		return os.ErrInvalid
 	}
+	if err := v.checkOptions(); err != nil {
+		return err
+	}
 	// We also need to handle resume logic here.
 	nupdated, err := v.fetchOrderMap(ctx, rt.Product)
 	if err != nil {
@@ -66,6 +69,18 @@ func (v *Limiter) Run(ctx context.Context, rt *trader.Runtime) error {
 		slog.Warn("reusing existing order as the active order", "limiter", v, "point", v.point, "order-id", activeOrderID)
 	}
 
+	// nextClipAt is the time before which next child order must not be
+	// created in the twap execution mode.
+	var nextClipAt time.Time
+
+	// expiryCh fires when the limiter deadline is reached. It is nil when the
+	// limiter has no deadline or the deadline is already handled.
+	var expiryCh <-chan time.Time
+	if e := v.expiry.Load(); e != nil && e.ExpiredAt.IsZero() {
+		expiryCh = time.After(time.Until(e.Deadline))
+	}
+	var lastRepriceAt time.Time
+
 	dirty := 0
 	flushCh := time.After(time.Minute)
 
@@ -120,6 +135,22 @@ func (v *Limiter) Run(ctx context.Context, rt *trader.Runtime) error {
 			}
 			flushCh = time.After(time.Minute)
 
+		case <-expiryCh:
+			expiryCh = nil
+			if activeOrderID != "" {
+				if err := v.cancel(localCtx, rt.Product, activeOrderID); err != nil {
+					return err
+				}
+				activeOrderID = ""
+				activeOrderAt = time.Time{}
+			}
+			v.expire(time.Now())
+			if err := kv.WithReadWriter(ctx, rt.Database, v.Save); err != nil {
+				slog.Error("expired limiter state could not be saved (will retry)", "limiter", v, "point", v.point, "err", err)
+				dirty++
+			}
+			slog.Info("limiter has reached it's deadline", "limiter", v, "point", v.point, "action", v.expiry.Load().Action, "pending", v.PendingSize())
+
 		case update := <-orderUpdatesCh:
 			dirty++
 			order, err := v.updateOrderMap(update)
@@ -131,14 +162,57 @@ func (v *Limiter) Run(ctx context.Context, rt *trader.Runtime) error {
 			if order != nil && order.IsDone() && order.ServerOrderID == activeOrderID {
 				slog.Info("limiter order is complete", "limiter", v, "point", v.point, "order-id", activeOrderID, "order-status", order.Status, "done-reason", order.DoneReason)
 				activeOrderID = ""
+				if d := v.nextClipDelay(); d > 0 && order.FilledSize.IsPositive() {
+					nextClipAt = time.Now().Add(d)
+				}
 			}
 
 		case ticker := <-tickerCh:
 			now := time.Now()
 			tickerPrice, _ := ticker.PricePoint()
 
+			switch v.expiryAction() {
+			case "reprice":
+				if now.Sub(lastRepriceAt) >= v.expiry.Load().RepriceInterval && v.reprice(tickerPrice) {
+					lastRepriceAt = now
+					dirty++
+					slog.Info("limiter price is moved toward the ticker", "limiter", v, "point", v.orderPoint(), "ticker", tickerPrice, "num-reprices", v.expiry.Load().NumReprices)
+					if activeOrderID != "" {
+						if err := v.cancel(localCtx, rt.Product, activeOrderID); err != nil {
+							return err
+						}
+						activeOrderID = ""
+						activeOrderAt = time.Time{}
+					}
+				}
+
+			case "aggressive":
+				// Replace the aggressive order if it is not filled in time because
+				// price has moved away.
+				if activeOrderID != "" && activeOrderAt.Add(v.expiry.Load().RepriceInterval).Before(now) {
+					if err := v.cancel(localCtx, rt.Product, activeOrderID); err != nil {
+						return err
+					}
+					dirty++
+					activeOrderID = ""
+					activeOrderAt = time.Time{}
+				}
+				if activeOrderID == "" && !rt.IsTripped() && !v.PendingSize().IsZero() {
+					v.setAggressivePrice(ticker)
+					id, err := v.create(localCtx, rt)
+					if err != nil {
+						return err
+					}
+					dirty++
+					activeOrderID = id
+					activeOrderAt = now
+				}
+				continue
+			}
+
+			op := v.orderPoint()
 			if v.IsSell() {
-				if tickerPrice.LessThanOrEqual(v.point.Cancel) {
+				if tickerPrice.LessThanOrEqual(op.Cancel) {
 					// Cancel an order after a minute has passed and cancel price is
 					// breached. One minute timeout reduces number of overall exchange
 					// operations.
@@ -151,8 +225,8 @@ func (v *Limiter) Run(ctx context.Context, rt *trader.Runtime) error {
 						activeOrderAt = time.Time{}
 					}
 				}
-				if tickerPrice.GreaterThan(v.point.Cancel) {
-					if activeOrderID == "" {
+				if tickerPrice.GreaterThan(op.Cancel) {
+					if activeOrderID == "" && !rt.IsTripped() && !now.Before(nextClipAt) {
 						id, err := v.create(localCtx, rt)
 						if err != nil {
 							return err
@@ -166,7 +240,7 @@ func (v *Limiter) Run(ctx context.Context, rt *trader.Runtime) error {
 			}
 
 			if v.IsBuy() {
-				if tickerPrice.GreaterThanOrEqual(v.point.Cancel) {
+				if tickerPrice.GreaterThanOrEqual(op.Cancel) {
 					// Cancel an order after a minute has passed and cancel price is
 					// breached. One minute timeout reduces number of overall exchange
 					// operations.
@@ -179,8 +253,8 @@ func (v *Limiter) Run(ctx context.Context, rt *trader.Runtime) error {
 						activeOrderAt = time.Time{}
 					}
 				}
-				if tickerPrice.GreaterThanOrEqual(v.point.Price) && tickerPrice.LessThan(v.point.Cancel) {
-					if activeOrderID == "" {
+				if tickerPrice.GreaterThanOrEqual(op.Price) && tickerPrice.LessThan(op.Cancel) {
+					if activeOrderID == "" && !rt.IsTripped() && !now.Before(nextClipAt) {
 						id, err := v.create(localCtx, rt)
 						if err != nil {
 							return err
@@ -235,21 +309,19 @@ func (v *Limiter) create(ctx context.Context, rt *trader.Runtime) (string, error
 		}
 	}
 
-	size := v.PendingSize()
-	if size.LessThan(rt.Product.BaseMinSize()) {
-		size = rt.Product.BaseMinSize()
-	}
+	size := v.orderSize(v.PendingSize(), rt.Product.BaseMinSize(), rt.Product.BaseIncrement())
+	price := v.orderPoint().Price
 
 	var err error
 	var latency time.Duration
 	var order exchange.Order
 	if v.IsSell() {
 		s := time.Now()
-		order, err = rt.Product.LimitSell(ctx, clientOrderID, size, v.point.Price)
+		order, err = rt.Product.LimitSell(ctx, clientOrderID, size, price)
 		latency = time.Now().Sub(s)
 	} else {
 		s := time.Now()
-		order, err = rt.Product.LimitBuy(ctx, clientOrderID, size, v.point.Price)
+		order, err = rt.Product.LimitBuy(ctx, clientOrderID, size, price)
 		latency = time.Now().Sub(s)
 	}
 	if err != nil {
@@ -268,7 +340,7 @@ func (v *Limiter) create(ctx context.Context, rt *trader.Runtime) (string, error
 		return "", err
 	}
 	v.orderMap.Store(orderID, sorder)
-	slog.Info("created new limit order", "limiter", v, "point", v.point, "order-id", orderID, "client-order-id", clientOrderID, "offset", offset, "latency", latency)
+	slog.Info("created new limit order", "limiter", v, "point", v.point, "order-id", orderID, "client-order-id", clientOrderID, "offset", offset, "size", size, "price", price, "execution", v.executionMode(), "latency", latency)
 	return orderID, nil
 }
 
@@ -310,8 +382,10 @@ func (v *Limiter) fetchOrderMap(ctx context.Context, product exchange.Product) (
 			}
 			// Exchanges may not keep the cancelled orders with no executed
 			// value. So, assign canceled status to non-existing orders.
-			order.Done = true
-			order.DoneReason = "NOTFOUND/CANCELED"
+			canceled := *order
+			canceled.Done = true
+			canceled.DoneReason = "NOTFOUND/CANCELED"
+			v.orderMap.Store(id, &canceled)
 			continue
 		}
 
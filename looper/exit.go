@@ -0,0 +1,162 @@
+// Copyright (c) 2025 BVK Chaitanya
+
+package looper
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+var d100 = decimal.NewFromInt(100)
+
+// Progress reports the looper progress toward it's exit conditions.
+type Progress struct {
+	UID string
+
+	Cycles    int64
+	MaxCycles int64 `json:",omitempty"`
+
+	Profit       decimal.Decimal
+	ProfitTarget *decimal.Decimal `json:",omitempty"`
+
+	EndDate *time.Time `json:",omitempty"`
+
+	// Done is true when one of the exit conditions is met. Looper completes
+	// when the next buy would have started.
+	Done bool
+
+	// ProgressPct is the largest percentage of progress toward any of the
+	// configured exit conditions.
+	ProgressPct decimal.Decimal
+}
+
+func (v *Looper) setMaxCyclesOption(opt, val string) (string, error) {
+	current := ""
+	if v.maxCyclesOpt != 0 {
+		current = strconv.FormatInt(v.maxCyclesOpt, 10)
+	}
+	value := strings.TrimPrefix(val, "undo:")
+	if value == "" {
+		v.maxCyclesOpt = 0
+		return "undo:" + current, nil
+	}
+	n, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		return "", fmt.Errorf("could not parse %s option value %q: %w", opt, val, err)
+	}
+	if n <= 0 {
+		return "", fmt.Errorf("%s option value must be positive", opt)
+	}
+	v.maxCyclesOpt = n
+	return "undo:" + current, nil
+}
+
+func (v *Looper) setProfitTargetOption(opt, val string) (string, error) {
+	current := ""
+	if v.profitTargetOpt != nil {
+		current = v.profitTargetOpt.String()
+	}
+	value := strings.TrimPrefix(val, "undo:")
+	if value == "" {
+		v.profitTargetOpt = nil
+		return "undo:" + current, nil
+	}
+	d, err := decimal.NewFromString(value)
+	if err != nil {
+		return "", fmt.Errorf("could not parse %s option value %q: %w", opt, val, err)
+	}
+	if !d.IsPositive() {
+		return "", fmt.Errorf("%s option value must be positive", opt)
+	}
+	v.profitTargetOpt = &d
+	return "undo:" + current, nil
+}
+
+// ParseEndDate parses end-date option value which is either a date in
+// YYYY-MM-DD format (in local time) or a timestamp in RFC3339 format.
+func ParseEndDate(value string) (time.Time, error) {
+	if t, err := time.ParseInLocation(time.DateOnly, value, time.Local); err == nil {
+		return t, nil
+	}
+	return time.Parse(time.RFC3339, value)
+}
+
+func (v *Looper) setEndDateOption(opt, val string) (string, error) {
+	current := ""
+	if v.endDateOpt != nil {
+		current = v.endDateOpt.Format(time.RFC3339)
+	}
+	value := strings.TrimPrefix(val, "undo:")
+	if value == "" {
+		v.endDateOpt = nil
+		return "undo:" + current, nil
+	}
+	t, err := ParseEndDate(value)
+	if err != nil {
+		return "", fmt.Errorf("could not parse %s option value %q (must be YYYY-MM-DD or RFC3339): %w", opt, val, err)
+	}
+	v.endDateOpt = &t
+	return "undo:" + current, nil
+}
+
+// completedCycles returns the number of completed buy-sell cycles.
+func (v *Looper) completedCycles() int64 {
+	var sold decimal.Decimal
+	for _, s := range v.sells {
+		sold = sold.Add(s.FilledSize())
+	}
+	return sold.Div(v.sellPoint.Size).IntPart()
+}
+
+// Progress returns the looper progress toward the max-cycles, profit-target
+// and end-date exit conditions.
+func (v *Looper) Progress(now time.Time) *Progress {
+	p := &Progress{
+		UID:          v.uid,
+		Cycles:       v.completedCycles(),
+		MaxCycles:    v.maxCyclesOpt,
+		Profit:       v.GetSummary(nil).Profit(),
+		ProfitTarget: v.profitTargetOpt,
+		EndDate:      v.endDateOpt,
+	}
+	if p.MaxCycles > 0 {
+		p.ProgressPct = decimal.Max(p.ProgressPct, decimal.NewFromInt(p.Cycles).Mul(d100).Div(decimal.NewFromInt(p.MaxCycles)))
+		p.Done = p.Done || p.Cycles >= p.MaxCycles
+	}
+	if p.ProfitTarget != nil {
+		p.ProgressPct = decimal.Max(p.ProgressPct, p.Profit.Mul(d100).Div(*p.ProfitTarget))
+		p.Done = p.Done || p.Profit.GreaterThanOrEqual(*p.ProfitTarget)
+	}
+	if p.EndDate != nil {
+		if !now.Before(*p.EndDate) {
+			p.Done = true
+		}
+	}
+	if p.Done {
+		p.ProgressPct = d100
+	}
+	p.ProgressPct = decimal.Min(p.ProgressPct, d100).Truncate(2)
+	return p
+}
+
+// exitReason returns a non-empty reason if one of the exit conditions is met.
+func (v *Looper) exitReason(now time.Time) string {
+	if v.maxCyclesOpt > 0 {
+		if n := v.completedCycles(); n >= v.maxCyclesOpt {
+			return fmt.Sprintf("completed %d buy-sell cycles", n)
+		}
+	}
+	if v.profitTargetOpt != nil {
+		if p := v.GetSummary(nil).Profit(); p.GreaterThanOrEqual(*v.profitTargetOpt) {
+			return fmt.Sprintf("realized profit %s reached the target %s", p.StringFixed(3), v.profitTargetOpt)
+		}
+	}
+	if v.endDateOpt != nil && !now.Before(*v.endDateOpt) {
+		return fmt.Sprintf("end date %s is reached", v.endDateOpt.Format(time.RFC3339))
+	}
+	return ""
+}
@@ -0,0 +1,144 @@
+// Copyright (c) 2025 BVK Chaitanya
+
+package looper
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/bvk/tradebot/exchange/exchangetest"
+	"github.com/bvkgo/kv"
+	"github.com/shopspring/decimal"
+)
+
+// loopPrices are the ticker prices that complete one buy-sell cycle of the
+// test looper.
+var loopPrices = []int64{101, 100, 106, 110}
+
+func testExitCondition(t *testing.T, opts map[string]string, wantCycles int64) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	p := newTestProduct()
+	rt := exchangetest.NewRuntime(p)
+	v := newTestLooper(t, opts)
+	errCh := exchangetest.Start(ctx, v, rt)
+
+	exchangetest.TickUntilDone(t, p, loopPrices, errCh)
+
+	want := decimal.NewFromInt(wantCycles)
+	if got := p.Fills("BUY"); !got.Equal(want) {
+		t.Fatalf("wanted %s bought, got %s", want, got)
+	}
+	if got := p.Fills("SELL"); !got.Equal(want) {
+		t.Fatalf("wanted %s sold, got %s", want, got)
+	}
+	if n := p.OpenOrders(); n != 0 {
+		t.Fatalf("wanted no open orders, got %d", n)
+	}
+	if got := v.completedCycles(); got != wantCycles {
+		t.Fatalf("wanted %d completed cycles, got %d", wantCycles, got)
+	}
+	if progress := v.Progress(time.Now()); !progress.Done || !progress.ProgressPct.Equal(d100) {
+		t.Fatalf("wanted progress to be done, got %+v", progress)
+	}
+}
+
+func TestMaxCycles(t *testing.T) {
+	testExitCondition(t, map[string]string{"max-cycles": "2"}, 2)
+}
+
+func TestProfitTarget(t *testing.T) {
+	// Every cycle makes 10 minus 0.21 in fees, so target of 15 needs two cycles.
+	testExitCondition(t, map[string]string{"profit-target": "15"}, 2)
+}
+
+func TestEndDate(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// Looper must not start a buy after the end date.
+	p := newTestProduct()
+	rt := exchangetest.NewRuntime(p)
+	past := time.Now().Add(-time.Hour).Format(time.RFC3339)
+	v := newTestLooper(t, map[string]string{"end-date": past})
+	if err := v.Run(ctx, rt); err != nil {
+		t.Fatalf("wanted looper to complete, got %v", err)
+	}
+	if sizes := p.Sizes("BUY"); len(sizes) != 0 {
+		t.Fatalf("wanted no buy orders after the end date, got %d", len(sizes))
+	}
+
+	// Buy that hasn't started filling must be abandoned at the end date.
+	p = newTestProduct()
+	rt = exchangetest.NewRuntime(p)
+	soon := time.Now().Add(time.Second).Format(time.RFC3339)
+	v = newTestLooper(t, map[string]string{"end-date": soon})
+	errCh := exchangetest.Start(ctx, v, rt)
+
+	exchangetest.TickUntilDone(t, p, []int64{101}, errCh)
+	if sizes := p.Sizes("BUY"); len(sizes) == 0 {
+		t.Fatalf("wanted a buy order before the end date")
+	}
+	if got := p.Fills("BUY"); !got.IsZero() {
+		t.Fatalf("wanted the buy to be abandoned without fills, got %s bought", got)
+	}
+	if n := p.OpenOrders(); n != 0 {
+		t.Fatalf("wanted the abandoned buy order to be canceled, got %d open orders", n)
+	}
+}
+
+func TestCompletedCyclesPartialSell(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	p := newTestProduct()
+	rt := exchangetest.NewRuntime(p)
+	v := newTestLooper(t, map[string]string{"max-cycles": "2"})
+	errCh := exchangetest.Start(ctx, v, rt)
+
+	// Complete one cycle and the second buy, then sell half of the second
+	// cycle.
+	exchangetest.TickUntil(t, p, loopPrices, func() bool { return p.Fills("BUY").Equal(decimal.NewFromInt(2)) })
+	exchangetest.TickUntil(t, p, []int64{106}, func() bool { return p.OpenOrders() > 0 })
+	half := decimal.NewFromFloat(0.5)
+	p.PartialFill("SELL", half)
+	for i := 0; !p.Fills("SELL").Equal(decimal.NewFromFloat(1.5)); i++ {
+		if i == 1000 {
+			t.Fatalf("partial sell is not filled in time")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	time.Sleep(100 * time.Millisecond)
+
+	cancel()
+	<-errCh
+
+	// Reload the looper to check the partial sell from the database.
+	var loaded *Looper
+	if err := kv.WithReader(context.Background(), rt.Database, func(ctx context.Context, r kv.Reader) (err error) {
+		loaded, err = Load(ctx, v.UID(), r)
+		return err
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if got := loaded.completedCycles(); got != 1 {
+		t.Fatalf("wanted partial sell to not complete a cycle, got %d cycles", got)
+	}
+	if progress := loaded.Progress(time.Now()); progress.Done || !progress.ProgressPct.Equal(decimal.NewFromInt(50)) {
+		t.Fatalf("wanted 50%% progress, got %+v", progress)
+	}
+
+	// Resumed looper must complete the partial sell before it completes.
+	ctx2, cancel2 := context.WithCancel(context.Background())
+	defer cancel2()
+	errCh = exchangetest.Start(ctx2, loaded, rt)
+	exchangetest.TickUntilDone(t, p, []int64{106, 110}, errCh)
+	if got := p.Fills("SELL"); !got.Equal(decimal.NewFromInt(2)) {
+		t.Fatalf("wanted 2 sold, got %s", got)
+	}
+	if got := loaded.completedCycles(); got != 2 {
+		t.Fatalf("wanted 2 completed cycles, got %d", got)
+	}
+}
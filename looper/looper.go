@@ -12,9 +12,11 @@ import (
 	"path"
 	"slices"
 	"sort"
+	"strconv"
 	"strings"
 	"sync"
 	"sync/atomic"
+	"time"
 
 	"github.com/bvk/tradebot/gobs"
 	"github.com/bvk/tradebot/kvutil"
@@ -52,6 +54,32 @@ type Looper struct {
 	retireOpt      bool
 	freezeBuysOpt  bool
 	freezeSellsOpt bool
+
+	stopLossOpt         *stopLoss
+	stopLossSlippageOpt *decimal.Decimal
+	stopLossActionOpt   string
+
+	// maxCyclesOpt, profitTargetOpt and endDateOpt when set, complete the
+	// looper before starting a new buy after the number of buy-sell cycles,
+	// the realized profit or the end date is reached.
+	maxCyclesOpt    int64
+	profitTargetOpt *decimal.Decimal
+	endDateOpt      *time.Time
+
+	// position holds the unsold inventory snapshot for the stop-loss monitor.
+	position atomic.Pointer[position]
+
+	// stopLossTicker holds the ticker price that triggered the stop-loss.
+	stopLossTicker atomic.Pointer[decimal.Decimal]
+
+	// stopLossMu protects the stoppedAt and stopLossSell fields, which are
+	// updated by the Run method while other methods can read them.
+	stopLossMu sync.Mutex
+
+	// stoppedAt is non-zero when stop-loss was triggered. stopLossSell holds the
+	// limiter that sells the inventory held at that time, if any.
+	stoppedAt    time.Time
+	stopLossSell *limiter.Limiter
 }
 
 var _ trader.Trader = &Looper{}
@@ -130,6 +158,12 @@ func (v *Looper) Actions() []*gobs.Action {
 			actions = append(actions, as[0])
 		}
 	}
+	if _, sell := v.stopLossState(); sell != nil {
+		if as := sell.Actions(); len(as) > 0 {
+			as[0].PairingKey = v.uid
+			actions = append(actions, as[0])
+		}
+	}
 	sort.Slice(actions, func(i, j int) bool {
 		return actions[i].Orders[0].CreateTime.Time.Before(actions[j].Orders[0].CreateTime.Time)
 	})
@@ -143,6 +177,37 @@ func (v *Looper) Pair() *point.Pair {
 	return &point.Pair{Buy: v.buyPoint, Sell: v.sellPoint}
 }
 
+// Options returns the options set on the looper in the format accepted by
+// SetOption.
+func (v *Looper) Options() map[string]string {
+	opts := make(map[string]string)
+	if v.retireOpt {
+		opts["retire"] = "true"
+	}
+	if v.freezeBuysOpt || v.freezeSellsOpt {
+		opts["freeze"] = v.currentFreezeValue()
+	}
+	if v.stopLossOpt != nil {
+		opts["stop-loss"] = v.stopLossOpt.String()
+	}
+	if v.stopLossSlippageOpt != nil {
+		opts["stop-loss-slippage-pct"] = v.stopLossSlippageOpt.String()
+	}
+	if v.stopLossActionOpt != "" {
+		opts["stop-loss-action"] = v.stopLossActionOpt
+	}
+	if v.maxCyclesOpt != 0 {
+		opts["max-cycles"] = strconv.FormatInt(v.maxCyclesOpt, 10)
+	}
+	if v.profitTargetOpt != nil {
+		opts["profit-target"] = v.profitTargetOpt.String()
+	}
+	if v.endDateOpt != nil {
+		opts["end-date"] = v.endDateOpt.Format(time.RFC3339)
+	}
+	return opts
+}
+
 func (v *Looper) Fees() decimal.Decimal {
 	var sum decimal.Decimal
 	for _, b := range v.buys {
@@ -151,6 +216,9 @@ func (v *Looper) Fees() decimal.Decimal {
 	for _, s := range v.sells {
 		sum = sum.Add(s.Fees())
 	}
+	if _, sell := v.stopLossState(); sell != nil {
+		sum = sum.Add(sell.Fees())
+	}
 	return sum
 }
 
@@ -167,6 +235,9 @@ func (v *Looper) SoldValue() decimal.Decimal {
 	for _, s := range v.sells {
 		sum = sum.Add(s.FilledValue())
 	}
+	if _, sell := v.stopLossState(); sell != nil {
+		sum = sum.Add(sell.FilledValue())
+	}
 	return sum
 }
 
@@ -191,11 +262,22 @@ func (v *Looper) GetSummary(r *timerange.Range) *gobs.Summary {
 		ProductID: v.productID,
 		Budget:    v.buyPoint.Value(),
 	}
+	_, stopLossSell := v.stopLossState()
 	for i := range max(len(v.buys), len(v.sells)) {
 		var ss *gobs.Summary
 		if i < len(v.sells) {
 			ss = v.sells[i].GetSummary(r)
 		}
+		// Stop-loss sell is paired with the last buy.
+		if stopLossSell != nil && i == len(v.buys)-1 {
+			if ss == nil {
+				ss = stopLossSell.GetSummary(r)
+			} else {
+				merged := *ss
+				merged.Add(stopLossSell.GetSummary(r))
+				ss = &merged
+			}
+		}
 
 		var bs *gobs.Summary
 		if i < len(v.buys) {
@@ -306,9 +388,19 @@ func (v *Looper) Save(ctx context.Context, rw kv.ReadWriter) error {
 		}
 		limiters = append(limiters, s.UID())
 	}
+	var stopLossID string
+	stoppedAt, stopLossSell := v.stopLossState()
+	if stopLossSell != nil {
+		if _, ok := v.dirtyLimiters.Load(stopLossSell); ok {
+			if err := stopLossSell.Save(ctx, rw); err != nil {
+				return fmt.Errorf("could not save stop-loss limiter: %w", err)
+			}
+		}
+		stopLossID = stopLossSell.UID()
+	}
 	gv := &gobs.LooperState{
 		V2: &gobs.LooperStateV2{
-			Options: make(map[string]string),
+			Options: v.Options(),
 
 			ProductID:    v.productID,
 			ExchangeName: v.exchangeName,
@@ -326,14 +418,11 @@ func (v *Looper) Save(ctx context.Context, rw kv.ReadWriter) error {
 				},
 			},
 			LifetimeSummary: v.GetSummary(nil),
+
+			StopLossTime:      stoppedAt,
+			StopLossLimiterID: stopLossID,
 		},
 	}
-	if v.retireOpt {
-		gv.V2.Options["retire"] = "true"
-	}
-	if v.freezeBuysOpt || v.freezeSellsOpt {
-		gv.V2.Options["freeze"] = v.currentFreezeValue()
-	}
 	if !slices.IsSorted(gv.V2.LimiterIDs) {
 		log.Printf("error: %s: limiter ids are not found in the sorted order", v.uid)
 	}
@@ -389,6 +478,14 @@ func Load(ctx context.Context, uid string, r kv.Reader) (*Looper, error) {
 		}
 		sells = append(sells, v)
 	}
+	var stopLossSell *limiter.Limiter
+	if id := gv.V2.StopLossLimiterID; id != "" {
+		s, err := limiter.Load(ctx, cleanUID(id), r)
+		if err != nil {
+			return nil, err
+		}
+		stopLossSell = s
+	}
 
 	v := &Looper{
 		uid:          uid,
@@ -396,6 +493,8 @@ func Load(ctx context.Context, uid string, r kv.Reader) (*Looper, error) {
 		exchangeName: gv.V2.ExchangeName,
 		buys:         buys,
 		sells:        sells,
+		stoppedAt:    gv.V2.StopLossTime,
+		stopLossSell: stopLossSell,
 		buyPoint: point.Point{
 			Size:   gv.V2.TradePair.Buy.Size,
 			Price:  gv.V2.TradePair.Buy.Price,
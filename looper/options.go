@@ -18,6 +18,18 @@ func (v *Looper) SetOption(opt, val string) (string, error) {
 		return v.setRetireOption(key, val)
 	case "freeze":
 		return v.setFreezeOption(key, val)
+	case "stop-loss":
+		return v.setStopLossOption(key, val)
+	case "stop-loss-slippage-pct":
+		return v.setStopLossSlippageOption(key, val)
+	case "stop-loss-action":
+		return v.setStopLossActionOption(key, val)
+	case "max-cycles":
+		return v.setMaxCyclesOption(key, val)
+	case "profit-target":
+		return v.setProfitTargetOption(key, val)
+	case "end-date":
+		return v.setEndDateOption(key, val)
 	default:
 		return "", fmt.Errorf("invalid/unsupported looper option %q", key)
 	}
@@ -72,16 +84,21 @@ func (v *Looper) setFreezeOption(opt, val string) (string, error) {
 	current := v.currentFreezeValue()
 
 	// Handle undo prefix if it exists.
-	value := strings.ToLower(val)
-	if strings.HasPrefix(value, "undo:") {
-		value = strings.TrimPrefix(value, "undo:")
-	}
+	value, undo := strings.CutPrefix(strings.ToLower(val), "undo:")
 
 	// No change.
 	if value == "" || value == current {
 		return "", nil
 	}
 
+	// Undo restores the previous value exactly instead of adding to the
+	// current value.
+	if undo {
+		v.freezeBuysOpt = value == "buy" || value == "buys" || value == "both"
+		v.freezeSellsOpt = value == "sell" || value == "sells" || value == "both"
+		return "undo:" + current, nil
+	}
+
 	if value == "buy" || value == "buys" || value == "both" {
 		v.freezeBuysOpt = true
 	}
@@ -32,6 +32,11 @@ func (v *Looper) Fix(ctx context.Context, rt *trader.Runtime) error {
 			return err
 		}
 	}
+	if _, sell := v.stopLossState(); sell != nil {
+		if err := sell.Fix(ctx, rt); err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
@@ -49,6 +54,11 @@ func (v *Looper) Refresh(ctx context.Context, rt *trader.Runtime) error {
 			return err
 		}
 	}
+	if _, sell := v.stopLossState(); sell != nil {
+		if err := sell.Refresh(ctx, rt); err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
@@ -79,6 +89,38 @@ func (v *Looper) Run(ctx context.Context, rt *trader.Runtime) error {
 		}
 	}
 
+	// A stopped out looper never trades again.
+	if stoppedAt, _ := v.stopLossState(); !stoppedAt.IsZero() {
+		return v.runStopLoss(ctx, rt)
+	}
+	if v.stopLossOpt == nil {
+		return v.run(ctx, rt)
+	}
+
+	sctx, scancel := context.WithCancelCause(ctx)
+	defer scancel(nil)
+
+	v.position.Store(v.holding())
+	go v.watchStopLoss(sctx, rt, v.stopLossOpt, scancel)
+
+	err := v.run(sctx, rt)
+	if ctx.Err() != nil || !errors.Is(context.Cause(sctx), errStopLoss) {
+		return err
+	}
+
+	ticker := v.stopLossTicker.Load()
+	for ctx.Err() == nil {
+		if err := v.stopOut(ctx, rt, *ticker); err != nil {
+			slog.Error("could not stop out the looper (will retry)", "looper", v, "err", err)
+			ctxutil.Sleep(ctx, time.Second)
+			continue
+		}
+		return v.runStopLoss(ctx, rt)
+	}
+	return context.Cause(ctx)
+}
+
+func (v *Looper) run(ctx context.Context, rt *trader.Runtime) error {
 	jobUpdatesCh := trader.GetJobUpdateChannel(ctx)
 	if jobUpdatesCh == nil {
 		slog.Warn("jobs updates channel is nil (ignored)", "looper", v)
@@ -98,6 +140,7 @@ func (v *Looper) Run(ctx context.Context, rt *trader.Runtime) error {
 		numSells, psell := sold.QuoRem(v.sellPoint.Size, 16)
 		nbuys, nsells := numBuys.IntPart(), numSells.IntPart()
 		holdings := bought.Sub(sold)
+		v.position.Store(v.holding())
 
 		action := "STOP"
 		switch {
@@ -129,6 +172,15 @@ func (v *Looper) Run(ctx context.Context, rt *trader.Runtime) error {
 			slog.Info("looper job is retired without starting a new buy", "looper", v, "bought", bought, "sold", sold, "numBuys", numBuys, "pbuy", pbuy, "numSells", numSells, "psell", psell, "nbuys", nbuys, "nsells", nsells, "holdings", holdings)
 			return nil
 		}
+		if action == "BUY" && pbuy.IsZero() {
+			if reason := v.exitReason(time.Now()); reason != "" {
+				slog.Info("looper job is complete because an exit condition is met", "looper", v, "reason", reason, "nbuys", nbuys, "nsells", nsells)
+				if rt.Messenger != nil {
+					rt.Messenger.SendMessage(ctx, time.Now(), "Looper %s in product %s (%s) is complete because %s.", v.uid, v.productID, v.exchangeName, reason)
+				}
+				return nil
+			}
+		}
 		if v.freezeBuysOpt && action == "BUY" {
 			slog.Info("looper job is frozen without starting a new buy due to freeze=buys option", "looper", v, "bought", bought, "sold", sold, "numBuys", numBuys, "pbuy", pbuy, "numSells", numSells, "psell", psell, "nbuys", nbuys, "nsells", nsells, "holdings", holdings)
 			<-ctx.Done()
@@ -174,7 +226,18 @@ func (v *Looper) Run(ctx context.Context, rt *trader.Runtime) error {
 
 			buyer := v.buys[len(v.buys)-1]
 			v.dirtyLimiters.Store(buyer, struct{}{})
-			if err := buyer.Run(ctx, rt); err != nil {
+
+			// A buy that hasn't started filling is abandoned at the end date.
+			bctx, bcancel := ctx, context.CancelFunc(func() {})
+			if v.endDateOpt != nil && buyer.FilledSize().IsZero() {
+				bctx, bcancel = context.WithDeadline(ctx, *v.endDateOpt)
+			}
+			err := buyer.Run(bctx, rt)
+			bcancel()
+			if err != nil {
+				if ctx.Err() == nil && errors.Is(err, context.DeadlineExceeded) {
+					continue
+				}
 				if ctx.Err() == nil {
 					slog.Error("could not resume last buyer (will retry)", "looper", v, "nbuys", nbuys, "err", err)
 					ctxutil.Sleep(ctx, time.Second)
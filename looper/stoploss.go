@@ -0,0 +1,329 @@
+// Copyright (c) 2024 BVK Chaitanya
+
+package looper
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/bvk/tradebot/ctxutil"
+	"github.com/bvk/tradebot/limiter"
+	"github.com/bvk/tradebot/point"
+	"github.com/bvk/tradebot/trader"
+	"github.com/bvkgo/kv"
+	"github.com/shopspring/decimal"
+	"github.com/visvasity/topic"
+)
+
+var errStopLoss = errors.New("stop-loss is triggered")
+
+var defaultStopLossSlippagePct = decimal.NewFromInt(1)
+
+// stopLoss is a downside protection rule for the looper. It is triggered when
+// the ticker price falls to an absolute price ("price:N"), falls by N percent
+// below the average cost of the held inventory ("pct:N") or when the
+// unrealized loss on the held inventory reaches N in quote currency
+// ("loss:N"). Rules are never triggered when there is no held inventory to
+// protect.
+type stopLoss struct {
+	kind  string
+	value decimal.Decimal
+}
+
+// position holds the unsold inventory size and it's cost.
+type position struct {
+	size decimal.Decimal
+	cost decimal.Decimal
+}
+
+func parseStopLoss(s string) (*stopLoss, error) {
+	kind, value, ok := strings.Cut(strings.ToLower(s), ":")
+	if !ok {
+		return nil, fmt.Errorf("stop-loss value %q must be in kind:number format", s)
+	}
+	switch kind {
+	case "price", "pct", "loss":
+	default:
+		return nil, fmt.Errorf("stop-loss kind %q is invalid (must be one of price, pct or loss)", kind)
+	}
+	d, err := decimal.NewFromString(value)
+	if err != nil {
+		return nil, fmt.Errorf("could not parse stop-loss value %q: %w", value, err)
+	}
+	if !d.IsPositive() {
+		return nil, fmt.Errorf("stop-loss value %q must be positive", value)
+	}
+	if kind == "pct" && d.GreaterThanOrEqual(decimal.NewFromInt(100)) {
+		return nil, fmt.Errorf("stop-loss percentage %q must be less than 100", value)
+	}
+	return &stopLoss{kind: kind, value: d}, nil
+}
+
+func (s *stopLoss) String() string {
+	return s.kind + ":" + s.value.String()
+}
+
+func (s *stopLoss) isTriggered(ticker decimal.Decimal, p *position) bool {
+	if p == nil || !p.size.IsPositive() {
+		return false
+	}
+	switch s.kind {
+	case "price":
+		return ticker.LessThanOrEqual(s.value)
+	case "pct":
+		avg := p.cost.Div(p.size)
+		limit := avg.Sub(avg.Mul(s.value).Div(decimal.NewFromInt(100)))
+		return ticker.LessThanOrEqual(limit)
+	case "loss":
+		loss := p.cost.Sub(ticker.Mul(p.size))
+		return loss.GreaterThanOrEqual(s.value)
+	}
+	return false
+}
+
+func (v *Looper) currentStopLossValue() string {
+	if v.stopLossOpt == nil {
+		return "none"
+	}
+	return v.stopLossOpt.String()
+}
+
+func (v *Looper) setStopLossOption(opt, val string) (string, error) {
+	current := v.currentStopLossValue()
+
+	value := strings.TrimPrefix(strings.ToLower(val), "undo:")
+	if value == "" || value == current {
+		return "", nil
+	}
+	if value == "none" {
+		v.stopLossOpt = nil
+		return "undo:" + current, nil
+	}
+	sl, err := parseStopLoss(value)
+	if err != nil {
+		return "", err
+	}
+	v.stopLossOpt = sl
+	return "undo:" + current, nil
+}
+
+func (v *Looper) stopLossSlippagePct() decimal.Decimal {
+	if v.stopLossSlippageOpt == nil {
+		return defaultStopLossSlippagePct
+	}
+	return *v.stopLossSlippageOpt
+}
+
+func (v *Looper) setStopLossSlippageOption(opt, val string) (string, error) {
+	current := v.stopLossSlippagePct().String()
+
+	value := strings.TrimPrefix(strings.ToLower(val), "undo:")
+	if value == "" || value == current {
+		return "", nil
+	}
+	d, err := decimal.NewFromString(value)
+	if err != nil {
+		return "", fmt.Errorf("could not parse %s value %q: %w", opt, value, err)
+	}
+	if d.IsNegative() || d.GreaterThanOrEqual(decimal.NewFromInt(50)) {
+		return "", fmt.Errorf("%s value %q must be in [0, 50) range", opt, value)
+	}
+	v.stopLossSlippageOpt = &d
+	return "undo:" + current, nil
+}
+
+func (v *Looper) currentStopLossAction() string {
+	if v.stopLossActionOpt == "" {
+		return "pause"
+	}
+	return v.stopLossActionOpt
+}
+
+func (v *Looper) setStopLossActionOption(opt, val string) (string, error) {
+	current := v.currentStopLossAction()
+
+	value := strings.TrimPrefix(strings.ToLower(val), "undo:")
+	if value == "" || value == current {
+		return "", nil
+	}
+	if value != "pause" && value != "retire" {
+		return "", fmt.Errorf("%s value %q is invalid (must be pause or retire)", opt, value)
+	}
+	v.stopLossActionOpt = value
+	return "undo:" + current, nil
+}
+
+// holding returns the unsold inventory and it's cost. Cost of the inventory is
+// computed from the prices of the buys that are not sold completely.
+func (v *Looper) holding() *position {
+	var bought decimal.Decimal
+	for _, b := range v.buys {
+		bought = bought.Add(b.FilledSize())
+	}
+	var sold decimal.Decimal
+	for _, s := range v.sells {
+		sold = sold.Add(s.FilledSize())
+	}
+	p := &position{size: bought.Sub(sold)}
+	if !p.size.IsPositive() {
+		return p
+	}
+
+	// Every sell is paired with the buy at the same index.
+	var unsoldSize, unsoldCost decimal.Decimal
+	for i, b := range v.buys {
+		size := b.FilledSize()
+		if !size.IsPositive() {
+			continue
+		}
+		unsold := size
+		if i < len(v.sells) {
+			unsold = unsold.Sub(v.sells[i].FilledSize())
+		}
+		if unsold.IsPositive() {
+			unsoldSize = unsoldSize.Add(unsold)
+			unsoldCost = unsoldCost.Add(b.FilledValue().Div(size).Mul(unsold))
+		}
+	}
+	if unsoldSize.IsPositive() {
+		p.cost = unsoldCost.Div(unsoldSize).Mul(p.size)
+	}
+	return p
+}
+
+// stopLossState returns the stop-loss trigger time and the stop-loss sell
+// limiter.
+func (v *Looper) stopLossState() (time.Time, *limiter.Limiter) {
+	v.stopLossMu.Lock()
+	defer v.stopLossMu.Unlock()
+
+	return v.stoppedAt, v.stopLossSell
+}
+
+func (v *Looper) setStopLossState(at time.Time, sell *limiter.Limiter) {
+	v.stopLossMu.Lock()
+	defer v.stopLossMu.Unlock()
+
+	v.stoppedAt, v.stopLossSell = at, sell
+}
+
+// watchStopLoss monitors the ticker and cancels the looper context with
+// errStopLoss when stop-loss rule is triggered. Ticker price that triggered the
+// stop-loss is saved in the stopLossTicker field.
+func (v *Looper) watchStopLoss(ctx context.Context, rt *trader.Runtime, rule *stopLoss, cancel context.CancelCauseFunc) {
+	priceUpdates, err := rt.Product.GetPriceUpdates()
+	if err != nil {
+		slog.Error("could not subscribe to price updates for stop-loss (stop-loss is disabled)", "looper", v, "err", err)
+		return
+	}
+	defer priceUpdates.Close()
+
+	tickerCh, err := topic.ReceiveCh(priceUpdates)
+	if err != nil {
+		slog.Error("could not receive price updates for stop-loss (stop-loss is disabled)", "looper", v, "err", err)
+		return
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case ticker, ok := <-tickerCh:
+			if !ok {
+				slog.Error("price updates for stop-loss are closed (stop-loss is disabled)", "looper", v)
+				return
+			}
+			price, _ := ticker.PricePoint()
+			if rule.isTriggered(price, v.position.Load()) {
+				slog.Warn("stop-loss is triggered", "looper", v, "stop-loss", rule, "ticker", price)
+				v.stopLossTicker.Store(&price)
+				cancel(errStopLoss)
+				return
+			}
+		}
+	}
+}
+
+// stopOut creates a limiter to sell all the held inventory at a price below
+// the ticker price by the slippage percentage. Looper never trades again once
+// it is stopped out.
+func (v *Looper) stopOut(ctx context.Context, rt *trader.Runtime, ticker decimal.Decimal) error {
+	holding := v.holding()
+	rule := v.currentStopLossValue()
+
+	hundred := decimal.NewFromInt(100)
+	slip := v.stopLossSlippagePct()
+	price := ticker.Sub(ticker.Mul(slip).Div(hundred))
+	// Cancel price must be below the sell price even when slippage is zero.
+	cancel := price.Sub(price.Mul(decimal.Max(slip, defaultStopLossSlippagePct)).Div(hundred))
+
+	var sell *limiter.Limiter
+	if holding.size.GreaterThanOrEqual(rt.Product.BaseMinSize()) {
+		p := &point.Point{Size: holding.size, Price: price, Cancel: cancel}
+		uid := path.Join(v.uid, "stoploss-000000")
+		s, err := limiter.New(uid, v.exchangeName, v.productID, p)
+		if err != nil {
+			return fmt.Errorf("could not create stop-loss sell limiter: %w", err)
+		}
+		sell = s
+		v.dirtyLimiters.Store(s, struct{}{})
+	}
+	v.setStopLossState(time.Now(), sell)
+	if err := kv.WithReadWriter(ctx, rt.Database, v.Save); err != nil {
+		v.setStopLossState(time.Time{}, nil)
+		return err
+	}
+
+	slog.Warn("looper is stopped out", "looper", v, "stop-loss", rule, "ticker", ticker, "holding-size", holding.size, "holding-cost", holding.cost, "sell-price", price)
+	rt.Messenger.SendMessage(ctx, time.Now(), "Stop-loss (%s) is triggered at price %s in product %s (%s); selling %s units of held inventory at %s.", rule, ticker.StringFixed(3), v.productID, v.exchangeName, holding.size, price.StringFixed(3))
+	return nil
+}
+
+// runStopLoss completes the stop-loss sell (if any) and then pauses or retires
+// the looper as per the stop-loss-action option. Paused loopers return
+// trader.ErrPaused, so that they are not resumed automatically.
+func (v *Looper) runStopLoss(ctx context.Context, rt *trader.Runtime) error {
+	jobUpdatesCh := trader.GetJobUpdateChannel(ctx)
+
+	if _, s := v.stopLossState(); s != nil && !s.PendingSize().IsZero() {
+		// Stop-loss sells must not wait for the circuit breaker.
+		srt := *rt
+		srt.Breaker = nil
+
+		v.dirtyLimiters.Store(s, struct{}{})
+		for ctx.Err() == nil {
+			if err := s.Run(ctx, &srt); err != nil {
+				if ctx.Err() == nil {
+					slog.Error("could not resume stop-loss sell limiter (will retry)", "looper", v, "err", err)
+					ctxutil.Sleep(ctx, time.Second)
+				}
+				continue
+			}
+
+			v.summary.Store(nil)
+			if err := kv.WithReadWriter(ctx, rt.Database, v.Save); err != nil {
+				slog.Error("could not save looper after stop-loss sell (ignored)", "looper", v, "err", err)
+			}
+			if jobUpdatesCh != nil {
+				jobUpdatesCh <- v.UID()
+			}
+			rt.Messenger.SendMessage(ctx, time.Now(), "Stop-loss sell is completed in product %s (%s) for %s.", v.productID, v.exchangeName, s.SoldValue().StringFixed(3))
+			break
+		}
+		if err := context.Cause(ctx); err != nil {
+			return err
+		}
+	}
+
+	if v.currentStopLossAction() == "retire" {
+		slog.Info("looper job is retired after stop-loss", "looper", v)
+		return nil
+	}
+	slog.Info("looper job is paused after stop-loss", "looper", v)
+	return fmt.Errorf("looper is stopped out: %w", trader.ErrPaused)
+}
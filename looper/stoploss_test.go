@@ -0,0 +1,148 @@
+// Copyright (c) 2025 BVK Chaitanya
+
+package looper
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/bvk/tradebot/exchange/exchangetest"
+	"github.com/bvk/tradebot/point"
+	"github.com/bvk/tradebot/trader"
+	"github.com/bvkgo/kv"
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+)
+
+func newTestProduct() *exchangetest.Product {
+	return exchangetest.NewProduct("test", "BTC-USD", decimal.NewFromFloat(0.01), decimal.NewFromFloat(0.1))
+}
+
+func newTestLooper(t *testing.T, opts map[string]string) *Looper {
+	d := decimal.NewFromInt
+	buy := &point.Point{Size: d(1), Price: d(100), Cancel: d(105)}
+	sell := &point.Point{Size: d(1), Price: d(110), Cancel: d(105)}
+	v, err := New(uuid.New().String(), "test", "BTC-USD", buy, sell)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for opt, val := range opts {
+		if _, err := v.SetOption(opt, val); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return v
+}
+
+func TestStopLossTrigger(t *testing.T) {
+	d := decimal.NewFromInt
+	hold := &position{size: d(2), cost: d(200)}
+
+	tests := []struct {
+		rule   string
+		ticker int64
+		want   bool
+	}{
+		{"price:90", 91, false},
+		{"price:90", 90, true},
+		{"pct:10", 91, false},
+		{"pct:10", 90, true},
+		{"loss:30", 86, false},
+		{"loss:30", 85, true},
+	}
+	for _, test := range tests {
+		rule, err := parseStopLoss(test.rule)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := rule.isTriggered(d(test.ticker), hold); got != test.want {
+			t.Fatalf("%s at ticker %d: wanted %v, got %v", test.rule, test.ticker, test.want, got)
+		}
+	}
+
+	// Rules are never triggered without inventory.
+	for _, s := range []string{"price:90", "pct:10", "loss:1"} {
+		rule, _ := parseStopLoss(s)
+		if rule.isTriggered(d(1), &position{}) || rule.isTriggered(d(1), nil) {
+			t.Fatalf("%s rule must not trigger without inventory", s)
+		}
+	}
+
+	for _, s := range []string{"price", "pct:100", "loss:-1", "gain:10"} {
+		if _, err := parseStopLoss(s); err == nil {
+			t.Fatalf("stop-loss %q: wanted parse error, got nil", s)
+		}
+	}
+}
+
+func testStopLossAction(t *testing.T, action string) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	p := newTestProduct()
+	rt := exchangetest.NewRuntime(p)
+	v := newTestLooper(t, map[string]string{"stop-loss": "loss:5", "stop-loss-action": action})
+	errCh := exchangetest.Start(ctx, v, rt)
+
+	// Complete the first buy.
+	exchangetest.TickUntil(t, p, []int64{101, 100}, func() bool { return p.Fills("BUY").Equal(decimal.NewFromInt(1)) })
+
+	// Loss of 5 at the ticker 95 must sell the inventory and stop the looper.
+	var status error
+	exchangetest.TickUntil(t, p, []int64{95}, func() bool {
+		select {
+		case status = <-errCh:
+			return true
+		default:
+			return false
+		}
+	})
+	if got := p.Fills("SELL"); !got.Equal(decimal.NewFromInt(1)) {
+		t.Fatalf("wanted the inventory to be sold by stop-loss, got %s sold", got)
+	}
+	if at, sell := v.stopLossState(); at.IsZero() || sell == nil {
+		t.Fatalf("wanted stop-loss state to be recorded, got %v and %v", at, sell)
+	}
+
+	switch action {
+	case "pause":
+		if !errors.Is(status, trader.ErrPaused) {
+			t.Fatalf("wanted ErrPaused for stop-loss pause action, got %v", status)
+		}
+	case "retire":
+		if status != nil {
+			t.Fatalf("wanted nil for stop-loss retire action, got %v", status)
+		}
+	}
+
+	// Stopped out looper must never trade again, even after a reload.
+	var loaded *Looper
+	if err := kv.WithReader(ctx, rt.Database, func(ctx context.Context, r kv.Reader) (err error) {
+		loaded, err = Load(ctx, v.UID(), r)
+		return err
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if at, _ := loaded.stopLossState(); at.IsZero() {
+		t.Fatalf("wanted stop-loss time to be saved")
+	}
+	err := loaded.Run(ctx, rt)
+	if action == "pause" && !errors.Is(err, trader.ErrPaused) {
+		t.Fatalf("wanted ErrPaused for the reloaded looper, got %v", err)
+	}
+	if action == "retire" && err != nil {
+		t.Fatalf("wanted nil for the reloaded looper, got %v", err)
+	}
+	if n := p.OpenOrders(); n != 0 {
+		t.Fatalf("wanted no open orders, got %d", n)
+	}
+}
+
+func TestStopLossPause(t *testing.T) {
+	testStopLossAction(t, "pause")
+}
+
+func TestStopLossRetire(t *testing.T) {
+	testStopLossAction(t, "retire")
+}
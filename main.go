@@ -12,10 +12,12 @@ import (
 
 	"github.com/bvk/tradebot/envfile"
 	"github.com/bvk/tradebot/subcmds"
+	"github.com/bvk/tradebot/subcmds/arbiter"
 	"github.com/bvk/tradebot/subcmds/coinbase"
 	"github.com/bvk/tradebot/subcmds/coinex"
 	subcmdsetrade "github.com/bvk/tradebot/subcmds/etrade"
 	"github.com/bvk/tradebot/subcmds/configure/alerts"
+	"github.com/bvk/tradebot/subcmds/configure/risk"
 	"github.com/bvk/tradebot/subcmds/db"
 	"github.com/bvk/tradebot/subcmds/exchange"
 	"github.com/bvk/tradebot/subcmds/fix"
@@ -23,6 +25,7 @@ import (
 	"github.com/bvk/tradebot/subcmds/limiter"
 	"github.com/bvk/tradebot/subcmds/looper"
 	"github.com/bvk/tradebot/subcmds/setup"
+	"github.com/bvk/tradebot/subcmds/token"
 	"github.com/bvk/tradebot/subcmds/waller"
 	"github.com/bvk/tradebot/subcmds/watcher"
 	"github.com/visvasity/cli"
@@ -74,8 +77,14 @@ func main() {
 		new(alerts.LowBalanceLimits),
 	}
 
+	riskCmds := []cli.Command{
+		new(risk.Limits),
+		new(risk.CircuitBreaker),
+	}
+
 	configureCmds := []cli.Command{
 		cli.NewGroup("alerts", "Configure Alerts", alertsCmds...),
+		cli.NewGroup("risk", "Configure Risk Controls", riskCmds...),
 	}
 
 	fixCmds := []cli.Command{
@@ -95,6 +104,13 @@ func main() {
 		new(job.Import),
 		new(job.SetName),
 		new(job.SetOption),
+		new(job.FollowUp),
+		new(job.History),
+		new(job.RestartPolicy),
+		new(job.Label),
+		new(job.Archive),
+		new(job.Restore),
+		new(job.Clone),
 	}
 
 	limiterCmds := []cli.Command{
@@ -119,12 +135,21 @@ func main() {
 		new(waller.Query),
 		new(waller.Upgrade),
 		new(waller.Simulate),
+		new(waller.Plan),
+		new(waller.Optimize),
+		new(waller.Stress),
 		new(waller.Summary),
 	}
 
 	watcherCmds := []cli.Command{
 		new(watcher.Add),
 		new(watcher.Print),
+		new(watcher.Promote),
+	}
+
+	arbiterCmds := []cli.Command{
+		new(arbiter.Add),
+		new(arbiter.Spreads),
 	}
 
 	exchangeCmds := []cli.Command{
@@ -156,10 +181,20 @@ func main() {
 		new(subcmdsetrade.CancelOrder),
 	}
 
+	tokenCmds := []cli.Command{
+		new(token.Create),
+		new(token.List),
+		new(token.Revoke),
+	}
+
 	cmds := []cli.Command{
 		new(subcmds.Run),
 		new(subcmds.Status),
 		new(subcmds.Summary),
+		new(subcmds.Budget),
+		new(subcmds.Apply),
+		new(subcmds.Diff),
+		new(subcmds.Events),
 		cli.NewGroup("configure", "Updates runtime configuration", configureCmds...),
 		cli.NewGroup("fix", "Fix misc. metadata issues", fixCmds...),
 		cli.NewGroup("job", "Control trader jobs", jobCmds...),
@@ -168,11 +203,13 @@ func main() {
 		cli.NewGroup("looper", "Manage buy-sell loops", looperCmds...),
 		cli.NewGroup("waller", "Manage trades in a price range", wallerCmds...),
 		cli.NewGroup("watcher", "Simulate trades in a price range", watcherCmds...),
+		cli.NewGroup("arbiter", "Monitor and trade cross-exchange spreads", arbiterCmds...),
 		cli.NewGroup("exchange", "View/query exchange directly", exchangeCmds...),
 		cli.NewGroup("coinbase", "Coinbase exchange operations", coinbaseCmds...),
 		cli.NewGroup("coinex", "CoinEx exchange operations", coinexCmds...),
 		cli.NewGroup("etrade", "E*TRADE exchange operations", etradeCmds...),
 		cli.NewGroup("setup", "Setup operations", setupCmds...),
+		cli.NewGroup("token", "Manage API tokens", tokenCmds...),
 	}
 	if err := cli.Run(context.Background(), cmds, os.Args[1:]); err != nil {
 		log.Fatal(err)
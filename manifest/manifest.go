@@ -0,0 +1,199 @@
+// Copyright (c) 2025 BVK Chaitanya
+
+// Package manifest implements declarative descriptions for the exchange
+// products, alert limits and trading jobs of a deployment. Manifests are
+// compared against the database to compute a plan of actions that makes the
+// database match the manifest.
+package manifest
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/bvk/tradebot/job"
+	"github.com/bvk/tradebot/point"
+	"github.com/shopspring/decimal"
+)
+
+// Version is the only manifest format version supported.
+const Version = 1
+
+// ManagedLabel is the job label that records the name of the manifest that
+// has created a job. Jobs with this label are retired when they are removed
+// from the manifest.
+const ManagedLabel = "manifest"
+
+// JobTypes are the job types that can be described in a manifest.
+var JobTypes = []string{"looper", "waller"}
+
+type Manifest struct {
+	Version int
+
+	// Name identifies the deployment described by the manifest.
+	Name string
+
+	// LowBalanceLimits when non-nil, holds the symbol to limit mapping for the
+	// low balance alerts across all exchanges.
+	LowBalanceLimits map[string]decimal.Decimal `json:",omitempty"`
+
+	// Exchanges holds the per-exchange configuration with lower-case exchange
+	// names as the keys.
+	Exchanges map[string]*Exchange `json:",omitempty"`
+
+	Jobs []*Job `json:",omitempty"`
+}
+
+type Exchange struct {
+	// Products holds the product ids to be enabled on the exchange. Products
+	// that are enabled, but not listed here are not disabled.
+	Products []string `json:",omitempty"`
+
+	// LowBalanceLimits when non-nil, holds the exchange specific limits for the
+	// low balance alerts.
+	LowBalanceLimits map[string]decimal.Decimal `json:",omitempty"`
+}
+
+type Job struct {
+	Name string
+
+	// Type must be one of the JobTypes.
+	Type string
+
+	Exchange string
+	Product  string
+
+	// Pairs holds the buy/sell pairs for the job. Looper jobs must have exactly
+	// one pair.
+	Pairs []*point.Pair
+
+	Options map[string]string `json:",omitempty"`
+	Labels  map[string]string `json:",omitempty"`
+
+	// Paused when true, keeps the job in paused state.
+	Paused bool `json:",omitempty"`
+}
+
+// Load reads and validates a manifest from a JSON file.
+func Load(file string) (*Manifest, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	return Parse(data)
+}
+
+// Parse decodes and validates a manifest from JSON data. Unknown fields are
+// reported as errors to catch the typos.
+func Parse(data []byte) (*Manifest, error) {
+	m := new(Manifest)
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(m); err != nil {
+		return nil, fmt.Errorf("could not decode manifest: %w", err)
+	}
+	if err := m.Check(); err != nil {
+		return nil, fmt.Errorf("invalid manifest: %w", err)
+	}
+	return m, nil
+}
+
+func (m *Manifest) Check() error {
+	if m.Version != Version {
+		return fmt.Errorf("unsupported manifest version %d", m.Version)
+	}
+	if len(m.Name) == 0 {
+		return fmt.Errorf("manifest name cannot be empty")
+	}
+	// Name is used as a label value, so it must be a valid label value.
+	if strings.ContainsAny(m.Name, ",=") {
+		return fmt.Errorf("manifest name %q cannot contain comma or equal characters", m.Name)
+	}
+	if err := checkLimits(m.LowBalanceLimits); err != nil {
+		return err
+	}
+	for name, ex := range m.Exchanges {
+		if name != strings.ToLower(name) {
+			return fmt.Errorf("exchange name %q must be in lower case", name)
+		}
+		if ex == nil {
+			return fmt.Errorf("exchange %q configuration cannot be empty", name)
+		}
+		for _, pid := range ex.Products {
+			if _, _, ok := strings.Cut(pid, "-"); !ok {
+				return fmt.Errorf("product id %q in exchange %q must be of the form BASE-QUOTE", pid, name)
+			}
+		}
+		if err := checkLimits(ex.LowBalanceLimits); err != nil {
+			return fmt.Errorf("exchange %q: %w", name, err)
+		}
+	}
+	var names []string
+	for i, j := range m.Jobs {
+		if j == nil {
+			return fmt.Errorf("job %d cannot be empty", i)
+		}
+		if err := j.Check(); err != nil {
+			return fmt.Errorf("job %d (%s): %w", i, j.Name, err)
+		}
+		if slices.Contains(names, j.Name) {
+			return fmt.Errorf("job name %q is repeated", j.Name)
+		}
+		names = append(names, j.Name)
+	}
+	return nil
+}
+
+func (j *Job) Check() error {
+	if len(j.Name) == 0 {
+		return fmt.Errorf("job name cannot be empty")
+	}
+	if !slices.Contains(JobTypes, strings.ToLower(j.Type)) {
+		return fmt.Errorf("job type %q must be one of %s", j.Type, strings.Join(JobTypes, ", "))
+	}
+	if len(j.Exchange) == 0 {
+		return fmt.Errorf("exchange name cannot be empty")
+	}
+	if len(j.Product) == 0 {
+		return fmt.Errorf("product id cannot be empty")
+	}
+	if len(j.Pairs) == 0 {
+		return fmt.Errorf("buy/sell pairs cannot be empty")
+	}
+	if strings.EqualFold(j.Type, "looper") && len(j.Pairs) != 1 {
+		return fmt.Errorf("looper jobs must have exactly one buy/sell pair")
+	}
+	for i, p := range j.Pairs {
+		if p == nil {
+			return fmt.Errorf("buy/sell pair %d cannot be empty", i)
+		}
+		if err := p.Check(); err != nil {
+			return fmt.Errorf("invalid buy/sell pair %d: %w", i, err)
+		}
+	}
+	if _, ok := j.Options["retire"]; ok {
+		return fmt.Errorf("retire option cannot be used in manifests; remove the job instead")
+	}
+	if err := job.CheckLabels(j.Labels); err != nil {
+		return err
+	}
+	if _, ok := j.Labels[ManagedLabel]; ok {
+		return fmt.Errorf("label %q is managed automatically", ManagedLabel)
+	}
+	return nil
+}
+
+func checkLimits(limits map[string]decimal.Decimal) error {
+	for symbol, limit := range limits {
+		if symbol != strings.ToUpper(symbol) {
+			return fmt.Errorf("symbol %q must be in upper case", symbol)
+		}
+		if limit.IsNegative() {
+			return fmt.Errorf("low balance limit for %q cannot be negative", symbol)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,248 @@
+// Copyright (c) 2025 BVK Chaitanya
+
+package manifest
+
+import (
+	"context"
+	"path"
+	"testing"
+
+	"github.com/bvk/tradebot/gobs"
+	"github.com/bvk/tradebot/job"
+	"github.com/bvk/tradebot/kvutil"
+	"github.com/bvk/tradebot/looper"
+	"github.com/bvk/tradebot/namer"
+	"github.com/bvk/tradebot/point"
+	"github.com/bvk/tradebot/server"
+	"github.com/bvkgo/kv"
+	"github.com/bvkgo/kv/kvmemdb"
+	"github.com/shopspring/decimal"
+)
+
+const testManifest = `{
+  "Version": 1,
+  "Name": "test",
+  "LowBalanceLimits": {"USD": "100"},
+  "Exchanges": {"coinbase": {"Products": ["BTC-USD", "ETH-USD"]}},
+  "Jobs": [{
+    "Name": "btc-loop",
+    "Type": "looper",
+    "Exchange": "coinbase",
+    "Product": "BTC-USD",
+    "Pairs": [{
+      "Buy": {"Size": "1", "Price": "100", "Cancel": "101"},
+      "Sell": {"Size": "1", "Price": "110", "Cancel": "109"}
+    }],
+    "Options": {"max-cycles": "10"}
+  }, {
+    "Name": "eth-loop",
+    "Type": "looper",
+    "Exchange": "coinbase",
+    "Product": "ETH-USD",
+    "Pairs": [{
+      "Buy": {"Size": "1", "Price": "10", "Cancel": "11"},
+      "Sell": {"Size": "1", "Price": "12", "Cancel": "11.5"}
+    }]
+  }]
+}`
+
+func TestParse(t *testing.T) {
+	if _, err := Parse([]byte(testManifest)); err != nil {
+		t.Fatal(err)
+	}
+
+	bad := []string{
+		`{"Version": 2, "Name": "test"}`,
+		`{"Version": 1}`,
+		`{"Version": 1, "Name": "test", "Unknown": true}`,
+		`{"Version": 1, "Name": "test", "Exchanges": {"Coinbase": {}}}`,
+		`{"Version": 1, "Name": "test", "Jobs": [{"Name": "a", "Type": "limiter", "Exchange": "x", "Product": "A-B"}]}`,
+		`{"Version": 1, "Name": "test", "Jobs": [{"Name": "a", "Type": "looper", "Exchange": "x", "Product": "A-B", "Pairs": []}]}`,
+	}
+	for i, s := range bad {
+		if _, err := Parse([]byte(s)); err == nil {
+			t.Fatalf("%d: wanted non-nil error for %s", i, s)
+		}
+	}
+}
+
+func addLooper(ctx context.Context, t *testing.T, db kv.Database, uid, name, product string, buy, sell *point.Point, labels map[string]string) {
+	loop, err := looper.New(uid, "coinbase", product, buy, sell)
+	if err != nil {
+		t.Fatal(err)
+	}
+	add := func(ctx context.Context, rw kv.ReadWriter) error {
+		if err := loop.Save(ctx, rw); err != nil {
+			return err
+		}
+		jd := &gobs.JobData{ID: uid, Typename: "Looper", State: gobs.PAUSED, Labels: labels}
+		if err := kvutil.Set(ctx, rw, path.Join(job.Keyspace, uid), jd); err != nil {
+			return err
+		}
+		return namer.SetName(ctx, rw, name, uid, "Looper")
+	}
+	if err := kv.WithReadWriter(ctx, db, add); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestPlan(t *testing.T) {
+	ctx := context.Background()
+	db := kvmemdb.New()
+
+	m, err := Parse([]byte(testManifest))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	state := &gobs.ServerState{
+		ExchangeMap: map[string]*gobs.ServerExchangeState{
+			"coinbase": {EnabledProductIDs: []string{"BTC-USD"}},
+		},
+	}
+	if err := kvutil.SetDB(ctx, db, server.ServerStateKey, state); err != nil {
+		t.Fatal(err)
+	}
+
+	d := decimal.NewFromInt
+	// Existing btc-loop matches the manifest except for the options and labels.
+	addLooper(ctx, t, db, "11111111-1111-1111-1111-111111111111", "btc-loop", "BTC-USD",
+		&point.Point{Size: d(1), Price: d(100), Cancel: d(101)},
+		&point.Point{Size: d(1), Price: d(110), Cancel: d(109)}, nil)
+	// Existing eth-loop has a different buy price.
+	addLooper(ctx, t, db, "22222222-2222-2222-2222-222222222222", "eth-loop", "ETH-USD",
+		&point.Point{Size: d(1), Price: d(9), Cancel: d(10)},
+		&point.Point{Size: d(1), Price: d(12), Cancel: d(11)}, map[string]string{ManagedLabel: "test"})
+	// Old job is created by the manifest, but removed from it.
+	addLooper(ctx, t, db, "33333333-3333-3333-3333-333333333333", "old-loop", "BTC-USD",
+		&point.Point{Size: d(1), Price: d(100), Cancel: d(101)},
+		&point.Point{Size: d(1), Price: d(110), Cancel: d(109)}, map[string]string{ManagedLabel: "test"})
+
+	var actions []*Action
+	plan := func(ctx context.Context, r kv.Reader) (err error) {
+		actions, err = Plan(ctx, r, m)
+		return err
+	}
+	if err := kv.WithReader(ctx, db, plan); err != nil {
+		t.Fatal(err)
+	}
+
+	var kinds []string
+	for _, a := range actions {
+		t.Logf("%s", a)
+		kinds = append(kinds, a.Kind)
+	}
+	want := []string{EnableProduct, SetAlerts, SetOption, SetLabels, Resume, Replace, Retire}
+	if len(kinds) != len(want) {
+		t.Fatalf("wanted %v, got %v", want, kinds)
+	}
+	for i := range want {
+		if kinds[i] != want[i] {
+			t.Fatalf("wanted %v, got %v", want, kinds)
+		}
+	}
+	if a := actions[0]; a.Product != "ETH-USD" {
+		t.Fatalf("wanted ETH-USD product to be enabled, got %s", a.Product)
+	}
+	if a := actions[5]; a.Name != "eth-loop" || !a.Retire {
+		t.Fatalf("wanted eth-loop to be retired and replaced, got %s", a)
+	}
+	if a := actions[6]; a.Name != "old-loop" {
+		t.Fatalf("wanted old-loop to be retired, got %s", a)
+	}
+
+	// Updated alerts must match the manifest.
+	UpdateAlerts(state, m)
+	if reason := alertsDiff(state.AlertsConfig, m); reason != "" {
+		t.Fatalf("wanted no alerts difference, got %q", reason)
+	}
+}
+
+func setJobState(ctx context.Context, t *testing.T, db kv.Database, uid string, state gobs.State) {
+	update := func(ctx context.Context, rw kv.ReadWriter) error {
+		key := path.Join(job.Keyspace, uid)
+		jd, err := kvutil.Get[gobs.JobData](ctx, rw, key)
+		if err != nil {
+			return err
+		}
+		jd.State = state
+		return kvutil.Set(ctx, rw, key, jd)
+	}
+	if err := kv.WithReadWriter(ctx, db, update); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestPlanRunning(t *testing.T) {
+	ctx := context.Background()
+	db := kvmemdb.New()
+
+	m, err := Parse([]byte(testManifest))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	d := decimal.NewFromInt
+	labels := map[string]string{ManagedLabel: "test"}
+	addLooper(ctx, t, db, "11111111-1111-1111-1111-111111111111", "btc-loop", "BTC-USD",
+		&point.Point{Size: d(1), Price: d(100), Cancel: d(101)},
+		&point.Point{Size: d(1), Price: d(110), Cancel: d(109)}, labels)
+	addLooper(ctx, t, db, "22222222-2222-2222-2222-222222222222", "eth-loop", "ETH-USD",
+		&point.Point{Size: d(1), Price: d(9), Cancel: d(10)},
+		&point.Point{Size: d(1), Price: d(12), Cancel: d(11)}, labels)
+	addLooper(ctx, t, db, "33333333-3333-3333-3333-333333333333", "old-loop", "BTC-USD",
+		&point.Point{Size: d(1), Price: d(100), Cancel: d(101)},
+		&point.Point{Size: d(1), Price: d(110), Cancel: d(109)}, labels)
+	for _, uid := range []string{
+		"11111111-1111-1111-1111-111111111111",
+		"22222222-2222-2222-2222-222222222222",
+		"33333333-3333-3333-3333-333333333333",
+	} {
+		setJobState(ctx, t, db, uid, gobs.RUNNING)
+	}
+
+	plan := func() []*Action {
+		var actions []*Action
+		if err := kv.WithReader(ctx, db, func(ctx context.Context, r kv.Reader) (err error) {
+			actions, err = Plan(ctx, r, m)
+			return err
+		}); err != nil {
+			t.Fatal(err)
+		}
+		return actions
+	}
+
+	// Option updates and retires on the running jobs must pause and resume the
+	// jobs.
+	byKind := make(map[string]*Action)
+	for _, a := range plan() {
+		t.Logf("%s", a)
+		byKind[a.Kind] = a
+	}
+	for _, kind := range []string{SetOption, Replace, Retire} {
+		a, ok := byKind[kind]
+		if !ok {
+			t.Fatalf("wanted a %s action", kind)
+		}
+		if !a.Running {
+			t.Fatalf("wanted %s action to be marked as running", a)
+		}
+	}
+
+	// Jobs that are paused in the manifest must be paused before the option
+	// updates.
+	m.Jobs[0].Paused = true
+	var kinds []string
+	for _, a := range plan() {
+		if a.Name != "btc-loop" {
+			continue
+		}
+		if a.Kind == SetOption && a.Running {
+			t.Fatalf("wanted %s action on the paused job to be not running", a)
+		}
+		kinds = append(kinds, a.Kind)
+	}
+	if len(kinds) != 2 || kinds[0] != Pause || kinds[1] != SetOption {
+		t.Fatalf("wanted pause before the set-option, got %v", kinds)
+	}
+}
@@ -0,0 +1,335 @@
+// Copyright (c) 2025 BVK Chaitanya
+
+package manifest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"os"
+	"path"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/bvk/tradebot/gobs"
+	"github.com/bvk/tradebot/job"
+	"github.com/bvk/tradebot/kvutil"
+	"github.com/bvk/tradebot/namer"
+	"github.com/bvk/tradebot/point"
+	"github.com/bvk/tradebot/server"
+	"github.com/bvk/tradebot/trader"
+	"github.com/bvkgo/kv"
+	"github.com/shopspring/decimal"
+)
+
+// Action kinds in a plan.
+const (
+	EnableProduct = "enable-product"
+	SetAlerts     = "set-alerts"
+	Create        = "create"
+	Replace       = "replace"
+	SetOption     = "set-option"
+	SetLabels     = "set-labels"
+	Pause         = "pause"
+	Resume        = "resume"
+	Retire        = "retire"
+)
+
+// Action is a single change required to make the database match the
+// manifest.
+type Action struct {
+	Kind string
+
+	// Exchange and Product are set for the enable-product actions.
+	Exchange string
+	Product  string
+
+	// Name is the job name for the job actions.
+	Name string
+
+	// UID is the existing job's id for the job actions. It is empty for the
+	// create actions.
+	UID string
+
+	// Job is the desired job for the create and replace actions.
+	Job *Job
+
+	// Retire is true for the replace actions when the existing job is not
+	// finished and must be retired before it is replaced.
+	Retire bool
+
+	// Running is true for the set-option, replace and retire actions when the
+	// existing job is running. Options cannot be updated on the running jobs,
+	// so they must be paused for the update and resumed afterwards.
+	Running bool
+
+	// OptionKey and OptionValue are set for the set-option actions.
+	OptionKey   string
+	OptionValue string
+
+	// Labels and RemoveLabels are set for the set-labels actions.
+	Labels       map[string]string
+	RemoveLabels []string
+
+	// Reason describes the difference found between the manifest and the
+	// database.
+	Reason string
+}
+
+func (a *Action) String() string {
+	var target string
+	switch a.Kind {
+	case EnableProduct:
+		target = a.Exchange + "/" + a.Product
+	case SetAlerts:
+		target = "low-balance-limits"
+	case SetOption:
+		target = fmt.Sprintf("%s %s=%s", a.Name, a.OptionKey, a.OptionValue)
+	case SetLabels:
+		target = fmt.Sprintf("%s %s", a.Name, job.LabelsString(a.Labels))
+	default:
+		target = a.Name
+	}
+	if len(a.Reason) != 0 {
+		return fmt.Sprintf("%s %s (%s)", a.Kind, target, a.Reason)
+	}
+	return fmt.Sprintf("%s %s", a.Kind, target)
+}
+
+// optioner is implemented by the job types that can report their options.
+type optioner interface {
+	Options() map[string]string
+}
+
+// Plan compares the manifest against the database and returns the actions
+// required to make the database match the manifest.
+func Plan(ctx context.Context, r kv.Reader, m *Manifest) ([]*Action, error) {
+	var actions []*Action
+
+	state, err := kvutil.Get[gobs.ServerState](ctx, r, server.ServerStateKey)
+	if err != nil {
+		if !errors.Is(err, os.ErrNotExist) {
+			return nil, fmt.Errorf("could not load server state: %w", err)
+		}
+		state = new(gobs.ServerState)
+	}
+
+	exchanges := slices.Sorted(maps.Keys(m.Exchanges))
+	for _, name := range exchanges {
+		var enabled []string
+		if estate, ok := state.ExchangeMap[name]; ok && estate != nil {
+			enabled = estate.EnabledProductIDs
+		}
+		for _, pid := range m.Exchanges[name].Products {
+			if !slices.Contains(enabled, pid) {
+				actions = append(actions, &Action{Kind: EnableProduct, Exchange: name, Product: pid})
+			}
+		}
+	}
+
+	if reason := alertsDiff(state.AlertsConfig, m); len(reason) != 0 {
+		actions = append(actions, &Action{Kind: SetAlerts, Reason: reason})
+	}
+
+	uids := make(map[string]bool)
+	for _, j := range m.Jobs {
+		jobActions, uid, err := planJob(ctx, r, m, j)
+		if err != nil {
+			return nil, fmt.Errorf("could not compare job %q: %w", j.Name, err)
+		}
+		if len(uid) != 0 {
+			uids[uid] = true
+		}
+		actions = append(actions, jobActions...)
+	}
+
+	// Retire the unfinished jobs created by this manifest that are not
+	// described in the manifest anymore.
+	retire := func(ctx context.Context, r kv.Reader, key string, jd *gobs.JobData) error {
+		if jd.State.IsDone() || uids[jd.ID] || jd.Labels[ManagedLabel] != m.Name {
+			return nil
+		}
+		name, _, _, err := namer.Resolve(ctx, r, jd.ID)
+		if err != nil {
+			if !errors.Is(err, os.ErrNotExist) {
+				return fmt.Errorf("could not resolve job id %q: %w", jd.ID, err)
+			}
+			name = jd.ID
+		}
+		actions = append(actions, &Action{Kind: Retire, Name: name, UID: jd.ID, Running: jd.State.IsRunning(), Reason: "not in the manifest"})
+		return nil
+	}
+	begin, end := kvutil.PathRange(job.Keyspace)
+	if err := kvutil.Ascend(ctx, r, begin, end, retire); err != nil {
+		return nil, fmt.Errorf("could not scan all jobs: %w", err)
+	}
+	return actions, nil
+}
+
+// planJob returns the actions for a single job in the manifest along with the
+// uid of the existing job with the same name if any.
+func planJob(ctx context.Context, r kv.Reader, m *Manifest, j *Job) ([]*Action, string, error) {
+	_, uid, _, err := namer.Resolve(ctx, r, j.Name)
+	if err != nil {
+		if !errors.Is(err, os.ErrNotExist) {
+			return nil, "", fmt.Errorf("could not resolve job name: %w", err)
+		}
+		return []*Action{{Kind: Create, Name: j.Name, Job: j}}, "", nil
+	}
+
+	jd, err := kvutil.Get[gobs.JobData](ctx, r, path.Join(job.Keyspace, uid))
+	if err != nil {
+		if !errors.Is(err, os.ErrNotExist) {
+			return nil, "", fmt.Errorf("could not read job data: %w", err)
+		}
+		// Name exists, but the job is removed or archived.
+		return []*Action{{Kind: Create, Name: j.Name, Job: j, Reason: "existing job is not found"}}, "", nil
+	}
+	if jd.State.IsDone() {
+		reason := fmt.Sprintf("existing job is %s", strings.ToLower(string(jd.State)))
+		return []*Action{{Kind: Replace, Name: j.Name, UID: uid, Job: j, Reason: reason}}, uid, nil
+	}
+
+	v, err := server.Load(ctx, r, uid, jd.Typename)
+	if err != nil {
+		return nil, "", fmt.Errorf("could not load job %q: %w", uid, err)
+	}
+	if reason := definitionDiff(jd, v, j); len(reason) != 0 {
+		return []*Action{{Kind: Replace, Name: j.Name, UID: uid, Job: j, Retire: true, Running: jd.State.IsRunning(), Reason: reason}}, uid, nil
+	}
+
+	// Jobs are paused before the option updates, so that they don't have to be
+	// paused and resumed for every option update.
+	var actions []*Action
+	running := jd.State.IsRunning()
+	if j.Paused && running {
+		actions = append(actions, &Action{Kind: Pause, Name: j.Name, UID: uid})
+		running = false
+	}
+
+	if x, ok := v.(optioner); ok {
+		current := x.Options()
+		for _, key := range slices.Sorted(maps.Keys(j.Options)) {
+			if value := j.Options[key]; current[key] != value {
+				actions = append(actions, &Action{Kind: SetOption, Name: j.Name, UID: uid, Running: running, OptionKey: key, OptionValue: value, Reason: fmt.Sprintf("current value is %q", current[key])})
+			}
+		}
+	}
+
+	labels := m.JobLabels(j)
+	if !maps.Equal(labels, jd.Labels) {
+		var remove []string
+		for key := range jd.Labels {
+			if _, ok := labels[key]; !ok {
+				remove = append(remove, key)
+			}
+		}
+		sort.Strings(remove)
+		actions = append(actions, &Action{Kind: SetLabels, Name: j.Name, UID: uid, Labels: labels, RemoveLabels: remove, Reason: fmt.Sprintf("current labels are %q", job.LabelsString(jd.Labels))})
+	}
+
+	if !j.Paused && jd.State == gobs.PAUSED {
+		actions = append(actions, &Action{Kind: Resume, Name: j.Name, UID: uid})
+	}
+	return actions, uid, nil
+}
+
+// definitionDiff returns a non-empty reason if the existing job cannot be
+// updated in place to match the manifest job.
+func definitionDiff(jd *gobs.JobData, v trader.Trader, j *Job) string {
+	if !strings.EqualFold(jd.Typename, j.Type) {
+		return fmt.Sprintf("job type is %s", strings.ToLower(jd.Typename))
+	}
+	if !strings.EqualFold(v.ExchangeName(), j.Exchange) {
+		return fmt.Sprintf("exchange is %s", v.ExchangeName())
+	}
+	if v.ProductID() != j.Product {
+		return fmt.Sprintf("product is %s", v.ProductID())
+	}
+
+	var pairs []*point.Pair
+	switch x := v.(type) {
+	case interface{ Pairs() []*point.Pair }:
+		pairs = x.Pairs()
+	case interface{ Pair() *point.Pair }:
+		pairs = []*point.Pair{x.Pair()}
+	}
+	if len(pairs) != len(j.Pairs) {
+		return fmt.Sprintf("job has %d buy/sell pairs", len(pairs))
+	}
+	for i := range pairs {
+		if !pairs[i].Equal(j.Pairs[i]) {
+			return fmt.Sprintf("buy/sell pair %d is %s", i, pairs[i])
+		}
+	}
+	return ""
+}
+
+// JobLabels returns the labels for a manifest job including the managed
+// label.
+func (m *Manifest) JobLabels(j *Job) map[string]string {
+	labels := maps.Clone(j.Labels)
+	if labels == nil {
+		labels = make(map[string]string)
+	}
+	labels[ManagedLabel] = m.Name
+	return labels
+}
+
+// alertsDiff returns a non-empty reason if the alert limits in the manifest
+// are different from the database. Limits are compared only when they are
+// specified in the manifest.
+func alertsDiff(cfg *gobs.AlertsConfig, m *Manifest) string {
+	if cfg == nil {
+		cfg = new(gobs.AlertsConfig)
+	}
+	if m.LowBalanceLimits != nil && !equalLimits(cfg.LowBalanceLimits, m.LowBalanceLimits) {
+		return "global limits are different"
+	}
+	for _, name := range slices.Sorted(maps.Keys(m.Exchanges)) {
+		limits := m.Exchanges[name].LowBalanceLimits
+		if limits == nil {
+			continue
+		}
+		var current map[string]decimal.Decimal
+		if ecfg, ok := cfg.PerExchangeConfig[name]; ok && ecfg != nil {
+			current = ecfg.LowBalanceLimits
+		}
+		if !equalLimits(current, limits) {
+			return fmt.Sprintf("limits for exchange %s are different", name)
+		}
+	}
+	return ""
+}
+
+func equalLimits(a, b map[string]decimal.Decimal) bool {
+	return maps.EqualFunc(a, b, func(x, y decimal.Decimal) bool { return x.Equal(y) })
+}
+
+// UpdateAlerts updates the alert limits in the server state to match the
+// manifest. Limits that are not specified in the manifest are left unchanged.
+func UpdateAlerts(state *gobs.ServerState, m *Manifest) {
+	if state.AlertsConfig == nil {
+		state.AlertsConfig = new(gobs.AlertsConfig)
+	}
+	cfg := state.AlertsConfig
+	if m.LowBalanceLimits != nil {
+		cfg.LowBalanceLimits = maps.Clone(m.LowBalanceLimits)
+	}
+	for name, ex := range m.Exchanges {
+		if ex.LowBalanceLimits == nil {
+			continue
+		}
+		if cfg.PerExchangeConfig == nil {
+			cfg.PerExchangeConfig = make(map[string]*gobs.AlertsConfig)
+		}
+		ecfg, ok := cfg.PerExchangeConfig[name]
+		if !ok || ecfg == nil {
+			ecfg = new(gobs.AlertsConfig)
+			cfg.PerExchangeConfig[name] = ecfg
+		}
+		ecfg.LowBalanceLimits = maps.Clone(ex.LowBalanceLimits)
+	}
+}
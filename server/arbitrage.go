@@ -0,0 +1,63 @@
+// Copyright (c) 2025 BVK Chaitanya
+
+package server
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	"github.com/bvk/tradebot/api"
+	"github.com/bvk/tradebot/arbiter"
+	"github.com/bvkgo/kv"
+	"github.com/google/uuid"
+)
+
+func (s *Server) doArbitrage(ctx context.Context, req *api.ArbitrageRequest) (_ *api.ArbitrageResponse, status error) {
+	defer func() {
+		if status != nil {
+			slog.ErrorContext(ctx, "arbitrage job request has failed", "error", status)
+		}
+	}()
+
+	if err := req.Check(); err != nil {
+		return nil, fmt.Errorf("invalid arbitrage job request: %w", err)
+	}
+
+	if _, err := s.getProduct(ctx, req.ExchangeA, req.ProductA); err != nil {
+		return nil, err
+	}
+	if _, err := s.getProduct(ctx, req.ExchangeB, req.ProductB); err != nil {
+		return nil, err
+	}
+
+	uid := uuid.New().String()
+	a := &arbiter.Leg{ExchangeName: req.ExchangeA, ProductID: req.ProductA, FeePct: req.FeePctA}
+	b := &arbiter.Leg{ExchangeName: req.ExchangeB, ProductID: req.ProductB, FeePct: req.FeePctB}
+	arb, err := arbiter.New(uid, a, b, req.ThresholdPct, req.TradeSize, req.MaxTrades)
+	if err != nil {
+		return nil, err
+	}
+
+	start := func(ctx context.Context, rw kv.ReadWriter) error {
+		if err := arb.Save(ctx, rw); err != nil {
+			return fmt.Errorf("could not save new arbiter: %v", err)
+		}
+		if err := s.runner.Add(ctx, rw, uid, "Arbiter"); err != nil {
+			return fmt.Errorf("could not add new arbiter as a job: %w", err)
+		}
+		return nil
+	}
+	if err := kv.WithReadWriter(ctx, s.db, start); err != nil {
+		return nil, err
+	}
+
+	if err := s.runner.Resume(ctx, uid, s.makeJobFunc(arb), s.cg.Context()); err != nil {
+		slog.Error("could not resume newly added arbiter job (ignored)", "err", err)
+	}
+
+	resp := &api.ArbitrageResponse{
+		UID: uid,
+	}
+	return resp, nil
+}
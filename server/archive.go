@@ -0,0 +1,166 @@
+// Copyright (c) 2025 BVK Chaitanya
+
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+
+	"github.com/bvk/tradebot/api"
+	"github.com/bvk/tradebot/arbiter"
+	"github.com/bvk/tradebot/gobs"
+	"github.com/bvk/tradebot/kvutil"
+	"github.com/bvk/tradebot/limiter"
+	"github.com/bvk/tradebot/looper"
+	"github.com/bvk/tradebot/namer"
+	"github.com/bvk/tradebot/waller"
+	"github.com/bvk/tradebot/watcher"
+	"github.com/bvkgo/kv"
+	"github.com/google/uuid"
+)
+
+// jobKeyspaces are the keyspaces that hold the trader job states. Child jobs
+// are saved in the same keyspaces under their parent job's uid.
+var jobKeyspaces = []string{
+	limiter.DefaultKeyspace,
+	looper.DefaultKeyspace,
+	waller.DefaultKeyspace,
+	watcher.DefaultKeyspace,
+	arbiter.DefaultKeyspace,
+}
+
+// jobKeyValues returns all key-values owned by a trader job, which includes
+// the key-values of it's child loopers and limiters.
+func jobKeyValues(ctx context.Context, r kv.Reader, uid string) ([]*gobs.KeyValue, error) {
+	var kvs []*gobs.KeyValue
+	for _, ks := range jobKeyspaces {
+		key := path.Join(ks, uid)
+		if v, err := r.Get(ctx, key); err == nil {
+			value, err := io.ReadAll(v)
+			if err != nil {
+				return nil, fmt.Errorf("could not read value at key %q: %w", key, err)
+			}
+			kvs = append(kvs, &gobs.KeyValue{Key: key, Value: value})
+		} else if !errors.Is(err, os.ErrNotExist) {
+			return nil, fmt.Errorf("could not read key %q: %w", key, err)
+		}
+
+		begin, end := kvutil.PathRange(key)
+		it, err := r.Ascend(ctx, begin, end)
+		if err != nil {
+			return nil, fmt.Errorf("could not scan keys under %q: %w", key, err)
+		}
+		for k, v, err := it.Fetch(ctx, false); err == nil; k, v, err = it.Fetch(ctx, true) {
+			value, err := io.ReadAll(v)
+			if err != nil {
+				kv.Close(it)
+				return nil, fmt.Errorf("could not read value at key %q: %w", k, err)
+			}
+			kvs = append(kvs, &gobs.KeyValue{Key: k, Value: value})
+		}
+		if _, _, err := it.Fetch(ctx, false); err != nil && !errors.Is(err, io.EOF) {
+			kv.Close(it)
+			return nil, fmt.Errorf("iterator fetch has failed: %w", err)
+		}
+		kv.Close(it)
+	}
+	return kvs, nil
+}
+
+// archiveJob moves a finished job and it's child jobs into the archive
+// keyspace with a precomputed lifetime summary.
+func (s *Server) archiveJob(ctx context.Context, uid string) (gobs.State, error) {
+	if _, ok := s.jobMap.Load(uid); ok {
+		return "", fmt.Errorf("job %q is currently running: %w", uid, os.ErrInvalid)
+	}
+
+	var state gobs.State
+	archive := func(ctx context.Context, rw kv.ReadWriter) error {
+		jd, err := s.runner.Get(ctx, rw, uid)
+		if err != nil {
+			return err
+		}
+		if !jd.State.IsDone() {
+			return fmt.Errorf("job %q is not finished yet (%s): %w", uid, jd.State, os.ErrInvalid)
+		}
+
+		v, err := Load(ctx, rw, uid, jd.Typename)
+		if err != nil {
+			return fmt.Errorf("could not load trader job %q: %w", uid, err)
+		}
+		kvs, err := jobKeyValues(ctx, rw, uid)
+		if err != nil {
+			return err
+		}
+		name, _, _, err := namer.Resolve(ctx, rw, uid)
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("could not resolve job id %q: %w", uid, err)
+		}
+
+		export := &gobs.JobExportData{
+			UID:       uid,
+			Name:      name,
+			KeyValues: kvs,
+		}
+		if err := s.runner.Archive(ctx, rw, export, v.GetSummary(nil)); err != nil {
+			return err
+		}
+		state = jd.State
+		return nil
+	}
+	if err := kv.WithReadWriter(ctx, s.db, archive); err != nil {
+		return "", fmt.Errorf("could not archive job %q: %w", uid, err)
+	}
+	return state, nil
+}
+
+func (s *Server) doJobArchive(ctx context.Context, req *api.JobArchiveRequest) (*api.JobArchiveResponse, error) {
+	if err := req.Check(); err != nil {
+		return nil, fmt.Errorf("invalid archive request: %w", err)
+	}
+
+	if len(req.Selector) != 0 {
+		results, err := s.forEachSelected(ctx, req.Selector, s.archiveJob)
+		if err != nil {
+			return nil, err
+		}
+		return &api.JobArchiveResponse{Jobs: results}, nil
+	}
+
+	if _, err := uuid.Parse(req.UID); err != nil {
+		return nil, fmt.Errorf("job uid must be an uuid: %w", err)
+	}
+	state, err := s.archiveJob(ctx, req.UID)
+	if err != nil {
+		return nil, err
+	}
+	return &api.JobArchiveResponse{FinalState: string(state)}, nil
+}
+
+func (s *Server) doJobRestore(ctx context.Context, req *api.JobRestoreRequest) (*api.JobRestoreResponse, error) {
+	if _, err := uuid.Parse(req.UID); err != nil {
+		return nil, fmt.Errorf("job uid must be an uuid: %w", err)
+	}
+
+	var state gobs.State
+	restore := func(ctx context.Context, rw kv.ReadWriter) error {
+		export, err := s.runner.Restore(ctx, rw, req.UID)
+		if err != nil {
+			return err
+		}
+		// Verify that restored job can be loaded.
+		if _, err := Load(ctx, rw, req.UID, export.Typename); err != nil {
+			return fmt.Errorf("could not load restored job %q: %w", req.UID, err)
+		}
+		state = export.JobState
+		return nil
+	}
+	if err := kv.WithReadWriter(ctx, s.db, restore); err != nil {
+		return nil, fmt.Errorf("could not restore job %q: %w", req.UID, err)
+	}
+	return &api.JobRestoreResponse{FinalState: string(state)}, nil
+}
@@ -0,0 +1,175 @@
+// Copyright (c) 2025 BVK Chaitanya
+
+package server
+
+import (
+	"context"
+	"errors"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/bvk/tradebot/api"
+	"github.com/bvk/tradebot/exchange/exchangetest"
+	"github.com/bvk/tradebot/gobs"
+	"github.com/bvk/tradebot/job"
+	"github.com/bvk/tradebot/looper"
+	"github.com/bvk/tradebot/point"
+	"github.com/bvk/tradebot/trader"
+	"github.com/bvk/tradebot/waller"
+	"github.com/bvkgo/kv"
+	"github.com/bvkgo/kv/kvmemdb"
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+)
+
+// runCycle runs the job till one buy and one sell are filled and then stops
+// the job, so that it's child limiters are saved in the database.
+func runCycle(t *testing.T, v trader.Trader, rt *trader.Runtime, p *exchangetest.Product) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	errCh := exchangetest.Start(ctx, v, rt)
+	exchangetest.TickUntil(t, p, []int64{101, 100, 106, 110}, func() bool {
+		return p.Fills("BUY").IsPositive() && p.Fills("SELL").IsPositive()
+	})
+	time.Sleep(100 * time.Millisecond)
+	cancel()
+	<-errCh
+}
+
+// countKeys returns the number of keys under the job's uid in all trader
+// keyspaces.
+func countKeys(t *testing.T, db kv.Database, uid string) int {
+	var kvs []*gobs.KeyValue
+	if err := kv.WithReader(context.Background(), db, func(ctx context.Context, r kv.Reader) (err error) {
+		kvs, err = jobKeyValues(ctx, r, uid)
+		return err
+	}); err != nil {
+		t.Fatal(err)
+	}
+	return len(kvs)
+}
+
+func TestArchiveRestore(t *testing.T) {
+	ctx := context.Background()
+	db := kvmemdb.New()
+	s := &Server{db: db, runner: job.NewRunner(db)}
+
+	d := decimal.NewFromInt
+	pair := &point.Pair{
+		Buy:  point.Point{Size: d(1), Price: d(100), Cancel: d(105)},
+		Sell: point.Point{Size: d(1), Price: d(110), Cancel: d(105)},
+	}
+
+	p := exchangetest.NewProduct("test", "BTC-USD", decimal.NewFromFloat(0.01), decimal.NewFromFloat(0.1))
+	rt := exchangetest.NewRuntime(p)
+	rt.Database = db
+	loop, err := looper.New(uuid.New().String(), "test", "BTC-USD", &pair.Buy, &pair.Sell)
+	if err != nil {
+		t.Fatal(err)
+	}
+	runCycle(t, loop, rt, p)
+
+	p = exchangetest.NewProduct("test", "BTC-USD", decimal.NewFromFloat(0.01), decimal.NewFromFloat(0.1))
+	rt = exchangetest.NewRuntime(p)
+	rt.Database = db
+	wall, err := waller.New(uuid.New().String(), "test", "BTC-USD", []*point.Pair{pair})
+	if err != nil {
+		t.Fatal(err)
+	}
+	runCycle(t, wall, rt, p)
+
+	jobs := []struct {
+		v        trader.Trader
+		typename string
+		nkeys    int
+	}{
+		// Looper with the buy and sell limiters of the completed cycle and the
+		// next buy limiter.
+		{loop, "looper", 4},
+		// Waller with a looper, which has three limiters as above.
+		{wall, "waller", 5},
+	}
+	for _, j := range jobs {
+		if n := countKeys(t, db, j.v.UID()); n != j.nkeys {
+			t.Fatalf("%s: wanted %d keys before archival, got %d", j.typename, j.nkeys, n)
+		}
+		if err := s.runner.Add(ctx, nil, j.v.UID(), j.typename); err != nil {
+			t.Fatal(err)
+		}
+		// Unfinished jobs cannot be archived.
+		if _, err := s.archiveJob(ctx, j.v.UID()); !errors.Is(err, os.ErrInvalid) {
+			t.Fatalf("%s: wanted ErrInvalid, got %v", j.typename, err)
+		}
+		if _, err := s.runner.Cancel(ctx, j.v.UID()); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := s.archiveJob(ctx, j.v.UID()); err != nil {
+			t.Fatal(err)
+		}
+		if n := countKeys(t, db, j.v.UID()); n != 0 {
+			t.Fatalf("%s: wanted no keys after archival, got %d", j.typename, n)
+		}
+		events, err := s.runner.History(ctx, nil, j.v.UID())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if n := len(events); n == 0 || events[n-1].Kind != job.ArchivedEvent {
+			t.Fatalf("%s: wanted last event to be %q, got %v", j.typename, job.ArchivedEvent, events)
+		}
+	}
+
+	// Archived jobs must be summarized from the archived data.
+	for _, req := range []*api.SummaryRequest{
+		{},
+		{Recalculate: true},
+		{BeginTime: time.Now().Add(-time.Hour)},
+	} {
+		resp, err := JobSummaries(ctx, db, req, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(resp.Jobs) != len(jobs) {
+			t.Fatalf("wanted %d archived job summaries, got %d", len(jobs), len(resp.Jobs))
+		}
+		for _, js := range resp.Jobs {
+			if !js.Archived || !js.Summary.NumBuys.Equal(d(1)) || !js.Summary.NumSells.Equal(d(1)) {
+				t.Fatalf("wanted one archived buy and sell in %s, got %+v", js.Type, js.Summary)
+			}
+		}
+	}
+
+	// Restored jobs must load with all their child limiters.
+	for _, j := range jobs {
+		if _, err := s.doJobRestore(ctx, &api.JobRestoreRequest{UID: j.v.UID()}); err != nil {
+			t.Fatal(err)
+		}
+		if n := countKeys(t, db, j.v.UID()); n != j.nkeys {
+			t.Fatalf("%s: wanted %d keys after restore, got %d", j.typename, j.nkeys, n)
+		}
+		var v trader.Trader
+		if err := kv.WithReader(ctx, db, func(ctx context.Context, r kv.Reader) (err error) {
+			v, err = Load(ctx, r, j.v.UID(), j.typename)
+			return err
+		}); err != nil {
+			t.Fatal(err)
+		}
+		if got, want := len(v.Actions()), len(j.v.Actions()); got != want || want == 0 {
+			t.Fatalf("%s: wanted %d actions after restore, got %d", j.typename, want, got)
+		}
+		if jd, err := s.runner.Get(ctx, nil, j.v.UID()); err != nil {
+			t.Fatal(err)
+		} else if jd.State != gobs.CANCELED {
+			t.Fatalf("%s: wanted CANCELED, got %v", j.typename, jd.State)
+		}
+		if _, err := s.runner.GetArchive(ctx, nil, j.v.UID()); !errors.Is(err, os.ErrNotExist) {
+			t.Fatalf("%s: wanted ErrNotExist for the archive, got %v", j.typename, err)
+		}
+	}
+
+	// Restoring twice must fail.
+	if _, err := s.doJobRestore(ctx, &api.JobRestoreRequest{UID: loop.UID()}); err == nil {
+		t.Fatalf("wanted non-nil error")
+	}
+}
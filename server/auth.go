@@ -0,0 +1,113 @@
+// Copyright (c) 2025 BVK Chaitanya
+
+package server
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/bvk/tradebot/api"
+	"github.com/bvk/tradebot/auth"
+	"github.com/bvk/tradebot/gobs"
+	"github.com/bvkgo/kv"
+)
+
+// readOnlyPaths holds the api paths that do not modify the trader state.
+var readOnlyPaths = []string{
+	api.JobListPath,
+	api.JobHistoryPath,
+	api.EventsPath,
+	api.BudgetPath,
+	api.SummaryPath,
+	api.StatusPath,
+	api.ExchangeGetOrderPath,
+	api.ExchangeGetProductPath,
+}
+
+// adminPaths holds the api paths that change the server configuration or
+// manage the access tokens.
+var adminPaths = []string{
+	api.ExchangeUpdateProductPath,
+	api.TokenCreatePath,
+	api.TokenListPath,
+	api.TokenRevokePath,
+}
+
+// HandlerScope returns the minimum token scope required to invoke the api
+// handler at the input path. All trader apis that are not read-only or
+// admin-only require the trade scope.
+func HandlerScope(p string) auth.Scope {
+	if slices.Contains(readOnlyPaths, p) {
+		return auth.ReadOnly
+	}
+	if slices.Contains(adminPaths, p) {
+		return auth.Admin
+	}
+	return auth.Trade
+}
+
+func (s *Server) doTokenCreate(ctx context.Context, req *api.TokenCreateRequest) (*api.TokenCreateResponse, error) {
+	if err := req.Check(); err != nil {
+		return nil, fmt.Errorf("invalid token create request: %w", err)
+	}
+	scope, err := auth.ParseScope(req.Scope)
+	if err != nil {
+		return nil, err
+	}
+	if !req.ExpiresAt.IsZero() && !req.ExpiresAt.After(time.Now()) {
+		return nil, fmt.Errorf("token expiry time must be in the future")
+	}
+
+	resp := new(api.TokenCreateResponse)
+	create := func(ctx context.Context, rw kv.ReadWriter) error {
+		token, t, err := auth.Create(ctx, rw, req.Name, scope, req.ExpiresAt)
+		if err != nil {
+			return err
+		}
+		resp.ID, resp.Token = t.ID, token
+		return nil
+	}
+	if err := kv.WithReadWriter(ctx, s.db, create); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+func (s *Server) doTokenList(ctx context.Context, req *api.TokenListRequest) (*api.TokenListResponse, error) {
+	var tokens []*gobs.APIToken
+	list := func(ctx context.Context, r kv.Reader) (err error) {
+		tokens, err = auth.List(ctx, r)
+		return err
+	}
+	if err := kv.WithReader(ctx, s.db, list); err != nil {
+		return nil, err
+	}
+
+	resp := new(api.TokenListResponse)
+	for _, t := range tokens {
+		info := &api.TokenInfo{
+			ID:        t.ID,
+			Name:      t.Name,
+			Scope:     t.Scope,
+			CreatedAt: t.CreatedAt,
+			ExpiresAt: t.ExpiresAt,
+		}
+		resp.Tokens = append(resp.Tokens, info)
+	}
+	return resp, nil
+}
+
+func (s *Server) doTokenRevoke(ctx context.Context, req *api.TokenRevokeRequest) (*api.TokenRevokeResponse, error) {
+	if err := req.Check(); err != nil {
+		return nil, fmt.Errorf("invalid token revoke request: %w", err)
+	}
+	revoke := func(ctx context.Context, rw kv.ReadWriter) error {
+		return auth.Revoke(ctx, rw, req.ID)
+	}
+	if err := kv.WithReadWriter(ctx, s.db, revoke); err != nil {
+		return nil, err
+	}
+	return new(api.TokenRevokeResponse), nil
+}
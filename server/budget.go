@@ -0,0 +1,162 @@
+// Copyright (c) 2025 BVK Chaitanya
+
+package server
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/bvk/tradebot/api"
+	"github.com/bvk/tradebot/limiter"
+	"github.com/bvk/tradebot/trader"
+	"github.com/bvk/tradebot/watcher"
+	"github.com/shopspring/decimal"
+)
+
+// budgetFeePct is the fee percentage used to compute the committed budget for
+// the jobs.
+var budgetFeePct = decimal.NewFromFloat(0.25)
+
+var quoteSuffixes = []string{"USDT", "USDC", "USD", "BTC", "ETH"}
+
+// quoteCurrency returns the quote currency for a product id.
+func quoteCurrency(productID string) string {
+	pid := strings.ToUpper(productID)
+	if _, quote, ok := strings.Cut(pid, "-"); ok {
+		return quote
+	}
+	for _, s := range quoteSuffixes {
+		if strings.HasSuffix(pid, s) && len(pid) > len(s) {
+			return s
+		}
+	}
+	// Stock symbols are traded in USD.
+	return "USD"
+}
+
+func budgetKey(exchangeName, currency string) string {
+	return strings.ToLower(exchangeName) + "/" + strings.ToUpper(currency)
+}
+
+// updateBalance records the available balance and the total balance including
+// the balance held for the open orders for a currency in an exchange.
+func (s *Server) updateBalance(exchangeName, currency string, available, hold decimal.Decimal) {
+	key := budgetKey(exchangeName, currency)
+	s.balanceMap.Store(key, available)
+	s.totalBalanceMap.Store(key, available.Add(hold))
+}
+
+// committedBudget returns the quote currency amount required by a job. Sell
+// limiters commit base currency instead of quote currency and watchers do not
+// trade, so they are not included.
+func committedBudget(v trader.Trader) decimal.Decimal {
+	switch x := v.(type) {
+	case *watcher.Watcher:
+		return decimal.Zero
+	case *limiter.Limiter:
+		if x.IsSell() {
+			return decimal.Zero
+		}
+	}
+	return v.BudgetAt(budgetFeePct)
+}
+
+// committedBudgets returns the committed budget and number of jobs per
+// exchange/currency key for all running jobs. Jobs that are not running do not
+// hold any funds in the exchange, so they are not included.
+func (s *Server) committedBudgets() (map[string]decimal.Decimal, map[string]int) {
+	budgets := make(map[string]decimal.Decimal)
+	counts := make(map[string]int)
+	s.jobMap.Range(func(uid string, v trader.Trader) bool {
+		budget := committedBudget(v)
+		if budget.IsZero() {
+			return true
+		}
+		key := budgetKey(v.ExchangeName(), quoteCurrency(v.ProductID()))
+		budgets[key] = budgets[key].Add(budget)
+		counts[key]++
+		return true
+	})
+	return budgets, counts
+}
+
+// checkBudget verifies that a new job doesn't over-commit the capital. Budgets
+// of the running jobs are compared against the total balance, because
+// available balance from the exchanges doesn't include the balance held for
+// the open orders of the same jobs. Over-commitment is rejected or only warned
+// as per the server's budget policy.
+func (s *Server) checkBudget(ctx context.Context, v trader.Trader) error {
+	if s.opts.BudgetPolicy == "none" {
+		return nil
+	}
+
+	budget := committedBudget(v)
+	if budget.IsZero() {
+		return nil
+	}
+
+	ccy := quoteCurrency(v.ProductID())
+	key := budgetKey(v.ExchangeName(), ccy)
+	balance, ok := s.totalBalanceMap.Load(key)
+	if !ok {
+		slog.Warn("balance is unknown; budget is not checked", "exchange", v.ExchangeName(), "currency", ccy, "budget", budget)
+		return nil
+	}
+
+	budgets, _ := s.committedBudgets()
+	committed := budgets[key]
+
+	total := committed.Add(budget)
+	if total.LessThanOrEqual(balance) {
+		return nil
+	}
+
+	if s.opts.BudgetPolicy == "reject" {
+		return fmt.Errorf("job budget %s %s with existing commitment %s exceeds total balance %s in exchange %s", budget.StringFixed(3), ccy, committed.StringFixed(3), balance.StringFixed(3), v.ExchangeName())
+	}
+
+	slog.Warn("new job over-commits the capital", "exchange", v.ExchangeName(), "currency", ccy, "budget", budget, "committed", committed, "balance", balance)
+	s.SendMessage(ctx, time.Now(), "New job in product %s (%s) needs %s %s, but %s is already committed against total balance of %s.", v.ProductID(), v.ExchangeName(), budget.StringFixed(3), ccy, committed.StringFixed(3), balance.StringFixed(3))
+	return nil
+}
+
+func (s *Server) doBudget(ctx context.Context, req *api.BudgetRequest) (*api.BudgetResponse, error) {
+	budgets, counts := s.committedBudgets()
+
+	keys := make(map[string]struct{})
+	for k := range budgets {
+		keys[k] = struct{}{}
+	}
+	s.balanceMap.Range(func(k string, _ decimal.Decimal) bool {
+		keys[k] = struct{}{}
+		return true
+	})
+
+	resp := new(api.BudgetResponse)
+	for key := range keys {
+		exchangeName, ccy, _ := strings.Cut(key, "/")
+		item := &api.BudgetResponseItem{
+			ExchangeName: exchangeName,
+			Currency:     ccy,
+			Committed:    budgets[key],
+			NumJobs:      counts[key],
+		}
+		if v, ok := s.balanceMap.Load(key); ok {
+			item.Available = v
+			item.Total, _ = s.totalBalanceMap.Load(key)
+			item.HasBalance = true
+		}
+		resp.Items = append(resp.Items, item)
+	}
+	sort.Slice(resp.Items, func(i, j int) bool {
+		if resp.Items[i].ExchangeName == resp.Items[j].ExchangeName {
+			return resp.Items[i].Currency < resp.Items[j].Currency
+		}
+		return resp.Items[i].ExchangeName < resp.Items[j].ExchangeName
+	})
+	return resp, nil
+}
@@ -0,0 +1,240 @@
+// Copyright (c) 2025 BVK Chaitanya
+
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/bvk/tradebot/exchange"
+	"github.com/bvk/tradebot/gobs"
+	"github.com/bvk/tradebot/kvutil"
+	"github.com/shopspring/decimal"
+	"github.com/visvasity/cli"
+	"github.com/visvasity/topic"
+)
+
+const circuitBreakerConfigInterval = time.Minute
+
+type pricePoint struct {
+	at    time.Time
+	price decimal.Decimal
+}
+
+// breaker implements trader.CircuitBreaker for a single product. Limiters
+// don't create new orders while the breaker is tripped, but existing orders
+// and jobs are left untouched.
+type breaker struct {
+	mu sync.Mutex
+
+	// name is the exchange/product key for the breaker.
+	name string
+
+	// points hold the ticker prices in the configured window.
+	points []pricePoint
+
+	trippedAt time.Time
+	reason    string
+}
+
+func (b *breaker) IsTripped() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return !b.trippedAt.IsZero()
+}
+
+func (b *breaker) status() (time.Time, string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return b.trippedAt, b.reason
+}
+
+// update records a new ticker price and returns a non-empty reason when the
+// breaker trips with this update.
+func (b *breaker) update(cfg *gobs.CircuitBreakerConfig, at time.Time, price, bid, ask decimal.Decimal) string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if !b.trippedAt.IsZero() {
+		return ""
+	}
+
+	if cfg.MaxSpreadPct.IsPositive() && bid.IsPositive() && ask.GreaterThan(bid) {
+		mid := bid.Add(ask).Div(decimal.NewFromInt(2))
+		spread := ask.Sub(bid).Div(mid).Mul(decimal.NewFromInt(100))
+		if spread.GreaterThan(cfg.MaxSpreadPct) {
+			return b.tripLocked(at, fmt.Sprintf("bid-ask spread %s%% is above %s%%", spread.StringFixed(2), cfg.MaxSpreadPct))
+		}
+	}
+
+	if !cfg.MovePct.IsPositive() || cfg.Window <= 0 || !price.IsPositive() {
+		return ""
+	}
+	b.points = append(b.points, pricePoint{at: at, price: price})
+	for len(b.points) > 1 && at.Sub(b.points[0].at) > cfg.Window {
+		b.points = b.points[1:]
+	}
+	low, high := b.points[0].price, b.points[0].price
+	for _, p := range b.points[1:] {
+		low, high = decimal.Min(low, p.price), decimal.Max(high, p.price)
+	}
+	move := high.Sub(low).Div(low).Mul(decimal.NewFromInt(100))
+	if move.GreaterThan(cfg.MovePct) {
+		return b.tripLocked(at, fmt.Sprintf("price moved %s%% (%s to %s) within %s", move.StringFixed(2), low, high, cfg.Window))
+	}
+	return ""
+}
+
+func (b *breaker) tripLocked(at time.Time, reason string) string {
+	b.trippedAt, b.reason = at, reason
+	b.points = nil
+	return reason
+}
+
+// reset resets a tripped breaker and returns true if breaker was tripped.
+func (b *breaker) reset() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.trippedAt.IsZero() {
+		return false
+	}
+	b.trippedAt, b.reason = time.Time{}, ""
+	b.points = nil
+	return true
+}
+
+// getBreaker returns the circuit breaker for a product, creating it if
+// necessary.
+func (s *Server) getBreaker(product exchange.Product) *breaker {
+	name := path.Join(product.ExchangeName(), product.ProductID())
+	b, _ := s.breakerMap.LoadOrStore(name, &breaker{name: name})
+	return b
+}
+
+func (s *Server) circuitBreakerConfig(ctx context.Context) *gobs.CircuitBreakerConfig {
+	state, err := kvutil.GetDB[gobs.ServerState](ctx, s.db, ServerStateKey)
+	if err != nil {
+		if !errors.Is(err, os.ErrNotExist) {
+			slog.Error("could not load server state for circuit breaker config (ignored)", "err", err)
+		}
+		return nil
+	}
+	return state.CircuitBreaker
+}
+
+// watchCircuitBreaker watches the price updates for a product and trips the
+// product's circuit breaker when the price moves too fast or the bid-ask
+// spread is too wide. Tripped breakers are reset automatically after the
+// cool-down period unless manual reset is configured.
+func (s *Server) watchCircuitBreaker(ctx context.Context, product exchange.Product) {
+	b := s.getBreaker(product)
+
+	priceUpdates, err := product.GetPriceUpdates()
+	if err != nil {
+		slog.Error("could not subscribe to price updates for circuit breaker", "product", b.name, "err", err)
+		return
+	}
+	defer priceUpdates.Close()
+
+	tickerCh, err := topic.ReceiveCh(priceUpdates)
+	if err != nil {
+		slog.Error("could not receive price updates for circuit breaker", "product", b.name, "err", err)
+		return
+	}
+
+	cfg := s.circuitBreakerConfig(ctx)
+	configTimer := time.NewTicker(circuitBreakerConfigInterval)
+	defer configTimer.Stop()
+
+	for {
+		var update exchange.PriceUpdate
+		select {
+		case <-ctx.Done():
+			return
+		case <-configTimer.C:
+			cfg = s.circuitBreakerConfig(ctx)
+		case update = <-tickerCh:
+		}
+
+		now := time.Now()
+		if cfg == nil {
+			// Breakers must not stay tripped after they are disabled.
+			if b.reset() {
+				s.SendMessage(ctx, now, "Circuit breaker for %s is disabled; new orders are resumed.", b.name)
+			}
+			continue
+		}
+
+		if trippedAt, _ := b.status(); !trippedAt.IsZero() {
+			if !cfg.ManualReset && cfg.CoolDown > 0 && now.Sub(trippedAt) >= cfg.CoolDown && b.reset() {
+				slog.Info("circuit breaker is reset after the cool-down", "product", b.name)
+				s.SendMessage(ctx, now, "Circuit breaker for %s is reset after %s cool-down; new orders are resumed.", b.name, cfg.CoolDown)
+			}
+			continue
+		}
+
+		if update == nil {
+			continue
+		}
+		price, _ := update.PricePoint()
+		var bid, ask decimal.Decimal
+		if v, ok := update.(exchange.BidAskUpdate); ok {
+			bid, ask = v.BidAsk()
+		}
+		if reason := b.update(cfg, now, price, bid, ask); reason != "" {
+			slog.Warn("circuit breaker has tripped", "product", b.name, "reason", reason)
+			resume := "use resume-trading command to resume"
+			if !cfg.ManualReset && cfg.CoolDown > 0 {
+				resume = fmt.Sprintf("will resume after %s", cfg.CoolDown)
+			}
+			s.SendMessage(ctx, now, "Circuit breaker for %s has tripped because %s; new orders are paused (%s).", b.name, reason, resume)
+		}
+	}
+}
+
+// resumeTradingCmd resets a tripped circuit breaker. Without any arguments, it
+// prints the tripped circuit breakers.
+func (s *Server) resumeTradingCmd(ctx context.Context, args []string) error {
+	stdout := cli.Stdout(ctx)
+	if len(args) == 0 {
+		var tripped []string
+		for name, b := range s.breakerMap.Range {
+			if at, reason := b.status(); !at.IsZero() {
+				tripped = append(tripped, fmt.Sprintf("%s: tripped at %s because %s", name, at.Format(time.DateTime), reason))
+			}
+		}
+		if len(tripped) == 0 {
+			fmt.Fprintln(stdout, "No circuit breakers are tripped.")
+			return nil
+		}
+		slices.Sort(tripped)
+		for _, s := range tripped {
+			fmt.Fprintln(stdout, s)
+		}
+		return nil
+	}
+
+	for _, name := range args {
+		b, ok := s.breakerMap.Load(name)
+		if !ok {
+			return fmt.Errorf("circuit breaker for %q not found (must be in exchange/product format)", name)
+		}
+		if !b.reset() {
+			fmt.Fprintf(stdout, "Circuit breaker for %s is not tripped.\n", name)
+			continue
+		}
+		slog.Info("circuit breaker is reset by the operator", "product", name)
+		fmt.Fprintf(stdout, "Circuit breaker for %s is reset; new orders are resumed.\n", name)
+	}
+	return nil
+}
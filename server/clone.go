@@ -0,0 +1,202 @@
+// Copyright (c) 2025 BVK Chaitanya
+
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"maps"
+	"os"
+	"time"
+
+	"github.com/bvk/tradebot/api"
+	"github.com/bvk/tradebot/gobs"
+	"github.com/bvk/tradebot/job"
+	"github.com/bvk/tradebot/limiter"
+	"github.com/bvk/tradebot/looper"
+	"github.com/bvk/tradebot/namer"
+	"github.com/bvk/tradebot/point"
+	"github.com/bvk/tradebot/waller"
+	"github.com/bvkgo/kv"
+	"github.com/google/uuid"
+)
+
+// clonePoint returns a copy of the input point with the price and size
+// overrides from the clone request.
+func clonePoint(p *point.Point, req *api.JobCloneRequest) *point.Point {
+	v := *p
+	if !req.PriceShift.IsZero() {
+		v.Price = v.Price.Add(req.PriceShift)
+		v.Cancel = v.Cancel.Add(req.PriceShift)
+	}
+	if !req.PriceScale.IsZero() {
+		v.Price = v.Price.Mul(req.PriceScale)
+		v.Cancel = v.Cancel.Mul(req.PriceScale)
+	}
+	if !req.SizeScale.IsZero() {
+		v.Size = v.Size.Mul(req.SizeScale)
+	}
+	return &v
+}
+
+// cloneOptions returns the options of an existing job that are applicable to
+// a new job. Retire option is not carried over because it only makes sense
+// for the original job. Freeze option is not carried over either, because it
+// is also set temporarily by the risk limits and stop-loss actions. Stop-loss
+// triggered state is not an option, so new jobs always start with the
+// stop-loss armed.
+func cloneOptions(opts map[string]string) map[string]string {
+	v := maps.Clone(opts)
+	delete(v, "retire")
+	delete(v, "freeze")
+	if len(v) == 0 {
+		return nil
+	}
+	return v
+}
+
+// cloneExpiry returns the expiry parameters of an existing limiter for a new
+// limiter. Fallback action state is not carried over. Limiters with a deadline
+// in the past cannot be cloned because the clone would expire immediately.
+func cloneExpiry(e *gobs.LimiterExpiry) (*gobs.LimiterExpiry, error) {
+	if e == nil {
+		return nil, nil
+	}
+	if !e.Deadline.After(time.Now()) {
+		return nil, fmt.Errorf("limiter expiry deadline %s has passed: %w", e.Deadline.Format(time.RFC3339), os.ErrInvalid)
+	}
+	return &gobs.LimiterExpiry{
+		Deadline:        e.Deadline,
+		Action:          e.Action,
+		RepriceStepPct:  e.RepriceStepPct,
+		RepriceInterval: e.RepriceInterval,
+		AggressivePct:   e.AggressivePct,
+	}, nil
+}
+
+// doJobClone creates a new paused job with the definition of an existing job
+// and the overrides from the request. New job is created through the same
+// request handlers, so it is validated like any other new job. Name and the
+// clone event are added in the same transaction that creates the new job.
+func (s *Server) doJobClone(ctx context.Context, req *api.JobCloneRequest) (_ *api.JobCloneResponse, status error) {
+	defer func() {
+		if status != nil {
+			slog.ErrorContext(ctx, "job clone request has failed", "error", status)
+		}
+	}()
+
+	if err := req.Check(); err != nil {
+		return nil, fmt.Errorf("invalid clone request: %w", err)
+	}
+	if _, err := uuid.Parse(req.UID); err != nil {
+		return nil, fmt.Errorf("job uid must be an uuid: %w", err)
+	}
+
+	if len(req.Name) != 0 {
+		if _, _, _, err := namer.ResolveDB(ctx, s.db, req.Name); err == nil {
+			return nil, fmt.Errorf("job name %q is already used: %w", req.Name, os.ErrExist)
+		} else if !errors.Is(err, os.ErrNotExist) {
+			return nil, fmt.Errorf("could not check for job name %q: %w", req.Name, err)
+		}
+	}
+
+	jd, err := s.runner.Get(ctx, nil, req.UID)
+	if err != nil {
+		return nil, err
+	}
+	v, err := s.loadJob(ctx, req.UID)
+	if err != nil {
+		return nil, err
+	}
+
+	productID := v.ProductID()
+	if len(req.ProductID) != 0 {
+		productID = req.ProductID
+	}
+
+	var typename string
+	setup := func(ctx context.Context, rw kv.ReadWriter, uid string) error {
+		if len(req.Name) != 0 {
+			if err := namer.SetName(ctx, rw, req.Name, uid, typename); err != nil {
+				return fmt.Errorf("could not assign name: %w", err)
+			}
+		}
+		return s.runner.AddEvent(ctx, rw, uid, job.ClonedEvent, "clone of job "+req.UID)
+	}
+
+	var uid string
+	switch j := v.(type) {
+	case *limiter.Limiter:
+		expiry, err := cloneExpiry(j.Expiry())
+		if err != nil {
+			return nil, err
+		}
+		lreq := &api.LimitRequest{
+			ExchangeName: j.ExchangeName(),
+			ProductID:    productID,
+			Point:        clonePoint(j.Point(), req),
+			Options:      cloneOptions(j.Options()),
+			Expiry:       expiry,
+			Labels:       jd.Labels,
+			Pause:        true,
+		}
+		typename = "Limiter"
+		resp, err := s.addLimit(ctx, lreq, setup)
+		if err != nil {
+			return nil, err
+		}
+		uid = resp.UID
+
+	case *looper.Looper:
+		pair := j.Pair()
+		lreq := &api.LoopRequest{
+			ExchangeName: j.ExchangeName(),
+			ProductID:    productID,
+			Buy:          clonePoint(&pair.Buy, req),
+			Sell:         clonePoint(&pair.Sell, req),
+			Options:      cloneOptions(j.Options()),
+			Labels:       jd.Labels,
+			Pause:        true,
+		}
+		typename = "Looper"
+		resp, err := s.addLoop(ctx, lreq, setup)
+		if err != nil {
+			return nil, err
+		}
+		uid = resp.UID
+
+	case *waller.Waller:
+		var pairs []*point.Pair
+		for _, p := range j.Pairs() {
+			pairs = append(pairs, &point.Pair{
+				Buy:  *clonePoint(&p.Buy, req),
+				Sell: *clonePoint(&p.Sell, req),
+			})
+		}
+		wreq := &api.WallRequest{
+			ExchangeName: j.ExchangeName(),
+			ProductID:    productID,
+			Pairs:        pairs,
+			Options:      cloneOptions(j.Options()),
+			Labels:       jd.Labels,
+			Pause:        true,
+		}
+		typename = "Waller"
+		resp, err := s.addWall(ctx, wreq, setup)
+		if err != nil {
+			return nil, err
+		}
+		uid = resp.UID
+
+	default:
+		return nil, fmt.Errorf("job %q of type %s cannot be cloned: %w", req.UID, jd.Typename, os.ErrInvalid)
+	}
+
+	resp := &api.JobCloneResponse{
+		UID:  uid,
+		Type: typename,
+	}
+	return resp, nil
+}
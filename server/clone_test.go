@@ -0,0 +1,63 @@
+// Copyright (c) 2025 BVK Chaitanya
+
+package server
+
+import (
+	"testing"
+	"time"
+
+	"github.com/bvk/tradebot/gobs"
+	"github.com/shopspring/decimal"
+)
+
+func TestCloneOptions(t *testing.T) {
+	opts := map[string]string{
+		"retire":     "true",
+		"freeze":     "buys",
+		"stop-loss":  "pct:10",
+		"max-cycles": "5",
+	}
+	v := cloneOptions(opts)
+	if len(v) != 2 || v["stop-loss"] != "pct:10" || v["max-cycles"] != "5" {
+		t.Fatalf("wanted only stop-loss and max-cycles options, got %v", v)
+	}
+	if len(opts) != 4 {
+		t.Fatalf("wanted source options to be unchanged, got %v", opts)
+	}
+	if v := cloneOptions(map[string]string{"freeze": "both"}); v != nil {
+		t.Fatalf("wanted nil options, got %v", v)
+	}
+}
+
+func TestCloneExpiry(t *testing.T) {
+	if v, err := cloneExpiry(nil); v != nil || err != nil {
+		t.Fatalf("wanted nil expiry and error, got %v and %v", v, err)
+	}
+
+	deadline := time.Now().Add(time.Hour)
+	e := &gobs.LimiterExpiry{
+		Deadline:        deadline,
+		Action:          "reprice",
+		RepriceStepPct:  decimal.NewFromFloat(0.5),
+		RepriceInterval: time.Minute,
+		OriginalPrice:   decimal.NewFromInt(100),
+		Price:           decimal.NewFromInt(101),
+		ExpiredAt:       time.Now(),
+		NumReprices:     2,
+	}
+	v, err := cloneExpiry(e)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !v.Deadline.Equal(deadline) || v.Action != "reprice" || !v.RepriceStepPct.Equal(e.RepriceStepPct) || v.RepriceInterval != time.Minute {
+		t.Fatalf("wanted expiry parameters to be copied, got %+v", v)
+	}
+	if !v.Price.IsZero() || !v.ExpiredAt.IsZero() || v.NumReprices != 0 || !v.OriginalPrice.IsZero() {
+		t.Fatalf("wanted no fallback state in the clone, got %+v", v)
+	}
+
+	e.Deadline = time.Now().Add(-time.Minute)
+	if _, err := cloneExpiry(e); err == nil {
+		t.Fatalf("wanted non-nil error for a passed deadline")
+	}
+}
@@ -0,0 +1,144 @@
+// Copyright (c) 2025 BVK Chaitanya
+
+package server
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/bvk/tradebot/api"
+	"github.com/bvk/tradebot/gobs"
+	"github.com/bvk/tradebot/point"
+	"github.com/shopspring/decimal"
+)
+
+// baseCurrency returns the base currency for a product id.
+func baseCurrency(productID string) string {
+	pid := strings.ToUpper(productID)
+	if base, _, ok := strings.Cut(pid, "-"); ok {
+		return base
+	}
+	if base, ok := strings.CutSuffix(pid, quoteCurrency(pid)); ok && len(base) > 0 {
+		return base
+	}
+	// Stock symbols are the base currency themselves.
+	return pid
+}
+
+// placedNow returns true if an order is placed for the point immediately at
+// the ticker price. It follows the same conditions used by the limiter jobs.
+func placedNow(p *point.Point, ticker decimal.Decimal) bool {
+	if ticker.IsZero() {
+		return false
+	}
+	if p.Side() == "SELL" {
+		return ticker.GreaterThan(p.Cancel)
+	}
+	return ticker.GreaterThanOrEqual(p.Price) && ticker.LessThan(p.Cancel)
+}
+
+// pointViolations returns the exchange product limits violated by a point.
+func pointViolations(p *point.Point, product *gobs.Product) []string {
+	var vs []string
+	if product.BaseMinSize.IsPositive() && p.Size.LessThan(product.BaseMinSize) {
+		vs = append(vs, fmt.Sprintf("size %s is below the min size %s", p.Size, product.BaseMinSize))
+	}
+	if product.BaseMaxSize.IsPositive() && p.Size.GreaterThan(product.BaseMaxSize) {
+		vs = append(vs, fmt.Sprintf("size %s is above the max size %s", p.Size, product.BaseMaxSize))
+	}
+	if product.BaseIncrement.IsPositive() && !p.Size.Mod(product.BaseIncrement).IsZero() {
+		vs = append(vs, fmt.Sprintf("size %s is not a multiple of the size increment %s", p.Size, product.BaseIncrement))
+	}
+	if product.QuoteIncrement.IsPositive() && !p.Price.Mod(product.QuoteIncrement).IsZero() {
+		vs = append(vs, fmt.Sprintf("price %s is not a multiple of the price increment %s", p.Price, product.QuoteIncrement))
+	}
+	if product.QuoteMinSize.IsPositive() && p.Value().LessThan(product.QuoteMinSize) {
+		vs = append(vs, fmt.Sprintf("order value %s is below the min value %s", p.Value(), product.QuoteMinSize))
+	}
+	return vs
+}
+
+// dryRun returns a report of the exchange-facing effects for a new job with
+// a single limit point or with buy/sell pairs. Sell points of the pairs are
+// placed only after their buys are filled, so they do not require any base
+// currency balance.
+func (s *Server) dryRun(ctx context.Context, exchangeName, productID string, limit *point.Point, pairs []*point.Pair) (*api.DryRunReport, error) {
+	ex, ok := s.exchangeMap[exchangeName]
+	if !ok {
+		return nil, fmt.Errorf("exchange with name %q not found: %w", exchangeName, os.ErrNotExist)
+	}
+	base, quote := baseCurrency(productID), quoteCurrency(productID)
+	product, err := ex.GetSpotProduct(ctx, base, quote)
+	if err != nil {
+		return nil, fmt.Errorf("could not get product %q details from exchange %q: %w", productID, exchangeName, err)
+	}
+
+	report := &api.DryRunReport{
+		ExchangeName:   exchangeName,
+		ProductID:      productID,
+		TickerPrice:    product.Price,
+		BaseMinSize:    product.BaseMinSize,
+		BaseIncrement:  product.BaseIncrement,
+		QuoteMinSize:   product.QuoteMinSize,
+		QuoteIncrement: product.QuoteIncrement,
+		BaseCurrency:   base,
+		QuoteCurrency:  quote,
+	}
+
+	addPoint := func(index int, p *point.Point, canPlace bool) *api.DryRunPoint {
+		dp := &api.DryRunPoint{
+			Pair:       index,
+			Side:       p.Side(),
+			Size:       p.Size,
+			Price:      p.Price,
+			Cancel:     p.Cancel,
+			PlacedNow:  canPlace && placedNow(p, product.Price),
+			Violations: pointViolations(p, product),
+		}
+		if len(dp.Violations) > 0 {
+			report.NumViolations++
+		}
+		report.Points = append(report.Points, dp)
+		return dp
+	}
+
+	if limit != nil {
+		dp := addPoint(-1, limit, true)
+		if dp.Side == "BUY" {
+			value := limit.Value().Add(limit.FeeAt(budgetFeePct))
+			report.QuoteWorstCase = value
+			if dp.PlacedNow {
+				report.QuoteNow = value
+			}
+		} else {
+			report.BaseWorstCase = limit.Size
+			if dp.PlacedNow {
+				report.BaseNow = limit.Size
+			}
+		}
+	}
+
+	for i, pair := range pairs {
+		buy := addPoint(i, &pair.Buy, true)
+		addPoint(i, &pair.Sell, false /* canPlace */)
+
+		value := pair.Buy.Value().Add(pair.Buy.FeeAt(budgetFeePct))
+		report.QuoteWorstCase = report.QuoteWorstCase.Add(value)
+		if buy.PlacedNow {
+			report.QuoteNow = report.QuoteNow.Add(value)
+		}
+	}
+
+	if v, ok := s.balanceMap.Load(budgetKey(exchangeName, base)); ok {
+		report.BaseAvailable, report.HasBaseBalance = v, true
+	}
+	if v, ok := s.balanceMap.Load(budgetKey(exchangeName, quote)); ok {
+		report.QuoteAvailable, report.HasQuoteBalance = v, true
+	}
+
+	budgets, _ := s.committedBudgets()
+	report.QuoteCommitted = budgets[budgetKey(exchangeName, quote)]
+	return report, nil
+}
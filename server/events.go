@@ -0,0 +1,335 @@
+// Copyright (c) 2025 BVK Chaitanya
+
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/bvk/tradebot/api"
+	"github.com/bvk/tradebot/exchange"
+	"github.com/bvk/tradebot/job"
+	"github.com/bvk/tradebot/namer"
+	"github.com/shopspring/decimal"
+	"github.com/visvasity/topic"
+)
+
+// eventsKeepAlive is the interval for sending SSE comments on idle streams so
+// that proxies and clients do not close the connection.
+const eventsKeepAlive = 30 * time.Second
+
+// fill holds the executed amounts of an order seen so far.
+type fill struct {
+	size, value, fee decimal.Decimal
+}
+
+func (s *Server) eventsHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			http.Error(w, "invalid http method type", http.StatusMethodNotAllowed)
+			return
+		}
+		flusher, ok := w.(http.Flusher)
+		if !ok {
+			http.Error(w, "streaming is not supported", http.StatusInternalServerError)
+			return
+		}
+
+		values := r.URL.Query()
+		query, err := api.ParseEventsQuery(values.Get("types"), values.Get("products"))
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		ctx, cancel := context.WithCancel(r.Context())
+		defer cancel()
+
+		stop := context.AfterFunc(s.cg.Context(), cancel)
+		defer stop()
+
+		eventCh := make(chan *api.Event)
+		if err := s.subscribeEvents(ctx, query, eventCh); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		w.Header().Set("content-type", "text/event-stream")
+		w.Header().Set("cache-control", "no-cache")
+		w.Header().Set("connection", "keep-alive")
+		w.WriteHeader(http.StatusOK)
+		flusher.Flush()
+
+		ticker := time.NewTicker(eventsKeepAlive)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+
+			case <-ticker.C:
+				if _, err := fmt.Fprint(w, ": keep-alive\n\n"); err != nil {
+					return
+				}
+				flusher.Flush()
+
+			case event := <-eventCh:
+				data, err := json.Marshal(event)
+				if err != nil {
+					slog.Error("could not marshal event (ignored)", "type", event.Type, "err", err)
+					continue
+				}
+				if _, err := fmt.Fprintf(w, "event: %s\ndata: %s\n\n", event.Type, data); err != nil {
+					return
+				}
+				flusher.Flush()
+			}
+		}
+	})
+}
+
+// subscribeEvents subscribes to the topics for the selected event types and
+// forwards the events into the input channel until the context is canceled.
+func (s *Server) subscribeEvents(ctx context.Context, query *api.EventsQuery, eventCh chan<- *api.Event) error {
+	var products []exchange.Product
+	if len(query.Products) > 0 {
+		for _, p := range query.Products {
+			ename, pid, _ := strings.Cut(p, ":")
+			product, err := s.getProduct(ctx, ename, pid)
+			if err != nil {
+				return err
+			}
+			products = append(products, product)
+		}
+	} else {
+		s.mu.Lock()
+		for _, pmap := range s.exProductsMap {
+			for _, product := range pmap {
+				products = append(products, product)
+			}
+		}
+		s.mu.Unlock()
+	}
+
+	var exchanges []exchange.Exchange
+	for name, ex := range s.exchangeMap {
+		if len(query.Products) > 0 && !slices.ContainsFunc(products, func(p exchange.Product) bool { return p.ExchangeName() == name }) {
+			continue
+		}
+		exchanges = append(exchanges, ex)
+	}
+
+	// Subscribe to all topics before returning, so that subscription errors are
+	// reported to the client.
+	var closers []func()
+	defer func() {
+		for _, c := range closers {
+			c()
+		}
+	}()
+
+	var forwarders []func()
+	if query.Has(api.OrderEventType) || query.Has(api.FillEventType) {
+		for _, product := range products {
+			updates, err := product.GetOrderUpdates()
+			if err != nil {
+				return fmt.Errorf("could not subscribe to order updates of %s:%s: %w", product.ExchangeName(), product.ProductID(), err)
+			}
+			closers = append(closers, updates.Close)
+			forwarders = append(forwarders, func() {
+				defer updates.Close()
+				forwardOrderEvents(ctx, query, product, updates, eventCh)
+			})
+		}
+	}
+	if query.Has(api.PriceEventType) {
+		for _, product := range products {
+			updates, err := product.GetPriceUpdates()
+			if err != nil {
+				return fmt.Errorf("could not subscribe to price updates of %s:%s: %w", product.ExchangeName(), product.ProductID(), err)
+			}
+			closers = append(closers, updates.Close)
+			forwarders = append(forwarders, func() {
+				defer updates.Close()
+				forwardEvents(ctx, updates, eventCh, func(v exchange.PriceUpdate) *api.Event {
+					price, at := v.PricePoint()
+					return &api.Event{
+						Type:         api.PriceEventType,
+						Time:         at.Time,
+						ExchangeName: product.ExchangeName(),
+						ProductID:    product.ProductID(),
+						Price:        &api.PriceEvent{Price: price},
+					}
+				})
+			})
+		}
+	}
+	if query.Has(api.BalanceEventType) {
+		for _, ex := range exchanges {
+			updates, err := ex.GetBalanceUpdates()
+			if err != nil {
+				return fmt.Errorf("could not subscribe to balance updates of %s: %w", ex.ExchangeName(), err)
+			}
+			closers = append(closers, updates.Close)
+			forwarders = append(forwarders, func() {
+				defer updates.Close()
+				forwardEvents(ctx, updates, eventCh, func(v exchange.BalanceUpdate) *api.Event {
+					ccy, amount := v.Balance()
+					return &api.Event{
+						Type:         api.BalanceEventType,
+						Time:         time.Now(),
+						ExchangeName: ex.ExchangeName(),
+						Balance:      &api.BalanceEvent{Currency: ccy, Amount: amount},
+					}
+				})
+			})
+		}
+	}
+	if query.Has(api.JobEventType) {
+		updates, err := s.runner.StateChanges()
+		if err != nil {
+			return fmt.Errorf("could not subscribe to job state changes: %w", err)
+		}
+		closers = append(closers, updates.Close)
+		forwarders = append(forwarders, func() {
+			defer updates.Close()
+			forwardEvents(ctx, updates, eventCh, func(v *job.StateChange) *api.Event {
+				name, _, _, _ := namer.ResolveDB(ctx, s.db, v.UID)
+				return &api.Event{
+					Type: api.JobEventType,
+					Time: v.Event.Time,
+					Job: &api.JobStateEvent{
+						UID:    v.UID,
+						Name:   name,
+						Kind:   v.Event.Kind,
+						State:  v.Event.State,
+						Reason: v.Event.Reason,
+					},
+				}
+			})
+		})
+	}
+
+	closers = nil
+	for _, f := range forwarders {
+		go f()
+	}
+	return nil
+}
+
+// forwardEvents converts the values from a topic receiver into events and
+// sends them to the events channel until the context is canceled.
+func forwardEvents[T any](ctx context.Context, r *topic.Receiver[T], eventCh chan<- *api.Event, fn func(T) *api.Event) {
+	ch, err := topic.ReceiveCh(r)
+	if err != nil {
+		return
+	}
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case v, ok := <-ch:
+			if !ok {
+				return
+			}
+			select {
+			case <-ctx.Done():
+				return
+			case eventCh <- fn(v):
+			}
+		}
+	}
+}
+
+// forwardOrderEvents sends the order update events and the fill events derived
+// from the order updates of a product. Fills are computed as the difference
+// in executed amounts between successive updates of an order.
+func forwardOrderEvents(ctx context.Context, query *api.EventsQuery, product exchange.Product, r *topic.Receiver[exchange.OrderUpdate], eventCh chan<- *api.Event) {
+	ch, err := topic.ReceiveCh(r)
+	if err != nil {
+		return
+	}
+
+	send := func(event *api.Event) bool {
+		select {
+		case <-ctx.Done():
+			return false
+		case eventCh <- event:
+			return true
+		}
+	}
+
+	fills := make(map[string]*fill)
+	for {
+		var update exchange.OrderUpdate
+		select {
+		case <-ctx.Done():
+			return
+		case v, ok := <-ch:
+			if !ok {
+				return
+			}
+			update = v
+		}
+
+		now := time.Now()
+		serverID, clientID := update.ServerID(), update.ClientID().String()
+		if query.Has(api.OrderEventType) {
+			event := &api.Event{
+				Type:         api.OrderEventType,
+				Time:         now,
+				ExchangeName: product.ExchangeName(),
+				ProductID:    product.ProductID(),
+				Order: &api.OrderEvent{
+					ServerID:      serverID,
+					ClientID:      clientID,
+					ExecutedFee:   update.ExecutedFee(),
+					ExecutedSize:  update.ExecutedSize(),
+					ExecutedValue: update.ExecutedValue(),
+					Status:        update.OrderStatus(),
+					Done:          update.IsDone(),
+				},
+			}
+			if !send(event) {
+				return
+			}
+		}
+
+		if query.Has(api.FillEventType) {
+			last, ok := fills[serverID]
+			if !ok {
+				last = new(fill)
+				fills[serverID] = last
+			}
+			if size := update.ExecutedSize(); size.GreaterThan(last.size) {
+				event := &api.Event{
+					Type:         api.FillEventType,
+					Time:         now,
+					ExchangeName: product.ExchangeName(),
+					ProductID:    product.ProductID(),
+					Fill: &api.FillEvent{
+						ServerID: serverID,
+						ClientID: clientID,
+						Size:     size.Sub(last.size),
+						Value:    update.ExecutedValue().Sub(last.value),
+						Fee:      update.ExecutedFee().Sub(last.fee),
+					},
+				}
+				last.size, last.value, last.fee = size, update.ExecutedValue(), update.ExecutedFee()
+				if !send(event) {
+					return
+				}
+			}
+			if update.IsDone() {
+				delete(fills, serverID)
+			}
+		}
+	}
+}
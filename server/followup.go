@@ -0,0 +1,149 @@
+// Copyright (c) 2025 BVK Chaitanya
+
+package server
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/bvk/tradebot/api"
+	"github.com/bvk/tradebot/gobs"
+	"github.com/bvk/tradebot/limiter"
+	"github.com/bvk/tradebot/looper"
+	"github.com/bvk/tradebot/point"
+	"github.com/bvk/tradebot/trader"
+	"github.com/bvkgo/kv"
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+)
+
+// filledSize returns the base size filled by the actions. It is the net
+// bought or sold size, or the total filled size when buys and sells cancel
+// each other.
+func filledSize(actions []*gobs.Action) decimal.Decimal {
+	var bought, sold decimal.Decimal
+	for _, a := range actions {
+		for _, o := range a.Orders {
+			if strings.EqualFold(o.Side, "BUY") {
+				bought = bought.Add(o.FilledSize)
+			} else {
+				sold = sold.Add(o.FilledSize)
+			}
+		}
+	}
+	if net := bought.Sub(sold).Abs(); !net.IsZero() {
+		return net
+	}
+	return bought.Add(sold)
+}
+
+// followUpPoint returns the trade point for a follow-up job with size derived
+// from the parent job's fills when it is not specified.
+func followUpPoint(p *gobs.Point, size decimal.Decimal) *point.Point {
+	v := &point.Point{Size: p.Size, Price: p.Price, Cancel: p.Cancel}
+	if v.Size.IsZero() {
+		v.Size = size
+	}
+	return v
+}
+
+// createFollowUp is invoked by the job runner when a job with a follow-up
+// declaration completes successfully. New job is not saved here; runner saves
+// it through the returned function after it's checks are successful.
+func (s *Server) createFollowUp(ctx context.Context, r kv.Reader, parent *gobs.JobData) (string, string, func(context.Context, kv.ReadWriter) error, error) {
+	v, err := Load(ctx, r, parent.ID, parent.Typename)
+	if err != nil {
+		return "", "", nil, fmt.Errorf("could not load parent job %q: %w", parent.ID, err)
+	}
+
+	size := filledSize(v.Actions())
+	if size.IsZero() {
+		return "", "", nil, fmt.Errorf("parent job %q has no filled orders", parent.ID)
+	}
+
+	uid := uuid.New().String()
+	f := parent.FollowUp
+
+	var typename string
+	var child trader.Trader
+	switch {
+	case strings.EqualFold(f.Typename, "limiter"):
+		typename = "Limiter"
+		p := f.Sell
+		if p == nil {
+			p = f.Buy
+		}
+		if p == nil {
+			return "", "", nil, fmt.Errorf("follow-up limiter has no trade point")
+		}
+		l, err := limiter.New(uid, v.ExchangeName(), v.ProductID(), followUpPoint(p, size))
+		if err != nil {
+			return "", "", nil, err
+		}
+		child = l
+
+	case strings.EqualFold(f.Typename, "looper"):
+		typename = "Looper"
+		if f.Buy == nil || f.Sell == nil {
+			return "", "", nil, fmt.Errorf("follow-up looper needs both buy and sell points")
+		}
+		l, err := looper.New(uid, v.ExchangeName(), v.ProductID(), followUpPoint(f.Buy, size), followUpPoint(f.Sell, size))
+		if err != nil {
+			return "", "", nil, err
+		}
+		child = l
+
+	default:
+		return "", "", nil, fmt.Errorf("unsupported follow-up job type %q", f.Typename)
+	}
+
+	slog.Info("created follow-up job", "parent", parent.ID, "child", uid, "type", f.Typename, "size", size)
+	return uid, typename, child.Save, nil
+}
+
+// startFollowUp resumes a newly created follow-up job.
+func (s *Server) startFollowUp(uid string) {
+	ctx := s.cg.Context()
+
+	child, err := s.loadJob(ctx, uid)
+	if err != nil {
+		slog.Error("could not load follow-up job (will be resumed on restart)", "job", uid, "err", err)
+		return
+	}
+
+	if err := s.runner.Resume(ctx, uid, s.makeJobFunc(child), ctx); err != nil {
+		slog.Error("could not resume follow-up job (will be resumed on restart)", "job", uid, "err", err)
+		return
+	}
+	log.Printf("resumed follow-up job with id %q", uid)
+	s.SendMessage(ctx, time.Now(), "Follow-up job %s is started in product %s (%s).", uid, child.ProductID(), child.ExchangeName())
+}
+
+func (s *Server) doJobFollowUp(ctx context.Context, req *api.JobFollowUpRequest) (*api.JobFollowUpResponse, error) {
+	if err := req.Check(); err != nil {
+		return nil, fmt.Errorf("invalid follow-up request: %w", err)
+	}
+
+	if _, err := uuid.Parse(req.UID); err != nil {
+		return nil, fmt.Errorf("job uid must be an uuid: %w", err)
+	}
+
+	var followUp *gobs.JobFollowUp
+	if !req.Remove {
+		followUp = &gobs.JobFollowUp{Typename: req.Typename}
+		if req.Buy != nil {
+			followUp.Buy = &gobs.Point{Size: req.Buy.Size, Price: req.Buy.Price, Cancel: req.Buy.Cancel}
+		}
+		if req.Sell != nil {
+			followUp.Sell = &gobs.Point{Size: req.Sell.Size, Price: req.Sell.Price, Cancel: req.Sell.Cancel}
+		}
+	}
+	if err := s.runner.SetFollowUp(ctx, nil /* writer */, req.UID, followUp); err != nil {
+		return nil, err
+	}
+	return &api.JobFollowUpResponse{}, nil
+}
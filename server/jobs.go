@@ -9,6 +9,7 @@ import (
 	"log"
 	"log/slog"
 	"os"
+	"slices"
 	"time"
 
 	"github.com/bvk/tradebot/api"
@@ -49,93 +50,182 @@ func (s *Server) makeJobFunc(v trader.Trader) job.Func {
 		s.jobMap.Store(uid, v)
 		defer s.jobMap.Delete(uid)
 
-		return v.Run(ctx, s.Runtime(product))
+		// Armed jobs must wait for the trigger before trading.
+		jd, err := s.runner.Get(ctx, nil, uid)
+		if err != nil {
+			return err
+		}
+		if jd.IsArmed() {
+			if err := s.waitForTrigger(ctx, uid, product, jd.Trigger); err != nil {
+				return err
+			}
+		}
+
+		if err := v.Run(ctx, s.Runtime(product)); err != nil {
+			if !errors.Is(err, trader.ErrPaused) {
+				return err
+			}
+			// Self-paused jobs must not be resumed automatically on restarts.
+			manual := func(ctx context.Context, rw kv.ReadWriter) error {
+				jd, err := s.runner.Get(ctx, rw, uid)
+				if err != nil {
+					return err
+				}
+				return s.runner.UpdateFlags(ctx, rw, uid, jd.Flags|ManualFlag)
+			}
+			if err := kv.WithReadWriter(ctx, s.db, manual); err != nil {
+				slog.Warn("could not mark self-paused job as manual (ignored)", "job", uid, "err", err)
+			}
+			return fmt.Errorf("%w: %w", job.ErrPause, err)
+		}
+		return nil
 	}
 }
 
 // doPause pauses a running job. If the job is not running and is not final
 // it's state is updated to manually-paused state.
 func (s *Server) doPause(ctx context.Context, req *api.JobPauseRequest) (*api.JobPauseResponse, error) {
-	if err := s.runner.Pause(ctx, req.UID); err != nil {
-		return nil, fmt.Errorf("could not pause job %q: %w", req.UID, err)
+	if len(req.Selector) != 0 {
+		results, err := s.forEachSelected(ctx, req.Selector, s.pauseJob)
+		if err != nil {
+			return nil, err
+		}
+		return &api.JobPauseResponse{Jobs: results}, nil
+	}
+
+	state, err := s.pauseJob(ctx, req.UID)
+	if err != nil {
+		return nil, err
+	}
+	resp := &api.JobPauseResponse{
+		FinalState: string(state),
+	}
+	return resp, nil
+}
+
+func (s *Server) pauseJob(ctx context.Context, uid string) (gobs.State, error) {
+	if err := s.runner.Pause(ctx, uid); err != nil {
+		return "", fmt.Errorf("could not pause job %q: %w", uid, err)
 	}
 
 	var state gobs.State
 	pause := func(ctx context.Context, rw kv.ReadWriter) error {
-		jd, err := s.runner.Get(ctx, rw, req.UID)
+		jd, err := s.runner.Get(ctx, rw, uid)
 		if err != nil {
-			return fmt.Errorf("could not get job %q data: %w", req.UID, err)
+			return fmt.Errorf("could not get job %q data: %w", uid, err)
 		}
-		if err := s.runner.UpdateFlags(ctx, rw, req.UID, jd.Flags|ManualFlag); err != nil {
-			log.Printf("job is paused, but could not mark job %q as manual (ignored): %v", req.UID, err)
+		if err := s.runner.UpdateFlags(ctx, rw, uid, jd.Flags|ManualFlag); err != nil {
+			log.Printf("job is paused, but could not mark job %q as manual (ignored): %v", uid, err)
 		}
 		state = jd.State
 		return nil
 	}
 	if err := kv.WithReadWriter(ctx, s.db, pause); err != nil {
-		return nil, fmt.Errorf("could not pause job %q: %w", req.UID, err)
+		return "", fmt.Errorf("could not pause job %q: %w", uid, err)
 	}
+	return state, nil
+}
 
-	resp := &api.JobPauseResponse{
+// doResume resumes a non-final job.
+func (s *Server) doResume(ctx context.Context, req *api.JobResumeRequest) (*api.JobResumeResponse, error) {
+	if len(req.Selector) != 0 {
+		results, err := s.forEachSelected(ctx, req.Selector, s.resumeJob)
+		if err != nil {
+			return nil, err
+		}
+		return &api.JobResumeResponse{Jobs: results}, nil
+	}
+
+	state, err := s.resumeJob(ctx, req.UID)
+	if err != nil {
+		return nil, err
+	}
+	resp := &api.JobResumeResponse{
 		FinalState: string(state),
 	}
 	return resp, nil
 }
 
-// doResume resumes a non-final job.
-func (s *Server) doResume(ctx context.Context, req *api.JobResumeRequest) (*api.JobResumeResponse, error) {
+func (s *Server) resumeJob(ctx context.Context, uid string) (gobs.State, error) {
 	var trader trader.Trader
 	resume := func(ctx context.Context, rw kv.ReadWriter) error {
-		jd, err := s.runner.Get(ctx, rw, req.UID)
+		jd, err := s.runner.Get(ctx, rw, uid)
 		if err != nil {
 			return err
 		}
 
 		if jd.Flags&ManualFlag != 0 {
 			jd.Flags = jd.Flags ^ ManualFlag
-			if err := s.runner.UpdateFlags(ctx, rw, req.UID, jd.Flags); err != nil {
-				log.Printf("could not clear the manual flag on job %q (ignored): %v", req.UID, err)
+			if err := s.runner.UpdateFlags(ctx, rw, uid, jd.Flags); err != nil {
+				log.Printf("could not clear the manual flag on job %q (ignored): %v", uid, err)
 				return err
 			}
 		}
 
-		v, err := Load(ctx, rw, req.UID, jd.Typename)
+		v, err := Load(ctx, rw, uid, jd.Typename)
 		if err != nil {
-			return fmt.Errorf("could not load trader job %q: %w", req.UID, err)
+			return fmt.Errorf("could not load trader job %q: %w", uid, err)
 		}
 		trader = v
 		return nil
 	}
 	if err := kv.WithReadWriter(ctx, s.db, resume); err != nil {
-		return nil, err
+		return "", err
 	}
 
-	if err := s.runner.Resume(ctx, req.UID, s.makeJobFunc(trader), s.cg.Context()); err != nil {
-		return nil, fmt.Errorf("could not resume job %q: %w", req.UID, err)
+	if err := s.runner.Resume(ctx, uid, s.makeJobFunc(trader), s.cg.Context()); err != nil {
+		return "", fmt.Errorf("could not resume job %q: %w", uid, err)
 	}
-	log.Printf("resumed job with id %q", req.UID)
-
-	resp := &api.JobResumeResponse{
-		FinalState: string(gobs.RUNNING),
-	}
-	return resp, nil
+	log.Printf("resumed job with id %q", uid)
+	return gobs.RUNNING, nil
 }
 
 // doCancel cancels a non-final job. If job is running, it will be stopped.
 func (s *Server) doCancel(ctx context.Context, req *api.JobCancelRequest) (*api.JobCancelResponse, error) {
-	jd, err := s.runner.Cancel(ctx, req.UID)
+	if len(req.Selector) != 0 {
+		results, err := s.forEachSelected(ctx, req.Selector, s.cancelJob)
+		if err != nil {
+			return nil, err
+		}
+		return &api.JobCancelResponse{Jobs: results}, nil
+	}
+
+	state, err := s.cancelJob(ctx, req.UID)
 	if err != nil {
 		return nil, err
 	}
 	resp := &api.JobCancelResponse{
-		FinalState: string(jd.State),
+		FinalState: string(state),
 	}
 	return resp, nil
 }
 
+func (s *Server) cancelJob(ctx context.Context, uid string) (gobs.State, error) {
+	jd, err := s.runner.Cancel(ctx, uid)
+	if err != nil {
+		return "", err
+	}
+	return jd.State, nil
+}
+
 func (s *Server) doList(ctx context.Context, req *api.JobListRequest) (*api.JobListResponse, error) {
+	selector, err := job.ParseLabels(req.Selector)
+	if err != nil {
+		return nil, fmt.Errorf("could not parse selector: %w", err)
+	}
+
 	resp := new(api.JobListResponse)
 	collect := func(ctx context.Context, r kv.Reader, jd *gobs.JobData) error {
+		if len(selector) != 0 {
+			labels, err := s.jobLabels(ctx, r, jd, selector)
+			if err != nil {
+				return err
+			}
+			if !job.MatchLabels(selector, labels) {
+				return nil
+			}
+		}
+
 		name, _, _, err := namer.Resolve(ctx, r, jd.ID)
 		if err != nil {
 			if !errors.Is(err, os.ErrNotExist) {
@@ -148,7 +238,13 @@ func (s *Server) doList(ctx context.Context, req *api.JobListRequest) (*api.JobL
 			State:      string(jd.State),
 			Name:       name,
 			ManualFlag: (jd.Flags & ManualFlag) != 0,
+			Labels:     jd.Labels,
+			Armed:      jd.IsArmed(),
+		}
+		if job.IsWaitingForRestart(jd) {
+			item.RestartAt = jd.RestartAt
 		}
+		item.Trigger, item.TriggerDistance = s.triggerStatus(jd)
 		resp.Jobs = append(resp.Jobs, item)
 		return nil
 	}
@@ -172,9 +268,20 @@ func (s *Server) doSetJobName(ctx context.Context, req *api.SetJobNameRequest) (
 		if err != nil {
 			return fmt.Errorf("could not load job %q: %w", req.UID, err)
 		}
+		old, _, _, err := namer.Resolve(ctx, rw, jd.ID)
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("could not resolve job id %q: %w", jd.ID, err)
+		}
 		if err := namer.SetName(ctx, rw, req.JobName, jd.ID, jd.Typename); err != nil {
 			return fmt.Errorf("could not assign name: %w", err)
 		}
+		reason := req.JobName
+		if old != "" && old != req.JobName {
+			reason = fmt.Sprintf("%s -> %s", old, req.JobName)
+		}
+		if err := s.runner.AddEvent(ctx, rw, jd.ID, job.RenamedEvent, reason); err != nil {
+			return fmt.Errorf("could not record job rename: %w", err)
+		}
 		return nil
 	}
 	if err := kv.WithReadWriter(ctx, s.db, setName); err != nil {
@@ -186,7 +293,15 @@ func (s *Server) doSetJobName(ctx context.Context, req *api.SetJobNameRequest) (
 
 func (s *Server) doJobSetOption(ctx context.Context, req *api.JobSetOptionRequest) (*api.JobSetOptionResponse, error) {
 	if err := req.Check(); err != nil {
-		return nil, fmt.Errorf("invalid rename request: %w", err)
+		return nil, fmt.Errorf("invalid set-option request: %w", err)
+	}
+
+	if len(req.Selector) != 0 {
+		results, err := s.setOptionSelected(ctx, req)
+		if err != nil {
+			return nil, err
+		}
+		return &api.JobSetOptionResponse{Jobs: results}, nil
 	}
 
 	if _, err := uuid.Parse(req.UID); err != nil {
@@ -194,35 +309,142 @@ func (s *Server) doJobSetOption(ctx context.Context, req *api.JobSetOptionReques
 	}
 
 	update := func(ctx context.Context, rw kv.ReadWriter) error {
-		// Job must not be running.
-		if _, ok := s.jobMap.Load(req.UID); ok {
-			return fmt.Errorf("job is currently running: %w", os.ErrInvalid)
+		v, err := s.loadIdleJob(ctx, rw, req.UID)
+		if err != nil {
+			return err
 		}
+		if _, err := v.SetOption(req.OptionKey, req.OptionValue); err != nil {
+			return fmt.Errorf("could not set job option: %w", err)
+		}
+		return s.saveJobOption(ctx, rw, v, req)
+	}
+	if err := kv.WithReadWriter(ctx, s.db, update); err != nil {
+		return nil, err
+	}
+	return &api.JobSetOptionResponse{}, nil
+}
 
-		// Job must not be complete already.
-		jd, err := s.runner.Get(ctx, rw, req.UID)
+// setOptionSelected updates an option on all unfinished jobs matching the
+// selector. All jobs are updated in a single transaction, so if option cannot
+// be set on any one of the jobs, none of the jobs are saved. Jobs are loaded
+// from the database in the transaction, so they need not be undone.
+func (s *Server) setOptionSelected(ctx context.Context, req *api.JobSetOptionRequest) ([]*api.JobResult, error) {
+	var results []*api.JobResult
+	update := func(ctx context.Context, rw kv.ReadWriter) error {
+		jds, err := s.selectJobs(ctx, rw, req.Selector)
 		if err != nil {
 			return err
 		}
+		jds = slices.DeleteFunc(jds, func(jd *gobs.JobData) bool { return jd.State.IsDone() })
+		if len(jds) == 0 {
+			return fmt.Errorf("no unfinished jobs match the selector %q: %w", req.Selector, os.ErrNotExist)
+		}
 
-		if jd.State.IsDone() {
-			return fmt.Errorf("job %q is already completed (%q)", req.UID, jd.State)
+		var jobs []trader.Trader
+		for _, jd := range jds {
+			v, err := s.loadIdleJob(ctx, rw, jd.ID)
+			if err != nil {
+				return err
+			}
+			if _, err := v.SetOption(req.OptionKey, req.OptionValue); err != nil {
+				return fmt.Errorf("could not set job option on %q: %w", jd.ID, err)
+			}
+			jobs = append(jobs, v)
 		}
 
-		job, err := Load(ctx, rw, req.UID, jd.Typename)
+		results = nil
+		for i, v := range jobs {
+			if err := s.saveJobOption(ctx, rw, v, req); err != nil {
+				return err
+			}
+			name, _, _, _ := namer.Resolve(ctx, rw, v.UID())
+			results = append(results, &api.JobResult{
+				UID:        v.UID(),
+				Name:       name,
+				FinalState: string(jds[i].State),
+			})
+		}
+		return nil
+	}
+	if err := kv.WithReadWriter(ctx, s.db, update); err != nil {
+		return nil, err
+	}
+	return results, nil
+}
+
+// loadIdleJob loads a job that is not running and is not complete.
+func (s *Server) loadIdleJob(ctx context.Context, rw kv.ReadWriter, uid string) (trader.Trader, error) {
+	// Job must not be running.
+	if _, ok := s.jobMap.Load(uid); ok {
+		return nil, fmt.Errorf("job %q is currently running: %w", uid, os.ErrInvalid)
+	}
+
+	// Job must not be complete already.
+	jd, err := s.runner.Get(ctx, rw, uid)
+	if err != nil {
+		return nil, err
+	}
+
+	if jd.State.IsDone() {
+		return nil, fmt.Errorf("job %q is already completed (%q)", uid, jd.State)
+	}
+
+	v, err := Load(ctx, rw, uid, jd.Typename)
+	if err != nil {
+		return nil, fmt.Errorf("could not load trader job %q: %w", uid, err)
+	}
+	return v, nil
+}
+
+// saveJobOption saves a job after an option update and records the update
+// in the job history.
+func (s *Server) saveJobOption(ctx context.Context, rw kv.ReadWriter, v trader.Trader, req *api.JobSetOptionRequest) error {
+	if err := v.Save(ctx, rw); err != nil {
+		return fmt.Errorf("could not save the job options: %w", err)
+	}
+	reason := fmt.Sprintf("%s=%s", req.OptionKey, req.OptionValue)
+	if err := s.runner.AddEvent(ctx, rw, v.UID(), job.OptionEvent, reason); err != nil {
+		return fmt.Errorf("could not record job option change: %w", err)
+	}
+	return nil
+}
+
+// doJobHistory returns the event history of a job.
+func (s *Server) doJobHistory(ctx context.Context, req *api.JobHistoryRequest) (*api.JobHistoryResponse, error) {
+	if len(req.UID) == 0 {
+		return nil, fmt.Errorf("job uid cannot be empty: %w", os.ErrInvalid)
+	}
+
+	resp := &api.JobHistoryResponse{UID: req.UID}
+	history := func(ctx context.Context, r kv.Reader) error {
+		jd, err := s.runner.Get(ctx, r, req.UID)
 		if err != nil {
-			return fmt.Errorf("could not load trader job %q: %w", req.UID, err)
+			if !errors.Is(err, os.ErrNotExist) {
+				return err
+			}
+			a, err := s.runner.GetArchive(ctx, r, req.UID)
+			if err != nil {
+				return err
+			}
+			jd = &gobs.JobData{ID: a.UID, Typename: a.Typename, State: a.State}
 		}
-		if _, err := job.SetOption(req.OptionKey, req.OptionValue); err != nil {
-			return fmt.Errorf("could not set job option: %w", err)
+		resp.Type = jd.Typename
+
+		name, _, _, err := namer.Resolve(ctx, r, jd.ID)
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("could not resolve job id %q: %w", jd.ID, err)
 		}
-		if err := job.Save(ctx, rw); err != nil {
-			return fmt.Errorf("could not save the job options: %w", err)
+		resp.Name = name
+
+		events, err := s.runner.History(ctx, r, req.UID)
+		if err != nil {
+			return err
 		}
+		resp.Events = events
 		return nil
 	}
-	if err := kv.WithReadWriter(ctx, s.db, update); err != nil {
-		return nil, err
+	if err := kv.WithReader(ctx, s.db, history); err != nil {
+		return nil, fmt.Errorf("could not fetch history of job %q: %w", req.UID, err)
 	}
-	return &api.JobSetOptionResponse{}, nil
+	return resp, nil
 }
@@ -0,0 +1,145 @@
+// Copyright (c) 2025 BVK Chaitanya
+
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"os"
+	"strings"
+
+	"github.com/bvk/tradebot/api"
+	"github.com/bvk/tradebot/gobs"
+	"github.com/bvk/tradebot/job"
+	"github.com/bvk/tradebot/namer"
+	"github.com/bvkgo/kv"
+	"github.com/google/uuid"
+)
+
+// jobLabels returns the user-defined labels of a job along with the reserved
+// labels derived from the job data. Job is loaded only when the selector
+// needs the product or exchange labels.
+func (s *Server) jobLabels(ctx context.Context, r kv.Reader, jd *gobs.JobData, selector map[string]string) (map[string]string, error) {
+	labels := maps.Clone(jd.Labels)
+	if labels == nil {
+		labels = make(map[string]string)
+	}
+	labels["uid"] = jd.ID
+	labels["type"] = strings.ToLower(jd.Typename)
+	labels["state"] = strings.ToLower(string(jd.State))
+
+	if _, ok := selector["name"]; ok {
+		name, _, _, err := namer.Resolve(ctx, r, jd.ID)
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			return nil, fmt.Errorf("could not resolve job id %q: %w", jd.ID, err)
+		}
+		labels["name"] = name
+	}
+
+	_, needProduct := selector["product"]
+	_, needExchange := selector["exchange"]
+	if needProduct || needExchange {
+		v, err := Load(ctx, r, jd.ID, jd.Typename)
+		if err != nil {
+			return nil, fmt.Errorf("could not load trader job %q: %w", jd.ID, err)
+		}
+		labels["product"] = v.ProductID()
+		labels["exchange"] = v.ExchangeName()
+	}
+	return labels, nil
+}
+
+// selectJobs returns all jobs matching the input label selector.
+func (s *Server) selectJobs(ctx context.Context, r kv.Reader, selectorStr string) ([]*gobs.JobData, error) {
+	selector, err := job.ParseLabels(selectorStr)
+	if err != nil {
+		return nil, fmt.Errorf("could not parse selector: %w", err)
+	}
+	if len(selector) == 0 {
+		return nil, fmt.Errorf("selector cannot be empty: %w", os.ErrInvalid)
+	}
+
+	var jds []*gobs.JobData
+	collect := func(ctx context.Context, r kv.Reader, jd *gobs.JobData) error {
+		labels, err := s.jobLabels(ctx, r, jd, selector)
+		if err != nil {
+			return err
+		}
+		if job.MatchLabels(selector, labels) {
+			jds = append(jds, jd)
+		}
+		return nil
+	}
+	if err := s.runner.Scan(ctx, r, collect); err != nil {
+		return nil, fmt.Errorf("could not scan all jobs: %w", err)
+	}
+	return jds, nil
+}
+
+// forEachSelected invokes the input function on all jobs matching the label
+// selector and collects the per-job results. Failures on one job do not stop
+// the operation on other jobs.
+func (s *Server) forEachSelected(ctx context.Context, selector string, fn func(context.Context, string) (gobs.State, error)) ([]*api.JobResult, error) {
+	var jds []*gobs.JobData
+	names := make(map[string]string)
+	selectJobs := func(ctx context.Context, r kv.Reader) error {
+		v, err := s.selectJobs(ctx, r, selector)
+		if err != nil {
+			return err
+		}
+		for _, jd := range v {
+			if name, _, _, err := namer.Resolve(ctx, r, jd.ID); err == nil {
+				names[jd.ID] = name
+			}
+		}
+		jds = v
+		return nil
+	}
+	if err := kv.WithReader(ctx, s.db, selectJobs); err != nil {
+		return nil, err
+	}
+	if len(jds) == 0 {
+		return nil, fmt.Errorf("no jobs match the selector %q: %w", selector, os.ErrNotExist)
+	}
+
+	var results []*api.JobResult
+	for _, jd := range jds {
+		result := &api.JobResult{UID: jd.ID, Name: names[jd.ID]}
+		state, err := fn(ctx, jd.ID)
+		if err != nil {
+			result.Error = err.Error()
+		}
+		result.FinalState = string(state)
+		results = append(results, result)
+	}
+	return results, nil
+}
+
+func (s *Server) doJobLabel(ctx context.Context, req *api.JobLabelRequest) (*api.JobLabelResponse, error) {
+	if err := req.Check(); err != nil {
+		return nil, fmt.Errorf("invalid label request: %w", err)
+	}
+
+	if _, err := uuid.Parse(req.UID); err != nil {
+		return nil, fmt.Errorf("job uid must be an uuid: %w", err)
+	}
+
+	resp := new(api.JobLabelResponse)
+	update := func(ctx context.Context, rw kv.ReadWriter) error {
+		if err := s.runner.UpdateLabels(ctx, rw, req.UID, req.Labels, req.Remove); err != nil {
+			return err
+		}
+		jd, err := s.runner.Get(ctx, rw, req.UID)
+		if err != nil {
+			return err
+		}
+		resp.Labels = jd.Labels
+		return nil
+	}
+	if err := kv.WithReadWriter(ctx, s.db, update); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
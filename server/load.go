@@ -8,6 +8,7 @@ import (
 	"path"
 	"strings"
 
+	"github.com/bvk/tradebot/arbiter"
 	"github.com/bvk/tradebot/limiter"
 	"github.com/bvk/tradebot/looper"
 	"github.com/bvk/tradebot/namer"
@@ -69,6 +70,18 @@ func LoadAll(ctx context.Context, r kv.Reader) ([]trader.Trader, error) {
 		traders = append(traders, v)
 	}
 
+	arbiterPick := func(k string) bool {
+		_, err := uuid.Parse(strings.TrimPrefix(k, arbiter.DefaultKeyspace))
+		return err == nil
+	}
+	arbiters, err := arbiter.LoadFunc(ctx, r, arbiterPick)
+	if err != nil {
+		return nil, fmt.Errorf("could not load all existing arbiters: %w", err)
+	}
+	for _, v := range arbiters {
+		traders = append(traders, v)
+	}
+
 	return traders, nil
 }
 
@@ -85,6 +98,7 @@ func Load(ctx context.Context, r kv.Reader, uid, typename string) (trader.Trader
 			{looper.DefaultKeyspace, "looper"},
 			{waller.DefaultKeyspace, "waller"},
 			{watcher.DefaultKeyspace, "watcher"},
+			{arbiter.DefaultKeyspace, "arbiter"},
 		}
 		for _, ks := range kss {
 			key := path.Join(ks[0], uid)
@@ -104,6 +118,8 @@ func Load(ctx context.Context, r kv.Reader, uid, typename string) (trader.Trader
 		return waller.Load(ctx, uid, r)
 	case strings.EqualFold(typename, "watcher"):
 		return watcher.Load(ctx, uid, r)
+	case strings.EqualFold(typename, "arbiter"):
+		return arbiter.Load(ctx, uid, r)
 	}
 
 	return nil, fmt.Errorf("unsupported trader type %q", typename)
@@ -39,6 +39,11 @@ func (s *Server) watchForLowBalance(ctx context.Context, ex exchange.Exchange) e
 
 		case update := <-updatesCh:
 			ccy, amount := update.Balance()
+			var hold decimal.Decimal
+			if v, ok := update.(exchange.HoldBalanceUpdate); ok {
+				hold = v.HoldBalance()
+			}
+			s.updateBalance(exname, ccy, amount, hold)
 			if err := s.alertOnLowBalance(ctx, exname, ccy, amount); err != nil {
 				slog.Warn("could not send low balance alert", "exchange", exname, "currency", ccy, "amount", amount)
 			}
@@ -6,9 +6,16 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"slices"
+	"strings"
 	"time"
+
+	"github.com/bvk/tradebot/job"
 )
 
+// jobTypes holds the job types that are managed by the job runner.
+var jobTypes = []string{"limiter", "looper", "waller", "watcher", "arbiter"}
+
 type Options struct {
 	// RunFixes when true, trader.Start method will call Fix method on all trade
 	// jobs (irrespective of their job status).
@@ -32,6 +39,16 @@ type Options struct {
 
 	// Max timeout for http requests.
 	MaxHttpClientTimeout time.Duration
+
+	// BudgetPolicy determines the action when a new job's budget over-commits
+	// the available balance. It must be one of "warn" (default), "reject" or
+	// "none".
+	BudgetPolicy string
+
+	// RestartPolicies holds the default restart policies for failed jobs keyed
+	// by the job type. Policy values must be in the format accepted by
+	// job.ParseRestartPolicy.
+	RestartPolicies map[string]string
 }
 
 func (v *Options) setDefaults() {
@@ -41,9 +58,23 @@ func (v *Options) setDefaults() {
 	if v.MaxHttpClientTimeout == 0 {
 		v.MaxHttpClientTimeout = 10 * time.Second
 	}
+	if v.BudgetPolicy == "" {
+		v.BudgetPolicy = "warn"
+	}
 }
 
 func (v *Options) Check() error {
+	if !slices.Contains([]string{"warn", "reject", "none"}, v.BudgetPolicy) {
+		return fmt.Errorf("budget policy %q must be one of warn, reject or none", v.BudgetPolicy)
+	}
+	for typename, policy := range v.RestartPolicies {
+		if !slices.Contains(jobTypes, strings.ToLower(typename)) {
+			return fmt.Errorf("restart policy job type %q must be one of %s", typename, strings.Join(jobTypes, ", "))
+		}
+		if _, err := job.ParseRestartPolicy(policy); err != nil {
+			return fmt.Errorf("invalid restart policy for job type %q: %w", typename, err)
+		}
+	}
 	if len(v.BinaryBackupPath) != 0 {
 		if !filepath.IsAbs(v.BinaryBackupPath) {
 			return fmt.Errorf("binary backup path must be an absolute path")
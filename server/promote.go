@@ -0,0 +1,122 @@
+// Copyright (c) 2025 BVK Chaitanya
+
+package server
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/bvk/tradebot/api"
+	"github.com/bvk/tradebot/waller"
+	"github.com/bvk/tradebot/watcher"
+	"github.com/bvkgo/kv"
+	"github.com/google/uuid"
+)
+
+// doWatchPromote creates a live waller job with the buy/sell pairs of a
+// watcher job and marks the watcher as superseded by the new waller.
+func (s *Server) doWatchPromote(ctx context.Context, req *api.WatchPromoteRequest) (_ *api.WatchPromoteResponse, status error) {
+	defer func() {
+		if status != nil {
+			slog.ErrorContext(ctx, "watch promote request has failed", "error", status)
+		}
+	}()
+
+	if err := req.Check(); err != nil {
+		return nil, fmt.Errorf("invalid watch promote request: %w", err)
+	}
+
+	jd, err := s.runner.Get(ctx, nil, req.UID)
+	if err != nil {
+		return nil, fmt.Errorf("could not get job %q data: %w", req.UID, err)
+	}
+	if !strings.EqualFold(jd.Typename, "Watcher") {
+		return nil, fmt.Errorf("job %q is not a watcher", req.UID)
+	}
+
+	// Running watcher must be paused so that it's in-memory state doesn't
+	// overwrite the superseded marker.
+	if jd.State.IsRunning() {
+		if err := s.runner.Pause(ctx, req.UID); err != nil {
+			return nil, fmt.Errorf("could not pause watcher %q: %w", req.UID, err)
+		}
+	}
+
+	var watch *watcher.Watcher
+	load := func(ctx context.Context, r kv.Reader) error {
+		v, err := watcher.Load(ctx, req.UID, r)
+		if err != nil {
+			return err
+		}
+		watch = v
+		return nil
+	}
+	if err := kv.WithReader(ctx, s.db, load); err != nil {
+		return nil, fmt.Errorf("could not load watcher %q: %w", req.UID, err)
+	}
+
+	if jd.State.IsRunning() {
+		defer func() {
+			if err := s.runner.Resume(ctx, req.UID, s.makeJobFunc(watch), s.cg.Context()); err != nil {
+				slog.Error("could not resume the promoted watcher job (ignored)", "watcher", watch, "err", err)
+			}
+		}()
+	}
+
+	if v := watch.SupersededBy(); v != "" && !req.Force {
+		return nil, fmt.Errorf("watcher is already superseded by job %q", v)
+	}
+
+	pairs := watch.Pairs(req.MinPrice, req.MaxPrice)
+	if len(pairs) == 0 {
+		return nil, fmt.Errorf("watcher has no buy/sell pairs in the selected price range")
+	}
+
+	if _, err := s.getProduct(ctx, watch.ExchangeName(), watch.ProductID()); err != nil {
+		return nil, err
+	}
+
+	uid := uuid.New().String()
+	wall, err := waller.New(uid, watch.ExchangeName(), watch.ProductID(), pairs)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.checkBudget(ctx, wall); err != nil {
+		return nil, err
+	}
+
+	prevUID, prevAt := watch.SupersededBy(), watch.SupersededAt()
+	watch.Supersede(uid, time.Now())
+
+	start := func(ctx context.Context, rw kv.ReadWriter) error {
+		if err := wall.Save(ctx, rw); err != nil {
+			return fmt.Errorf("could not save new waller: %v", err)
+		}
+		if err := s.runner.Add(ctx, rw, uid, "Waller"); err != nil {
+			return fmt.Errorf("could not add new waller as a job: %w", err)
+		}
+		if err := watch.Save(ctx, rw); err != nil {
+			return fmt.Errorf("could not mark the watcher as superseded: %w", err)
+		}
+		return nil
+	}
+	if err := kv.WithReadWriter(ctx, s.db, start); err != nil {
+		watch.Supersede(prevUID, prevAt)
+		return nil, err
+	}
+
+	if err := s.runner.Resume(ctx, uid, s.makeJobFunc(wall), s.cg.Context()); err != nil {
+		slog.Error("could not resume newly added waller job (ignored)", "err", err)
+	}
+
+	resp := &api.WatchPromoteResponse{
+		UID:            uid,
+		NumPairs:       len(pairs),
+		WatcherSummary: watch.GetSummary(nil),
+	}
+	return resp, nil
+}
@@ -0,0 +1,82 @@
+// Copyright (c) 2025 BVK Chaitanya
+
+package server
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"log/slog"
+	"time"
+
+	"github.com/bvk/tradebot/api"
+	"github.com/bvk/tradebot/gobs"
+	"github.com/bvk/tradebot/job"
+	"github.com/bvkgo/kv"
+	"github.com/google/uuid"
+)
+
+// restartJob is invoked by the job runner to restart a failed job as per
+// it's restart policy.
+func (s *Server) restartJob(uid string) {
+	ctx := s.cg.Context()
+
+	v, err := s.loadJob(ctx, uid)
+	if err != nil {
+		slog.Error("could not load failed job for restart (will be resumed on restart)", "job", uid, "err", err)
+		return
+	}
+
+	if err := s.runner.Resume(ctx, uid, s.makeJobFunc(v), ctx); err != nil {
+		slog.Error("could not restart failed job (will be resumed on restart)", "job", uid, "err", err)
+		return
+	}
+	log.Printf("restarted failed job with id %q", uid)
+}
+
+// escalateJob is invoked by the job runner when a failed job cannot be
+// restarted anymore.
+func (s *Server) escalateJob(uid string, attempts int, status error) {
+	ctx := s.cg.Context()
+	slog.Error("job has failed and restart attempts are exhausted", "job", uid, "attempts", attempts, "err", status)
+	s.SendMessage(ctx, time.Now(), "Job %s has failed after %d restart attempts and needs attention: %v", uid, attempts, status)
+}
+
+func (s *Server) doJobRestartPolicy(ctx context.Context, req *api.JobRestartPolicyRequest) (*api.JobRestartPolicyResponse, error) {
+	if err := req.Check(); err != nil {
+		return nil, fmt.Errorf("invalid restart policy request: %w", err)
+	}
+
+	if _, err := uuid.Parse(req.UID); err != nil {
+		return nil, fmt.Errorf("job uid must be an uuid: %w", err)
+	}
+
+	var policy *gobs.JobRestartPolicy
+	if !req.Remove {
+		p, err := job.ParseRestartPolicy(req.Policy)
+		if err != nil {
+			return nil, err
+		}
+		policy = p
+	}
+
+	resp := new(api.JobRestartPolicyResponse)
+	update := func(ctx context.Context, rw kv.ReadWriter) error {
+		if err := s.runner.SetRestartPolicy(ctx, rw, req.UID, policy); err != nil {
+			return err
+		}
+		reason := "default"
+		if policy != nil {
+			reason = job.RestartPolicyString(policy)
+		}
+		if err := s.runner.AddEvent(ctx, rw, req.UID, job.OptionEvent, "restart-policy="+reason); err != nil {
+			return fmt.Errorf("could not record restart policy change: %w", err)
+		}
+		resp.Policy = reason
+		return nil
+	}
+	if err := kv.WithReadWriter(ctx, s.db, update); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
@@ -0,0 +1,354 @@
+// Copyright (c) 2025 BVK Chaitanya
+
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/bvk/tradebot/gobs"
+	"github.com/bvk/tradebot/kvutil"
+	"github.com/bvk/tradebot/looper"
+	"github.com/bvk/tradebot/trader"
+	"github.com/bvk/tradebot/waller"
+	"github.com/bvkgo/kv"
+	"github.com/shopspring/decimal"
+)
+
+const RiskStateKey = "/server/risk-state"
+
+const riskCheckInterval = time.Minute
+
+var defaultRiskReleasePct = decimal.NewFromInt(80)
+
+// inventory holds the unsold inventory of jobs in an exchange or product
+// scope.
+type inventory struct {
+	unsoldSize  decimal.Decimal
+	unsoldValue decimal.Decimal
+	positions   int
+
+	// jobs holds the loopers and wallers that can be frozen.
+	jobs []trader.Trader
+}
+
+func (v *inventory) add(t trader.Trader) {
+	s := t.GetSummary(nil)
+	v.unsoldSize = v.unsoldSize.Add(s.UnsoldSize)
+	v.unsoldValue = v.unsoldValue.Add(s.UnsoldValue)
+
+	switch x := t.(type) {
+	case *looper.Looper:
+		if s.UnsoldSize.IsPositive() {
+			v.positions++
+		}
+		v.jobs = append(v.jobs, t)
+	case *waller.Waller:
+		v.positions += x.HeldPositions()
+		v.jobs = append(v.jobs, t)
+	}
+}
+
+// exceeds returns a description of the first limit exceeded by the inventory
+// after scaling the limits with the input percentage.
+func (v *inventory) exceeds(limits *gobs.RiskLimits, pct decimal.Decimal, perProduct bool) string {
+	scale := func(d decimal.Decimal) decimal.Decimal {
+		return d.Mul(pct).Div(decimal.NewFromInt(100))
+	}
+	if perProduct && limits.MaxUnsoldSize.IsPositive() {
+		if max := scale(limits.MaxUnsoldSize); v.unsoldSize.GreaterThan(max) {
+			return fmt.Sprintf("unsold size %s is above %s", v.unsoldSize.StringFixed(5), max.StringFixed(5))
+		}
+	}
+	if limits.MaxUnsoldValue.IsPositive() {
+		if max := scale(limits.MaxUnsoldValue); v.unsoldValue.GreaterThan(max) {
+			return fmt.Sprintf("unsold value %s is above %s", v.unsoldValue.StringFixed(3), max.StringFixed(3))
+		}
+	}
+	if limits.MaxHeldPositions > 0 {
+		if max := scale(decimal.NewFromInt(int64(limits.MaxHeldPositions))); decimal.NewFromInt(int64(v.positions)).GreaterThan(max) {
+			return fmt.Sprintf("held positions %d are above %s", v.positions, max.StringFixed(0))
+		}
+	}
+	return ""
+}
+
+// watchRiskLimits periodically evaluates inventory risk limits for all running
+// jobs.
+func (s *Server) watchRiskLimits(ctx context.Context) {
+	for ctx.Err() == nil {
+		if err := s.checkRiskLimits(ctx); err != nil {
+			slog.Error("could not check inventory risk limits (will retry)", "err", err)
+		}
+		select {
+		case <-ctx.Done():
+		case <-time.After(riskCheckInterval):
+		}
+	}
+}
+
+func (s *Server) checkRiskLimits(ctx context.Context) error {
+	state, err := kvutil.GetDB[gobs.ServerState](ctx, s.db, ServerStateKey)
+	if err != nil {
+		if !errors.Is(err, os.ErrNotExist) {
+			return err
+		}
+		state = new(gobs.ServerState)
+	}
+	rstate, err := kvutil.GetDB[gobs.RiskState](ctx, s.db, RiskStateKey)
+	if err != nil {
+		if !errors.Is(err, os.ErrNotExist) {
+			return err
+		}
+		rstate = new(gobs.RiskState)
+	}
+	if rstate.Frozen == nil {
+		rstate.Frozen = make(map[string][]string)
+	}
+	if rstate.Jobs == nil {
+		rstate.Jobs = make(map[string]*gobs.RiskFreeze)
+	}
+
+	config := state.RiskLimits
+	if config == nil {
+		config = new(gobs.RiskLimitsConfig)
+	}
+	if config.PerExchangeLimits == nil && config.PerProductLimits == nil && len(rstate.Frozen) == 0 {
+		return nil
+	}
+	releasePct := config.ReleasePct
+	if !releasePct.IsPositive() {
+		releasePct = defaultRiskReleasePct
+	}
+
+	inventories := make(map[string]*inventory)
+	addTo := func(key string, t trader.Trader) {
+		v, ok := inventories[key]
+		if !ok {
+			v = new(inventory)
+			inventories[key] = v
+		}
+		v.add(t)
+	}
+	s.jobMap.Range(func(uid string, t trader.Trader) bool {
+		ename := strings.ToLower(t.ExchangeName())
+		addTo(ename, t)
+		addTo(path.Join(ename, t.ProductID()), t)
+		return true
+	})
+
+	limitsFor := func(scope string) (*gobs.RiskLimits, bool) {
+		if strings.Contains(scope, "/") {
+			return config.PerProductLimits[scope], true
+		}
+		return config.PerExchangeLimits[scope], false
+	}
+
+	dirty := false
+
+	// Release the frozen scopes when inventory falls below the hysteresis
+	// threshold or when limits are removed.
+	for scope, frozen := range rstate.Frozen {
+		limits, perProduct := limitsFor(scope)
+		if limits != nil {
+			if v, ok := inventories[scope]; ok && v.exceeds(limits, releasePct, perProduct) != "" {
+				continue
+			}
+		}
+		for _, uid := range frozen {
+			// Jobs that are complete need no undo. Undo values are restored as is,
+			// because empty undo value is a valid no-op for SetOption.
+			var unfreeze func(string) error
+			if !s.isJobDone(ctx, uid) {
+				unfreeze = func(undo string) error {
+					return s.setJobOption(ctx, uid, "freeze", undo)
+				}
+			}
+			if err := releaseFreeze(rstate, scope, uid, unfreeze); err != nil {
+				slog.Error("could not release frozen buys for the job (will retry)", "scope", scope, "job", uid, "err", err)
+				continue
+			}
+			dirty = true
+		}
+		if len(rstate.Frozen[scope]) == 0 {
+			delete(rstate.Frozen, scope)
+			dirty = true
+			slog.Info("inventory is below the risk limits; buys are released", "scope", scope)
+			s.SendMessage(ctx, time.Now(), "Inventory for %s is back below %s%% of the risk limits; frozen buys are released.", scope, releasePct)
+		}
+	}
+
+	// Freeze the buys for scopes exceeding the limits.
+	for scope, v := range inventories {
+		limits, perProduct := limitsFor(scope)
+		if limits == nil {
+			continue
+		}
+		reason := v.exceeds(limits, decimal.NewFromInt(100), perProduct)
+		if reason == "" {
+			continue
+		}
+		var newly []string
+		for _, t := range v.jobs {
+			uid := t.UID()
+			if slices.Contains(rstate.Frozen[scope], uid) {
+				continue
+			}
+			freeze := func() (string, error) {
+				return s.setJobOptionUndo(ctx, uid, "freeze", "buys")
+			}
+			if err := holdFreeze(rstate, scope, uid, freeze); err != nil {
+				slog.Error("could not freeze buys for the job (will retry)", "scope", scope, "job", uid, "err", err)
+				continue
+			}
+			newly = append(newly, uid)
+			dirty = true
+		}
+		if len(newly) > 0 {
+			slog.Warn("inventory risk limit is exceeded; buys are frozen", "scope", scope, "reason", reason, "jobs", newly)
+			s.SendMessage(ctx, time.Now(), "Inventory risk limit for %s is exceeded (%s); buys are frozen for %d jobs.", scope, reason, len(newly))
+		}
+	}
+
+	if dirty {
+		if err := kvutil.SetDB(ctx, s.db, RiskStateKey, rstate); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// holdFreeze records a freeze on the job for the scope. Job's buys are frozen
+// with the freeze function only when no other scope is holding a freeze on
+// the job already.
+func holdFreeze(rstate *gobs.RiskState, scope, uid string, freeze func() (string, error)) error {
+	rec, ok := rstate.Jobs[uid]
+	if !ok {
+		undo, err := freeze()
+		if err != nil {
+			return err
+		}
+		rec = &gobs.RiskFreeze{Undo: undo}
+		rstate.Jobs[uid] = rec
+	}
+	rec.Scopes++
+	rstate.Frozen[scope] = append(rstate.Frozen[scope], uid)
+	return nil
+}
+
+// releaseFreeze removes the scope's freeze on the job. Job's freeze option is
+// restored with the unfreeze function only when the scope is the last one
+// holding a freeze on the job. Unfreeze function can be nil for the jobs that
+// need no undo.
+func releaseFreeze(rstate *gobs.RiskState, scope, uid string, unfreeze func(undo string) error) error {
+	if rec, ok := rstate.Jobs[uid]; ok {
+		if rec.Scopes <= 1 && unfreeze != nil {
+			if err := unfreeze(rec.Undo); err != nil {
+				return err
+			}
+		}
+		if rec.Scopes--; rec.Scopes <= 0 {
+			delete(rstate.Jobs, uid)
+		}
+	}
+	rstate.Frozen[scope] = slices.DeleteFunc(slices.Clone(rstate.Frozen[scope]), func(id string) bool { return id == uid })
+	return nil
+}
+
+// setJobOption updates an option on a job. Running jobs are paused and resumed
+// around the update.
+func (s *Server) setJobOption(ctx context.Context, uid, key, value string) error {
+	_, err := s.setJobOptionUndo(ctx, uid, key, value)
+	return err
+}
+
+// setJobOptionUndo is the same as setJobOption, but also returns the undo value
+// for the option.
+func (s *Server) setJobOptionUndo(ctx context.Context, uid, key, value string) (string, error) {
+	_, running := s.jobMap.Load(uid)
+	if running {
+		if err := s.runner.Pause(ctx, uid); err != nil {
+			return "", fmt.Errorf("could not pause job %q: %w", uid, err)
+		}
+	}
+
+	var undo string
+	var job trader.Trader
+	update := func(ctx context.Context, rw kv.ReadWriter) error {
+		jd, err := s.runner.Get(ctx, rw, uid)
+		if err != nil {
+			return err
+		}
+		if jd.State.IsDone() {
+			return fmt.Errorf("job %q is already completed (%q)", uid, jd.State)
+		}
+		v, err := Load(ctx, rw, uid, jd.Typename)
+		if err != nil {
+			return fmt.Errorf("could not load trader job %q: %w", uid, err)
+		}
+		u, err := v.SetOption(key, value)
+		if err != nil {
+			return fmt.Errorf("could not set job option: %w", err)
+		}
+		if err := v.Save(ctx, rw); err != nil {
+			return fmt.Errorf("could not save the job options: %w", err)
+		}
+		undo, job = u, v
+		return nil
+	}
+	updateErr := kv.WithReadWriter(ctx, s.db, update)
+
+	if running {
+		if job == nil {
+			// Resume the job with it's old options when update has failed.
+			var err error
+			if job, err = s.loadJob(ctx, uid); err != nil {
+				return "", errors.Join(updateErr, err)
+			}
+		}
+		if err := s.runner.Resume(ctx, uid, s.makeJobFunc(job), s.cg.Context()); err != nil {
+			return "", errors.Join(updateErr, fmt.Errorf("could not resume job %q: %w", uid, err))
+		}
+	}
+	if updateErr != nil {
+		return "", updateErr
+	}
+	return undo, nil
+}
+
+// isJobDone returns true if the job is complete or doesn't exist anymore.
+func (s *Server) isJobDone(ctx context.Context, uid string) bool {
+	jd, err := s.runner.Get(ctx, nil /* reader */, uid)
+	if err != nil {
+		return errors.Is(err, os.ErrNotExist)
+	}
+	return jd.State.IsDone()
+}
+
+// loadJob loads a trader job from the database.
+func (s *Server) loadJob(ctx context.Context, uid string) (trader.Trader, error) {
+	var job trader.Trader
+	load := func(ctx context.Context, r kv.Reader) error {
+		jd, err := s.runner.Get(ctx, r, uid)
+		if err != nil {
+			return err
+		}
+		v, err := Load(ctx, r, uid, jd.Typename)
+		if err != nil {
+			return fmt.Errorf("could not load trader job %q: %w", uid, err)
+		}
+		job = v
+		return nil
+	}
+	if err := kv.WithReader(ctx, s.db, load); err != nil {
+		return nil, err
+	}
+	return job, nil
+}
@@ -0,0 +1,83 @@
+// Copyright (c) 2025 BVK Chaitanya
+
+package server
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/bvk/tradebot/gobs"
+)
+
+func TestRiskFreezeOverlappingScopes(t *testing.T) {
+	rstate := &gobs.RiskState{
+		Frozen: make(map[string][]string),
+		Jobs:   make(map[string]*gobs.RiskFreeze),
+	}
+
+	// option is the freeze option value of the test job.
+	option := "sells"
+	freeze := func() (string, error) {
+		undo := "undo:" + option
+		option = "buys"
+		return undo, nil
+	}
+	unfreeze := func(undo string) error {
+		option = undo[len("undo:"):]
+		return nil
+	}
+
+	const uid = "job"
+	if err := holdFreeze(rstate, "coinbase", uid, freeze); err != nil {
+		t.Fatal(err)
+	}
+	// Second scope must share the freeze record without freezing again.
+	if err := holdFreeze(rstate, "coinbase/BTC-USD", uid, func() (string, error) {
+		t.Fatalf("job that is already frozen must not be frozen again")
+		return "", nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if rec := rstate.Jobs[uid]; option != "buys" || rec == nil || rec.Scopes != 2 || rec.Undo != "undo:sells" {
+		t.Fatalf("wanted job frozen by two scopes, got option %q and record %+v", option, rec)
+	}
+
+	// Releasing the product scope must keep the job frozen for the exchange
+	// scope.
+	if err := releaseFreeze(rstate, "coinbase/BTC-USD", uid, unfreeze); err != nil {
+		t.Fatal(err)
+	}
+	if rec := rstate.Jobs[uid]; option != "buys" || rec == nil || rec.Scopes != 1 {
+		t.Fatalf("wanted job frozen by the exchange scope, got option %q and record %+v", option, rec)
+	}
+	if n := len(rstate.Frozen["coinbase/BTC-USD"]); n != 0 {
+		t.Fatalf("wanted no jobs frozen for the product scope, got %d", n)
+	}
+
+	// Failed unfreeze must keep the freeze for a retry.
+	if err := releaseFreeze(rstate, "coinbase", uid, func(string) error { return errors.New("failed") }); err == nil {
+		t.Fatalf("wanted non-nil error")
+	}
+	if rec := rstate.Jobs[uid]; rec == nil || rec.Scopes != 1 || len(rstate.Frozen["coinbase"]) != 1 {
+		t.Fatalf("wanted the freeze to be retained, got record %+v", rec)
+	}
+
+	// Releasing the last scope must restore the original option.
+	if err := releaseFreeze(rstate, "coinbase", uid, unfreeze); err != nil {
+		t.Fatal(err)
+	}
+	if option != "sells" || len(rstate.Jobs) != 0 || len(rstate.Frozen["coinbase"]) != 0 {
+		t.Fatalf("wanted original option sells and no freeze records, got %q and %v", option, rstate.Jobs)
+	}
+
+	// Finished jobs are released without an undo.
+	if err := holdFreeze(rstate, "coinbase", uid, freeze); err != nil {
+		t.Fatal(err)
+	}
+	if err := releaseFreeze(rstate, "coinbase", uid, nil); err != nil {
+		t.Fatal(err)
+	}
+	if option != "buys" || len(rstate.Jobs) != 0 {
+		t.Fatalf("wanted finished job to be released as is, got %q and %v", option, rstate.Jobs)
+	}
+}
@@ -28,14 +28,17 @@ import (
 	"github.com/bvk/tradebot/kvutil"
 	"github.com/bvk/tradebot/limiter"
 	"github.com/bvk/tradebot/looper"
+	"github.com/bvk/tradebot/point"
 	"github.com/bvk/tradebot/pushover"
 	"github.com/bvk/tradebot/syncmap"
 	"github.com/bvk/tradebot/telegram"
 	"github.com/bvk/tradebot/trader"
+	"github.com/bvk/tradebot/trigger"
 	"github.com/bvk/tradebot/waller"
 	"github.com/bvk/tradebot/watcher"
 	"github.com/bvkgo/kv"
 	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
 	"github.com/visvasity/cli"
 )
 
@@ -105,6 +108,21 @@ type Server struct {
 
 	jobMap syncmap.Map[string, trader.Trader]
 
+	// balanceMap holds the available balance for an exchange/currency key as
+	// reported by the exchange balance updates.
+	balanceMap syncmap.Map[string, decimal.Decimal]
+
+	// totalBalanceMap holds the available balance plus the balance held for the
+	// open orders for an exchange/currency key.
+	totalBalanceMap syncmap.Map[string, decimal.Decimal]
+
+	// armedMap holds trigger watchers for the running jobs that are armed.
+	armedMap syncmap.Map[string, *trigger.Watcher]
+
+	// breakerMap holds the circuit breakers for the open products keyed by
+	// exchange/product names.
+	breakerMap syncmap.Map[string, *breaker]
+
 	mu sync.Mutex
 
 	state *gobs.ServerState
@@ -163,6 +181,15 @@ func New(newctx context.Context, secretsFilePath string, db kv.Database, opts *O
 		runner:                 job.NewRunner(db),
 		alertFreezeDeadlineMap: make(map[string]time.Time),
 	}
+	t.runner.SetFollowUpFuncs(t.createFollowUp, t.startFollowUp)
+	t.runner.SetRestartFuncs(t.restartJob, t.escalateJob)
+	for typename, policy := range opts.RestartPolicies {
+		p, err := job.ParseRestartPolicy(policy)
+		if err != nil {
+			return nil, fmt.Errorf("invalid restart policy for job type %q: %w", typename, err)
+		}
+		t.runner.SetDefaultRestartPolicy(typename, p)
+	}
 
 	t.handlerMap[api.JobListPath] = httpPostJSONHandler(t.doList)
 	t.handlerMap[api.JobCancelPath] = httpPostJSONHandler(t.doCancel)
@@ -170,16 +197,34 @@ func New(newctx context.Context, secretsFilePath string, db kv.Database, opts *O
 	t.handlerMap[api.JobPausePath] = httpPostJSONHandler(t.doPause)
 	t.handlerMap[api.JobSetOptionPath] = httpPostJSONHandler(t.doJobSetOption)
 	t.handlerMap[api.SetJobNamePath] = httpPostJSONHandler(t.doSetJobName)
+	t.handlerMap[api.JobFollowUpPath] = httpPostJSONHandler(t.doJobFollowUp)
+	t.handlerMap[api.JobHistoryPath] = httpPostJSONHandler(t.doJobHistory)
+	t.handlerMap[api.JobRestartPolicyPath] = httpPostJSONHandler(t.doJobRestartPolicy)
+	t.handlerMap[api.JobLabelPath] = httpPostJSONHandler(t.doJobLabel)
+	t.handlerMap[api.JobArchivePath] = httpPostJSONHandler(t.doJobArchive)
+	t.handlerMap[api.JobRestorePath] = httpPostJSONHandler(t.doJobRestore)
+	t.handlerMap[api.JobClonePath] = httpPostJSONHandler(t.doJobClone)
+	t.handlerMap[api.EventsPath] = t.eventsHandler()
 
 	t.handlerMap[api.LimitPath] = httpPostJSONHandler(t.doLimit)
 	t.handlerMap[api.LoopPath] = httpPostJSONHandler(t.doLoop)
 	t.handlerMap[api.WallPath] = httpPostJSONHandler(t.doWall)
 	t.handlerMap[api.WatchPath] = httpPostJSONHandler(t.doWatch)
+	t.handlerMap[api.WatchPromotePath] = httpPostJSONHandler(t.doWatchPromote)
+	t.handlerMap[api.ArbitragePath] = httpPostJSONHandler(t.doArbitrage)
+
+	t.handlerMap[api.BudgetPath] = httpPostJSONHandler(t.doBudget)
+	t.handlerMap[api.SummaryPath] = httpPostJSONHandler(t.doSummary)
+	t.handlerMap[api.StatusPath] = httpPostJSONHandler(t.doStatus)
 
 	t.handlerMap[api.ExchangeGetOrderPath] = httpPostJSONHandler(t.doExchangeGetOrder)
 	t.handlerMap[api.ExchangeGetProductPath] = httpPostJSONHandler(t.doGetProduct)
 	t.handlerMap[api.ExchangeUpdateProductPath] = httpPostJSONHandler(t.doExchangeUpdateProduct)
 
+	t.handlerMap[api.TokenCreatePath] = httpPostJSONHandler(t.doTokenCreate)
+	t.handlerMap[api.TokenListPath] = httpPostJSONHandler(t.doTokenList)
+	t.handlerMap[api.TokenRevokePath] = httpPostJSONHandler(t.doTokenRevoke)
+
 	return t, nil
 }
 
@@ -221,11 +266,22 @@ func (s *Server) HandlerMap() map[string]http.Handler {
 func (s *Server) Runtime(product exchange.Product) *trader.Runtime {
 	exchange := s.exchangeMap[product.ExchangeName()]
 	return &trader.Runtime{
-		Exchange:  exchange,
-		Database:  s.db,
-		Product:   product,
-		Messenger: s,
+		Exchange:   exchange,
+		Database:   s.db,
+		Product:    product,
+		Messenger:  s,
+		Breaker:    s.getBreaker(product),
+		RuntimeFor: s.runtimeFor,
+	}
+}
+
+// runtimeFor returns the runtime for a product, opening it if necessary.
+func (s *Server) runtimeFor(ctx context.Context, exchangeName, productID string) (*trader.Runtime, error) {
+	product, err := s.getProduct(ctx, exchangeName, productID)
+	if err != nil {
+		return nil, err
 	}
+	return s.Runtime(product), nil
 }
 
 func (s *Server) SendMessage(ctx context.Context, at time.Time, msgfmt string, args ...interface{}) {
@@ -338,6 +394,9 @@ func (s *Server) Start(ctx context.Context) (status error) {
 			})
 		}
 		s.exchangeMap = exchangeMap
+
+		// Configure background watcher for inventory risk limits.
+		s.cg.Go(s.watchRiskLimits)
 	}
 
 	if err := s.loadProducts(ctx); err != nil {
@@ -373,6 +432,9 @@ func (s *Server) Start(ctx context.Context) (status error) {
 		if err := s.AddTelegramCommand(ctx, "stats", "Prints system and service stats", s.statsCmd); err != nil {
 			slog.Error("could not add stats telegram command (ignored)", "err", err)
 		}
+		if err := s.AddTelegramCommand(ctx, "resume-trading", "Resets tripped circuit breakers", s.resumeTradingCmd); err != nil {
+			slog.Error("could not add resume-trading telegram command (ignored)", "err", err)
+		}
 	}
 
 	if s.opts.RunFixes {
@@ -398,6 +460,11 @@ func (s *Server) Start(ctx context.Context) (status error) {
 			return nil
 		}
 
+		if job.IsWaitingForRestart(jd) {
+			log.Printf("job %q has failed and is waiting to be restarted at %s", uid, jd.RestartAt)
+			return nil
+		}
+
 		trader, err := Load(ctx, r, uid, jd.Typename)
 		if err != nil {
 			return fmt.Errorf("could not load trader job %q: %w", uid, err)
@@ -416,6 +483,10 @@ func (s *Server) Start(ctx context.Context) (status error) {
 		log.Printf("resumed job with id %q", uid)
 	}
 
+	if err := s.runner.ScheduleRestarts(ctx); err != nil {
+		return err
+	}
+
 	// Send a profit status notification to telegram upon every successful
 	// restart.
 	if s.telegramClient != nil {
@@ -499,6 +570,10 @@ func (s *Server) getProductLocked(ctx context.Context, exchangeName, productID s
 	}
 
 	pmap[productID] = product
+
+	s.cg.Go(func(ctx context.Context) {
+		s.watchCircuitBreaker(ctx, product)
+	})
 	return product, nil
 }
 
@@ -560,7 +635,14 @@ func httpPostJSONHandler[T1 any, T2 any](fun func(context.Context, *T1) (*T2, er
 	})
 }
 
-func (s *Server) doLimit(ctx context.Context, req *api.LimitRequest) (_ *api.LimitResponse, status error) {
+func (s *Server) doLimit(ctx context.Context, req *api.LimitRequest) (*api.LimitResponse, error) {
+	return s.addLimit(ctx, req, nil)
+}
+
+// addLimit creates a new limiter job for the request. Optional setup function
+// is invoked in the same transaction that creates the job, so that job is not
+// created when the setup fails.
+func (s *Server) addLimit(ctx context.Context, req *api.LimitRequest, setup func(context.Context, kv.ReadWriter, string) error) (_ *api.LimitResponse, status error) {
 	defer func() {
 		if status != nil {
 			slog.ErrorContext(ctx, "limit request has failed", "error", status)
@@ -580,6 +662,28 @@ func (s *Server) doLimit(ctx context.Context, req *api.LimitRequest) (_ *api.Lim
 	if err != nil {
 		return nil, err
 	}
+	for key, value := range req.Options {
+		if _, err := limit.SetOption(key, value); err != nil {
+			return nil, err
+		}
+	}
+	if req.Expiry != nil {
+		if err := limit.SetExpiry(req.Expiry); err != nil {
+			return nil, err
+		}
+	}
+
+	if req.DryRun {
+		report, err := s.dryRun(ctx, req.ExchangeName, req.ProductID, req.Point, nil)
+		if err != nil {
+			return nil, err
+		}
+		return &api.LimitResponse{DryRun: report}, nil
+	}
+
+	if err := s.checkBudget(ctx, limit); err != nil {
+		return nil, err
+	}
 
 	start := func(ctx context.Context, rw kv.ReadWriter) error {
 		if err := limit.Save(ctx, rw); err != nil {
@@ -588,14 +692,37 @@ func (s *Server) doLimit(ctx context.Context, req *api.LimitRequest) (_ *api.Lim
 		if err := s.runner.Add(ctx, rw, uid, "Limiter"); err != nil {
 			return fmt.Errorf("could not add new limiter as a job: %w", err)
 		}
+		if len(req.Labels) != 0 {
+			if err := s.runner.UpdateLabels(ctx, rw, uid, req.Labels, nil /* remove */); err != nil {
+				return fmt.Errorf("could not set labels for the new limiter: %w", err)
+			}
+		}
+		if req.Trigger != nil {
+			if err := s.runner.SetTrigger(ctx, rw, uid, req.Trigger.ToGob()); err != nil {
+				return fmt.Errorf("could not set trigger for the new limiter: %w", err)
+			}
+		}
+		if req.Pause {
+			if err := s.runner.UpdateFlags(ctx, rw, uid, ManualFlag); err != nil {
+				slog.Error("could not mark job as paused manually", "err", err)
+				return err
+			}
+		}
+		if setup != nil {
+			if err := setup(ctx, rw, uid); err != nil {
+				return err
+			}
+		}
 		return nil
 	}
 	if err := kv.WithReadWriter(ctx, s.db, start); err != nil {
 		return nil, err
 	}
 
-	if err := s.runner.Resume(ctx, uid, s.makeJobFunc(limit), s.cg.Context()); err != nil {
-		slog.Error("could not resume newly added limiter job (ignored)", "err", err)
+	if !req.Pause {
+		if err := s.runner.Resume(ctx, uid, s.makeJobFunc(limit), s.cg.Context()); err != nil {
+			slog.Error("could not resume newly added limiter job (ignored)", "err", err)
+		}
 	}
 
 	resp := &api.LimitResponse{
@@ -604,7 +731,14 @@ func (s *Server) doLimit(ctx context.Context, req *api.LimitRequest) (_ *api.Lim
 	return resp, nil
 }
 
-func (s *Server) doLoop(ctx context.Context, req *api.LoopRequest) (_ *api.LoopResponse, status error) {
+func (s *Server) doLoop(ctx context.Context, req *api.LoopRequest) (*api.LoopResponse, error) {
+	return s.addLoop(ctx, req, nil)
+}
+
+// addLoop creates a new looper job for the request. Optional setup function
+// is invoked in the same transaction that creates the job, so that job is not
+// created when the setup fails.
+func (s *Server) addLoop(ctx context.Context, req *api.LoopRequest, setup func(context.Context, kv.ReadWriter, string) error) (_ *api.LoopResponse, status error) {
 	defer func() {
 		if status != nil {
 			slog.ErrorContext(ctx, "loop has failed", "error", status)
@@ -624,6 +758,24 @@ func (s *Server) doLoop(ctx context.Context, req *api.LoopRequest) (_ *api.LoopR
 	if err != nil {
 		return nil, err
 	}
+	for key, value := range req.Options {
+		if _, err := loop.SetOption(key, value); err != nil {
+			return nil, err
+		}
+	}
+
+	if req.DryRun {
+		pairs := []*point.Pair{{Buy: *req.Buy, Sell: *req.Sell}}
+		report, err := s.dryRun(ctx, req.ExchangeName, req.ProductID, nil, pairs)
+		if err != nil {
+			return nil, err
+		}
+		return &api.LoopResponse{DryRun: report}, nil
+	}
+
+	if err := s.checkBudget(ctx, loop); err != nil {
+		return nil, err
+	}
 
 	start := func(ctx context.Context, rw kv.ReadWriter) error {
 		if err := loop.Save(ctx, rw); err != nil {
@@ -632,12 +784,27 @@ func (s *Server) doLoop(ctx context.Context, req *api.LoopRequest) (_ *api.LoopR
 		if err := s.runner.Add(ctx, rw, uid, "Looper"); err != nil {
 			return fmt.Errorf("could not add new looper as a job: %w", err)
 		}
+		if len(req.Labels) != 0 {
+			if err := s.runner.UpdateLabels(ctx, rw, uid, req.Labels, nil /* remove */); err != nil {
+				return fmt.Errorf("could not set labels for the new looper: %w", err)
+			}
+		}
+		if req.Trigger != nil {
+			if err := s.runner.SetTrigger(ctx, rw, uid, req.Trigger.ToGob()); err != nil {
+				return fmt.Errorf("could not set trigger for the new looper: %w", err)
+			}
+		}
 		if req.Pause {
 			if err := s.runner.UpdateFlags(ctx, rw, uid, ManualFlag); err != nil {
 				slog.Error("could not mark job as paused manually", "err", err)
 				return err
 			}
 		}
+		if setup != nil {
+			if err := setup(ctx, rw, uid); err != nil {
+				return err
+			}
+		}
 		return nil
 	}
 	if err := kv.WithReadWriter(ctx, s.db, start); err != nil {
@@ -656,7 +823,14 @@ func (s *Server) doLoop(ctx context.Context, req *api.LoopRequest) (_ *api.LoopR
 	return resp, nil
 }
 
-func (s *Server) doWall(ctx context.Context, req *api.WallRequest) (_ *api.WallResponse, status error) {
+func (s *Server) doWall(ctx context.Context, req *api.WallRequest) (*api.WallResponse, error) {
+	return s.addWall(ctx, req, nil)
+}
+
+// addWall creates a new waller job for the request. Optional setup function
+// is invoked in the same transaction that creates the job, so that job is not
+// created when the setup fails.
+func (s *Server) addWall(ctx context.Context, req *api.WallRequest, setup func(context.Context, kv.ReadWriter, string) error) (_ *api.WallResponse, status error) {
 	defer func() {
 		if status != nil {
 			slog.ErrorContext(ctx, "wall has failed", "error", status)
@@ -676,6 +850,23 @@ func (s *Server) doWall(ctx context.Context, req *api.WallRequest) (_ *api.WallR
 	if err != nil {
 		return nil, err
 	}
+	for key, value := range req.Options {
+		if _, err := wall.SetOption(key, value); err != nil {
+			return nil, err
+		}
+	}
+
+	if req.DryRun {
+		report, err := s.dryRun(ctx, req.ExchangeName, req.ProductID, nil, req.Pairs)
+		if err != nil {
+			return nil, err
+		}
+		return &api.WallResponse{DryRun: report}, nil
+	}
+
+	if err := s.checkBudget(ctx, wall); err != nil {
+		return nil, err
+	}
 
 	start := func(ctx context.Context, rw kv.ReadWriter) error {
 		if err := wall.Save(ctx, rw); err != nil {
@@ -684,14 +875,37 @@ func (s *Server) doWall(ctx context.Context, req *api.WallRequest) (_ *api.WallR
 		if err := s.runner.Add(ctx, rw, uid, "Waller"); err != nil {
 			return fmt.Errorf("could not add new waller as a job: %w", err)
 		}
+		if len(req.Labels) != 0 {
+			if err := s.runner.UpdateLabels(ctx, rw, uid, req.Labels, nil /* remove */); err != nil {
+				return fmt.Errorf("could not set labels for the new waller: %w", err)
+			}
+		}
+		if req.Trigger != nil {
+			if err := s.runner.SetTrigger(ctx, rw, uid, req.Trigger.ToGob()); err != nil {
+				return fmt.Errorf("could not set trigger for the new waller: %w", err)
+			}
+		}
+		if req.Pause {
+			if err := s.runner.UpdateFlags(ctx, rw, uid, ManualFlag); err != nil {
+				slog.Error("could not mark job as paused manually", "err", err)
+				return err
+			}
+		}
+		if setup != nil {
+			if err := setup(ctx, rw, uid); err != nil {
+				return err
+			}
+		}
 		return nil
 	}
 	if err := kv.WithReadWriter(ctx, s.db, start); err != nil {
 		return nil, err
 	}
 
-	if err := s.runner.Resume(ctx, uid, s.makeJobFunc(wall), s.cg.Context()); err != nil {
-		slog.Error("could not resume newly added waller job (ignored)", "err", err)
+	if !req.Pause {
+		if err := s.runner.Resume(ctx, uid, s.makeJobFunc(wall), s.cg.Context()); err != nil {
+			slog.Error("could not resume newly added waller job (ignored)", "err", err)
+		}
 	}
 
 	resp := &api.WallResponse{
@@ -720,6 +934,9 @@ func (s *Server) doWatch(ctx context.Context, req *api.WatchRequest) (_ *api.Wat
 	if err != nil {
 		return nil, err
 	}
+	if err := watch.SetFillModel(req.FillModel); err != nil {
+		return nil, err
+	}
 
 	start := func(ctx context.Context, rw kv.ReadWriter) error {
 		if err := watch.Save(ctx, rw); err != nil {
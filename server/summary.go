@@ -0,0 +1,440 @@
+// Copyright (c) 2025 BVK Chaitanya
+
+package server
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/bvk/tradebot/api"
+	"github.com/bvk/tradebot/coinbase"
+	"github.com/bvk/tradebot/coinex"
+	"github.com/bvk/tradebot/gobs"
+	"github.com/bvk/tradebot/job"
+	"github.com/bvk/tradebot/limiter"
+	"github.com/bvk/tradebot/looper"
+	"github.com/bvk/tradebot/namer"
+	"github.com/bvk/tradebot/timerange"
+	"github.com/bvk/tradebot/trader"
+	"github.com/bvk/tradebot/waller"
+	"github.com/bvk/tradebot/watcher"
+	"github.com/bvkgo/kv"
+	"github.com/bvkgo/kv/kvmemdb"
+	"github.com/shopspring/decimal"
+)
+
+// LiveFunc returns the in-memory job object for a running job.
+type LiveFunc func(uid string) (trader.Trader, bool)
+
+// jobSummary returns the summary for the looper, waller and watcher jobs from
+// the database. It returns nil for other job types.
+func jobSummary(ctx context.Context, r kv.Reader, jd *gobs.JobData, period *timerange.Range, recal bool) (*gobs.Summary, error) {
+	switch {
+	case strings.EqualFold(jd.Typename, "watcher"):
+		return watcher.Summary(ctx, r, jd.ID, period, recal)
+	case strings.EqualFold(jd.Typename, "waller"):
+		return waller.Summary(ctx, r, jd.ID, period, recal)
+	case strings.EqualFold(jd.Typename, "looper"):
+		return looper.Summary(ctx, r, jd.ID, period, recal)
+	default:
+		return nil, nil
+	}
+}
+
+// archivedSummary returns the summary of an archived job. Summary computed at
+// the time of archival is used unless a time period or recalculation is
+// requested, in which case archived data is loaded into a temporary memdb.
+func archivedSummary(ctx context.Context, a *gobs.JobArchive, jd *gobs.JobData, period *timerange.Range, recal bool) (*gobs.Summary, error) {
+	if period == nil && !recal && a.Summary != nil {
+		return a.Summary, nil
+	}
+	export, err := job.DecodeArchive(a)
+	if err != nil {
+		return nil, err
+	}
+	memdb := kvmemdb.New()
+	load := func(ctx context.Context, rw kv.ReadWriter) error {
+		for _, kv := range export.KeyValues {
+			if err := rw.Set(ctx, kv.Key, bytes.NewReader(kv.Value)); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	if err := kv.WithReadWriter(ctx, memdb, load); err != nil {
+		return nil, fmt.Errorf("could not load archived job %q into memdb: %w", a.UID, err)
+	}
+	var sum *gobs.Summary
+	if err := kv.WithReader(ctx, memdb, func(ctx context.Context, r kv.Reader) (err error) {
+		sum, err = jobSummary(ctx, r, jd, period, recal)
+		return err
+	}); err != nil {
+		return nil, err
+	}
+	return sum, nil
+}
+
+// JobSummaries computes the per-job and aggregate summaries of all or selected
+// jobs including the archived jobs. Summaries of the running jobs are taken
+// from the in-memory job objects when live is non-nil and recalculation is
+// not requested. Prices and accounts in the response are not filled.
+func JobSummaries(ctx context.Context, db kv.Database, req *api.SummaryRequest, live LiveFunc) (*api.SummaryResponse, error) {
+	if err := req.Check(); err != nil {
+		return nil, fmt.Errorf("invalid summary request: %w", err)
+	}
+
+	now := time.Now()
+	var period *timerange.Range
+	if !req.BeginTime.IsZero() || !req.EndTime.IsZero() {
+		period = &timerange.Range{Begin: req.BeginTime, End: req.EndTime}
+		if period.End.IsZero() {
+			period.End = now
+		}
+	}
+
+	selected := func(name, uid string) bool {
+		return len(req.Jobs) == 0 || slices.Contains(req.Jobs, name) || slices.Contains(req.Jobs, uid)
+	}
+
+	resp := new(api.SummaryResponse)
+	addJob := func(jd *gobs.JobData, name string, archived bool, sum *gobs.Summary) {
+		// Copy the summary cause cached summaries may be shared with the jobs.
+		v := *sum
+		// Adjust to reflect the user chosen time periods.
+		if period == nil {
+			v.EndAt = now
+			if v.BeginAt.IsZero() {
+				v.BeginAt = v.EndAt
+			}
+		} else {
+			if !period.Begin.IsZero() {
+				v.BeginAt = period.Begin
+			}
+			v.EndAt = period.End
+		}
+		js := &api.JobSummary{
+			UID:      jd.ID,
+			Name:     name,
+			Type:     jd.Typename,
+			State:    jd.State,
+			Archived: archived,
+			Summary:  &v,
+		}
+		resp.Jobs = append(resp.Jobs, js)
+	}
+
+	collect := func(ctx context.Context, r kv.Reader) error {
+		collectJobs := func(ctx context.Context, r kv.Reader, jd *gobs.JobData) error {
+			name, _, _, err := namer.Resolve(ctx, r, jd.ID)
+			if err != nil {
+				if !errors.Is(err, os.ErrNotExist) {
+					return err
+				}
+				name = jd.ID
+			}
+			if !selected(name, jd.ID) {
+				return nil
+			}
+
+			var sum *gobs.Summary
+			if live != nil && !req.Recalculate {
+				if t, ok := live(jd.ID); ok {
+					sum = t.GetSummary(period)
+				}
+			}
+			if sum == nil {
+				v, err := jobSummary(ctx, r, jd, period, req.Recalculate)
+				if err != nil {
+					return err
+				}
+				sum = v
+			}
+			if sum != nil {
+				addJob(jd, name, false, sum)
+			}
+			return nil
+		}
+		if err := job.NewRunner(db).Scan(ctx, r, collectJobs); err != nil {
+			return err
+		}
+
+		collectArchived := func(ctx context.Context, r kv.Reader, a *gobs.JobArchive) error {
+			name := a.Name
+			if len(name) == 0 {
+				name = a.UID
+			}
+			if !selected(name, a.UID) {
+				return nil
+			}
+			jd := &gobs.JobData{ID: a.UID, Typename: a.Typename, State: a.State, Labels: a.Labels}
+			sum, err := archivedSummary(ctx, a, jd, period, req.Recalculate)
+			if err != nil {
+				return err
+			}
+			if sum != nil {
+				addJob(jd, name, true, sum)
+			}
+			return nil
+		}
+		return job.NewRunner(db).ScanArchive(ctx, r, collectArchived)
+	}
+	if err := kv.WithReader(ctx, db, collect); err != nil {
+		return nil, err
+	}
+
+	resp.Total, resp.Running = new(gobs.Summary), new(gobs.Summary)
+	for _, js := range resp.Jobs {
+		if slices.Contains(req.IgnoreJobTypes, strings.ToLower(js.Type)) {
+			continue
+		}
+		resp.Total.Add(js.Summary)
+		if js.State.IsRunning() {
+			resp.Running.Add(js.Summary)
+		}
+	}
+	resp.Total.EndAt = now
+	resp.Running.EndAt = now
+	return resp, nil
+}
+
+// FixSummaries saves the non-running, unarchived jobs in the input summaries
+// so that their cached summaries are updated in the database.
+func FixSummaries(ctx context.Context, db kv.Database, resp *api.SummaryResponse) error {
+	for _, js := range resp.Jobs {
+		if js.State.IsRunning() {
+			slog.Warn("job is currently running, so summary is not updated (skipped)", "uid", js.UID)
+			continue
+		}
+		if js.Archived {
+			continue
+		}
+		fix := func(ctx context.Context, rw kv.ReadWriter) error {
+			job, err := Load(ctx, rw, js.UID, js.Type)
+			if err != nil {
+				return fmt.Errorf("could not load traders: %w", err)
+			}
+			if err := job.Save(ctx, rw); err != nil {
+				slog.Error("could not save job", "job", job, "err", err)
+				return err
+			}
+			return nil
+		}
+		if err := kv.WithReadWriter(ctx, db, fix); err != nil {
+			return fmt.Errorf("could not apply summary fix: %w", err)
+		}
+	}
+	return nil
+}
+
+// liveOrLoad returns the in-memory job object for a running job when live is
+// non-nil and loads the job from the database otherwise.
+func liveOrLoad(ctx context.Context, r kv.Reader, uid, typename string, live LiveFunc) (trader.Trader, error) {
+	if live != nil {
+		if t, ok := live(uid); ok {
+			return t, nil
+		}
+	}
+	return Load(ctx, r, uid, typename)
+}
+
+// JobStatuses computes the trade statuses of all or selected jobs that
+// support status reports. Statuses of the running jobs are taken from the
+// in-memory job objects when live is non-nil, so that only the jobs that are
+// not running are loaded from the database. Prices and accounts in the
+// response are not filled.
+func JobStatuses(ctx context.Context, db kv.Database, req *api.StatusRequest, live LiveFunc) (*api.StatusResponse, error) {
+	if err := req.Check(); err != nil {
+		return nil, fmt.Errorf("invalid status request: %w", err)
+	}
+
+	var jobs []trader.Trader
+	var uids []string
+	names := make(map[string]string)
+	states := make(map[string]gobs.State)
+	load := func(ctx context.Context, r kv.Reader) error {
+		if len(req.Jobs) > 0 {
+			for _, arg := range req.Jobs {
+				_, uid, typename, err := namer.Resolve(ctx, r, arg)
+				if err != nil {
+					if !errors.Is(err, os.ErrNotExist) {
+						return fmt.Errorf("could not resolve job type %q: %w", arg, err)
+					}
+					uid = arg
+				}
+				job, err := liveOrLoad(ctx, r, uid, typename, live)
+				if err != nil {
+					return fmt.Errorf("could not load job with uid %q: %w", uid, err)
+				}
+				jobs = append(jobs, job)
+			}
+		} else {
+			// Only the loopers and wallers report their status.
+			collect := func(ctx context.Context, r kv.Reader, jd *gobs.JobData) error {
+				if !strings.EqualFold(jd.Typename, "looper") && !strings.EqualFold(jd.Typename, "waller") {
+					return nil
+				}
+				job, err := liveOrLoad(ctx, r, jd.ID, jd.Typename, live)
+				if err != nil {
+					return fmt.Errorf("could not load job with uid %q: %w", jd.ID, err)
+				}
+				jobs = append(jobs, job)
+				return nil
+			}
+			if err := job.NewRunner(db).Scan(ctx, r, collect); err != nil {
+				return err
+			}
+		}
+
+		for _, j := range jobs {
+			uid := j.UID()
+			uid = strings.TrimPrefix(uid, limiter.DefaultKeyspace)
+			uid = strings.TrimPrefix(uid, looper.DefaultKeyspace)
+			uid = strings.TrimPrefix(uid, waller.DefaultKeyspace)
+			uids = append(uids, uid)
+			name := uid
+			if v, _, _, err := namer.Resolve(ctx, r, uid); err == nil {
+				name = v
+			}
+			names[uid] = name
+			if v, err := job.Status(ctx, r, uid); err == nil {
+				states[uid] = v
+			}
+		}
+		return nil
+	}
+	if err := kv.WithReader(ctx, db, load); err != nil {
+		return nil, err
+	}
+
+	period := &timerange.Range{Begin: req.BeginTime, End: req.EndTime}
+	resp := new(api.StatusResponse)
+	for i, j := range jobs {
+		v, ok := j.(trader.Statuser)
+		if !ok {
+			continue
+		}
+		s := v.Status(period)
+		if s == nil {
+			continue
+		}
+		uid := uids[i]
+		state, ok := states[uid]
+		if !ok {
+			state = "UNKNOWN"
+		}
+		js := &api.JobStatus{
+			UID:    uid,
+			Name:   names[uid],
+			State:  state,
+			Status: s,
+		}
+		resp.Jobs = append(resp.Jobs, js)
+	}
+	return resp, nil
+}
+
+// ExchangePrices returns the current product prices from the public price
+// endpoints of the input exchanges. Failures are logged and ignored.
+func ExchangePrices(ctx context.Context, exchanges []string) map[string]map[string]decimal.Decimal {
+	prices := make(map[string]map[string]decimal.Decimal)
+	if slices.Contains(exchanges, "coinbase") {
+		pmap, err := coinbase.GetProductPriceMap(ctx)
+		if err != nil {
+			slog.Warn("could not get product pricing from coinbase (ignored)", "err", err)
+		}
+		prices["coinbase"] = pmap
+	}
+	if slices.Contains(exchanges, "coinex") {
+		pmap, err := coinex.GetProductPriceMap(ctx)
+		if err != nil {
+			slog.Warn("could not get product pricing from coinex (ignored)", "err", err)
+		}
+		prices["coinex"] = pmap
+	}
+	return prices
+}
+
+// ProductPrices returns the current product prices from the input exchange.
+// Coinbase prices are taken from the datastore. Failures are logged and an
+// empty map is returned.
+func ProductPrices(ctx context.Context, db kv.Database, exchangeName string) map[string]decimal.Decimal {
+	switch strings.ToLower(exchangeName) {
+	case "coinex":
+		pmap, err := coinex.GetProductPriceMap(ctx)
+		if err != nil {
+			slog.Warn("could not load price information (ignored)", "err", err)
+			break
+		}
+		return pmap
+
+	case "coinbase":
+		pmap, err := coinbase.NewDatastore(db).ProductsPriceMap(ctx)
+		if err != nil {
+			if !errors.Is(err, os.ErrNotExist) {
+				slog.Warn("could not load price information (ignored)", "err", err)
+			}
+			break
+		}
+		return pmap
+	}
+	return make(map[string]decimal.Decimal)
+}
+
+// Accounts returns the last known coinbase account balances.
+func Accounts(ctx context.Context, db kv.Database) ([]*gobs.Account, error) {
+	accounts, err := coinbase.NewDatastore(db).LoadAccounts(ctx)
+	if err != nil {
+		if !errors.Is(err, os.ErrNotExist) {
+			return nil, fmt.Errorf("could not load coinbase account balances: %w", err)
+		}
+	}
+	return accounts, nil
+}
+
+func (s *Server) liveJob(uid string) (trader.Trader, bool) {
+	return s.jobMap.Load(uid)
+}
+
+func (s *Server) doSummary(ctx context.Context, req *api.SummaryRequest) (*api.SummaryResponse, error) {
+	resp, err := JobSummaries(ctx, s.db, req, s.liveJob)
+	if err != nil {
+		return nil, err
+	}
+
+	var exchanges []string
+	for _, js := range resp.Jobs {
+		if !slices.Contains(exchanges, js.Summary.Exchange) {
+			exchanges = append(exchanges, js.Summary.Exchange)
+		}
+	}
+	resp.Prices = ExchangePrices(ctx, exchanges)
+
+	accounts, err := Accounts(ctx, s.db)
+	if err != nil {
+		return nil, err
+	}
+	resp.Accounts = accounts
+	return resp, nil
+}
+
+func (s *Server) doStatus(ctx context.Context, req *api.StatusRequest) (*api.StatusResponse, error) {
+	resp, err := JobStatuses(ctx, s.db, req, s.liveJob)
+	if err != nil {
+		return nil, err
+	}
+	resp.Prices = ProductPrices(ctx, s.db, req.PricesFrom)
+
+	accounts, err := Accounts(ctx, s.db)
+	if err != nil {
+		return nil, err
+	}
+	resp.Accounts = accounts
+	return resp, nil
+}
@@ -0,0 +1,94 @@
+// Copyright (c) 2025 BVK Chaitanya
+
+package server
+
+import (
+	"context"
+	"testing"
+
+	"github.com/bvk/tradebot/api"
+	"github.com/bvk/tradebot/job"
+	"github.com/bvk/tradebot/limiter"
+	"github.com/bvk/tradebot/looper"
+	"github.com/bvk/tradebot/point"
+	"github.com/bvk/tradebot/trader"
+	"github.com/bvkgo/kv"
+	"github.com/bvkgo/kv/kvmemdb"
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+)
+
+func TestJobStatusesLive(t *testing.T) {
+	ctx := context.Background()
+	db := kvmemdb.New()
+	runner := job.NewRunner(db)
+
+	d := decimal.NewFromInt
+	buy := &point.Point{Size: d(1), Price: d(100), Cancel: d(105)}
+	sell := &point.Point{Size: d(1), Price: d(110), Cancel: d(105)}
+
+	running, stopped := uuid.New().String(), uuid.New().String()
+	var traders []trader.Trader
+	for _, uid := range []string{running, stopped} {
+		v, err := looper.New(uid, "test", "BTC-USD", buy, sell)
+		if err != nil {
+			t.Fatal(err)
+		}
+		traders = append(traders, v)
+	}
+	limit, err := limiter.New(uuid.New().String(), "test", "BTC-USD", buy)
+	if err != nil {
+		t.Fatal(err)
+	}
+	traders = append(traders, limit)
+	for _, v := range traders {
+		add := func(ctx context.Context, rw kv.ReadWriter) error {
+			if err := v.Save(ctx, rw); err != nil {
+				return err
+			}
+			typename := "Looper"
+			if _, ok := v.(*limiter.Limiter); ok {
+				typename = "Limiter"
+			}
+			return runner.Add(ctx, rw, v.UID(), typename)
+		}
+		if err := kv.WithReadWriter(ctx, db, add); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Live object of the running job is in a different product than it's
+	// saved copy, so that statuses show where the job was taken from.
+	liveLoop, err := looper.New(running, "test", "ETH-USD", buy, sell)
+	if err != nil {
+		t.Fatal(err)
+	}
+	live := func(uid string) (trader.Trader, bool) {
+		if uid == running {
+			return liveLoop, true
+		}
+		return nil, false
+	}
+
+	resp, err := JobStatuses(ctx, db, &api.StatusRequest{}, live)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]string{running: "ETH-USD", stopped: "BTC-USD"}
+	if len(resp.Jobs) != len(want) {
+		t.Fatalf("wanted %d looper statuses, got %d", len(want), len(resp.Jobs))
+	}
+	for _, js := range resp.Jobs {
+		if p := js.Status.ProductID; p != want[js.UID] {
+			t.Fatalf("wanted product %s for job %s, got %s", want[js.UID], js.UID, p)
+		}
+	}
+
+	resp, err = JobStatuses(ctx, db, &api.StatusRequest{Jobs: []string{running}}, live)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(resp.Jobs) != 1 || resp.Jobs[0].Status.ProductID != "ETH-USD" {
+		t.Fatalf("wanted live status of the selected job, got %+v", resp.Jobs)
+	}
+}
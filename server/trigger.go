@@ -0,0 +1,73 @@
+// Copyright (c) 2025 BVK Chaitanya
+
+package server
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/bvk/tradebot/exchange"
+	"github.com/bvk/tradebot/gobs"
+	"github.com/bvk/tradebot/trigger"
+	"github.com/visvasity/topic"
+)
+
+// waitForTrigger blocks till the trigger of an armed job fires or the context
+// is canceled. Fired triggers are recorded in the database, so that jobs are
+// not armed again after a restart.
+func (s *Server) waitForTrigger(ctx context.Context, uid string, product exchange.Product, jt *gobs.JobTrigger) error {
+	t := trigger.FromGob(jt)
+	w := trigger.NewWatcher(t)
+
+	s.armedMap.Store(uid, w)
+	defer s.armedMap.Delete(uid)
+
+	priceUpdates, err := product.GetPriceUpdates()
+	if err != nil {
+		return err
+	}
+	defer priceUpdates.Close()
+
+	tickerCh, err := topic.ReceiveCh(priceUpdates)
+	if err != nil {
+		return err
+	}
+
+	slog.Info("job is armed and waiting for the trigger", "job", uid, "trigger", t, "product", product.ProductID())
+	for {
+		select {
+		case <-ctx.Done():
+			return context.Cause(ctx)
+		case ticker := <-tickerCh:
+			price, _ := ticker.PricePoint()
+			if !w.Update(price, time.Now()) {
+				continue
+			}
+		}
+		break
+	}
+
+	if err := s.runner.FireTrigger(ctx, uid, time.Now()); err != nil {
+		return err
+	}
+	slog.Info("job trigger has fired", "job", uid, "trigger", t, "product", product.ProductID())
+	s.SendMessage(ctx, time.Now(), "Trigger %s has fired for job %s in product %s (%s).", t, uid, product.ProductID(), product.ExchangeName())
+	return nil
+}
+
+// triggerStatus returns the trigger condition and it's current distance from
+// firing for a job.
+func (s *Server) triggerStatus(jd *gobs.JobData) (condition, distance string) {
+	if jd.Trigger == nil {
+		return "", ""
+	}
+	condition = trigger.FromGob(jd.Trigger).String()
+	if !jd.IsArmed() {
+		return condition, "fired"
+	}
+	if w, ok := s.armedMap.Load(jd.ID); ok {
+		return condition, w.Distance()
+	}
+	return condition, ""
+}
@@ -0,0 +1,359 @@
+// Copyright (c) 2025 BVK Chaitanya
+
+package subcmds
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/bvk/tradebot/api"
+	"github.com/bvk/tradebot/gobs"
+	"github.com/bvk/tradebot/kvutil"
+	"github.com/bvk/tradebot/manifest"
+	"github.com/bvk/tradebot/server"
+	"github.com/bvk/tradebot/subcmds/cmdutil"
+	"github.com/bvkgo/kv"
+	"github.com/visvasity/cli"
+)
+
+type Diff struct {
+	cmdutil.DBFlags
+}
+
+func (c *Diff) Purpose() string {
+	return "Prints the changes required to match a deployment manifest"
+}
+
+func (c *Diff) Command() (string, *flag.FlagSet, cli.CmdFunc) {
+	fset := flag.NewFlagSet("diff", flag.ContinueOnError)
+	c.DBFlags.SetFlags(fset)
+	return "diff", fset, cli.CmdFunc(c.run)
+}
+
+func (c *Diff) run(ctx context.Context, args []string) error {
+	if len(args) != 1 {
+		return fmt.Errorf("this command takes one (manifest-file) argument")
+	}
+	m, err := manifest.Load(args[0])
+	if err != nil {
+		return err
+	}
+
+	db, closer, err := c.DBFlags.GetDatabase(ctx)
+	if err != nil {
+		return fmt.Errorf("could not create database client: %w", err)
+	}
+	defer closer()
+
+	actions, err := planManifest(ctx, db, m)
+	if err != nil {
+		return err
+	}
+	printPlan(actions)
+	return nil
+}
+
+type Apply struct {
+	cmdutil.DBFlags
+
+	yes bool
+}
+
+func (c *Apply) Purpose() string {
+	return "Updates exchange products, alerts and jobs to match a deployment manifest"
+}
+
+func (c *Apply) Command() (string, *flag.FlagSet, cli.CmdFunc) {
+	fset := flag.NewFlagSet("apply", flag.ContinueOnError)
+	c.DBFlags.SetFlags(fset)
+	fset.BoolVar(&c.yes, "yes", false, "when true, applies the plan without asking for a confirmation")
+	return "apply", fset, cli.CmdFunc(c.run)
+}
+
+func (c *Apply) Description() string {
+	return `
+
+Command "apply" reads a deployment manifest in JSON format, compares it with
+the database and prints a plan of the changes required to match the manifest.
+After a confirmation, the changes are applied through the server. Command
+"diff" only prints the plan.
+
+A manifest describes the products to enable on every exchange, the low
+balance alert limits and the named looper and waller jobs with their buy/sell
+pairs, options and labels:
+
+  {
+    "Version": 1,
+    "Name": "main",
+    "LowBalanceLimits": {"USD": "100"},
+    "Exchanges": {"coinbase": {"Products": ["BTC-USD"]}},
+    "Jobs": [{
+      "Name": "btc-loop",
+      "Type": "looper",
+      "Exchange": "coinbase",
+      "Product": "BTC-USD",
+      "Pairs": [{
+        "Buy": {"Size": "0.001", "Price": "60000", "Cancel": "60100"},
+        "Sell": {"Size": "0.001", "Price": "61000", "Cancel": "60900"}
+      }],
+      "Options": {"max-cycles": "10"}
+    }]
+  }
+
+Jobs are matched by their names. New jobs are created with a "manifest=NAME"
+label. Jobs with different exchange, product or buy/sell pairs cannot be
+updated in place, so they are retired and renamed, and a new job is created
+with the same name. Jobs with the manifest label that are removed from the
+manifest are retired. Options and labels are updated in place and jobs are
+paused or resumed as per their Paused field. Running jobs are paused briefly
+for the option updates and are resumed afterwards.
+
+Products that are not listed in the manifest are not disabled and alert
+limits are updated only when they are specified in the manifest.
+
+`
+}
+
+func (c *Apply) run(ctx context.Context, args []string) error {
+	if len(args) != 1 {
+		return fmt.Errorf("this command takes one (manifest-file) argument")
+	}
+	m, err := manifest.Load(args[0])
+	if err != nil {
+		return err
+	}
+
+	db, closer, err := c.DBFlags.GetDatabase(ctx)
+	if err != nil {
+		return fmt.Errorf("could not create database client: %w", err)
+	}
+	defer closer()
+
+	actions, err := planManifest(ctx, db, m)
+	if err != nil {
+		return err
+	}
+	printPlan(actions)
+	if len(actions) == 0 {
+		return nil
+	}
+
+	if !c.yes {
+		fmt.Printf("\nApply %d changes? Only 'yes' will be accepted: ", len(actions))
+		answer, err := bufio.NewReader(os.Stdin).ReadString('\n')
+		if err != nil {
+			return fmt.Errorf("could not read the confirmation: %w", err)
+		}
+		if strings.TrimSpace(answer) != "yes" {
+			return fmt.Errorf("apply is canceled")
+		}
+	}
+
+	// Alert limits are updated directly in the database after all other
+	// changes, so that server state updates do not overwrite them.
+	var alerts *manifest.Action
+	for i, a := range actions {
+		if a.Kind == manifest.SetAlerts {
+			alerts = a
+			continue
+		}
+		if err := c.apply(ctx, m, a); err != nil {
+			return fmt.Errorf("could not apply change %d (%s): %w", i+1, a, err)
+		}
+		fmt.Printf("done: %s\n", a)
+	}
+	if alerts != nil {
+		update := func(ctx context.Context, rw kv.ReadWriter) error {
+			state, err := kvutil.Get[gobs.ServerState](ctx, rw, server.ServerStateKey)
+			if err != nil {
+				if !errors.Is(err, os.ErrNotExist) {
+					return err
+				}
+				state = new(gobs.ServerState)
+			}
+			manifest.UpdateAlerts(state, m)
+			return kvutil.Set(ctx, rw, server.ServerStateKey, state)
+		}
+		if err := kv.WithReadWriter(ctx, db, update); err != nil {
+			return fmt.Errorf("could not update alert limits: %w", err)
+		}
+		fmt.Printf("done: %s\n", alerts)
+	}
+	return nil
+}
+
+func (c *Apply) apply(ctx context.Context, m *manifest.Manifest, a *manifest.Action) error {
+	switch a.Kind {
+	case manifest.EnableProduct:
+		base, quote, _ := strings.Cut(a.Product, "-")
+		req := &api.ExchangeUpdateProductRequest{
+			ExchangeName: a.Exchange,
+			ProductType:  "SPOT",
+			Base:         base,
+			Quote:        quote,
+			Enable:       true,
+		}
+		resp, err := cmdutil.Post[api.ExchangeUpdateProductResponse](ctx, &c.ClientFlags, api.ExchangeUpdateProductPath, req)
+		if err != nil {
+			return err
+		}
+		if len(resp.Error) != 0 {
+			return errors.New(resp.Error)
+		}
+		return nil
+
+	case manifest.Create:
+		return c.createJob(ctx, m, a.Job)
+
+	case manifest.Replace:
+		if a.Retire {
+			if err := c.setOption(ctx, a, "retire", "true"); err != nil {
+				return fmt.Errorf("could not retire existing job: %w", err)
+			}
+		}
+		name := fmt.Sprintf("%s-replaced-%s", a.Name, time.Now().Format("20060102-150405"))
+		if err := c.setName(ctx, a.UID, name); err != nil {
+			return fmt.Errorf("could not rename existing job: %w", err)
+		}
+		return c.createJob(ctx, m, a.Job)
+
+	case manifest.SetOption:
+		return c.setOption(ctx, a, a.OptionKey, a.OptionValue)
+
+	case manifest.SetLabels:
+		req := &api.JobLabelRequest{
+			UID:    a.UID,
+			Labels: a.Labels,
+			Remove: a.RemoveLabels,
+		}
+		_, err := cmdutil.Post[api.JobLabelResponse](ctx, &c.ClientFlags, api.JobLabelPath, req)
+		return err
+
+	case manifest.Pause:
+		return c.pause(ctx, a.UID)
+
+	case manifest.Resume:
+		return c.resume(ctx, a.UID)
+
+	case manifest.Retire:
+		return c.setOption(ctx, a, "retire", "true")
+
+	default:
+		return fmt.Errorf("unsupported action kind %q", a.Kind)
+	}
+}
+
+func (c *Apply) createJob(ctx context.Context, m *manifest.Manifest, j *manifest.Job) error {
+	var uid string
+	if strings.EqualFold(j.Type, "looper") {
+		req := &api.LoopRequest{
+			ExchangeName: j.Exchange,
+			ProductID:    j.Product,
+			Buy:          &j.Pairs[0].Buy,
+			Sell:         &j.Pairs[0].Sell,
+			Options:      j.Options,
+			Labels:       m.JobLabels(j),
+			Pause:        j.Paused,
+		}
+		resp, err := cmdutil.Post[api.LoopResponse](ctx, &c.ClientFlags, api.LoopPath, req)
+		if err != nil {
+			return err
+		}
+		uid = resp.UID
+	} else {
+		req := &api.WallRequest{
+			ExchangeName: j.Exchange,
+			ProductID:    j.Product,
+			Pairs:        j.Pairs,
+			Options:      j.Options,
+			Labels:       m.JobLabels(j),
+			Pause:        j.Paused,
+		}
+		resp, err := cmdutil.Post[api.WallResponse](ctx, &c.ClientFlags, api.WallPath, req)
+		if err != nil {
+			return err
+		}
+		uid = resp.UID
+	}
+	if err := c.setName(ctx, uid, j.Name); err != nil {
+		return fmt.Errorf("job %s is created, but could not set the job name: %w", uid, err)
+	}
+	return nil
+}
+
+func (c *Apply) setName(ctx context.Context, uid, name string) error {
+	req := &api.SetJobNameRequest{
+		UID:     uid,
+		JobName: name,
+	}
+	_, err := cmdutil.Post[api.SetJobNameResponse](ctx, &c.ClientFlags, api.SetJobNamePath, req)
+	return err
+}
+
+// setOption updates an option on the job of the input action. Server only
+// updates the options on idle jobs, so running jobs are paused for the update
+// and resumed afterwards.
+func (c *Apply) setOption(ctx context.Context, a *manifest.Action, key, value string) (status error) {
+	if a.Running {
+		if err := c.pause(ctx, a.UID); err != nil {
+			return fmt.Errorf("could not pause the running job: %w", err)
+		}
+		defer func() {
+			if err := c.resume(ctx, a.UID); err != nil {
+				status = errors.Join(status, fmt.Errorf("could not resume the job: %w", err))
+			}
+		}()
+	}
+
+	req := &api.JobSetOptionRequest{
+		UID:         a.UID,
+		OptionKey:   key,
+		OptionValue: value,
+	}
+	_, err := cmdutil.Post[api.JobSetOptionResponse](ctx, &c.ClientFlags, api.JobSetOptionPath, req)
+	return err
+}
+
+func (c *Apply) pause(ctx context.Context, uid string) error {
+	req := &api.JobPauseRequest{UID: uid}
+	_, err := cmdutil.Post[api.JobPauseResponse](ctx, &c.ClientFlags, api.JobPausePath, req)
+	return err
+}
+
+func (c *Apply) resume(ctx context.Context, uid string) error {
+	req := &api.JobResumeRequest{UID: uid}
+	_, err := cmdutil.Post[api.JobResumeResponse](ctx, &c.ClientFlags, api.JobResumePath, req)
+	return err
+}
+
+func planManifest(ctx context.Context, db kv.Database, m *manifest.Manifest) ([]*manifest.Action, error) {
+	var actions []*manifest.Action
+	plan := func(ctx context.Context, r kv.Reader) error {
+		v, err := manifest.Plan(ctx, r, m)
+		if err != nil {
+			return err
+		}
+		actions = v
+		return nil
+	}
+	if err := kv.WithReader(ctx, db, plan); err != nil {
+		return nil, fmt.Errorf("could not compare manifest with the database: %w", err)
+	}
+	return actions, nil
+}
+
+func printPlan(actions []*manifest.Action) {
+	if len(actions) == 0 {
+		fmt.Println("No changes. Database matches the manifest.")
+		return
+	}
+	for i, a := range actions {
+		fmt.Printf("%3d. %s\n", i+1, a)
+	}
+}
@@ -0,0 +1,141 @@
+// Copyright (c) 2025 BVK Chaitanya
+
+package arbiter
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+
+	"github.com/bvk/tradebot/api"
+	"github.com/bvk/tradebot/arbiter"
+	"github.com/bvk/tradebot/subcmds/cmdutil"
+	"github.com/shopspring/decimal"
+	"github.com/visvasity/cli"
+)
+
+type Add struct {
+	cmdutil.ClientFlags
+
+	name string
+
+	exchangeA, productA string
+	exchangeB, productB string
+
+	feePctA, feePctB float64
+
+	thresholdPct float64
+
+	tradeSize string
+
+	maxTrades int
+}
+
+func (c *Add) check() error {
+	if len(c.exchangeA) == 0 || len(c.exchangeB) == 0 {
+		return fmt.Errorf("exchange names cannot be empty")
+	}
+	if len(c.productA) == 0 || len(c.productB) == 0 {
+		return fmt.Errorf("product names cannot be empty")
+	}
+	if c.feePctA < 0 || c.feePctB < 0 {
+		return fmt.Errorf("fee percentages cannot be negative")
+	}
+	if c.thresholdPct < 0 {
+		return fmt.Errorf("threshold percentage cannot be negative")
+	}
+	if c.maxTrades < 0 {
+		return fmt.Errorf("max trades cannot be negative")
+	}
+	if err := arbiter.CheckProducts(c.productA, c.productB); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (c *Add) Run(ctx context.Context, args []string) error {
+	if len(args) != 0 {
+		return fmt.Errorf("this command takes no arguments")
+	}
+	if err := c.check(); err != nil {
+		return err
+	}
+
+	size := decimal.Zero
+	if c.tradeSize != "" {
+		v, err := decimal.NewFromString(c.tradeSize)
+		if err != nil {
+			return fmt.Errorf("could not parse trade size: %w", err)
+		}
+		size = v
+	}
+
+	req1 := &api.ArbitrageRequest{
+		ExchangeA:    c.exchangeA,
+		ProductA:     c.productA,
+		FeePctA:      decimal.NewFromFloat(c.feePctA),
+		ExchangeB:    c.exchangeB,
+		ProductB:     c.productB,
+		FeePctB:      decimal.NewFromFloat(c.feePctB),
+		ThresholdPct: decimal.NewFromFloat(c.thresholdPct),
+		TradeSize:    size,
+		MaxTrades:    c.maxTrades,
+	}
+	resp1, err := cmdutil.Post[api.ArbitrageResponse](ctx, &c.ClientFlags, api.ArbitragePath, req1)
+	if err != nil {
+		return err
+	}
+
+	req2 := &api.SetJobNameRequest{
+		UID:     resp1.UID,
+		JobName: c.name,
+	}
+	if _, err := cmdutil.Post[api.SetJobNameResponse](ctx, &c.ClientFlags, api.SetJobNamePath, req2); err != nil {
+		log.Printf("job is created, but could not set the job name (ignored): %v", err)
+	}
+
+	jsdata, _ := json.MarshalIndent(resp1, "", "  ")
+	fmt.Printf("%s\n", jsdata)
+	return nil
+}
+
+func (c *Add) Command() (string, *flag.FlagSet, cli.CmdFunc) {
+	fset := flag.NewFlagSet("add", flag.ContinueOnError)
+	c.ClientFlags.SetFlags(fset)
+	fset.StringVar(&c.name, "name", "", "a name for the trader job")
+	fset.StringVar(&c.exchangeA, "exchange-a", "coinbase", "exchange name for the first leg")
+	fset.StringVar(&c.productA, "product-a", "", "product id on the first exchange")
+	fset.Float64Var(&c.feePctA, "fee-pct-a", 0.25, "fee percentage on the first exchange")
+	fset.StringVar(&c.exchangeB, "exchange-b", "coinex", "exchange name for the second leg")
+	fset.StringVar(&c.productB, "product-b", "", "product id on the second exchange")
+	fset.Float64Var(&c.feePctB, "fee-pct-b", 0.25, "fee percentage on the second exchange")
+	fset.Float64Var(&c.thresholdPct, "threshold-pct", 0.1, "minimum spread percentage above the fees for alerts and trades")
+	fset.StringVar(&c.tradeSize, "trade-size", "", "size for the paired orders (default only monitors the spread)")
+	fset.IntVar(&c.maxTrades, "max-trades", 0, "max number of paired trades (default no limit)")
+	return "add", fset, cli.CmdFunc(c.Run)
+}
+
+func (c *Add) Purpose() string {
+	return "Creates a new cross-exchange spread monitor job"
+}
+
+func (c *Add) Description() string {
+	return `
+
+Command "add" creates a job that watches the prices for the same asset on two
+different exchanges and records the spread history. Product ids are compared
+after normalizing the USDC and USDT quote currencies to USD, so BTC-USD on one
+exchange can be paired with BTCUSDT on another.
+
+An alert is sent when buying on one exchange and selling on the other is more
+profitable than the fees on both exchanges plus the threshold percentage.
+
+When trade-size is set, job also places paired limit orders at the best ask on
+the cheaper exchange and at the best bid on the expensive exchange using the
+existing balances on both exchanges. Orders that are not filled within a short
+time are canceled and unbalanced fills are reported in the alerts.
+
+`
+}
@@ -0,0 +1,106 @@
+// Copyright (c) 2025 BVK Chaitanya
+
+package arbiter
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/bvk/tradebot/arbiter"
+	"github.com/bvk/tradebot/gobs"
+	"github.com/bvk/tradebot/namer"
+	"github.com/bvk/tradebot/subcmds/cmdutil"
+	"github.com/bvkgo/kv"
+	"github.com/visvasity/cli"
+)
+
+type Spreads struct {
+	cmdutil.DBFlags
+
+	last time.Duration
+}
+
+func (c *Spreads) Run(ctx context.Context, args []string) error {
+	if len(args) != 1 {
+		return fmt.Errorf("this command takes one arbiter argument")
+	}
+	arg := args[0]
+
+	var arb *arbiter.Arbiter
+	getter := func(ctx context.Context, r kv.Reader) error {
+		_, uid, _, err := namer.Resolve(ctx, r, arg)
+		if err != nil {
+			if !errors.Is(err, os.ErrNotExist) {
+				return fmt.Errorf("could not resolve arbiter argument %q: %w", arg, err)
+			}
+			uid = arg
+		}
+		v, err := arbiter.Load(ctx, uid, r)
+		if err != nil {
+			return err
+		}
+		arb = v
+		return nil
+	}
+
+	db, closer, err := c.DBFlags.GetDatabase(ctx)
+	if err != nil {
+		return err
+	}
+	defer closer()
+
+	if err := kv.WithReader(ctx, db, getter); err != nil {
+		return err
+	}
+
+	a, b := arb.ExchangeName(), arb.OtherExchangeName()
+	since := time.Now().Add(-c.last)
+
+	tw := tabwriter.NewWriter(os.Stdout, 0, 0, 1, ' ', tabwriter.AlignRight)
+	fmt.Fprintf(tw, "Time\tBid (%s)\tAsk (%s)\tBid (%s)\tAsk (%s)\tSpread %s->%s %%\tSpread %s->%s %%\t\n", a, a, b, b, a, b, b, a)
+	for _, s := range arb.Spreads() {
+		if c.last != 0 && s.Time.Before(since) {
+			continue
+		}
+		ab, ba := arbiter.SpreadPcts(s)
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%s\t%s\t\n", s.Time.Format(time.DateTime), s.BidA, s.AskA, s.BidB, s.AskB, ab.StringFixed(3), ba.StringFixed(3))
+	}
+	tw.Flush()
+
+	if trades := arb.Trades(); len(trades) > 0 {
+		fmt.Println()
+		tw := tabwriter.NewWriter(os.Stdout, 0, 0, 1, ' ', tabwriter.AlignRight)
+		fmt.Fprintf(tw, "Time\tBuy Exchange\tBought Size\tBuy Price\tSell Exchange\tSold Size\tSell Price\tNet Spread %%\t\n")
+		for _, t := range trades {
+			bsize, bprice := filled(t.Buy)
+			ssize, sprice := filled(t.Sell)
+			fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\t\n", t.Time.Format(time.DateTime),
+				t.BuyExchange, bsize, bprice, t.SellExchange, ssize, sprice, t.NetSpreadPct.StringFixed(3))
+		}
+		tw.Flush()
+	}
+	return nil
+}
+
+func (c *Spreads) Command() (string, *flag.FlagSet, cli.CmdFunc) {
+	fset := flag.NewFlagSet("spreads", flag.ContinueOnError)
+	c.DBFlags.SetFlags(fset)
+	fset.DurationVar(&c.last, "last", 24*time.Hour, "prints spreads only in the recent duration (zero prints all)")
+	return "spreads", fset, cli.CmdFunc(c.Run)
+}
+
+func (c *Spreads) Purpose() string {
+	return "Prints the spread history and trades of an arbiter job"
+}
+
+func filled(order *gobs.Order) (size, price string) {
+	if order == nil {
+		return "-", "-"
+	}
+	return order.FilledSize.String(), order.FilledPrice.StringFixed(5)
+}
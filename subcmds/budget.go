@@ -0,0 +1,68 @@
+// Copyright (c) 2025 BVK Chaitanya
+
+package subcmds
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/bvk/tradebot/api"
+	"github.com/bvk/tradebot/subcmds/cmdutil"
+	"github.com/visvasity/cli"
+)
+
+type Budget struct {
+	cmdutil.ClientFlags
+}
+
+func (c *Budget) Purpose() string {
+	return "Prints committed vs. available capital"
+}
+
+func (c *Budget) Command() (string, *flag.FlagSet, cli.CmdFunc) {
+	fset := flag.NewFlagSet("budget", flag.ContinueOnError)
+	c.ClientFlags.SetFlags(fset)
+	return "budget", fset, cli.CmdFunc(c.run)
+}
+
+func (c *Budget) Description() string {
+	return `
+
+Command "budget" prints the capital committed by all running trading jobs
+for each quote currency in every exchange along with the available and total
+balances as reported by the exchange. Total balance includes the balance held
+for the open orders, so uncommitted capital is computed from the total
+balance. Sell limiters and watchers do not commit any quote currency, so they
+are not included.
+
+`
+}
+
+func (c *Budget) run(ctx context.Context, args []string) error {
+	if len(args) != 0 {
+		return fmt.Errorf("this command takes no arguments")
+	}
+
+	req := &api.BudgetRequest{}
+	resp, err := cmdutil.Post[api.BudgetResponse](ctx, &c.ClientFlags, api.BudgetPath, req)
+	if err != nil {
+		return err
+	}
+
+	tw := tabwriter.NewWriter(os.Stdout, 0, 0, 1, ' ', tabwriter.AlignRight)
+	fmt.Fprintf(tw, "Exchange\tCurrency\tJobs\tCommitted\tAvailable\tTotal\tUncommitted\t\n")
+	for _, item := range resp.Items {
+		available, total, uncommitted := "-", "-", "-"
+		if item.HasBalance {
+			available = item.Available.StringFixed(3)
+			total = item.Total.StringFixed(3)
+			uncommitted = item.Total.Sub(item.Committed).StringFixed(3)
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%d\t%s\t%s\t%s\t%s\t\n", item.ExchangeName, item.Currency, item.NumJobs, item.Committed.StringFixed(3), available, total, uncommitted)
+	}
+	tw.Flush()
+	return nil
+}
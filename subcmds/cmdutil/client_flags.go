@@ -3,8 +3,10 @@
 package cmdutil
 
 import (
+	"bufio"
 	"bytes"
 	"context"
+	"crypto/tls"
 	"encoding/json"
 	"flag"
 	"fmt"
@@ -12,17 +14,29 @@ import (
 	"net"
 	"net/http"
 	"net/url"
+	"os"
 	"path"
+	"strings"
 	"time"
 
 	"github.com/bvk/tradebot/subcmds/defaults"
 )
 
+// APITokenEnv is the environment variable that holds the default api token.
+const APITokenEnv = "TRADEBOT_API_TOKEN"
+
 type ClientFlags struct {
 	port        int
 	Host        string
 	APIPath     string
 	HTTPTimeout time.Duration
+
+	apiToken string
+
+	tls     bool
+	tlsCA   string
+	tlsCert string
+	tlsKey  string
 }
 
 func (cf *ClientFlags) SetFlags(fset *flag.FlagSet) {
@@ -30,6 +44,11 @@ func (cf *ClientFlags) SetFlags(fset *flag.FlagSet) {
 	fset.StringVar(&cf.Host, "connect-host", "127.0.0.1", "Hostname or IP address for the api endpoint")
 	fset.StringVar(&cf.APIPath, "api-path", "/", "base path to the api handler")
 	fset.DurationVar(&cf.HTTPTimeout, "http-timeout", 30*time.Second, "http client timeout")
+	fset.StringVar(&cf.apiToken, "api-token", os.Getenv(APITokenEnv), "api token for the requests; defaults to $"+APITokenEnv)
+	fset.BoolVar(&cf.tls, "tls", false, "when true, api endpoint is accessed over https")
+	fset.StringVar(&cf.tlsCA, "tls-ca", "", "path to the PEM encoded CA certificates to verify the server")
+	fset.StringVar(&cf.tlsCert, "tls-cert", "", "path to the PEM encoded client certificate")
+	fset.StringVar(&cf.tlsKey, "tls-key", "", "path to the PEM encoded client private key")
 }
 
 func (cf *ClientFlags) Port() int {
@@ -37,17 +56,75 @@ func (cf *ClientFlags) Port() int {
 }
 
 func (cf *ClientFlags) AddressURL() *url.URL {
+	scheme := "http"
+	if cf.tls {
+		scheme = "https"
+	}
 	return &url.URL{
-		Scheme: "http",
+		Scheme: scheme,
 		Host:   net.JoinHostPort(cf.Host, fmt.Sprintf("%d", cf.Port())),
 		Path:   cf.APIPath,
 	}
 }
 
+// HttpClient returns a http client that adds the api token to all requests
+// and uses the client certificates when configured. Errors in loading the tls
+// certificates are reported by the requests.
 func (cf *ClientFlags) HttpClient() *http.Client {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	var tlsErr error
+	if cf.tls {
+		transport.TLSClientConfig, tlsErr = cf.tlsConfig()
+	}
 	return &http.Client{
 		Timeout: cf.HTTPTimeout,
+		Transport: &authTransport{
+			token: cf.apiToken,
+			err:   tlsErr,
+			next:  transport,
+		},
+	}
+}
+
+func (cf *ClientFlags) tlsConfig() (*tls.Config, error) {
+	config := &tls.Config{MinVersion: tls.VersionTLS12}
+	if len(cf.tlsCA) != 0 {
+		pool, err := loadCertPool(cf.tlsCA)
+		if err != nil {
+			return nil, err
+		}
+		config.RootCAs = pool
+	}
+	if len(cf.tlsCert) != 0 || len(cf.tlsKey) != 0 {
+		if len(cf.tlsCert) == 0 || len(cf.tlsKey) == 0 {
+			return nil, fmt.Errorf("both -tls-cert and -tls-key flags must be set: %w", os.ErrInvalid)
+		}
+		cert, err := tls.LoadX509KeyPair(cf.tlsCert, cf.tlsKey)
+		if err != nil {
+			return nil, fmt.Errorf("could not load client certificate: %w", err)
+		}
+		config.Certificates = []tls.Certificate{cert}
 	}
+	return config, nil
+}
+
+// authTransport adds the api token to the http requests.
+type authTransport struct {
+	token string
+	err   error
+	next  http.RoundTripper
+}
+
+func (t *authTransport) RoundTrip(r *http.Request) (*http.Response, error) {
+	if t.err != nil {
+		return nil, t.err
+	}
+	if len(t.token) == 0 {
+		return t.next.RoundTrip(r)
+	}
+	r = r.Clone(r.Context())
+	r.Header.Set("authorization", "Bearer "+t.token)
+	return t.next.RoundTrip(r)
 }
 
 func Post[RESP, REQ any](ctx context.Context, cf *ClientFlags, subpath string, req *REQ) (*RESP, error) {
@@ -80,3 +157,59 @@ func Post[RESP, REQ any](ctx context.Context, cf *ClientFlags, subpath string, r
 	}
 	return response, nil
 }
+
+// Stream sends a GET request to a server-sent events endpoint and invokes the
+// callback with the event name and data of every event until the stream ends
+// or the context is canceled. HTTP timeout is not applied to the streams.
+func Stream(ctx context.Context, cf *ClientFlags, subpath string, query url.Values, fn func(event string, data []byte) error) error {
+	addrURL := cf.AddressURL()
+	addrURL.Path = path.Join(addrURL.Path, subpath)
+	addrURL.RawQuery = query.Encode()
+	r, err := http.NewRequestWithContext(ctx, http.MethodGet, addrURL.String(), nil)
+	if err != nil {
+		return err
+	}
+	r.Header.Set("accept", "text/event-stream")
+
+	client := cf.HttpClient()
+	client.Timeout = 0
+	resp, err := client.Do(r)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		data, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("http status code %d: %s", resp.StatusCode, data)
+	}
+
+	var event string
+	var data []byte
+	scanner := bufio.NewScanner(resp.Body)
+	scanner.Buffer(nil, 1<<20)
+	for scanner.Scan() {
+		line := scanner.Text()
+		switch {
+		case len(line) == 0:
+			if len(data) > 0 {
+				if err := fn(event, data); err != nil {
+					return err
+				}
+			}
+			event, data = "", nil
+		case strings.HasPrefix(line, ":"):
+			// Comments are used as keep-alive messages.
+		case strings.HasPrefix(line, "event:"):
+			event = strings.TrimSpace(strings.TrimPrefix(line, "event:"))
+		case strings.HasPrefix(line, "data:"):
+			if len(data) > 0 {
+				data = append(data, '\n')
+			}
+			data = append(data, strings.TrimPrefix(strings.TrimPrefix(line, "data:"), " ")...)
+		}
+	}
+	if err := scanner.Err(); err != nil && ctx.Err() == nil {
+		return err
+	}
+	return context.Cause(ctx)
+}
@@ -0,0 +1,63 @@
+// Copyright (c) 2025 BVK Chaitanya
+
+package cmdutil
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/bvk/tradebot/api"
+)
+
+// PrintDryRun prints the exchange-facing effects report for a new job. It
+// returns a non-nil error if any trade point violates the exchange limits.
+func PrintDryRun(r *api.DryRunReport) error {
+	if r == nil {
+		return fmt.Errorf("server did not return a dry-run report")
+	}
+
+	fmt.Printf("Exchange: %s\n", r.ExchangeName)
+	fmt.Printf("Product: %s\n", r.ProductID)
+	fmt.Printf("Ticker Price: %s\n", r.TickerPrice)
+	fmt.Printf("Min Size: %s (increment %s)\n", r.BaseMinSize, r.BaseIncrement)
+	fmt.Printf("Min Value: %s (price increment %s)\n", r.QuoteMinSize, r.QuoteIncrement)
+	fmt.Println()
+
+	nplaced := 0
+	tw := tabwriter.NewWriter(os.Stdout, 0, 0, 1, ' ', 0)
+	fmt.Fprintf(tw, "Pair\tSide\tSize\tPrice\tCancel\tPlacedNow\tViolations\t\n")
+	for _, p := range r.Points {
+		pair := ""
+		if p.Pair >= 0 {
+			pair = fmt.Sprintf("%d", p.Pair)
+		}
+		if p.PlacedNow {
+			nplaced++
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%t\t%s\t\n", pair, p.Side, p.Size, p.Price, p.Cancel, p.PlacedNow, strings.Join(p.Violations, "; "))
+	}
+	tw.Flush()
+	fmt.Println()
+
+	available := func(ok bool, v fmt.Stringer) string {
+		if !ok {
+			return "unknown"
+		}
+		return v.String()
+	}
+	fmt.Printf("Orders Placed Now: %d\n", nplaced)
+	fmt.Printf("Required %s Now: %s\n", r.QuoteCurrency, r.QuoteNow.StringFixed(3))
+	fmt.Printf("Required %s Worst Case: %s\n", r.QuoteCurrency, r.QuoteWorstCase.StringFixed(3))
+	fmt.Printf("Committed %s: %s\n", r.QuoteCurrency, r.QuoteCommitted.StringFixed(3))
+	fmt.Printf("Available %s: %s\n", r.QuoteCurrency, available(r.HasQuoteBalance, r.QuoteAvailable))
+	fmt.Printf("Required %s Now: %s\n", r.BaseCurrency, r.BaseNow)
+	fmt.Printf("Required %s Worst Case: %s\n", r.BaseCurrency, r.BaseWorstCase)
+	fmt.Printf("Available %s: %s\n", r.BaseCurrency, available(r.HasBaseBalance, r.BaseAvailable))
+
+	if r.NumViolations > 0 {
+		return fmt.Errorf("%d trade points violate the exchange limits", r.NumViolations)
+	}
+	return nil
+}
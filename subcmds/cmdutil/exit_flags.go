@@ -0,0 +1,59 @@
+// Copyright (c) 2025 BVK Chaitanya
+
+package cmdutil
+
+import (
+	"flag"
+	"fmt"
+	"strconv"
+
+	"github.com/bvk/tradebot/looper"
+	"github.com/shopspring/decimal"
+)
+
+// ExitFlags holds the command-line flags for the looper exit options, which
+// are also applied to all child loopers of a waller.
+type ExitFlags struct {
+	maxCycles    int64
+	profitTarget float64
+	endDate      string
+}
+
+func (ef *ExitFlags) SetFlags(fset *flag.FlagSet) {
+	fset.Int64Var(&ef.maxCycles, "max-cycles", 0, "when non-zero, loops are completed after this many buy-sell cycles")
+	fset.Float64Var(&ef.profitTarget, "profit-target", 0, "when non-zero, loops are completed after realizing this much profit")
+	fset.StringVar(&ef.endDate, "end-date", "", "when non-empty, loops are completed at this date (YYYY-MM-DD or RFC3339 format)")
+}
+
+func (ef *ExitFlags) Check() error {
+	if ef.maxCycles < 0 {
+		return fmt.Errorf("max-cycles cannot be negative")
+	}
+	if ef.profitTarget < 0 {
+		return fmt.Errorf("profit-target cannot be negative")
+	}
+	if ef.endDate != "" {
+		if _, err := looper.ParseEndDate(ef.endDate); err != nil {
+			return fmt.Errorf("could not parse end-date: %w", err)
+		}
+	}
+	return nil
+}
+
+// Options returns the job options for the exit flags or nil.
+func (ef *ExitFlags) Options() map[string]string {
+	opts := make(map[string]string)
+	if ef.maxCycles > 0 {
+		opts["max-cycles"] = strconv.FormatInt(ef.maxCycles, 10)
+	}
+	if ef.profitTarget > 0 {
+		opts["profit-target"] = decimal.NewFromFloat(ef.profitTarget).String()
+	}
+	if ef.endDate != "" {
+		opts["end-date"] = ef.endDate
+	}
+	if len(opts) == 0 {
+		return nil
+	}
+	return opts
+}
@@ -0,0 +1,31 @@
+// Copyright (c) 2025 BVK Chaitanya
+
+package cmdutil
+
+import (
+	"flag"
+	"fmt"
+
+	"github.com/bvk/tradebot/job"
+)
+
+// LabelFlags holds the command-line flag for the user-defined job labels.
+type LabelFlags struct {
+	labels string
+}
+
+func (lf *LabelFlags) SetFlags(fset *flag.FlagSet) {
+	fset.StringVar(&lf.labels, "labels", "", "comma separated key=value labels for selecting the job in bulk operations")
+}
+
+// Labels returns the parsed labels or nil.
+func (lf *LabelFlags) Labels() (map[string]string, error) {
+	labels, err := job.ParseLabels(lf.labels)
+	if err != nil {
+		return nil, fmt.Errorf("could not parse labels: %w", err)
+	}
+	if err := job.CheckLabels(labels); err != nil {
+		return nil, err
+	}
+	return labels, nil
+}
@@ -3,21 +3,94 @@
 package cmdutil
 
 import (
+	"crypto/tls"
+	"crypto/x509"
 	"flag"
+	"fmt"
+	"os"
 
+	"github.com/bvk/tradebot/auth"
 	"github.com/bvk/tradebot/subcmds/defaults"
 )
 
 type ServerFlags struct {
 	port int
 	IP   string
+
+	tlsCert     string
+	tlsKey      string
+	tlsClientCA string
+
+	authLoopback bool
 }
 
 func (sf *ServerFlags) SetFlags(fset *flag.FlagSet) {
 	fset.IntVar(&sf.port, "listen-port", defaults.ServerPort(), "TCP port number for the api endpoint")
 	fset.StringVar(&sf.IP, "listen-ip", "127.0.0.1", "TCP ip address for the api endpoint")
+	fset.StringVar(&sf.tlsCert, "tls-cert", "", "path to the PEM encoded server certificate; enables https")
+	fset.StringVar(&sf.tlsKey, "tls-key", "", "path to the PEM encoded server private key")
+	fset.StringVar(&sf.tlsClientCA, "tls-client-ca", "", "path to the PEM encoded CA certificates; requires verified client certificates")
+	fset.BoolVar(&sf.authLoopback, "auth-loopback", false, "when true, api tokens are required for the loopback clients too")
 }
 
 func (sf *ServerFlags) Port() int {
 	return sf.port
 }
+
+// IsTLS returns true if the api endpoint is configured to use https.
+func (sf *ServerFlags) IsTLS() bool {
+	return len(sf.tlsCert) != 0
+}
+
+// TLSConfig returns the tls configuration for the api endpoint. It returns nil
+// if tls is not configured.
+func (sf *ServerFlags) TLSConfig() (*tls.Config, error) {
+	if len(sf.tlsCert) == 0 && len(sf.tlsKey) == 0 {
+		if len(sf.tlsClientCA) != 0 {
+			return nil, fmt.Errorf("client certificates require -tls-cert and -tls-key flags: %w", os.ErrInvalid)
+		}
+		return nil, nil
+	}
+	if len(sf.tlsCert) == 0 || len(sf.tlsKey) == 0 {
+		return nil, fmt.Errorf("both -tls-cert and -tls-key flags must be set: %w", os.ErrInvalid)
+	}
+	cert, err := tls.LoadX509KeyPair(sf.tlsCert, sf.tlsKey)
+	if err != nil {
+		return nil, fmt.Errorf("could not load tls certificate: %w", err)
+	}
+	config := &tls.Config{
+		MinVersion:   tls.VersionTLS12,
+		Certificates: []tls.Certificate{cert},
+	}
+	if len(sf.tlsClientCA) != 0 {
+		pool, err := loadCertPool(sf.tlsClientCA)
+		if err != nil {
+			return nil, err
+		}
+		// Client certificates are enforced by the authenticator, so that http
+		// server initialization check can succeed without a certificate.
+		config.ClientCAs = pool
+		config.ClientAuth = tls.VerifyClientCertIfGiven
+	}
+	return config, nil
+}
+
+// AuthOptions returns the options for the api request authenticator.
+func (sf *ServerFlags) AuthOptions() *auth.Options {
+	return &auth.Options{
+		AuthLoopback:      sf.authLoopback,
+		RequireClientCert: len(sf.tlsClientCA) != 0,
+	}
+}
+
+func loadCertPool(file string) (*x509.CertPool, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, fmt.Errorf("could not read certificates file %q: %w", file, err)
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(data) {
+		return nil, fmt.Errorf("could not find any certificates in %q: %w", file, os.ErrInvalid)
+	}
+	return pool, nil
+}
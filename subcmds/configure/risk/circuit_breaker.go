@@ -0,0 +1,140 @@
+// Copyright (c) 2025 BVK Chaitanya
+
+package risk
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/bvk/tradebot/gobs"
+	"github.com/bvk/tradebot/kvutil"
+	"github.com/bvk/tradebot/server"
+	"github.com/bvk/tradebot/subcmds/cmdutil"
+	"github.com/shopspring/decimal"
+	"github.com/visvasity/cli"
+)
+
+type CircuitBreaker struct {
+	cmdutil.DBFlags
+
+	disable bool
+}
+
+func (c *CircuitBreaker) Purpose() string {
+	return "Configures the market crash circuit breaker"
+}
+
+func (c *CircuitBreaker) Command() (string, *flag.FlagSet, cli.CmdFunc) {
+	fset := new(flag.FlagSet)
+	c.DBFlags.SetFlags(fset)
+	fset.BoolVar(&c.disable, "disable", false, "When true, disables the circuit breaker")
+	return "circuit-breaker", fset, cli.CmdFunc(c.run)
+}
+
+func (c *CircuitBreaker) Description() string {
+	return `
+
+Command "circuit-breaker" configures the circuit breaker that pauses new order
+creation by all limiters in a product when the product's price moves more than
+move-pct percent within the window duration or when the bid-ask spread is
+above max-spread-pct percent. Jobs and their existing orders are not canceled.
+
+Settings are specified as KEY=VALUE arguments where KEY is one of move-pct,
+window, max-spread-pct, cool-down or manual-reset. Tripped breakers are reset
+after the cool-down duration, unless manual-reset=true is set, in which case,
+they must be reset with the resume-trading telegram command. Changes take
+effect within a minute on the running server.
+
+Example: tradebot configure risk circuit-breaker move-pct=10 window=15m cool-down=1h
+
+`
+}
+
+func (c *CircuitBreaker) run(ctx context.Context, args []string) error {
+	if len(args) == 0 && !c.disable {
+		return fmt.Errorf("this command takes one or more KEY=VALUE arguments")
+	}
+
+	db, closer, err := c.DBFlags.GetDatabase(ctx)
+	if err != nil {
+		return fmt.Errorf("could not create database client: %w", err)
+	}
+	defer closer()
+
+	tx, err := db.NewTransaction(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	state, err := kvutil.Get[gobs.ServerState](ctx, tx, server.ServerStateKey)
+	if err != nil {
+		if !errors.Is(err, os.ErrNotExist) {
+			return err
+		}
+		state = new(gobs.ServerState)
+	}
+
+	if c.disable {
+		state.CircuitBreaker = nil
+	} else {
+		cfg := state.CircuitBreaker
+		if cfg == nil {
+			cfg = new(gobs.CircuitBreakerConfig)
+		}
+		for _, arg := range args {
+			key, value, ok := strings.Cut(arg, "=")
+			if !ok {
+				return fmt.Errorf("invalid KEY=VALUE argument %q", arg)
+			}
+			switch key {
+			case "move-pct", "max-spread-pct":
+				v, err := decimal.NewFromString(value)
+				if err != nil || v.IsNegative() {
+					return fmt.Errorf("invalid value in %q", arg)
+				}
+				if key == "move-pct" {
+					cfg.MovePct = v
+				} else {
+					cfg.MaxSpreadPct = v
+				}
+			case "window", "cool-down":
+				v, err := time.ParseDuration(value)
+				if err != nil || v < 0 {
+					return fmt.Errorf("invalid value in %q", arg)
+				}
+				if key == "window" {
+					cfg.Window = v
+				} else {
+					cfg.CoolDown = v
+				}
+			case "manual-reset":
+				v, err := strconv.ParseBool(value)
+				if err != nil {
+					return fmt.Errorf("invalid value in %q", arg)
+				}
+				cfg.ManualReset = v
+			default:
+				return fmt.Errorf("unsupported setting name in %q", arg)
+			}
+		}
+		if cfg.MovePct.IsPositive() && cfg.Window == 0 {
+			return fmt.Errorf("move-pct setting requires a positive window duration")
+		}
+		state.CircuitBreaker = cfg
+	}
+
+	if err := kvutil.Set(ctx, tx, server.ServerStateKey, state); err != nil {
+		return err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return err
+	}
+	return nil
+}
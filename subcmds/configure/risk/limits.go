@@ -0,0 +1,173 @@
+// Copyright (c) 2025 BVK Chaitanya
+
+package risk
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path"
+	"strconv"
+	"strings"
+
+	"github.com/bvk/tradebot/gobs"
+	"github.com/bvk/tradebot/kvutil"
+	"github.com/bvk/tradebot/server"
+	"github.com/bvk/tradebot/subcmds/cmdutil"
+	"github.com/shopspring/decimal"
+	"github.com/visvasity/cli"
+)
+
+type Limits struct {
+	cmdutil.DBFlags
+
+	exchange string
+	product  string
+	remove   bool
+}
+
+func (c *Limits) Purpose() string {
+	return "Adds or updates inventory risk limits that freeze buys"
+}
+
+func (c *Limits) Command() (string, *flag.FlagSet, cli.CmdFunc) {
+	fset := new(flag.FlagSet)
+	c.DBFlags.SetFlags(fset)
+	fset.StringVar(&c.exchange, "exchange", "", "Name of the target exchange")
+	fset.StringVar(&c.product, "product", "", "Product id for product specific limits")
+	fset.BoolVar(&c.remove, "remove", false, "When true, removes the limits for the exchange or product")
+	return "limits", fset, cli.CmdFunc(c.run)
+}
+
+func (c *Limits) Description() string {
+	return `
+
+Command "limits" configures inventory risk limits for an exchange or a product
+in an exchange. Limits are specified as KEY=VALUE arguments where KEY is one of
+max-unsold-size, max-unsold-value or max-held-positions. Argument
+release-pct=VALUE sets the percentage of limits below which the inventory must
+fall before frozen buys are released automatically (default 80).
+
+When a limit is exceeded, server applies freeze=buys option on all loopers and
+wallers in the exchange or product and alerts the operator.
+
+Example: tradebot configure risk limits -exchange coinbase -product BTC-USD max-unsold-value=5000 max-held-positions=10
+
+`
+}
+
+func (c *Limits) run(ctx context.Context, args []string) error {
+	exchange := strings.ToLower(c.exchange)
+	if len(exchange) == 0 {
+		return fmt.Errorf("exchange name cannot be empty")
+	}
+	if len(args) == 0 && !c.remove {
+		return fmt.Errorf("this command takes one or more KEY=VALUE arguments")
+	}
+
+	var releasePct *decimal.Decimal
+	limits := new(gobs.RiskLimits)
+	for _, arg := range args {
+		key, value, ok := strings.Cut(arg, "=")
+		if !ok {
+			return fmt.Errorf("invalid KEY=VALUE argument %q", arg)
+		}
+		switch key {
+		case "max-held-positions":
+			v, err := strconv.Atoi(value)
+			if err != nil || v < 0 {
+				return fmt.Errorf("invalid value in %q", arg)
+			}
+			limits.MaxHeldPositions = v
+		case "max-unsold-size", "max-unsold-value", "release-pct":
+			v, err := decimal.NewFromString(value)
+			if err != nil || v.IsNegative() {
+				return fmt.Errorf("invalid value in %q", arg)
+			}
+			switch key {
+			case "max-unsold-size":
+				if c.product == "" {
+					return fmt.Errorf("max-unsold-size is only supported for product specific limits")
+				}
+				limits.MaxUnsoldSize = v
+			case "max-unsold-value":
+				limits.MaxUnsoldValue = v
+			default:
+				if v.GreaterThan(decimal.NewFromInt(100)) {
+					return fmt.Errorf("release-pct cannot be above 100")
+				}
+				releasePct = &v
+			}
+		default:
+			return fmt.Errorf("unsupported limit name in %q", arg)
+		}
+	}
+
+	db, closer, err := c.DBFlags.GetDatabase(ctx)
+	if err != nil {
+		return fmt.Errorf("could not create database client: %w", err)
+	}
+	defer closer()
+
+	tx, err := db.NewTransaction(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	state, err := kvutil.Get[gobs.ServerState](ctx, tx, server.ServerStateKey)
+	if err != nil {
+		if !errors.Is(err, os.ErrNotExist) {
+			return err
+		}
+		state = new(gobs.ServerState)
+	}
+	if state.RiskLimits == nil {
+		state.RiskLimits = new(gobs.RiskLimitsConfig)
+	}
+	cfg := state.RiskLimits
+	if cfg.PerExchangeLimits == nil {
+		cfg.PerExchangeLimits = make(map[string]*gobs.RiskLimits)
+	}
+	if cfg.PerProductLimits == nil {
+		cfg.PerProductLimits = make(map[string]*gobs.RiskLimits)
+	}
+
+	limitsMap, key := cfg.PerExchangeLimits, exchange
+	if c.product != "" {
+		limitsMap, key = cfg.PerProductLimits, path.Join(exchange, c.product)
+	}
+	if c.remove {
+		delete(limitsMap, key)
+	} else {
+		old, ok := limitsMap[key]
+		if !ok {
+			old = new(gobs.RiskLimits)
+			limitsMap[key] = old
+		}
+		// Update only the limits specified on the command-line.
+		for _, arg := range args {
+			switch k, _, _ := strings.Cut(arg, "="); k {
+			case "max-held-positions":
+				old.MaxHeldPositions = limits.MaxHeldPositions
+			case "max-unsold-size":
+				old.MaxUnsoldSize = limits.MaxUnsoldSize
+			case "max-unsold-value":
+				old.MaxUnsoldValue = limits.MaxUnsoldValue
+			}
+		}
+	}
+	if releasePct != nil {
+		cfg.ReleasePct = *releasePct
+	}
+
+	if err := kvutil.Set(ctx, tx, server.ServerStateKey, state); err != nil {
+		return err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,112 @@
+// Copyright (c) 2025 BVK Chaitanya
+
+package subcmds
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"net/url"
+	"os"
+	"time"
+
+	"github.com/bvk/tradebot/api"
+	"github.com/bvk/tradebot/subcmds/cmdutil"
+	"github.com/visvasity/cli"
+)
+
+type Events struct {
+	cmdutil.ClientFlags
+
+	types    string
+	products string
+	json     bool
+}
+
+func (c *Events) Purpose() string {
+	return "Streams order, fill, job, balance and price events"
+}
+
+func (c *Events) Command() (string, *flag.FlagSet, cli.CmdFunc) {
+	fset := flag.NewFlagSet("events", flag.ContinueOnError)
+	c.ClientFlags.SetFlags(fset)
+	fset.StringVar(&c.types, "types", "", "comma separated event types (order, fill, job, balance, price)")
+	fset.StringVar(&c.products, "products", "", "comma separated exchange:product names to stream events for")
+	fset.BoolVar(&c.json, "json", false, "prints events in json format")
+	return "events", fset, cli.CmdFunc(c.run)
+}
+
+func (c *Events) Description() string {
+	return `
+
+Command "events" connects to the server-sent events endpoint of the trader and
+prints the events as they happen until it is interrupted. Order, fill, job and
+balance events are streamed by default. Price events must be requested
+explicitly with the -types flag and require one or more products.
+
+Products are specified in exchange:product form, for example:
+
+  tradebot events -types=fill,price -products=coinbase:BTC-USD
+
+`
+}
+
+func (c *Events) run(ctx context.Context, args []string) error {
+	if len(args) != 0 {
+		return fmt.Errorf("this command takes no arguments")
+	}
+	if _, err := api.ParseEventsQuery(c.types, c.products); err != nil {
+		return err
+	}
+
+	query := make(url.Values)
+	if len(c.types) > 0 {
+		query.Set("types", c.types)
+	}
+	if len(c.products) > 0 {
+		query.Set("products", c.products)
+	}
+
+	print := func(typ string, data []byte) error {
+		if c.json {
+			fmt.Fprintf(os.Stdout, "%s\n", data)
+			return nil
+		}
+		event := new(api.Event)
+		if err := json.Unmarshal(data, event); err != nil {
+			return fmt.Errorf("could not parse %q event: %w", typ, err)
+		}
+		fmt.Fprintf(os.Stdout, "%s %s\n", event.Time.Local().Format(time.DateTime), eventString(event))
+		return nil
+	}
+	if err := cmdutil.Stream(ctx, &c.ClientFlags, api.EventsPath, query, print); err != nil && !errors.Is(err, context.Canceled) {
+		return err
+	}
+	return nil
+}
+
+func eventString(e *api.Event) string {
+	switch {
+	case e.Order != nil:
+		return fmt.Sprintf("order %s:%s %s status=%s size=%s value=%s fee=%s done=%t", e.ExchangeName, e.ProductID, e.Order.ServerID, e.Order.Status, e.Order.ExecutedSize, e.Order.ExecutedValue, e.Order.ExecutedFee, e.Order.Done)
+	case e.Fill != nil:
+		return fmt.Sprintf("fill %s:%s %s size=%s value=%s fee=%s", e.ExchangeName, e.ProductID, e.Fill.ServerID, e.Fill.Size, e.Fill.Value, e.Fill.Fee)
+	case e.Job != nil:
+		name := e.Job.Name
+		if len(name) == 0 {
+			name = e.Job.UID
+		}
+		if len(e.Job.Reason) > 0 {
+			return fmt.Sprintf("job %s %s state=%s reason=%q", name, e.Job.Kind, e.Job.State, e.Job.Reason)
+		}
+		return fmt.Sprintf("job %s %s state=%s", name, e.Job.Kind, e.Job.State)
+	case e.Balance != nil:
+		return fmt.Sprintf("balance %s %s %s", e.ExchangeName, e.Balance.Currency, e.Balance.Amount)
+	case e.Price != nil:
+		return fmt.Sprintf("price %s:%s %s", e.ExchangeName, e.ProductID, e.Price.Price)
+	default:
+		return e.Type
+	}
+}
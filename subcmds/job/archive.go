@@ -0,0 +1,138 @@
+// Copyright (c) 2025 BVK Chaitanya
+
+package job
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/bvk/tradebot/api"
+	"github.com/bvk/tradebot/namer"
+	"github.com/bvk/tradebot/subcmds/cmdutil"
+	"github.com/visvasity/cli"
+)
+
+type Archive struct {
+	cmdutil.DBFlags
+
+	selector string
+}
+
+func (c *Archive) Command() (string, *flag.FlagSet, cli.CmdFunc) {
+	fset := new(flag.FlagSet)
+	c.DBFlags.SetFlags(fset)
+	fset.StringVar(&c.selector, "selector", "", selectorUsage)
+	return "archive", fset, cli.CmdFunc(c.run)
+}
+
+func (c *Archive) run(ctx context.Context, args []string) error {
+	if len(c.selector) != 0 {
+		if len(args) != 0 {
+			return fmt.Errorf("job argument cannot be used with the selector")
+		}
+		req := &api.JobArchiveRequest{
+			Selector: c.selector,
+		}
+		resp, err := cmdutil.Post[api.JobArchiveResponse](ctx, &c.ClientFlags, api.JobArchivePath, req)
+		if err != nil {
+			return err
+		}
+		return printResults(resp.Jobs)
+	}
+
+	if len(args) != 1 {
+		return fmt.Errorf("this command takes one (job-id) argument")
+	}
+	jobArg := args[0]
+
+	db, closer, err := c.DBFlags.GetDatabase(ctx)
+	if err != nil {
+		return fmt.Errorf("could not create database client: %w", err)
+	}
+	defer closer()
+
+	_, uid, _, err := namer.ResolveDB(ctx, db, jobArg)
+	if err != nil {
+		if !errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("could not resolve job argument %q: %w", jobArg, err)
+		}
+		uid = jobArg
+	}
+
+	req := &api.JobArchiveRequest{
+		UID: uid,
+	}
+	resp, err := cmdutil.Post[api.JobArchiveResponse](ctx, &c.ClientFlags, api.JobArchivePath, req)
+	if err != nil {
+		return err
+	}
+	jsdata, _ := json.MarshalIndent(resp, "", "  ")
+	fmt.Printf("%s\n", jsdata)
+	return nil
+}
+
+func (c *Archive) Purpose() string {
+	return "Moves finished jobs into the compressed archive"
+}
+
+func (c *Archive) Description() string {
+	return `
+Command "archive" moves a completed, canceled or failed job along with it's
+child jobs out of the main keyspaces into a compressed archive. A summary of
+the job is computed at the time of archival, so archived jobs continue to be
+included in the summary reports without loading their full data.
+
+Job names and event histories are retained for the archived jobs. Archived
+jobs can be moved back with the "restore" command.
+`
+}
+
+type Restore struct {
+	cmdutil.DBFlags
+}
+
+func (c *Restore) Command() (string, *flag.FlagSet, cli.CmdFunc) {
+	fset := new(flag.FlagSet)
+	c.DBFlags.SetFlags(fset)
+	return "restore", fset, cli.CmdFunc(c.run)
+}
+
+func (c *Restore) run(ctx context.Context, args []string) error {
+	if len(args) != 1 {
+		return fmt.Errorf("this command takes one (job-id) argument")
+	}
+	jobArg := args[0]
+
+	db, closer, err := c.DBFlags.GetDatabase(ctx)
+	if err != nil {
+		return fmt.Errorf("could not create database client: %w", err)
+	}
+	defer closer()
+
+	_, uid, _, err := namer.ResolveDB(ctx, db, jobArg)
+	if err != nil {
+		if !errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("could not resolve job argument %q: %w", jobArg, err)
+		}
+		uid = jobArg
+	}
+
+	req := &api.JobRestoreRequest{
+		UID: uid,
+	}
+	resp, err := cmdutil.Post[api.JobRestoreResponse](ctx, &c.ClientFlags, api.JobRestorePath, req)
+	if err != nil {
+		return err
+	}
+	jsdata, _ := json.MarshalIndent(resp, "", "  ")
+	fmt.Printf("%s\n", jsdata)
+	return nil
+}
+
+func (c *Restore) Purpose() string {
+	return "Moves an archived job back into the main keyspaces"
+}
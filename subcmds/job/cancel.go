@@ -18,9 +18,25 @@ import (
 
 type Cancel struct {
 	cmdutil.DBFlags
+
+	selector string
 }
 
 func (c *Cancel) run(ctx context.Context, args []string) error {
+	if len(c.selector) != 0 {
+		if len(args) != 0 {
+			return fmt.Errorf("job argument cannot be used with the selector")
+		}
+		req := &api.JobCancelRequest{
+			Selector: c.selector,
+		}
+		resp, err := cmdutil.Post[api.JobCancelResponse](ctx, &c.ClientFlags, api.JobCancelPath, req)
+		if err != nil {
+			return err
+		}
+		return printResults(resp.Jobs)
+	}
+
 	if len(args) != 1 {
 		return fmt.Errorf("this command takes one (job-id) argument")
 	}
@@ -55,6 +71,7 @@ func (c *Cancel) run(ctx context.Context, args []string) error {
 func (c *Cancel) Command() (string, *flag.FlagSet, cli.CmdFunc) {
 	fset := new(flag.FlagSet)
 	c.DBFlags.SetFlags(fset)
+	fset.StringVar(&c.selector, "selector", "", selectorUsage)
 	return "cancel", fset, cli.CmdFunc(c.run)
 }
 
@@ -0,0 +1,97 @@
+// Copyright (c) 2025 BVK Chaitanya
+
+package job
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/bvk/tradebot/api"
+	"github.com/bvk/tradebot/namer"
+	"github.com/bvk/tradebot/subcmds/cmdutil"
+	"github.com/shopspring/decimal"
+	"github.com/visvasity/cli"
+)
+
+type Clone struct {
+	cmdutil.DBFlags
+
+	name      string
+	productID string
+
+	priceShift float64
+	priceScale float64
+	sizeScale  float64
+}
+
+func (c *Clone) Command() (string, *flag.FlagSet, cli.CmdFunc) {
+	fset := new(flag.FlagSet)
+	c.DBFlags.SetFlags(fset)
+	fset.StringVar(&c.name, "name", "", "when non-empty, assigns the name to the new job")
+	fset.StringVar(&c.productID, "product", "", "when non-empty, uses this product for the new job")
+	fset.Float64Var(&c.priceShift, "price-shift", 0, "when non-zero, adds this amount to all prices")
+	fset.Float64Var(&c.priceScale, "price-scale", 0, "when non-zero, multiplies all prices by this factor (ex: 0.9 for 10% lower)")
+	fset.Float64Var(&c.sizeScale, "size-scale", 0, "when non-zero, multiplies all sizes by this factor")
+	return "clone", fset, cli.CmdFunc(c.run)
+}
+
+func (c *Clone) run(ctx context.Context, args []string) error {
+	if len(args) != 1 {
+		return fmt.Errorf("this command takes one (job-id) argument")
+	}
+	jobArg := args[0]
+
+	db, closer, err := c.DBFlags.GetDatabase(ctx)
+	if err != nil {
+		return fmt.Errorf("could not create database client: %w", err)
+	}
+	defer closer()
+
+	_, uid, _, err := namer.ResolveDB(ctx, db, jobArg)
+	if err != nil {
+		if !errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("could not resolve job argument %q: %w", jobArg, err)
+		}
+		uid = jobArg
+	}
+
+	req := &api.JobCloneRequest{
+		UID:        uid,
+		Name:       c.name,
+		ProductID:  c.productID,
+		PriceShift: decimal.NewFromFloat(c.priceShift),
+		PriceScale: decimal.NewFromFloat(c.priceScale),
+		SizeScale:  decimal.NewFromFloat(c.sizeScale),
+	}
+	if err := req.Check(); err != nil {
+		return err
+	}
+	resp, err := cmdutil.Post[api.JobCloneResponse](ctx, &c.ClientFlags, api.JobClonePath, req)
+	if err != nil {
+		return err
+	}
+	jsdata, _ := json.MarshalIndent(resp, "", "  ")
+	fmt.Printf("%s\n", jsdata)
+	return nil
+}
+
+func (c *Clone) Purpose() string {
+	return "Creates a new paused job from an existing job's definition"
+}
+
+func (c *Clone) Description() string {
+	return `
+Command "clone" creates a new job with the same exchange, product, buy/sell
+points and options of an existing limiter, looper or waller job. Product,
+prices and sizes of the new job can be changed with the flags. For example,
+"-price-scale 0.9" creates the same waller, but with all prices 10% lower.
+
+New job is validated like any other new job and is created in the paused
+state. Use the "resume" command to start it. Labels of the original job are
+copied to the new job.
+`
+}
@@ -0,0 +1,122 @@
+// Copyright (c) 2025 BVK Chaitanya
+
+package job
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/bvk/tradebot/api"
+	"github.com/bvk/tradebot/namer"
+	"github.com/bvk/tradebot/point"
+	"github.com/bvk/tradebot/subcmds/cmdutil"
+	"github.com/shopspring/decimal"
+	"github.com/visvasity/cli"
+)
+
+type FollowUp struct {
+	cmdutil.DBFlags
+
+	typename string
+	remove   bool
+
+	buySize         float64
+	buyPrice        float64
+	buyCancelOffset float64
+
+	sellSize         float64
+	sellPrice        float64
+	sellCancelOffset float64
+}
+
+func (c *FollowUp) Command() (string, *flag.FlagSet, cli.CmdFunc) {
+	fset := flag.NewFlagSet("follow-up", flag.ContinueOnError)
+	c.DBFlags.SetFlags(fset)
+	fset.StringVar(&c.typename, "type", "limiter", "follow-up job type; must be one of limiter or looper")
+	fset.BoolVar(&c.remove, "remove", false, "when true, removes the existing follow-up declaration")
+	fset.Float64Var(&c.buySize, "buy-size", 0, "buy size for the follow-up job; zero uses the parent job's filled size")
+	fset.Float64Var(&c.buyPrice, "buy-price", 0, "limit buy price for the follow-up job")
+	fset.Float64Var(&c.buyCancelOffset, "buy-cancel-offset", 0, "buy cancel price offset for the follow-up job")
+	fset.Float64Var(&c.sellSize, "sell-size", 0, "sell size for the follow-up job; zero uses the parent job's filled size")
+	fset.Float64Var(&c.sellPrice, "sell-price", 0, "limit sell price for the follow-up job")
+	fset.Float64Var(&c.sellCancelOffset, "sell-cancel-offset", 0, "sell cancel price offset for the follow-up job")
+	return "follow-up", fset, cli.CmdFunc(c.run)
+}
+
+func (c *FollowUp) Purpose() string {
+	return "Declares a job to start when a job completes"
+}
+
+func (c *FollowUp) Description() string {
+	return `
+
+Command "follow-up" declares a limiter or looper job that is created and
+started automatically when the input job completes successfully. Follow-up job
+is created in the same database transaction that marks the parent job as
+completed, so chains survive restarts.
+
+Buy and sell sizes default to the base size filled by the parent job, so that,
+for example, a sell limiter can be sized to the amount bought by a buy
+limiter.
+
+`
+}
+
+func (c *FollowUp) run(ctx context.Context, args []string) error {
+	if len(args) != 1 {
+		return fmt.Errorf("this command takes one (job-id) argument")
+	}
+	jobArg := args[0]
+
+	req := &api.JobFollowUpRequest{
+		Typename: c.typename,
+		Remove:   c.remove,
+	}
+	if c.buyPrice > 0 {
+		req.Buy = &point.Point{
+			Size:   decimal.NewFromFloat(c.buySize),
+			Price:  decimal.NewFromFloat(c.buyPrice),
+			Cancel: decimal.NewFromFloat(c.buyPrice + c.buyCancelOffset),
+		}
+	}
+	if c.sellPrice > 0 {
+		req.Sell = &point.Point{
+			Size:   decimal.NewFromFloat(c.sellSize),
+			Price:  decimal.NewFromFloat(c.sellPrice),
+			Cancel: decimal.NewFromFloat(c.sellPrice - c.sellCancelOffset),
+		}
+	}
+
+	db, closer, err := c.DBFlags.GetDatabase(ctx)
+	if err != nil {
+		return fmt.Errorf("could not create database client: %w", err)
+	}
+	defer closer()
+
+	_, uid, _, err := namer.ResolveDB(ctx, db, jobArg)
+	if err != nil {
+		if !errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("could not resolve job argument %q: %w", jobArg, err)
+		}
+		uid = jobArg
+	}
+	req.UID = uid
+	req.Typename = strings.ToLower(req.Typename)
+
+	if err := req.Check(); err != nil {
+		return err
+	}
+
+	resp, err := cmdutil.Post[api.JobFollowUpResponse](ctx, &c.ClientFlags, api.JobFollowUpPath, req)
+	if err != nil {
+		return err
+	}
+	jsdata, _ := json.MarshalIndent(resp, "", "  ")
+	fmt.Printf("%s\n", jsdata)
+	return nil
+}
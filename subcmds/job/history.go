@@ -0,0 +1,78 @@
+// Copyright (c) 2025 BVK Chaitanya
+
+package job
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/bvk/tradebot/api"
+	"github.com/bvk/tradebot/namer"
+	"github.com/bvk/tradebot/subcmds/cmdutil"
+	"github.com/visvasity/cli"
+)
+
+type History struct {
+	cmdutil.DBFlags
+}
+
+func (c *History) Command() (string, *flag.FlagSet, cli.CmdFunc) {
+	fset := new(flag.FlagSet)
+	c.DBFlags.SetFlags(fset)
+	return "history", fset, cli.CmdFunc(c.run)
+}
+
+func (c *History) run(ctx context.Context, args []string) error {
+	if len(args) != 1 {
+		return fmt.Errorf("this command takes one (job-id) argument")
+	}
+	jobArg := args[0]
+
+	db, closer, err := c.DBFlags.GetDatabase(ctx)
+	if err != nil {
+		return fmt.Errorf("could not create database client: %w", err)
+	}
+	defer closer()
+
+	_, uid, _, err := namer.ResolveDB(ctx, db, jobArg)
+	if err != nil {
+		if !errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("could not resolve job argument %q: %w", jobArg, err)
+		}
+		uid = jobArg
+	}
+
+	req := &api.JobHistoryRequest{
+		UID: uid,
+	}
+	resp, err := cmdutil.Post[api.JobHistoryResponse](ctx, &c.ClientFlags, api.JobHistoryPath, req)
+	if err != nil {
+		return err
+	}
+
+	tw := tabwriter.NewWriter(os.Stdout, 0, 0, 1, ' ', 0)
+	fmt.Fprintf(tw, "Time\tEvent\tState\tReason\t\n")
+	for _, e := range resp.Events {
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t\n", e.Time.Local().Format(time.DateTime), e.Kind, e.State, e.Reason)
+	}
+	tw.Flush()
+	return nil
+}
+
+func (c *History) Purpose() string {
+	return "Prints the state transitions and other events of a trading job"
+}
+
+func (c *History) Description() string {
+	return `
+Command "history" prints the events recorded for a trading job in the time
+order. Events are recorded when a job is created, resumed, paused, canceled,
+completed or failed, when it's options are changed and when it is renamed.
+Failed events include the error returned by the job.
+`
+}
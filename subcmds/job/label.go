@@ -0,0 +1,101 @@
+// Copyright (c) 2025 BVK Chaitanya
+
+package job
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/bvk/tradebot/api"
+	"github.com/bvk/tradebot/job"
+	"github.com/bvk/tradebot/namer"
+	"github.com/bvk/tradebot/subcmds/cmdutil"
+	"github.com/visvasity/cli"
+)
+
+type Label struct {
+	cmdutil.DBFlags
+
+	remove string
+}
+
+func (c *Label) Command() (string, *flag.FlagSet, cli.CmdFunc) {
+	fset := flag.NewFlagSet("label", flag.ContinueOnError)
+	c.DBFlags.SetFlags(fset)
+	fset.StringVar(&c.remove, "remove", "", "comma separated label keys to remove from the job")
+	return "label", fset, cli.CmdFunc(c.run)
+}
+
+func (c *Label) Purpose() string {
+	return "Adds, updates or removes labels on a trading job"
+}
+
+func (c *Label) Description() string {
+	return `
+
+Command "label" updates the user-defined labels on a job. Labels are key=value
+pairs that can be used with the -selector flag of the job commands to operate
+on multiple jobs at once. For example,
+
+    tradebot job label my-waller strategy=grid,owner=alice
+    tradebot job pause -selector product=BTC-USD,strategy=grid
+
+Labels uid, name, type, state, product and exchange are derived from the job
+and cannot be set by the users, but they can be used in the selectors.
+
+`
+}
+
+func (c *Label) run(ctx context.Context, args []string) error {
+	if len(args) != 1 && len(args) != 2 {
+		return fmt.Errorf("this command takes job-id and optional key=value,... arguments")
+	}
+	jobArg := args[0]
+
+	req := new(api.JobLabelRequest)
+	if len(args) == 2 {
+		labels, err := job.ParseLabels(args[1])
+		if err != nil {
+			return err
+		}
+		if err := job.CheckLabels(labels); err != nil {
+			return err
+		}
+		req.Labels = labels
+	}
+	if len(c.remove) != 0 {
+		for _, k := range strings.Split(c.remove, ",") {
+			req.Remove = append(req.Remove, strings.TrimSpace(k))
+		}
+	}
+
+	db, closer, err := c.DBFlags.GetDatabase(ctx)
+	if err != nil {
+		return fmt.Errorf("could not create database client: %w", err)
+	}
+	defer closer()
+
+	_, uid, _, err := namer.ResolveDB(ctx, db, jobArg)
+	if err != nil {
+		if !errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("could not resolve job argument %q: %w", jobArg, err)
+		}
+		uid = jobArg
+	}
+	req.UID = uid
+
+	if err := req.Check(); err != nil {
+		return err
+	}
+
+	resp, err := cmdutil.Post[api.JobLabelResponse](ctx, &c.ClientFlags, api.JobLabelPath, req)
+	if err != nil {
+		return err
+	}
+	fmt.Println(job.LabelsString(resp.Labels))
+	return nil
+}
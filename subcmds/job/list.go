@@ -10,17 +10,21 @@ import (
 	"text/tabwriter"
 
 	"github.com/bvk/tradebot/api"
+	"github.com/bvk/tradebot/job"
 	"github.com/bvk/tradebot/subcmds/cmdutil"
 	"github.com/visvasity/cli"
 )
 
 type List struct {
 	cmdutil.ClientFlags
+
+	selector string
 }
 
 func (c *List) Command() (string, *flag.FlagSet, cli.CmdFunc) {
 	fset := new(flag.FlagSet)
 	c.ClientFlags.SetFlags(fset)
+	fset.StringVar(&c.selector, "selector", "", "when non-empty, lists only the jobs matching the key=value,... label selector")
 	return "list", fset, cli.CmdFunc(c.run)
 }
 
@@ -29,16 +33,25 @@ func (c *List) run(ctx context.Context, args []string) error {
 		return fmt.Errorf("this command takes no arguments")
 	}
 
-	req := &api.JobListRequest{}
+	req := &api.JobListRequest{
+		Selector: c.selector,
+	}
 	resp, err := cmdutil.Post[api.JobListResponse](ctx, &c.ClientFlags, api.JobListPath, req)
 	if err != nil {
 		return err
 	}
 
 	tw := tabwriter.NewWriter(os.Stdout, 0, 0, 1, ' ', tabwriter.AlignRight)
-	fmt.Fprintf(tw, "Name\tUID\tType\tStatus\t\n")
-	for _, job := range resp.Jobs {
-		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t\n", job.Name, job.UID, job.Type, job.State)
+	fmt.Fprintf(tw, "Name\tUID\tType\tStatus\tTrigger\tDistance\tLabels\t\n")
+	for _, item := range resp.Jobs {
+		state := item.State
+		if item.Armed && state == "RUNNING" {
+			state = "ARMED"
+		}
+		if !item.RestartAt.IsZero() {
+			state = "FAILED/RESTARTING"
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%s\t%s\t\n", item.Name, item.UID, item.Type, state, item.Trigger, item.TriggerDistance, job.LabelsString(item.Labels))
 	}
 	tw.Flush()
 	return nil
@@ -18,15 +18,32 @@ import (
 
 type Pause struct {
 	cmdutil.DBFlags
+
+	selector string
 }
 
 func (c *Pause) Command() (string, *flag.FlagSet, cli.CmdFunc) {
 	fset := new(flag.FlagSet)
 	c.DBFlags.SetFlags(fset)
+	fset.StringVar(&c.selector, "selector", "", selectorUsage)
 	return "pause", fset, cli.CmdFunc(c.run)
 }
 
 func (c *Pause) run(ctx context.Context, args []string) error {
+	if len(c.selector) != 0 {
+		if len(args) != 0 {
+			return fmt.Errorf("job argument cannot be used with the selector")
+		}
+		req := &api.JobPauseRequest{
+			Selector: c.selector,
+		}
+		resp, err := cmdutil.Post[api.JobPauseResponse](ctx, &c.ClientFlags, api.JobPausePath, req)
+		if err != nil {
+			return err
+		}
+		return printResults(resp.Jobs)
+	}
+
 	if len(args) != 1 {
 		return fmt.Errorf("this command takes one (job-id) argument")
 	}
@@ -0,0 +1,95 @@
+// Copyright (c) 2025 BVK Chaitanya
+
+package job
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/bvk/tradebot/api"
+	"github.com/bvk/tradebot/namer"
+	"github.com/bvk/tradebot/subcmds/cmdutil"
+	"github.com/visvasity/cli"
+)
+
+type RestartPolicy struct {
+	cmdutil.DBFlags
+
+	policy string
+	remove bool
+}
+
+func (c *RestartPolicy) Command() (string, *flag.FlagSet, cli.CmdFunc) {
+	fset := flag.NewFlagSet("restart-policy", flag.ContinueOnError)
+	c.DBFlags.SetFlags(fset)
+	fset.StringVar(&c.policy, "policy", "", "restart policy for the job; must be never or on-failure with optional parameters")
+	fset.BoolVar(&c.remove, "remove", false, "when true, removes the job's policy so that default policy for the job type is used")
+	return "restart-policy", fset, cli.CmdFunc(c.run)
+}
+
+func (c *RestartPolicy) Purpose() string {
+	return "Updates the automatic restart policy for a failed job"
+}
+
+func (c *RestartPolicy) Description() string {
+	return `
+
+Command "restart-policy" updates the policy that determines if a job is
+restarted automatically when it fails. Policy must be "never" or "on-failure"
+with optional parameters as in the following example:
+
+    on-failure:max-attempts=5,window=1h,backoff=1m,max-backoff=30m
+
+Failed jobs are restarted after the backoff duration, which doubles with every
+attempt till the max-backoff. When max-attempts restarts are already made
+within the window duration, job is left in the FAILED state and a message is
+sent to the user.
+
+Job specific policy overrides the default policy for the job type configured
+with the -restart-policy flag of the "run" command.
+
+`
+}
+
+func (c *RestartPolicy) run(ctx context.Context, args []string) error {
+	if len(args) != 1 {
+		return fmt.Errorf("this command takes one (job-id) argument")
+	}
+	jobArg := args[0]
+
+	req := &api.JobRestartPolicyRequest{
+		Policy: c.policy,
+		Remove: c.remove,
+	}
+
+	db, closer, err := c.DBFlags.GetDatabase(ctx)
+	if err != nil {
+		return fmt.Errorf("could not create database client: %w", err)
+	}
+	defer closer()
+
+	_, uid, _, err := namer.ResolveDB(ctx, db, jobArg)
+	if err != nil {
+		if !errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("could not resolve job argument %q: %w", jobArg, err)
+		}
+		uid = jobArg
+	}
+	req.UID = uid
+
+	if err := req.Check(); err != nil {
+		return err
+	}
+
+	resp, err := cmdutil.Post[api.JobRestartPolicyResponse](ctx, &c.ClientFlags, api.JobRestartPolicyPath, req)
+	if err != nil {
+		return err
+	}
+	jsdata, _ := json.MarshalIndent(resp, "", "  ")
+	fmt.Printf("%s\n", jsdata)
+	return nil
+}
@@ -18,15 +18,32 @@ import (
 
 type Resume struct {
 	cmdutil.DBFlags
+
+	selector string
 }
 
 func (c *Resume) Command() (string, *flag.FlagSet, cli.CmdFunc) {
 	fset := new(flag.FlagSet)
 	c.DBFlags.SetFlags(fset)
+	fset.StringVar(&c.selector, "selector", "", selectorUsage)
 	return "resume", fset, cli.CmdFunc(c.run)
 }
 
 func (c *Resume) run(ctx context.Context, args []string) error {
+	if len(c.selector) != 0 {
+		if len(args) != 0 {
+			return fmt.Errorf("job argument cannot be used with the selector")
+		}
+		req := &api.JobResumeRequest{
+			Selector: c.selector,
+		}
+		resp, err := cmdutil.Post[api.JobResumeResponse](ctx, &c.ClientFlags, api.JobResumePath, req)
+		if err != nil {
+			return err
+		}
+		return printResults(resp.Jobs)
+	}
+
 	if len(args) != 1 {
 		return fmt.Errorf("this command takes one (job-id) argument")
 	}
@@ -0,0 +1,34 @@
+// Copyright (c) 2025 BVK Chaitanya
+
+package job
+
+import (
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/bvk/tradebot/api"
+)
+
+const selectorUsage = "when non-empty, applies to all jobs matching the key=value,... label selector (ex: product=BTC-USD,type=waller)"
+
+// printResults prints the per-job results of an operation on the jobs matched
+// by a selector. It returns a non-nil error if operation has failed on any of
+// the jobs.
+func printResults(results []*api.JobResult) error {
+	nfailed := 0
+	tw := tabwriter.NewWriter(os.Stdout, 0, 0, 1, ' ', 0)
+	fmt.Fprintf(tw, "Name\tUID\tState\tError\t\n")
+	for _, r := range results {
+		if r.Error != "" {
+			nfailed++
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t\n", r.Name, r.UID, r.FinalState, r.Error)
+	}
+	tw.Flush()
+
+	if nfailed > 0 {
+		return fmt.Errorf("operation has failed on %d of %d jobs", nfailed, len(results))
+	}
+	return nil
+}
@@ -19,11 +19,14 @@ import (
 
 type SetOption struct {
 	cmdutil.DBFlags
+
+	selector string
 }
 
 func (c *SetOption) Command() (string, *flag.FlagSet, cli.CmdFunc) {
 	fset := flag.NewFlagSet("set-option", flag.ContinueOnError)
 	c.DBFlags.SetFlags(fset)
+	fset.StringVar(&c.selector, "selector", "", selectorUsage)
 	return "set-option", fset, cli.CmdFunc(c.run)
 }
 
@@ -31,7 +34,39 @@ func (c *SetOption) Purpose() string {
 	return "Update a trading job options"
 }
 
+func (c *SetOption) Description() string {
+	return `
+
+Command "set-option" updates an option on a job that is not running. When
+-selector flag is used, option is updated on all jobs matching the label
+selector, in which case, the only argument is the opt=value pair. Option is
+updated on all matching jobs or none of them, so all matching jobs must be
+paused and must accept the option value.
+
+`
+}
+
 func (c *SetOption) run(ctx context.Context, args []string) error {
+	if len(c.selector) != 0 {
+		if len(args) != 1 {
+			return fmt.Errorf("this command takes one (opt=value) argument with the selector")
+		}
+		optKey, optVal, _ := strings.Cut(args[0], "=")
+		if optKey == "" || optVal == "" {
+			return fmt.Errorf("option argument must be in key=value form")
+		}
+		req := &api.JobSetOptionRequest{
+			Selector:    c.selector,
+			OptionKey:   optKey,
+			OptionValue: optVal,
+		}
+		resp, err := cmdutil.Post[api.JobSetOptionResponse](ctx, &c.ClientFlags, api.JobSetOptionPath, req)
+		if err != nil {
+			return err
+		}
+		return printResults(resp.Jobs)
+	}
+
 	if len(args) != 2 {
 		return fmt.Errorf("this command takes two (job-id, opt=value) arguments")
 	}
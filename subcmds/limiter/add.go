@@ -7,16 +7,25 @@ import (
 	"encoding/json"
 	"flag"
 	"fmt"
+	"slices"
+	"strings"
+	"time"
 
 	"github.com/bvk/tradebot/api"
+	"github.com/bvk/tradebot/gobs"
+	"github.com/bvk/tradebot/limiter"
 	"github.com/bvk/tradebot/point"
 	"github.com/bvk/tradebot/subcmds/cmdutil"
+	"github.com/bvk/tradebot/trigger"
 	"github.com/shopspring/decimal"
 	"github.com/visvasity/cli"
 )
 
 type Add struct {
 	cmdutil.ClientFlags
+	cmdutil.LabelFlags
+
+	dryRun bool
 
 	product  string
 	exchange string
@@ -25,6 +34,18 @@ type Add struct {
 	size         float64
 	price        float64
 	cancelOffset float64
+
+	trigger string
+
+	execution    string
+	sizeLimit    float64
+	twapInterval time.Duration
+
+	expireAfter     time.Duration
+	expiryAction    string
+	repriceStepPct  float64
+	repriceInterval time.Duration
+	aggressivePct   float64
 }
 
 func (c *Add) check() error {
@@ -57,6 +78,18 @@ func (c *Add) check() error {
 	if cancelPrice <= 0 {
 		return fmt.Errorf("cancel-price cannot be zero or negative")
 	}
+	if c.execution != "" && !slices.Contains(limiter.ExecutionModes, c.execution) {
+		return fmt.Errorf("execution must be one of %s", strings.Join(limiter.ExecutionModes, ", "))
+	}
+	if c.execution != "" && c.execution != "limit" && c.sizeLimit <= 0 {
+		return fmt.Errorf("execution mode %q requires a positive size-limit", c.execution)
+	}
+	if c.expireAfter < 0 {
+		return fmt.Errorf("expire-after duration cannot be negative")
+	}
+	if c.expireAfter > 0 && !slices.Contains(limiter.ExpiryActions, c.expiryAction) {
+		return fmt.Errorf("expiry-action must be one of %s", strings.Join(limiter.ExpiryActions, ", "))
+	}
 	return nil
 }
 
@@ -68,6 +101,20 @@ func (c *Add) Run(ctx context.Context, args []string) error {
 		return err
 	}
 
+	labels, err := c.LabelFlags.Labels()
+	if err != nil {
+		return err
+	}
+
+	var armed *trigger.Trigger
+	if c.trigger != "" {
+		v, err := trigger.Parse(c.trigger)
+		if err != nil {
+			return err
+		}
+		armed = v
+	}
+
 	var cancelPrice float64
 	if c.side == "BUY" {
 		cancelPrice = c.price + c.cancelOffset
@@ -83,11 +130,35 @@ func (c *Add) Run(ctx context.Context, args []string) error {
 			Price:  decimal.NewFromFloat(c.price),
 			Cancel: decimal.NewFromFloat(cancelPrice),
 		},
+		Trigger: armed,
+		Labels:  labels,
+		DryRun:  c.dryRun,
+	}
+	if c.execution != "" && c.execution != "limit" {
+		req.Options = map[string]string{
+			"execution":  c.execution,
+			"size-limit": decimal.NewFromFloat(c.sizeLimit).String(),
+		}
+		if c.twapInterval != 0 {
+			req.Options["twap-interval"] = c.twapInterval.String()
+		}
+	}
+	if c.expireAfter > 0 {
+		req.Expiry = &gobs.LimiterExpiry{
+			Deadline:        time.Now().Add(c.expireAfter),
+			Action:          c.expiryAction,
+			RepriceStepPct:  decimal.NewFromFloat(c.repriceStepPct),
+			RepriceInterval: c.repriceInterval,
+			AggressivePct:   decimal.NewFromFloat(c.aggressivePct),
+		}
 	}
 	resp, err := cmdutil.Post[api.LimitResponse](ctx, &c.ClientFlags, api.LimitPath, req)
 	if err != nil {
 		return err
 	}
+	if c.dryRun {
+		return cmdutil.PrintDryRun(resp.DryRun)
+	}
 	jsdata, _ := json.MarshalIndent(resp, "", "  ")
 	fmt.Printf("%s\n", jsdata)
 	return nil
@@ -96,12 +167,23 @@ func (c *Add) Run(ctx context.Context, args []string) error {
 func (c *Add) Command() (string, *flag.FlagSet, cli.CmdFunc) {
 	fset := flag.NewFlagSet("add", flag.ContinueOnError)
 	c.ClientFlags.SetFlags(fset)
+	c.LabelFlags.SetFlags(fset)
 	fset.Float64Var(&c.size, "size", 0, "asset size for the trade")
+	fset.BoolVar(&c.dryRun, "dry-run", false, "when true, prints the exchange effects report from the server without creating the job")
 	fset.Float64Var(&c.price, "price", 0, "limit price for the trade")
 	fset.StringVar(&c.side, "side", "", "must be one of BUY or SELL")
 	fset.Float64Var(&c.cancelOffset, "cancel-offset", 0, "cancel-price offset for the trade")
 	fset.StringVar(&c.product, "product", "", "product id for the trade")
 	fset.StringVar(&c.exchange, "exchange", "coinbase", "exchange name for the product")
+	fset.StringVar(&c.execution, "execution", "limit", "one of limit|iceberg|twap execution modes")
+	fset.Float64Var(&c.sizeLimit, "size-limit", 0, "approximate size of the child orders for iceberg and twap execution modes")
+	fset.DurationVar(&c.twapInterval, "twap-interval", 0, "average delay between the child orders in twap execution mode (default 5m)")
+	fset.DurationVar(&c.expireAfter, "expire-after", 0, "when non-zero, the fallback action is taken if the job is not complete within this duration")
+	fset.StringVar(&c.expiryAction, "expiry-action", "complete", "one of complete|reprice|aggressive fallback actions after the expiry")
+	fset.Float64Var(&c.repriceStepPct, "reprice-step-pct", 0.1, "percentage of the limit price moved toward the ticker in every reprice step")
+	fset.DurationVar(&c.repriceInterval, "reprice-interval", time.Minute, "delay between the reprice steps (also the timeout for aggressive orders)")
+	fset.Float64Var(&c.aggressivePct, "aggressive-pct", 0.5, "percentage above the ask (or below the bid) for aggressive orders")
+	fset.StringVar(&c.trigger, "trigger", "", "when non-empty, job is armed till the price trigger (ex: below:25000, stay-above:30000:15m, ma-above:30000:1h) fires")
 	return "add", fset, cli.CmdFunc(c.Run)
 }
 
@@ -123,5 +205,21 @@ the limit-price respectively. Buy orders are canceled when the ticker price
 moves above the cancel-price and sell orders are canceled when the ticker price
 moves below the cancel-price.
 
+Large orders can be executed in multiple smaller child orders with the
+"iceberg" or "twap" execution modes. Iceberg mode keeps only a randomized clip
+of about size-limit on the book and places the next clip after the current one
+is filled. Twap mode additionally waits for about twap-interval between the
+clips.
+
+Jobs can be time-bounded with the expire-after flag. When the job is not
+complete by the deadline, active order is canceled and one of the following
+fallback actions is taken:
+
+  - complete: Job is completed with the partially filled size (if any)
+  - reprice: Limit price is moved toward the ticker by reprice-step-pct of the
+    original price every reprice-interval, until the remaining size is filled
+  - aggressive: Remaining size is placed at aggressive-pct above the ask price
+    (for buys) or below the bid price (for sells) so that it fills immediately
+
 `
 }
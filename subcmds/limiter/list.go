@@ -8,12 +8,19 @@ import (
 	"flag"
 	"fmt"
 	"io"
+	"os"
 	"path"
 	"strings"
+	"text/tabwriter"
+	"time"
 
+	"github.com/bvk/tradebot/gobs"
+	"github.com/bvk/tradebot/kvutil"
 	"github.com/bvk/tradebot/limiter"
+	"github.com/bvk/tradebot/point"
 	"github.com/bvk/tradebot/subcmds/cmdutil"
 	"github.com/bvkgo/kv"
+	"github.com/shopspring/decimal"
 	"github.com/visvasity/cli"
 )
 
@@ -36,13 +43,40 @@ func (c *List) Run(ctx context.Context, args []string) error {
 		}
 		defer kv.Close(it)
 
+		tw := tabwriter.NewWriter(os.Stdout, 0, 0, 1, ' ', 0)
+		fmt.Fprintf(tw, "Key\tPoint\tFilled\tDeadline\tAction\tStatus\t\n")
 		for k, _, err := it.Fetch(ctx, false); err == nil; k, _, err = it.Fetch(ctx, true) {
 			if !strings.HasPrefix(k, keyspace) {
 				break
 			}
 
-			fmt.Println(k)
+			gv, err := kvutil.Get[gobs.LimiterState](ctx, r, k)
+			if err != nil || gv.V2 == nil {
+				fmt.Fprintf(tw, "%s\t-\t-\t-\t-\t-\t\n", k)
+				continue
+			}
+			var filled decimal.Decimal
+			for _, order := range gv.V2.ServerIDOrderMap {
+				filled = filled.Add(order.FilledSize)
+			}
+			deadline, action, status := "-", "-", "-"
+			if e := gv.V2.Expiry; e != nil {
+				deadline = e.Deadline.Format(time.DateTime)
+				action = e.Action
+				status = "pending"
+				if !e.ExpiredAt.IsZero() {
+					status = "expired"
+					if e.NumReprices > 0 {
+						status = fmt.Sprintf("expired (%d reprices to %s)", e.NumReprices, e.Price)
+					} else if e.Price.IsPositive() {
+						status = fmt.Sprintf("expired (at %s)", e.Price)
+					}
+				}
+			}
+			p := point.Point(gv.V2.TradePoint)
+			fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%s\t\n", k, p, filled, deadline, action, status)
 		}
+		tw.Flush()
 
 		if _, _, err := it.Fetch(ctx, false); err != nil && !errors.Is(err, io.EOF) {
 			return err
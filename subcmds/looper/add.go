@@ -11,12 +11,15 @@ import (
 	"github.com/bvk/tradebot/api"
 	"github.com/bvk/tradebot/point"
 	"github.com/bvk/tradebot/subcmds/cmdutil"
+	"github.com/bvk/tradebot/trigger"
 	"github.com/shopspring/decimal"
 	"github.com/visvasity/cli"
 )
 
 type Add struct {
 	cmdutil.ClientFlags
+	cmdutil.ExitFlags
+	cmdutil.LabelFlags
 
 	product  string
 	exchange string
@@ -30,9 +33,15 @@ type Add struct {
 	sellCancelOffset float64
 
 	paused bool
+	dryRun bool
+
+	trigger string
 }
 
 func (c *Add) check() error {
+	if err := c.ExitFlags.Check(); err != nil {
+		return err
+	}
 	if len(c.product) == 0 {
 		return fmt.Errorf("product name cannot be empty")
 	}
@@ -68,6 +77,20 @@ func (c *Add) Run(ctx context.Context, args []string) error {
 		return err
 	}
 
+	labels, err := c.LabelFlags.Labels()
+	if err != nil {
+		return err
+	}
+
+	var armed *trigger.Trigger
+	if c.trigger != "" {
+		v, err := trigger.Parse(c.trigger)
+		if err != nil {
+			return err
+		}
+		armed = v
+	}
+
 	req := &api.LoopRequest{
 		ProductID:    c.product,
 		ExchangeName: c.exchange,
@@ -81,12 +104,19 @@ func (c *Add) Run(ctx context.Context, args []string) error {
 			Price:  decimal.NewFromFloat(c.sellPrice),
 			Cancel: decimal.NewFromFloat(c.sellPrice - c.sellCancelOffset),
 		},
-		Pause: c.paused,
+		Pause:   c.paused,
+		Trigger: armed,
+		Options: c.ExitFlags.Options(),
+		Labels:  labels,
+		DryRun:  c.dryRun,
 	}
 	resp, err := cmdutil.Post[api.LoopResponse](ctx, &c.ClientFlags, api.LoopPath, req)
 	if err != nil {
 		return err
 	}
+	if c.dryRun {
+		return cmdutil.PrintDryRun(resp.DryRun)
+	}
 	jsdata, _ := json.MarshalIndent(resp, "", "  ")
 	fmt.Printf("%s\n", jsdata)
 	return nil
@@ -95,6 +125,8 @@ func (c *Add) Run(ctx context.Context, args []string) error {
 func (c *Add) Command() (string, *flag.FlagSet, cli.CmdFunc) {
 	fset := flag.NewFlagSet("add", flag.ContinueOnError)
 	c.ClientFlags.SetFlags(fset)
+	c.ExitFlags.SetFlags(fset)
+	c.LabelFlags.SetFlags(fset)
 	fset.StringVar(&c.product, "product", "", "product id for the trade")
 	fset.StringVar(&c.exchange, "exchange", "coinbase", "exchange name for the product")
 	fset.Float64Var(&c.buySize, "buy-size", 0, "buy-size for the trade")
@@ -104,6 +136,8 @@ func (c *Add) Command() (string, *flag.FlagSet, cli.CmdFunc) {
 	fset.Float64Var(&c.sellPrice, "sell-price", 0, "limit sell-price for the trade")
 	fset.Float64Var(&c.sellCancelOffset, "sell-cancel-offset", 0, "sell-cancel price offset for the trade")
 	fset.BoolVar(&c.paused, "paused", false, "When true, job is created as paused and should be resumed manually")
+	fset.BoolVar(&c.dryRun, "dry-run", false, "when true, prints the exchange effects report from the server without creating the job")
+	fset.StringVar(&c.trigger, "trigger", "", "when non-empty, job is armed till the price trigger (ex: below:25000, stay-above:30000:15m, ma-above:30000:1h) fires")
 	return "add", fset, cli.CmdFunc(c.Run)
 }
 
@@ -120,5 +154,11 @@ created. Price point for the limit-sell must be above the limit-buy price point
 so that a positive profit can be secured. Asset size for the sell orders can be
 lower than the buy-size, but it cannot be greater than the buy-size.
 
+Loops run indefinitely by default. With the max-cycles, profit-target or
+end-date flags, job is completed when the number of buy-sell cycles, the
+realized profit or the end date is reached. Loops complete only before
+starting a new buy, so that held inventory is always sold first. A buy that
+hasn't started filling is abandoned at the end date.
+
 `
 }
@@ -12,6 +12,7 @@ import (
 	"regexp"
 	"slices"
 	"strings"
+	"time"
 
 	"github.com/bvk/tradebot/gobs"
 	"github.com/bvk/tradebot/kvutil"
@@ -45,7 +46,7 @@ func (c *List) Run(ctx context.Context, args []string) error {
 		keyRe = re
 	}
 
-	dataTypes := []string{"state", "status"}
+	dataTypes := []string{"state", "status", "progress"}
 	if !slices.Contains(dataTypes, c.dataType) {
 		return fmt.Errorf("invalid data-type %q", c.dataType)
 	}
@@ -86,6 +87,13 @@ func (c *List) Run(ctx context.Context, args []string) error {
 				return nil
 			}
 			value = status
+		case "progress":
+			uid := strings.TrimPrefix(k, looper.DefaultKeyspace)
+			t, err := looper.Load(ctx, uid, r)
+			if err != nil {
+				return fmt.Errorf("could not load looper instance at key %q: %w", k, err)
+			}
+			value = t.Progress(time.Now())
 		}
 
 		if tmpl == nil {
@@ -112,7 +120,7 @@ func (c *List) Command() (string, *flag.FlagSet, cli.CmdFunc) {
 	fset := flag.NewFlagSet("list", flag.ContinueOnError)
 	c.DBFlags.SetFlags(fset)
 	fset.StringVar(&c.keyRe, "key-regexp", "", "regular expression to pick keys")
-	fset.StringVar(&c.dataType, "data-type", "state", "one of state|status")
+	fset.StringVar(&c.dataType, "data-type", "state", "one of state|status|progress")
 	fset.StringVar(&c.printTemplate, "print-template", "", "text/template to print the value")
 	return "list", fset, cli.CmdFunc(c.Run)
 }
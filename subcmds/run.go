@@ -4,6 +4,7 @@ package subcmds
 
 import (
 	"context"
+	"crypto/tls"
 	"errors"
 	"flag"
 	"fmt"
@@ -22,6 +23,7 @@ import (
 	"syscall"
 	"time"
 
+	"github.com/bvk/tradebot/auth"
 	"github.com/bvk/tradebot/ctxutil"
 	"github.com/bvk/tradebot/daemonize"
 	"github.com/bvk/tradebot/httputil"
@@ -56,6 +58,8 @@ type Run struct {
 	noFetchCandles       bool
 	maxFetchTimeLatency  time.Duration
 	maxHttpClientTimeout time.Duration
+	budgetPolicy         string
+	restartPolicies      map[string]string
 
 	secretsPath string
 	dataDir     string
@@ -73,6 +77,8 @@ func (c *Run) Command() (string, *flag.FlagSet, cli.CmdFunc) {
 	fset.BoolVar(&c.noFetchCandles, "no-fetch-candles", true, "when true, candle data is not saved in the datastore")
 	fset.DurationVar(&c.maxFetchTimeLatency, "max-fetch-time-latency", 0, "max latency for fetch-time operation in finding time difference")
 	fset.DurationVar(&c.maxHttpClientTimeout, "max-http-client-timeout", 30*time.Second, "default max timeout for http requests")
+	fset.StringVar(&c.budgetPolicy, "budget-policy", "warn", "action when a new job over-commits the available balance; must be one of warn, reject or none")
+	fset.Func("restart-policy", "default restart policy for failed jobs of a type as type=policy (can be repeated)", c.parseRestartPolicy)
 	fset.StringVar(&c.secretsPath, "secrets-file", "", "path to credentials file")
 	fset.StringVar(&c.dataDir, "data-dir", defaults.DataDir(), "path to the data directory")
 	fset.StringVar(&c.logDir, "log-dir", defaults.LogDir(), "path to the logs directory")
@@ -107,9 +113,43 @@ JSON format. A example secrets file format is given below:
 Users should consult the exchange specific documentation to learn how to create
 the API keys.
 
+RESTART POLICIES
+
+Jobs that fail are left in the FAILED state by default. The -restart-policy
+flag configures automatic restarts for failed jobs of a type, for example,
+"-restart-policy limiter=on-failure:max-attempts=3,backoff=2m". Restarts are
+delayed with an exponential backoff and a message is sent when a job's
+restart attempts within the window are exhausted. Policies for individual jobs
+can be updated with the "job restart-policy" command.
+
+API AUTHENTICATION
+
+Requests from non-loopback clients must carry an API token in the
+"Authorization: Bearer <token>" header. Tokens have one of the read-only,
+trade or admin scopes and are managed with the "token" commands. Requests
+from loopback clients are trusted unless -auth-loopback flag is set, so the
+first admin token must be created locally.
+
+The -tls-cert and -tls-key flags serve the API over https and the
+-tls-client-ca flag additionally requires verified client certificates for
+all API requests. Clients use the -api-token, -tls, -tls-ca, -tls-cert and
+-tls-key flags or the TRADEBOT_API_TOKEN environment variable.
+
 `
 }
 
+func (c *Run) parseRestartPolicy(s string) error {
+	typename, policy, ok := strings.Cut(s, "=")
+	if !ok || len(typename) == 0 || len(policy) == 0 {
+		return fmt.Errorf("restart policy %q must be of the form type=policy", s)
+	}
+	if c.restartPolicies == nil {
+		c.restartPolicies = make(map[string]string)
+	}
+	c.restartPolicies[strings.ToLower(typename)] = policy
+	return nil
+}
+
 func (c *Run) run(ctx context.Context, args []string) error {
 	ctx, stop := signal.NotifyContext(ctx, os.Interrupt, syscall.SIGTERM)
 	defer stop()
@@ -177,13 +217,24 @@ func (c *Run) run(ctx context.Context, args []string) error {
 		IP:   net.ParseIP(c.IP),
 		Port: c.Port(),
 	}
+	tlsConfig, err := c.TLSConfig()
+	if err != nil {
+		return err
+	}
+	scheme := "http"
+	if tlsConfig != nil {
+		scheme = "https"
+	}
 
 	// Health checker for the background process initialization. We need to
 	// verify that responding http server is really our child and not an older
 	// instance.
 	check := func(ctx context.Context, child *os.Process) (bool, error) {
 		client := http.Client{Timeout: time.Second}
-		resp, err := client.Get(fmt.Sprintf("http://%s/pid", addr.String()))
+		if tlsConfig != nil {
+			client.Transport = &http.Transport{TLSClientConfig: &tls.Config{InsecureSkipVerify: true}}
+		}
+		resp, err := client.Get(fmt.Sprintf("%s://%s/pid", scheme, addr.String()))
 		if err != nil {
 			return true, err
 		}
@@ -292,27 +343,20 @@ func (c *Run) run(ctx context.Context, args []string) error {
 	}
 	defer s.Close()
 
-	tcpServer, err := s.StartTCP(ctx, addr)
+	var tcpServer int64
+	if tlsConfig != nil {
+		tcpServer, err = s.StartTLS(ctx, addr, tlsConfig)
+	} else {
+		tcpServer, err = s.StartTCP(ctx, addr)
+	}
 	if err != nil {
 		return fmt.Errorf("could not start http server on %s: %w", addr, err)
 	}
 	defer s.Stop(tcpServer)
 
-	if !c.noPprof {
-		s.AddHandler("/debug/pprof/", http.HandlerFunc(pprof.Index))
-		s.AddHandler("/debug/pprof/cmdline", http.HandlerFunc(pprof.Cmdline))
-		s.AddHandler("/debug/pprof/profile", http.HandlerFunc(pprof.Profile))
-		s.AddHandler("/debug/pprof/symbol", http.HandlerFunc(pprof.Symbol))
-		s.AddHandler("/debug/pprof/trace", http.HandlerFunc(pprof.Trace))
+	if !addr.IP.IsLoopback() && tlsConfig == nil {
+		slog.Warn("api endpoint is not on a loopback address and api tokens are sent without tls", "addr", addr)
 	}
-	s.AddHandler("/debug/logging/on", http.HandlerFunc(func(http.ResponseWriter, *http.Request) {
-		backend.SetLevel(slog.LevelDebug)
-		slog.Info("debug logging is turned on by the user through REST endpoint")
-	}))
-	s.AddHandler("/debug/logging/off", http.HandlerFunc(func(http.ResponseWriter, *http.Request) {
-		slog.Info("debug logging is turned off by the user through REST endpoint")
-		backend.SetLevel(slog.LevelInfo)
-	}))
 
 	// Open the database.
 	bopts := badger.DefaultOptions(dataDir)
@@ -323,7 +367,27 @@ func (c *Run) run(ctx context.Context, args []string) error {
 	defer bdb.Close()
 	db := kvbadger.New(bdb, isGoodKey)
 
-	s.AddHandler("/db/", http.StripPrefix("/db", kvhttp.Handler(db)))
+	// All handlers except the /pid handler require api tokens from the
+	// non-loopback clients.
+	authn := auth.New(db, c.AuthOptions())
+
+	if !c.noPprof {
+		s.AddHandler("/debug/pprof/", authn.Handler(auth.Admin, http.HandlerFunc(pprof.Index)))
+		s.AddHandler("/debug/pprof/cmdline", authn.Handler(auth.Admin, http.HandlerFunc(pprof.Cmdline)))
+		s.AddHandler("/debug/pprof/profile", authn.Handler(auth.Admin, http.HandlerFunc(pprof.Profile)))
+		s.AddHandler("/debug/pprof/symbol", authn.Handler(auth.Admin, http.HandlerFunc(pprof.Symbol)))
+		s.AddHandler("/debug/pprof/trace", authn.Handler(auth.Admin, http.HandlerFunc(pprof.Trace)))
+	}
+	s.AddHandler("/debug/logging/on", authn.Handler(auth.Admin, http.HandlerFunc(func(http.ResponseWriter, *http.Request) {
+		backend.SetLevel(slog.LevelDebug)
+		slog.Info("debug logging is turned on by the user through REST endpoint")
+	})))
+	s.AddHandler("/debug/logging/off", authn.Handler(auth.Admin, http.HandlerFunc(func(http.ResponseWriter, *http.Request) {
+		slog.Info("debug logging is turned off by the user through REST endpoint")
+		backend.SetLevel(slog.LevelInfo)
+	})))
+
+	s.AddHandler("/db/", authn.Handler(auth.Admin, http.StripPrefix("/db", kvhttp.Handler(db))))
 
 	// Start other services.
 	topts := &server.Options{
@@ -333,6 +397,8 @@ func (c *Run) run(ctx context.Context, args []string) error {
 		MaxFetchTimeLatency:  c.maxFetchTimeLatency,
 		MaxHttpClientTimeout: c.maxHttpClientTimeout,
 		BinaryBackupPath:     c.binaryBackupPath(),
+		BudgetPolicy:         c.budgetPolicy,
+		RestartPolicies:      c.restartPolicies,
 	}
 	trader, err := server.New(ctx, c.secretsPath, db, topts)
 	if err != nil {
@@ -343,7 +409,7 @@ func (c *Run) run(ctx context.Context, args []string) error {
 	// Add trader api handlers
 	traderAPIs := trader.HandlerMap()
 	for k, v := range traderAPIs {
-		s.AddHandler(k, v)
+		s.AddHandler(k, authn.Handler(server.HandlerScope(k), v))
 	}
 	defer func() {
 		for k := range traderAPIs {
@@ -380,7 +446,7 @@ func (c *Run) run(ctx context.Context, args []string) error {
 	}()
 
 	// Wait for the signals
-	log.Printf("started tradebot server at %s", addr)
+	log.Printf("started tradebot server at %s://%s", scheme, addr)
 	<-ctx.Done()
 	log.Printf("tradebot server is shutting down")
 	return nil
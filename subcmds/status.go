@@ -4,10 +4,8 @@ package subcmds
 
 import (
 	"context"
-	"errors"
 	"flag"
 	"fmt"
-	"log"
 	"os"
 	"slices"
 	"sort"
@@ -15,19 +13,12 @@ import (
 	"text/tabwriter"
 	"time"
 
-	"github.com/bvk/tradebot/coinbase"
-	"github.com/bvk/tradebot/coinex"
+	"github.com/bvk/tradebot/api"
 	"github.com/bvk/tradebot/gobs"
-	"github.com/bvk/tradebot/job"
-	"github.com/bvk/tradebot/limiter"
-	"github.com/bvk/tradebot/looper"
-	"github.com/bvk/tradebot/namer"
 	"github.com/bvk/tradebot/server"
 	"github.com/bvk/tradebot/subcmds/cmdutil"
 	"github.com/bvk/tradebot/timerange"
 	"github.com/bvk/tradebot/trader"
-	"github.com/bvk/tradebot/waller"
-	"github.com/bvkgo/kv"
 	"github.com/shopspring/decimal"
 	"github.com/visvasity/cli"
 )
@@ -56,103 +47,18 @@ func (c *Status) Command() (string, *flag.FlagSet, cli.CmdFunc) {
 	return "status", fset, cli.CmdFunc(c.run)
 }
 
-func (c *Status) run(ctx context.Context, args []string) error {
-	db, closer, err := c.DBFlags.GetDatabase(ctx)
-	if err != nil {
-		return err
-	}
-	defer closer()
-
-	datastore := coinbase.NewDatastore(db)
-	accounts, err := datastore.LoadAccounts(ctx)
-	if err != nil {
-		if !errors.Is(err, os.ErrNotExist) {
-			return fmt.Errorf("could not load coinbase account balances: %w", err)
-		}
-	}
-
-	productPriceMap := make(map[string]decimal.Decimal)
-	switch strings.ToLower(c.pricesFrom) {
-	case "coinex":
-		pmap, err := coinex.GetProductPriceMap(ctx)
-		if err != nil {
-			log.Printf("could not load price information (ignored): %v", err)
-			pmap = make(map[string]decimal.Decimal)
-		}
-		productPriceMap = pmap
+func (c *Status) Description() string {
+	return `
 
-	case "coinbase":
-		pmap, err := datastore.ProductsPriceMap(ctx)
-		if err != nil {
-			if !errors.Is(err, os.ErrNotExist) {
-				log.Printf("could not load price information (ignored): %v", err)
-			}
-			pmap = make(map[string]decimal.Decimal)
-		}
-		productPriceMap = pmap
-	}
+Command "status" fetches the job statuses from the running trader server,
+which uses the live job objects for the running jobs. When -data-dir or
+-from-backup flag is given, statuses are computed locally from the database
+instead.
 
-	var assets []string
-	holdMap := make(map[string]decimal.Decimal)
-	availMap := make(map[string]decimal.Decimal)
-	currencyMap := make(map[string]string)
-	for _, a := range accounts {
-		holdMap[a.Name] = a.Hold
-		availMap[a.Name] = a.Available
-		currencyMap[a.Name] = a.CurrencyID
-		assets = append(assets, a.Name)
-	}
-	sort.Strings(assets)
-
-	var jobs []trader.Trader
-	uid2nameMap := make(map[string]string)
-	uid2statusMap := make(map[string]string)
-	load := func(ctx context.Context, r kv.Reader) error {
-		if len(args) > 0 {
-			for _, arg := range args {
-				_, uid, typename, err := namer.Resolve(ctx, r, arg)
-				if err != nil {
-					if !errors.Is(err, os.ErrNotExist) {
-						return fmt.Errorf("could not resolve job type %q: %w", arg, err)
-					}
-					uid = arg
-				}
-				job, err := server.Load(ctx, r, uid, typename)
-				if err != nil {
-					return fmt.Errorf("could not load job with uid %q: %w", uid, err)
-				}
-				jobs = append(jobs, job)
-			}
-		} else {
-			vs, err := server.LoadAll(ctx, r)
-			if err != nil {
-				return fmt.Errorf("could not load traders: %w", err)
-			}
-			jobs = vs
-		}
-
-		for _, j := range jobs {
-			uid := j.UID()
-			uid = strings.TrimPrefix(uid, limiter.DefaultKeyspace)
-			uid = strings.TrimPrefix(uid, looper.DefaultKeyspace)
-			uid = strings.TrimPrefix(uid, waller.DefaultKeyspace)
-			name := uid
-			if v, _, _, err := namer.Resolve(ctx, r, uid); err == nil {
-				name = v
-			}
-			uid2nameMap[j.UID()] = name
-			status := "UNKNOWN"
-			if v, err := job.StatusDB(ctx, db, j.UID()); err == nil {
-				status = string(v)
-			}
-			uid2statusMap[uid] = status
-		}
-		return nil
-	}
-	if err := kv.WithReader(ctx, db, load); err != nil {
-		return err
-	}
+`
+}
 
+func (c *Status) run(ctx context.Context, args []string) error {
 	now := time.Now()
 	parseTime := func(s string) (time.Time, error) {
 		if d, err := time.ParseDuration(s); err == nil {
@@ -180,23 +86,55 @@ func (c *Status) run(ctx context.Context, args []string) error {
 		period.End = v
 	}
 
-	// Remove jobs that don't implement Status interface.
-	jobs = slices.DeleteFunc(jobs, func(j trader.Trader) bool {
-		if _, ok := j.(trader.Statuser); !ok {
-			return true
+	req := &api.StatusRequest{
+		Jobs:       args,
+		BeginTime:  period.Begin,
+		EndTime:    period.End,
+		PricesFrom: c.pricesFrom,
+	}
+
+	var resp *api.StatusResponse
+	if c.DBFlags.IsRemoteDatabase() {
+		v, err := cmdutil.Post[api.StatusResponse](ctx, &c.DBFlags.ClientFlags, api.StatusPath, req)
+		if err != nil {
+			return err
 		}
-		return false
-	})
+		resp = v
+	} else {
+		v, err := c.localStatus(ctx, req)
+		if err != nil {
+			return err
+		}
+		resp = v
+	}
+
+	productPriceMap := resp.Prices
+	if productPriceMap == nil {
+		productPriceMap = make(map[string]decimal.Decimal)
+	}
+
+	var assets []string
+	holdMap := make(map[string]decimal.Decimal)
+	availMap := make(map[string]decimal.Decimal)
+	currencyMap := make(map[string]string)
+	for _, a := range resp.Accounts {
+		holdMap[a.Name] = a.Hold
+		availMap[a.Name] = a.Available
+		currencyMap[a.Name] = a.CurrencyID
+		assets = append(assets, a.Name)
+	}
+	sort.Strings(assets)
+
+	jobs := slices.Clone(resp.Jobs)
 	sort.Slice(jobs, func(i, j int) bool {
-		return jobs[i].ProductID() < jobs[j].ProductID()
+		return jobs[i].Status.ProductID < jobs[j].Status.ProductID
 	})
 
-	var statuses []*trader.Status
-	for _, j := range jobs {
-		if v, ok := j.(trader.Statuser); ok {
-			if s := v.Status(&period); s != nil {
-				statuses = append(statuses, s)
-			}
+	var statuses, runningStatuses []*trader.Status
+	for _, js := range jobs {
+		statuses = append(statuses, js.Status)
+		if js.State == gobs.RUNNING {
+			runningStatuses = append(runningStatuses, js.Status)
 		}
 	}
 
@@ -207,13 +145,6 @@ func (c *Status) run(ctx context.Context, args []string) error {
 			curUnsoldValue = curUnsoldValue.Add(s.UnsoldSize.Mul(p))
 		}
 	}
-
-	var runningStatuses []*trader.Status
-	for _, s := range statuses {
-		if status, ok := uid2statusMap[s.UID]; ok && status == string(gobs.RUNNING) {
-			runningStatuses = append(runningStatuses, s)
-		}
-	}
 	runningSum := trader.Summarize(runningStatuses)
 
 	var (
@@ -316,26 +247,46 @@ func (c *Status) run(ctx context.Context, args []string) error {
 		tw.Flush()
 	}
 
-	if len(statuses) > 0 {
-		order := []string{"RUNNING", "PAUSED", "COMPLETED", "FAILED", "CANCELED"}
-		sort.Slice(statuses, func(i, j int) bool {
-			a, b := statuses[i], statuses[j]
-			astatus, bstatus := uid2statusMap[a.UID], uid2statusMap[b.UID]
-			if astatus == bstatus {
-				return uid2nameMap[a.UID] < uid2nameMap[b.UID]
+	if len(jobs) > 0 {
+		order := []gobs.State{gobs.RUNNING, gobs.PAUSED, gobs.COMPLETED, gobs.FAILED, gobs.CANCELED}
+		sort.Slice(jobs, func(i, j int) bool {
+			a, b := jobs[i], jobs[j]
+			if a.State == b.State {
+				return a.Name < b.Name
 			}
-			return slices.Index(order, astatus) < slices.Index(order, bstatus)
+			return slices.Index(order, a.State) < slices.Index(order, b.State)
 		})
 
 		fmt.Println()
 		tw := tabwriter.NewWriter(os.Stdout, 0, 0, 1, ' ', tabwriter.AlignRight)
 		fmt.Fprintf(tw, "Name/UID\tStatus\tProduct\tBudget\tReturn\tAnnualReturn\tDays\tBuys\tSells\tProfit\tFees\tBoughtValue\tSoldValue\tUnsoldValue\tSoldSize\tUnsoldSize\t\n")
-		for _, s := range statuses {
-			name := uid2nameMap[s.UID]
-			status := uid2statusMap[s.UID]
-			fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s%%\t%s%%\t%s\t%d\t%d\t%s\t%s\t%s\t%s\t%s\t%s\t%s\t\n", name, status, s.ProductID, s.Budget.StringFixed(3), s.ReturnRate().StringFixed(3), s.AnnualReturnRate().StringFixed(3), s.NumDays().StringFixed(2), s.NumBuys, s.NumSells, s.Profit().StringFixed(3), s.Fees().StringFixed(3), s.Bought().StringFixed(3), s.Sold().StringFixed(3), s.UnsoldValue.StringFixed(3), s.SoldSize.Sub(s.OversoldSize).StringFixed(3), s.UnsoldSize.StringFixed(3))
+		for _, js := range jobs {
+			s := js.Status
+			fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s%%\t%s%%\t%s\t%d\t%d\t%s\t%s\t%s\t%s\t%s\t%s\t%s\t\n", js.Name, js.State, s.ProductID, s.Budget.StringFixed(3), s.ReturnRate().StringFixed(3), s.AnnualReturnRate().StringFixed(3), s.NumDays().StringFixed(2), s.NumBuys, s.NumSells, s.Profit().StringFixed(3), s.Fees().StringFixed(3), s.Bought().StringFixed(3), s.Sold().StringFixed(3), s.UnsoldValue.StringFixed(3), s.SoldSize.Sub(s.OversoldSize).StringFixed(3), s.UnsoldSize.StringFixed(3))
 		}
 		tw.Flush()
 	}
 	return nil
 }
+
+// localStatus computes the status response by reading the database directly.
+func (c *Status) localStatus(ctx context.Context, req *api.StatusRequest) (*api.StatusResponse, error) {
+	db, closer, err := c.DBFlags.GetDatabase(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer closer()
+
+	resp, err := server.JobStatuses(ctx, db, req, nil /* live */)
+	if err != nil {
+		return nil, err
+	}
+	resp.Prices = server.ProductPrices(ctx, db, req.PricesFrom)
+
+	accounts, err := server.Accounts(ctx, db)
+	if err != nil {
+		return nil, err
+	}
+	resp.Accounts = accounts
+	return resp, nil
+}
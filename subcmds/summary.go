@@ -4,11 +4,8 @@ package subcmds
 
 import (
 	"context"
-	"errors"
 	"flag"
 	"fmt"
-	"log/slog"
-	"maps"
 	"os"
 	"slices"
 	"sort"
@@ -16,18 +13,13 @@ import (
 	"text/tabwriter"
 	"time"
 
+	"github.com/bvk/tradebot/api"
 	"github.com/bvk/tradebot/coinbase"
 	"github.com/bvk/tradebot/coinex"
 	"github.com/bvk/tradebot/gobs"
-	"github.com/bvk/tradebot/job"
-	"github.com/bvk/tradebot/looper"
-	"github.com/bvk/tradebot/namer"
 	"github.com/bvk/tradebot/server"
 	"github.com/bvk/tradebot/subcmds/cmdutil"
 	"github.com/bvk/tradebot/timerange"
-	"github.com/bvk/tradebot/waller"
-	"github.com/bvk/tradebot/watcher"
-	"github.com/bvkgo/kv"
 	"github.com/shopspring/decimal"
 	"github.com/visvasity/cli"
 )
@@ -65,6 +57,20 @@ func (c *Summary) Command() (string, *flag.FlagSet, cli.CmdFunc) {
 	return "summary", fset, cli.CmdFunc(c.run)
 }
 
+func (c *Summary) Description() string {
+	return `
+
+Command "summary" fetches the job summaries from the running trader server,
+which uses the live job objects for the running jobs. When -data-dir or
+-from-backup flag is given, summaries are computed locally from the database
+instead.
+
+The -fix-summary flag updates the saved summaries in the database, so it is
+only allowed when the database is accessed directly.
+
+`
+}
+
 func (c *Summary) run(ctx context.Context, args []string) error {
 	// Prepare a time-period if it was given.
 	now := time.Now()
@@ -98,117 +104,39 @@ func (c *Summary) run(ctx context.Context, args []string) error {
 
 	ignoreJobTypes := strings.Split(c.ignoreJobTypes, ",")
 
-	// Open the database.
-	db, closer, err := c.DBFlags.GetDatabase(ctx)
-	if err != nil {
-		return err
+	req := &api.SummaryRequest{
+		Jobs:           args,
+		Recalculate:    c.recal,
+		IgnoreJobTypes: ignoreJobTypes,
+	}
+	if period != nil {
+		req.BeginTime, req.EndTime = period.Begin, period.End
 	}
-	defer closer()
-
-	runner := job.NewRunner(db)
-	exchanges := []string{}
-	uid2nameMap := make(map[string]string)
-	uid2jdMap := make(map[string]*gobs.JobData)
-	uid2sumMap := make(map[string]*gobs.Summary)
-	collectJobs := func(ctx context.Context, r kv.Reader, jd *gobs.JobData) error {
-		name, _, _, err := namer.Resolve(ctx, r, jd.ID)
-		if err != nil {
-			if !errors.Is(err, os.ErrNotExist) {
-				return err
-			}
-			name = jd.ID
-		}
-		if len(args) != 0 && !slices.Contains(args, name) && !slices.Contains(args, jd.ID) {
-			return nil // Skip the job
-		}
-
-		var sum *gobs.Summary
-		switch {
-		case strings.EqualFold(jd.Typename, "watcher"):
-			sum, err = watcher.Summary(ctx, r, jd.ID, period, c.recal)
-			if err != nil {
-				return err
-			}
-		case strings.EqualFold(jd.Typename, "waller"):
-			sum, err = waller.Summary(ctx, r, jd.ID, period, c.recal)
-			if err != nil {
-				return err
-			}
-		case strings.EqualFold(jd.Typename, "looper"):
-			sum, err = looper.Summary(ctx, r, jd.ID, period, c.recal)
-			if err != nil {
-				return err
-			}
-		default:
-			return nil // Skip the job
-		}
 
-		// Adjust to reflect the user chosen time periods.
-		if period == nil {
-			sum.EndAt = now
-			if sum.BeginAt.IsZero() {
-				sum.BeginAt = sum.EndAt
-			}
-		} else {
-			if !period.Begin.IsZero() {
-				sum.BeginAt = period.Begin
-			}
-			sum.EndAt = period.End
+	var resp *api.SummaryResponse
+	if c.DBFlags.IsRemoteDatabase() {
+		if c.fixSummary {
+			return fmt.Errorf("-fix-summary flag requires direct database access with -data-dir flag")
 		}
-
-		uid2jdMap[jd.ID] = jd
-		uid2sumMap[jd.ID] = sum
-		uid2nameMap[jd.ID] = name
-		if !slices.Contains(exchanges, sum.Exchange) {
-			exchanges = append(exchanges, sum.Exchange)
+		v, err := cmdutil.Post[api.SummaryResponse](ctx, &c.DBFlags.ClientFlags, api.SummaryPath, req)
+		if err != nil {
+			return err
 		}
-		return nil
-	}
-	if err := runner.Scan(ctx, nil, collectJobs); err != nil {
-		return err
-	}
-
-	if c.fixSummary {
-		for _, jd := range uid2jdMap {
-			jd := jd
-			if jd.State.IsRunning() {
-				slog.Warn("job is currently running, so summary is not updated (skipped)", "uid", jd.ID)
-				continue
-			}
-			fix := func(ctx context.Context, rw kv.ReadWriter) error {
-				job, err := server.Load(ctx, rw, jd.ID, jd.Typename)
-				if err != nil {
-					return fmt.Errorf("could not load traders: %w", err)
-				}
-				if err := job.Save(ctx, rw); err != nil {
-					slog.Error("could not save job", "job", job, "err", err)
-					return err
-				}
-				return nil
-			}
-			if err := kv.WithReadWriter(ctx, db, fix); err != nil {
-				return fmt.Errorf("could not apply summary fix: %w", err)
-			}
+		resp = v
+	} else {
+		v, err := c.localSummary(ctx, req)
+		if err != nil {
+			return err
 		}
+		resp = v
 	}
 
-	// Collect current prices.
+	exchangeProductPriceMap := resp.Prices
 	exchangePriceMap := make(map[string]map[string]decimal.Decimal)
-	exchangeProductPriceMap := make(map[string]map[string]decimal.Decimal)
-	if slices.Contains(exchanges, "coinbase") {
-		pmap, err := coinbase.GetProductPriceMap(ctx)
-		if err != nil {
-			slog.Warn("could not get product pricing from coinbase (ignored)", "err", err)
-		}
-		exchangeProductPriceMap["coinbase"] = pmap
+	if pmap, ok := exchangeProductPriceMap["coinbase"]; ok {
 		exchangePriceMap["coinbase"] = coinbase.GetPriceMap(pmap)
 	}
-	if slices.Contains(exchanges, "coinex") {
-		pmap, err := coinex.GetProductPriceMap(ctx)
-		if err != nil {
-			slog.Warn("could not get product pricing from coinex (ignored)", "err", err)
-		}
-		exchangeProductPriceMap["coinex"] = pmap
+	if pmap, ok := exchangeProductPriceMap["coinex"]; ok {
 		exchangePriceMap["coinex"] = coinex.GetPriceMap(pmap)
 	}
 
@@ -218,24 +146,17 @@ func (c *Summary) run(ctx context.Context, args []string) error {
 
 	// Print overall summary when no time period is specified and all jobs are requested.
 	if period == nil && len(args) == 0 {
-		allSum := new(gobs.Summary)
-		runningSum := new(gobs.Summary)
+		allSum, runningSum := resp.Total, resp.Running
 		var curUnsoldValue decimal.Decimal
-		for uid, sum := range uid2sumMap {
-			jd := uid2jdMap[uid]
-			if slices.Contains(ignoreJobTypes, strings.ToLower(jd.Typename)) {
+		for _, js := range resp.Jobs {
+			if slices.Contains(ignoreJobTypes, strings.ToLower(js.Type)) {
 				continue
 			}
-			allSum.Add(sum)
-			if uid2jdMap[uid].State.IsRunning() {
-				runningSum.Add(sum)
-			}
+			sum := js.Summary
 			if p, ok := exchangeProductPriceMap[sum.Exchange][sum.ProductID]; ok {
 				curUnsoldValue = curUnsoldValue.Add(sum.UnsoldSize.Mul(p))
 			}
 		}
-		allSum.EndAt = time.Now()
-		runningSum.EndAt = time.Now()
 
 		fmt.Printf("Num Days: %s\n", allSum.NumDays().StringFixed(2))
 		fmt.Printf("Num Buys: %s\n", allSum.NumBuys.StringFixed(1))
@@ -289,14 +210,8 @@ func (c *Summary) run(ctx context.Context, args []string) error {
 
 	// FIXME: The following doesn't work for CoinEx.
 	if c.accounts {
-		// Fetch last known account balances.
-		datastore := coinbase.NewDatastore(db)
-		accounts, err := datastore.LoadAccounts(ctx)
-		if err != nil {
-			if !errors.Is(err, os.ErrNotExist) {
-				return fmt.Errorf("could not load coinbase account balances: %w", err)
-			}
-		}
+		// Last known account balances.
+		accounts := resp.Accounts
 		var assets []string
 		holdMap := make(map[string]decimal.Decimal)
 		availMap := make(map[string]decimal.Decimal)
@@ -352,34 +267,68 @@ func (c *Summary) run(ctx context.Context, args []string) error {
 	}
 
 	// Pick a job order.
-	uids := slices.Collect(maps.Keys(uid2jdMap))
 	order := []gobs.State{gobs.RUNNING, gobs.PAUSED, gobs.COMPLETED, gobs.FAILED, gobs.CANCELED}
-	sort.SliceStable(uids, func(i, j int) bool {
-		is, js := uid2jdMap[uids[i]].State, uid2jdMap[uids[j]].State
+	jobs := slices.Clone(resp.Jobs)
+	sort.SliceStable(jobs, func(i, j int) bool {
+		is, js := jobs[i].State, jobs[j].State
 		if x, y := slices.Index(order, is), slices.Index(order, js); x != y {
 			return x < y
 		}
-		in, _ := uid2nameMap[uids[i]]
-		jn, _ := uid2nameMap[uids[j]]
-		if in != jn {
+		if in, jn := jobs[i].Name, jobs[j].Name; in != jn {
 			return in < jn
 		}
-		return uid2sumMap[uids[i]].ProductID < uid2sumMap[uids[j]].ProductID
+		return jobs[i].Summary.ProductID < jobs[j].Summary.ProductID
 	})
 
 	// Print the job summary table.
 	fmt.Println()
 	tw := tabwriter.NewWriter(os.Stdout, 0, 0, 1, ' ', tabwriter.AlignRight)
 	fmt.Fprintf(tw, "Name/UID\tKind\tStatus\tProduct\tBudget\tReturn\tAnnualReturn\tDays\tBuys\tSells\tProfit\tBoughtValue\tBoughtSize\tBoughtFees\tSoldValue\tSoldSize\tSoldFees\tUnsoldValue\tUnsoldSize\tUnsoldFees\tOversoldValue\tOversoldSize\tOversoldFees\t\n")
-	for _, uid := range uids {
-		jd := uid2jdMap[uid]
-		name, ok := uid2nameMap[uid]
-		if !ok {
-			name = jd.ID
+	for _, js := range jobs {
+		name := js.Name
+		if len(name) == 0 {
+			name = js.UID
 		}
-		s := uid2sumMap[uid]
-		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%s%%\t%s%%\t%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\t\n", name, jd.Typename, jd.State, s.ProductID, s.Budget.StringFixed(3), s.ReturnPct().StringFixed(3), s.AnnualPct().StringFixed(3), s.NumDays().StringFixed(2), s.NumBuys.StringFixed(1), s.NumSells.StringFixed(1), s.Profit().StringFixed(3), s.BoughtValue.StringFixed(3), s.BoughtSize.StringFixed(3), s.BoughtFees.StringFixed(3), s.SoldValue.StringFixed(3), s.SoldSize.StringFixed(3), s.SoldFees.StringFixed(3), s.UnsoldValue.StringFixed(3), s.UnsoldSize.StringFixed(3), s.UnsoldFees.StringFixed(3), s.OversoldValue.StringFixed(3), s.OversoldSize.StringFixed(3), s.OversoldFees.StringFixed(3))
+		s := js.Summary
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%s%%\t%s%%\t%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\t\n", name, js.Type, js.State, s.ProductID, s.Budget.StringFixed(3), s.ReturnPct().StringFixed(3), s.AnnualPct().StringFixed(3), s.NumDays().StringFixed(2), s.NumBuys.StringFixed(1), s.NumSells.StringFixed(1), s.Profit().StringFixed(3), s.BoughtValue.StringFixed(3), s.BoughtSize.StringFixed(3), s.BoughtFees.StringFixed(3), s.SoldValue.StringFixed(3), s.SoldSize.StringFixed(3), s.SoldFees.StringFixed(3), s.UnsoldValue.StringFixed(3), s.UnsoldSize.StringFixed(3), s.UnsoldFees.StringFixed(3), s.OversoldValue.StringFixed(3), s.OversoldSize.StringFixed(3), s.OversoldFees.StringFixed(3))
 	}
 	tw.Flush()
 	return nil
 }
+
+// localSummary computes the summary response by reading the database
+// directly.
+func (c *Summary) localSummary(ctx context.Context, req *api.SummaryRequest) (*api.SummaryResponse, error) {
+	db, closer, err := c.DBFlags.GetDatabase(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer closer()
+
+	resp, err := server.JobSummaries(ctx, db, req, nil /* live */)
+	if err != nil {
+		return nil, err
+	}
+	if c.fixSummary {
+		if err := server.FixSummaries(ctx, db, resp); err != nil {
+			return nil, err
+		}
+	}
+
+	var exchanges []string
+	for _, js := range resp.Jobs {
+		if !slices.Contains(exchanges, js.Summary.Exchange) {
+			exchanges = append(exchanges, js.Summary.Exchange)
+		}
+	}
+	resp.Prices = server.ExchangePrices(ctx, exchanges)
+
+	if c.accounts {
+		accounts, err := server.Accounts(ctx, db)
+		if err != nil {
+			return nil, err
+		}
+		resp.Accounts = accounts
+	}
+	return resp, nil
+}
@@ -0,0 +1,76 @@
+// Copyright (c) 2025 BVK Chaitanya
+
+package token
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"time"
+
+	"github.com/bvk/tradebot/api"
+	"github.com/bvk/tradebot/auth"
+	"github.com/bvk/tradebot/subcmds/cmdutil"
+	"github.com/visvasity/cli"
+)
+
+type Create struct {
+	cmdutil.ClientFlags
+
+	scope   string
+	expires time.Duration
+}
+
+func (c *Create) Command() (string, *flag.FlagSet, cli.CmdFunc) {
+	fset := flag.NewFlagSet("create", flag.ContinueOnError)
+	c.ClientFlags.SetFlags(fset)
+	fset.StringVar(&c.scope, "scope", string(auth.ReadOnly), "token scope; must be one of read-only, trade or admin")
+	fset.DurationVar(&c.expires, "expires", 0, "token lifetime; zero creates a token that never expires")
+	return "create", fset, cli.CmdFunc(c.run)
+}
+
+func (c *Create) Purpose() string {
+	return "Creates a new API token"
+}
+
+func (c *Create) Description() string {
+	return `
+
+Command "create" creates a new API token with the input name and prints the
+token string. Token string is not saved by the server, so it must be recorded
+by the user. Clients pass the token with the -api-token flag or the
+TRADEBOT_API_TOKEN environment variable.
+
+Read-only tokens can only query the jobs, events and budgets. Trade tokens can
+also create and control the trading jobs. Admin tokens can perform all
+operations including the raw database access and token management.
+
+`
+}
+
+func (c *Create) run(ctx context.Context, args []string) error {
+	if len(args) != 1 {
+		return fmt.Errorf("this command takes one token-name argument")
+	}
+	scope, err := auth.ParseScope(c.scope)
+	if err != nil {
+		return err
+	}
+	if c.expires < 0 {
+		return fmt.Errorf("token lifetime cannot be negative")
+	}
+
+	req := &api.TokenCreateRequest{
+		Name:  args[0],
+		Scope: string(scope),
+	}
+	if c.expires > 0 {
+		req.ExpiresAt = time.Now().Add(c.expires)
+	}
+	resp, err := cmdutil.Post[api.TokenCreateResponse](ctx, &c.ClientFlags, api.TokenCreatePath, req)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("%s\n", resp.Token)
+	return nil
+}
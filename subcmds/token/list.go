@@ -0,0 +1,58 @@
+// Copyright (c) 2025 BVK Chaitanya
+
+package token
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/bvk/tradebot/api"
+	"github.com/bvk/tradebot/subcmds/cmdutil"
+	"github.com/visvasity/cli"
+)
+
+type List struct {
+	cmdutil.ClientFlags
+}
+
+func (c *List) Command() (string, *flag.FlagSet, cli.CmdFunc) {
+	fset := flag.NewFlagSet("list", flag.ContinueOnError)
+	c.ClientFlags.SetFlags(fset)
+	return "list", fset, cli.CmdFunc(c.run)
+}
+
+func (c *List) Purpose() string {
+	return "Lists the API tokens"
+}
+
+func (c *List) run(ctx context.Context, args []string) error {
+	if len(args) != 0 {
+		return fmt.Errorf("this command takes no arguments")
+	}
+
+	req := &api.TokenListRequest{}
+	resp, err := cmdutil.Post[api.TokenListResponse](ctx, &c.ClientFlags, api.TokenListPath, req)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	tw := tabwriter.NewWriter(os.Stdout, 0, 0, 1, ' ', 0)
+	fmt.Fprintf(tw, "ID\tName\tScope\tCreated\tExpires\t\n")
+	for _, t := range resp.Tokens {
+		expires := "never"
+		if !t.ExpiresAt.IsZero() {
+			expires = t.ExpiresAt.Local().Format(time.DateTime)
+			if !now.Before(t.ExpiresAt) {
+				expires += " (expired)"
+			}
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t\n", t.ID, t.Name, t.Scope, t.CreatedAt.Local().Format(time.DateTime), expires)
+	}
+	tw.Flush()
+	return nil
+}
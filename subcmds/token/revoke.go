@@ -0,0 +1,40 @@
+// Copyright (c) 2025 BVK Chaitanya
+
+package token
+
+import (
+	"context"
+	"flag"
+	"fmt"
+
+	"github.com/bvk/tradebot/api"
+	"github.com/bvk/tradebot/subcmds/cmdutil"
+	"github.com/visvasity/cli"
+)
+
+type Revoke struct {
+	cmdutil.ClientFlags
+}
+
+func (c *Revoke) Command() (string, *flag.FlagSet, cli.CmdFunc) {
+	fset := flag.NewFlagSet("revoke", flag.ContinueOnError)
+	c.ClientFlags.SetFlags(fset)
+	return "revoke", fset, cli.CmdFunc(c.run)
+}
+
+func (c *Revoke) Purpose() string {
+	return "Revokes API tokens"
+}
+
+func (c *Revoke) run(ctx context.Context, args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("this command takes one or more token-id arguments")
+	}
+	for _, id := range args {
+		req := &api.TokenRevokeRequest{ID: id}
+		if _, err := cmdutil.Post[api.TokenRevokeResponse](ctx, &c.ClientFlags, api.TokenRevokePath, req); err != nil {
+			return fmt.Errorf("could not revoke token %q: %w", id, err)
+		}
+	}
+	return nil
+}
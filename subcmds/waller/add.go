@@ -12,22 +12,29 @@ import (
 	"github.com/bvk/tradebot/api"
 	"github.com/bvk/tradebot/point"
 	"github.com/bvk/tradebot/subcmds/cmdutil"
+	"github.com/bvk/tradebot/trigger"
 	"github.com/visvasity/cli"
 )
 
 type Add struct {
 	cmdutil.ClientFlags
+	cmdutil.ExitFlags
+	cmdutil.LabelFlags
 
 	dryRun bool
 
 	product  string
 	exchange string
 	name     string
+	trigger  string
 
 	spec Spec
 }
 
 func (c *Add) check() error {
+	if err := c.ExitFlags.Check(); err != nil {
+		return err
+	}
 	if len(c.product) == 0 {
 		return fmt.Errorf("product name cannot be empty")
 	}
@@ -69,25 +76,36 @@ func (c *Add) Run(ctx context.Context, args []string) error {
 		return fmt.Errorf("could not determine buy/sell points")
 	}
 
-	if c.dryRun {
-		for i, p := range pairs {
-			d0, _ := json.Marshal(p.Buy)
-			fmt.Printf("buy-%d:  %s\n", i, d0)
-			d1, _ := json.Marshal(p.Sell)
-			fmt.Printf("sell-%d: %s\n", i, d1)
+	labels, err := c.LabelFlags.Labels()
+	if err != nil {
+		return err
+	}
+
+	var armed *trigger.Trigger
+	if c.trigger != "" {
+		v, err := trigger.Parse(c.trigger)
+		if err != nil {
+			return err
 		}
-		return nil
+		armed = v
 	}
 
 	req1 := &api.WallRequest{
 		ProductID:    c.product,
 		ExchangeName: c.exchange,
 		Pairs:        pairs,
+		Trigger:      armed,
+		Options:      c.ExitFlags.Options(),
+		Labels:       labels,
+		DryRun:       c.dryRun,
 	}
 	resp1, err := cmdutil.Post[api.WallResponse](ctx, &c.ClientFlags, api.WallPath, req1)
 	if err != nil {
 		return err
 	}
+	if c.dryRun {
+		return cmdutil.PrintDryRun(resp1.DryRun)
+	}
 
 	req2 := &api.SetJobNameRequest{
 		UID:     resp1.UID,
@@ -105,11 +123,14 @@ func (c *Add) Run(ctx context.Context, args []string) error {
 func (c *Add) Command() (string, *flag.FlagSet, cli.CmdFunc) {
 	fset := flag.NewFlagSet("add", flag.ContinueOnError)
 	c.ClientFlags.SetFlags(fset)
+	c.ExitFlags.SetFlags(fset)
+	c.LabelFlags.SetFlags(fset)
 	c.spec.SetFlags(fset)
-	fset.BoolVar(&c.dryRun, "dry-run", false, "when true only prints the trade points")
+	fset.BoolVar(&c.dryRun, "dry-run", false, "when true, prints the exchange effects report from the server without creating the job")
 	fset.StringVar(&c.name, "name", "", "a name for the trader job")
 	fset.StringVar(&c.product, "product", "", "product id for the trader")
 	fset.StringVar(&c.exchange, "exchange", "coinbase", "exchange name for the product")
+	fset.StringVar(&c.trigger, "trigger", "", "when non-empty, job is armed till the price trigger (ex: below:25000, stay-above:30000:15m, ma-above:30000:1h) fires")
 	return "add", fset, cli.CmdFunc(c.Run)
 }
 
@@ -136,5 +157,10 @@ when the ticker price goes completely below the chosen price-range then all buy
 points will be executed, and sell points will be waiting for the ticker to come
 back up.
 
+Loops run indefinitely by default. The max-cycles, profit-target and end-date
+flags are applied to every loop, which completes before starting it's next buy
+after the condition is met. Waller job is complete when all of it's loops are
+complete.
+
 `
 }
@@ -0,0 +1,121 @@
+// Copyright (c) 2025 BVK Chaitanya
+
+package waller
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"slices"
+	"time"
+
+	"github.com/bvk/tradebot/coinbase"
+	"github.com/bvk/tradebot/gobs"
+	"github.com/bvkgo/kv"
+)
+
+// dayStats holds the daily price statistics aggregated from the candles.
+type dayStats struct {
+	day time.Time
+
+	high, low, close float64
+}
+
+// loadCandles returns the candles saved in the database for a product in the
+// [begin, end) time range, sorted by the start time.
+func loadCandles(ctx context.Context, db kv.Database, productID string, begin, end time.Time) ([]*gobs.Candle, error) {
+	var candles []*gobs.Candle
+	ds := coinbase.NewDatastore(db)
+	if err := ds.ScanCandles(ctx, productID, begin, end, func(c *gobs.Candle) error {
+		candles = append(candles, c)
+		return nil
+	}); err != nil {
+		return nil, fmt.Errorf("could not scan candles for product %q: %w", productID, err)
+	}
+	slices.SortFunc(candles, func(a, b *gobs.Candle) int {
+		return a.StartTime.Time.Compare(b.StartTime.Time)
+	})
+	return candles, nil
+}
+
+// dailyStats aggregates the candles into per-day statistics.
+func dailyStats(candles []*gobs.Candle) []*dayStats {
+	var days []*dayStats
+	var last *dayStats
+	for _, c := range candles {
+		day := c.StartTime.Time.Truncate(24 * time.Hour)
+		high, _ := c.High.Float64()
+		low, _ := c.Low.Float64()
+		close, _ := c.Close.Float64()
+		if last == nil || !last.day.Equal(day) {
+			last = &dayStats{day: day, high: high, low: low}
+			days = append(days, last)
+		}
+		last.high = max(last.high, high)
+		last.low = min(last.low, low)
+		last.close = close
+	}
+	return days
+}
+
+// logReturns returns the log returns between successive prices.
+func logReturns(prices []float64) []float64 {
+	var rets []float64
+	for i := 1; i < len(prices); i++ {
+		if prices[i-1] > 0 && prices[i] > 0 {
+			rets = append(rets, math.Log(prices[i]/prices[i-1]))
+		}
+	}
+	return rets
+}
+
+func mean(vs []float64) float64 {
+	if len(vs) == 0 {
+		return 0
+	}
+	var sum float64
+	for _, v := range vs {
+		sum += v
+	}
+	return sum / float64(len(vs))
+}
+
+func stddev(vs []float64) float64 {
+	if len(vs) < 2 {
+		return 0
+	}
+	m := mean(vs)
+	var sum float64
+	for _, v := range vs {
+		sum += (v - m) * (v - m)
+	}
+	return math.Sqrt(sum / float64(len(vs)-1))
+}
+
+// percentile returns the p-th percentile of the input values.
+func percentile(vs []float64, p float64) float64 {
+	if len(vs) == 0 {
+		return 0
+	}
+	sorted := slices.Clone(vs)
+	slices.Sort(sorted)
+	index := int(math.Round(p / 100 * float64(len(sorted)-1)))
+	return sorted[min(max(index, 0), len(sorted)-1)]
+}
+
+// averageTrueRange returns the average true range over the last n days.
+func averageTrueRange(days []*dayStats, n int) float64 {
+	var trs []float64
+	for i, d := range days {
+		tr := d.high - d.low
+		if i > 0 {
+			prev := days[i-1].close
+			tr = max(tr, math.Abs(d.high-prev), math.Abs(d.low-prev))
+		}
+		trs = append(trs, tr)
+	}
+	if len(trs) > n {
+		trs = trs[len(trs)-n:]
+	}
+	return mean(trs)
+}
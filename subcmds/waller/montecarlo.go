@@ -0,0 +1,160 @@
+// Copyright (c) 2025 BVK Chaitanya
+
+package waller
+
+import (
+	"fmt"
+	"math"
+	"math/rand/v2"
+	"slices"
+	"time"
+
+	"github.com/bvk/tradebot/gobs"
+)
+
+// pathModel generates synthetic ticker price paths.
+type pathModel interface {
+	// path fills the prices slice with a price path starting at the input
+	// price.
+	path(r *rand.Rand, start float64, prices []float64)
+}
+
+// resampleCloses returns the closing prices of the candles at the step
+// interval.
+func resampleCloses(candles []*gobs.Candle, step time.Duration) []float64 {
+	var closes []float64
+	var bucket time.Time
+	for _, c := range candles {
+		close, _ := c.Close.Float64()
+		if b := c.StartTime.Time.Truncate(step); len(closes) == 0 || !b.Equal(bucket) {
+			bucket = b
+			closes = append(closes, close)
+			continue
+		}
+		closes[len(closes)-1] = close
+	}
+	return closes
+}
+
+// gbmModel generates geometric brownian motion paths with the drift and
+// volatility of the historical returns.
+type gbmModel struct {
+	mu, sigma float64
+}
+
+func newGBMModel(rets []float64) *gbmModel {
+	return &gbmModel{mu: mean(rets), sigma: stddev(rets)}
+}
+
+func (m *gbmModel) path(r *rand.Rand, start float64, prices []float64) {
+	price := start
+	for i := range prices {
+		price *= math.Exp(m.mu + m.sigma*r.NormFloat64())
+		prices[i] = price
+	}
+}
+
+// bootstrapModel generates paths by sampling blocks of historical returns with
+// replacement, which preserves the short term volatility clustering.
+type bootstrapModel struct {
+	rets  []float64
+	block int
+}
+
+func newBootstrapModel(rets []float64, block int) *bootstrapModel {
+	return &bootstrapModel{rets: rets, block: max(1, min(block, len(rets)))}
+}
+
+func (m *bootstrapModel) path(r *rand.Rand, start float64, prices []float64) {
+	price := start
+	for i := 0; i < len(prices); {
+		offset := r.IntN(len(m.rets) - m.block + 1)
+		for j := 0; j < m.block && i < len(prices); j, i = j+1, i+1 {
+			price *= math.Exp(m.rets[offset+j])
+			prices[i] = price
+		}
+	}
+}
+
+// regimeModel generates paths from a two-state Markov chain switching between
+// calm and volatile regimes. Regimes, their return distributions and the
+// switching probabilities are estimated from the historical returns by
+// comparing each block's volatility against the median.
+type regimeModel struct {
+	mu, sigma [2]float64
+
+	// switchProb holds the per-step probability of leaving a regime.
+	switchProb [2]float64
+}
+
+func newRegimeModel(rets []float64, block int) (*regimeModel, error) {
+	block = max(2, block)
+	var vols []float64
+	for i := 0; i+block <= len(rets); i += block {
+		vols = append(vols, stddev(rets[i:i+block]))
+	}
+	if len(vols) < 2 {
+		return nil, fmt.Errorf("not enough history to estimate the regimes")
+	}
+	median := percentile(vols, 50)
+
+	labels := make([]int, 0, len(vols)*block)
+	var samples [2][]float64
+	for i, v := range vols {
+		label := 0
+		if v > median {
+			label = 1
+		}
+		for _, ret := range rets[i*block : (i+1)*block] {
+			samples[label] = append(samples[label], ret)
+			labels = append(labels, label)
+		}
+	}
+
+	m := new(regimeModel)
+	var stays, switches [2]int
+	for i := 1; i < len(labels); i++ {
+		if labels[i] == labels[i-1] {
+			stays[labels[i-1]]++
+		} else {
+			switches[labels[i-1]]++
+		}
+	}
+	for k := range 2 {
+		m.mu[k], m.sigma[k] = mean(samples[k]), stddev(samples[k])
+		if n := stays[k] + switches[k]; n > 0 {
+			m.switchProb[k] = float64(switches[k]) / float64(n)
+		}
+	}
+	return m, nil
+}
+
+func (m *regimeModel) path(r *rand.Rand, start float64, prices []float64) {
+	price := start
+	state := r.IntN(2)
+	for i := range prices {
+		if r.Float64() < m.switchProb[state] {
+			state = 1 - state
+		}
+		price *= math.Exp(m.mu[state] + m.sigma[state]*r.NormFloat64())
+		prices[i] = price
+	}
+}
+
+// distribution holds the percentiles of a metric over all paths.
+type distribution struct {
+	mean, p5, p25, p50, p75, p95 float64
+}
+
+func newDistribution(vs []float64) *distribution {
+	sorted := slices.Clone(vs)
+	slices.Sort(sorted)
+	return &distribution{
+		mean: mean(sorted),
+		p5:   percentile(sorted, 5),
+		p25:  percentile(sorted, 25),
+		p50:  percentile(sorted, 50),
+		p75:  percentile(sorted, 75),
+		p95:  percentile(sorted, 95),
+	}
+}
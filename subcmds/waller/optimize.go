@@ -0,0 +1,363 @@
+// Copyright (c) 2025 BVK Chaitanya
+
+package waller
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"runtime"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"text/tabwriter"
+	"time"
+
+	"github.com/bvk/tradebot/subcmds/cmdutil"
+	"github.com/bvk/tradebot/waller"
+	"github.com/visvasity/cli"
+)
+
+type Optimize struct {
+	cmdutil.DBFlags
+
+	product string
+
+	beginDate, endDate string
+
+	priceInterval time.Duration
+
+	beginPrices      string
+	endPrices        string
+	buyIntervals     string
+	buyIntervalPcts  string
+	profitMargins    string
+	profitMarginPcts string
+	cancelOffsetPcts string
+
+	buySize float64
+	feePct  float64
+
+	sortBy string
+	top    int
+}
+
+func (c *Optimize) Command() (string, *flag.FlagSet, cli.CmdFunc) {
+	fset := flag.NewFlagSet("optimize", flag.ContinueOnError)
+	c.DBFlags.SetFlags(fset)
+	fset.StringVar(&c.product, "product", "", "product id with the candles data (when no prices file is given)")
+	fset.StringVar(&c.beginDate, "begin-date", "", "date of start day for the candles in YYYY-MM-DD format (default 90 days ago)")
+	fset.StringVar(&c.endDate, "end-date", "", "date of stop day for the candles in YYYY-MM-DD format (default today)")
+	fset.DurationVar(&c.priceInterval, "price-interval", time.Minute, "time interval between the prices in the prices file")
+	fset.StringVar(&c.beginPrices, "begin-prices", "", "comma separated list of begin prices")
+	fset.StringVar(&c.endPrices, "end-prices", "", "comma separated list of end prices")
+	fset.StringVar(&c.buyIntervals, "buy-intervals", "", "comma separated list of buy intervals")
+	fset.StringVar(&c.buyIntervalPcts, "buy-interval-pcts", "", "comma separated list of buy interval percents")
+	fset.StringVar(&c.profitMargins, "profit-margins", "", "comma separated list of profit margins")
+	fset.StringVar(&c.profitMarginPcts, "profit-margin-pcts", "", "comma separated list of profit margin percents")
+	fset.StringVar(&c.cancelOffsetPcts, "cancel-offset-pcts", "5", "comma separated list of cancel offset percents")
+	fset.Float64Var(&c.buySize, "buy-size", 0, "asset buy-size for the trade")
+	fset.Float64Var(&c.feePct, "fee-pct", 0.25, "exchange fee percentage to adjust sell margin")
+	fset.StringVar(&c.sortBy, "sort-by", "profit", "one of profit|return|utilization|unsold")
+	fset.IntVar(&c.top, "top", 20, "number of top specs to print")
+	return "optimize", fset, cli.CmdFunc(c.run)
+}
+
+func (c *Optimize) Purpose() string {
+	return "Finds the best waller spec over historical prices"
+}
+
+func (c *Optimize) Description() string {
+	return `
+
+Command "optimize" simulates waller jobs for all combinations of the input spec
+parameters over a historical price series and ranks them by simulated profit,
+annualized return on the budget, capital utilization or maximum unsold
+inventory value. Simulations are run in parallel on all CPUs.
+
+Historical prices are read from the optional prices file argument in the same
+format as the "simulate" command or from the candles data in the database for
+the product.
+
+Parameters take comma separated lists of values. Buy intervals (fixed and
+percent) and profit margins (fixed and percent) are combined, so each spec
+uses one buy interval and one profit margin.
+
+Example: tradebot waller optimize -product BTC-USD -buy-size 0.001 -begin-prices 50000,55000 -end-prices 70000 -buy-interval-pcts 0.5,1,2 -profit-margin-pcts 0.5,1,2
+
+`
+}
+
+type optimizeResult struct {
+	spec *Spec
+
+	numSells int
+	profit   float64
+	budget   float64
+	apr      float64
+
+	// utilization is the average percentage of the budget held as inventory.
+	utilization float64
+
+	maxUnsoldValue float64
+}
+
+func parseFloats(name, s string) ([]float64, error) {
+	var vs []float64
+	for _, f := range strings.Split(s, ",") {
+		if f = strings.TrimSpace(f); f == "" {
+			continue
+		}
+		v, err := strconv.ParseFloat(f, 64)
+		if err != nil || v <= 0 {
+			return nil, fmt.Errorf("invalid value %q in %s", f, name)
+		}
+		vs = append(vs, v)
+	}
+	return vs, nil
+}
+
+// specs returns the waller specs for all combinations of the parameters.
+func (c *Optimize) specs() ([]*Spec, error) {
+	lists := make(map[string][]float64)
+	for name, s := range map[string]string{
+		"begin-prices":       c.beginPrices,
+		"end-prices":         c.endPrices,
+		"buy-intervals":      c.buyIntervals,
+		"buy-interval-pcts":  c.buyIntervalPcts,
+		"profit-margins":     c.profitMargins,
+		"profit-margin-pcts": c.profitMarginPcts,
+		"cancel-offset-pcts": c.cancelOffsetPcts,
+	} {
+		vs, err := parseFloats(name, s)
+		if err != nil {
+			return nil, err
+		}
+		lists[name] = vs
+	}
+	for _, name := range []string{"begin-prices", "end-prices", "cancel-offset-pcts"} {
+		if len(lists[name]) == 0 {
+			return nil, fmt.Errorf("%s cannot be empty", name)
+		}
+	}
+	if len(lists["buy-intervals"])+len(lists["buy-interval-pcts"]) == 0 {
+		return nil, fmt.Errorf("one of buy-intervals or buy-interval-pcts must be given")
+	}
+	if len(lists["profit-margins"])+len(lists["profit-margin-pcts"]) == 0 {
+		return nil, fmt.Errorf("one of profit-margins or profit-margin-pcts must be given")
+	}
+
+	type interval struct{ fixed, pct float64 }
+	var intervals, margins []interval
+	for _, v := range lists["buy-intervals"] {
+		intervals = append(intervals, interval{fixed: v})
+	}
+	for _, v := range lists["buy-interval-pcts"] {
+		intervals = append(intervals, interval{pct: v})
+	}
+	for _, v := range lists["profit-margins"] {
+		margins = append(margins, interval{fixed: v})
+	}
+	for _, v := range lists["profit-margin-pcts"] {
+		margins = append(margins, interval{pct: v})
+	}
+
+	var specs []*Spec
+	for _, begin := range lists["begin-prices"] {
+		for _, end := range lists["end-prices"] {
+			if end <= begin {
+				continue
+			}
+			for _, bi := range intervals {
+				for _, pm := range margins {
+					for _, co := range lists["cancel-offset-pcts"] {
+						spec := &Spec{
+							feePercentage:   c.feePct,
+							beginPriceRange: begin,
+							endPriceRange:   end,
+							buyInterval:     bi.fixed,
+							buyIntervalPct:  bi.pct,
+							profitMargin:    pm.fixed,
+							profitMarginPct: pm.pct,
+							buySize:         c.buySize,
+							cancelOffsetPct: co,
+						}
+						if err := spec.Check(); err != nil {
+							log.Printf("skipping invalid spec %s: %v", spec.flagArgs(), err)
+							continue
+						}
+						specs = append(specs, spec)
+					}
+				}
+			}
+		}
+	}
+	if len(specs) == 0 {
+		return nil, fmt.Errorf("no valid specs in the parameter combinations")
+	}
+	return specs, nil
+}
+
+func (c *Optimize) prices(ctx context.Context, args []string) ([]float64, time.Duration, error) {
+	if len(args) == 1 {
+		prices, err := readPricesFile(args[0])
+		if err != nil {
+			return nil, 0, err
+		}
+		return prices, time.Duration(len(prices)) * c.priceInterval, nil
+	}
+	if c.product == "" {
+		return nil, 0, fmt.Errorf("one of prices file argument or product flag is required")
+	}
+
+	end := time.Now()
+	if c.endDate != "" {
+		v, err := time.Parse("2006-01-02", c.endDate)
+		if err != nil {
+			return nil, 0, fmt.Errorf("could not parse end date argument: %w", err)
+		}
+		end = v
+	}
+	begin := end.AddDate(0, 0, -90)
+	if c.beginDate != "" {
+		v, err := time.Parse("2006-01-02", c.beginDate)
+		if err != nil {
+			return nil, 0, fmt.Errorf("could not parse begin date argument: %w", err)
+		}
+		begin = v
+	}
+
+	db, closer, err := c.DBFlags.GetDatabase(ctx)
+	if err != nil {
+		return nil, 0, fmt.Errorf("could not create database client: %w", err)
+	}
+	defer closer()
+
+	candles, err := loadCandles(ctx, db, c.product, begin, end)
+	if err != nil {
+		return nil, 0, err
+	}
+	if len(candles) == 0 {
+		return nil, 0, fmt.Errorf("no candles found for product %q", c.product)
+	}
+	first, last := candles[0], candles[len(candles)-1]
+	duration := last.StartTime.Time.Add(last.Duration).Sub(first.StartTime.Time)
+	return candlePath(candles), duration, nil
+}
+
+func (c *Optimize) run(ctx context.Context, args []string) error {
+	if len(args) > 1 {
+		return fmt.Errorf("this command takes at most one prices file argument")
+	}
+	if c.buySize <= 0 {
+		return fmt.Errorf("buy size must be positive")
+	}
+	less, ok := optimizeSorters[c.sortBy]
+	if !ok {
+		return fmt.Errorf("invalid sort-by value %q", c.sortBy)
+	}
+
+	specs, err := c.specs()
+	if err != nil {
+		return err
+	}
+	prices, duration, err := c.prices(ctx, args)
+	if err != nil {
+		return err
+	}
+	if len(prices) < 2 || duration <= 0 {
+		return fmt.Errorf("need at least two prices for the simulation")
+	}
+
+	results := make([]*optimizeResult, len(specs))
+	indexCh := make(chan int)
+	var wg sync.WaitGroup
+	for range runtime.NumCPU() {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indexCh {
+				results[i] = simulateSpec(specs[i], prices, duration)
+			}
+		}()
+	}
+	for i := range specs {
+		if ctx.Err() != nil {
+			break
+		}
+		indexCh <- i
+	}
+	close(indexCh)
+	wg.Wait()
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	sort.SliceStable(results, func(i, j int) bool {
+		return less(results[i], results[j])
+	})
+
+	fmt.Printf("Simulated %d specs over %d prices (%s)\n", len(specs), len(prices), duration.Round(time.Minute))
+	fmt.Println()
+	printOptimizeResults(os.Stdout, results[:min(c.top, len(results))])
+
+	best := results[0]
+	fmt.Println()
+	fmt.Printf("Best spec: %s\n", best.spec.flagArgs())
+	return nil
+}
+
+var optimizeSorters = map[string]func(a, b *optimizeResult) bool{
+	"profit":      func(a, b *optimizeResult) bool { return a.profit > b.profit },
+	"return":      func(a, b *optimizeResult) bool { return a.apr > b.apr },
+	"utilization": func(a, b *optimizeResult) bool { return a.utilization > b.utilization },
+	"unsold":      func(a, b *optimizeResult) bool { return a.maxUnsoldValue < b.maxUnsoldValue },
+}
+
+func simulateSpec(spec *Spec, prices []float64, duration time.Duration) *optimizeResult {
+	pairs := spec.BuySellPairs()
+	s := newSimulator(pairs, spec.FeePct())
+	budget, _ := waller.Analyze(pairs, spec.FeePct()).Budget().Float64()
+
+	var maxUnsold, heldSum float64
+	for _, price := range prices {
+		s.step(price)
+		heldSum += s.heldValue
+		maxUnsold = max(maxUnsold, s.heldValue)
+	}
+
+	years := duration.Hours() / 24 / 365
+	return &optimizeResult{
+		spec:           spec,
+		numSells:       s.numSells,
+		profit:         s.profit,
+		budget:         budget,
+		apr:            s.profit / budget / years * 100,
+		utilization:    heldSum / float64(len(prices)) / budget * 100,
+		maxUnsoldValue: maxUnsold,
+	}
+}
+
+func printOptimizeResults(w io.Writer, results []*optimizeResult) {
+	tw := tabwriter.NewWriter(w, 0, 0, 1, ' ', tabwriter.AlignRight)
+	fmt.Fprintf(tw, "Rank\tBegin\tEnd\tInterval\tMargin\tCancel%%\tPairs\tSells\tProfit\tBudget\tReturn%%\tUtilization%%\tMaxUnsold\t\n")
+	for i, r := range results {
+		s := r.spec
+		interval := strconv.FormatFloat(s.buyInterval, 'f', -1, 64)
+		if s.buyIntervalPct > 0 {
+			interval = strconv.FormatFloat(s.buyIntervalPct, 'f', -1, 64) + "%"
+		}
+		margin := strconv.FormatFloat(s.profitMargin, 'f', -1, 64)
+		if s.profitMarginPct > 0 {
+			margin = strconv.FormatFloat(s.profitMarginPct, 'f', -1, 64) + "%"
+		}
+		fmt.Fprintf(tw, "%d\t%g\t%g\t%s\t%s\t%g\t%d\t%d\t%.3f\t%.3f\t%.2f\t%.2f\t%.3f\t\n",
+			i+1, s.beginPriceRange, s.endPriceRange, interval, margin, s.cancelOffsetPct,
+			len(s.pairs), r.numSells, r.profit, r.budget, r.apr, r.utilization, r.maxUnsoldValue)
+	}
+	tw.Flush()
+}
@@ -0,0 +1,224 @@
+// Copyright (c) 2025 BVK Chaitanya
+
+package waller
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/bvk/tradebot/subcmds/cmdutil"
+	"github.com/bvk/tradebot/waller"
+	"github.com/visvasity/cli"
+)
+
+type Plan struct {
+	cmdutil.DBFlags
+
+	product string
+
+	beginDate, endDate string
+
+	fillsPerDay float64
+	rangeDays   float64
+	rangeSigmas float64
+	atrDays     int
+
+	buySize         float64
+	feePct          float64
+	cancelOffsetPct float64
+
+	printPairs bool
+}
+
+func (c *Plan) Command() (string, *flag.FlagSet, cli.CmdFunc) {
+	fset := flag.NewFlagSet("plan", flag.ContinueOnError)
+	c.DBFlags.SetFlags(fset)
+	fset.StringVar(&c.product, "product", "", "product id with the candles data")
+	fset.StringVar(&c.beginDate, "begin-date", "", "date of start day in YYYY-MM-DD format (default 90 days ago)")
+	fset.StringVar(&c.endDate, "end-date", "", "date of stop day in YYYY-MM-DD format (default today)")
+	fset.Float64Var(&c.fillsPerDay, "fills-per-day", 4, "target number of buy and sell fills per day")
+	fset.Float64Var(&c.rangeDays, "range-days", 30, "number of days the price range should cover")
+	fset.Float64Var(&c.rangeSigmas, "range-sigmas", 1.5, "price range width in standard deviations over the range-days")
+	fset.IntVar(&c.atrDays, "atr-days", 14, "number of days for the average true range")
+	fset.Float64Var(&c.buySize, "buy-size", 0, "asset buy-size for the trade")
+	fset.Float64Var(&c.feePct, "fee-pct", 0.25, "exchange fee percentage to adjust sell margin")
+	fset.Float64Var(&c.cancelOffsetPct, "cancel-offset-pct", 5, "cancel-at price as pct of middle of the price range")
+	fset.BoolVar(&c.printPairs, "print-pairs", true, "when true, prints buy-sell points")
+	fset.BoolVar(&skipVolatilityTable, "skip-volatility-table", true, "when false, prints analysis based on volatility")
+	return "plan", fset, cli.CmdFunc(c.run)
+}
+
+func (c *Plan) Purpose() string {
+	return "Proposes a waller spec from the historical candles data"
+}
+
+func (c *Plan) Description() string {
+	return `
+
+Command "plan" reads the historical candles data for a product from the
+database and proposes a waller spec that targets a number of fills per day.
+
+Price range is centered at the last closing price and covers range-sigmas
+standard deviations of the daily returns scaled to range-days. Buy interval is
+chosen so that a simulation of the proposed spec over the historical candle
+prices executes fills-per-day buys or sells on average. Profit margin matches
+the buy interval, but is never below three times the fee percentage.
+
+Candles data must be synced into the database beforehand with the "coinbase
+sync -data-type=candles" command.
+
+`
+}
+
+func (c *Plan) run(ctx context.Context, args []string) error {
+	if len(args) != 0 {
+		return fmt.Errorf("this command takes no arguments")
+	}
+	if c.product == "" {
+		return fmt.Errorf("product id cannot be empty")
+	}
+	if c.buySize <= 0 {
+		return fmt.Errorf("buy size must be positive")
+	}
+	if c.fillsPerDay <= 0 || c.rangeDays <= 0 || c.rangeSigmas <= 0 || c.atrDays <= 0 {
+		return fmt.Errorf("fills-per-day, range-days, range-sigmas and atr-days must be positive")
+	}
+
+	end := time.Now()
+	if c.endDate != "" {
+		v, err := time.Parse("2006-01-02", c.endDate)
+		if err != nil {
+			return fmt.Errorf("could not parse end date argument: %w", err)
+		}
+		end = v
+	}
+	begin := end.AddDate(0, 0, -90)
+	if c.beginDate != "" {
+		v, err := time.Parse("2006-01-02", c.beginDate)
+		if err != nil {
+			return fmt.Errorf("could not parse begin date argument: %w", err)
+		}
+		begin = v
+	}
+
+	db, closer, err := c.DBFlags.GetDatabase(ctx)
+	if err != nil {
+		return fmt.Errorf("could not create database client: %w", err)
+	}
+	defer closer()
+
+	candles, err := loadCandles(ctx, db, c.product, begin, end)
+	if err != nil {
+		return err
+	}
+	days := dailyStats(candles)
+	if len(days) < 2 {
+		return fmt.Errorf("need candles data for at least two days (found %d)", len(days))
+	}
+
+	var closes, ranges []float64
+	for _, d := range days {
+		closes = append(closes, d.close)
+		if d.close > 0 {
+			ranges = append(ranges, (d.high-d.low)/d.close*100)
+		}
+	}
+	last := days[len(days)-1].close
+	dailyVolPct := stddev(logReturns(closes)) * 100
+	atr := averageTrueRange(days, c.atrDays)
+	rangePct := percentile(ranges, 50)
+
+	// Price range is centered at the last close.
+	widthPct := min(dailyVolPct*math.Sqrt(c.rangeDays)*c.rangeSigmas, 90)
+	beginPrice := last * (1 - widthPct/100)
+	endPrice := last * (1 + widthPct/100)
+
+	// Buy interval is searched for the wanted number of fills over the
+	// historical prices in the proposed price range.
+	prices := candlePath(candles)
+	newSpec := func(intervalPct float64) *Spec {
+		return &Spec{
+			feePercentage:   c.feePct,
+			beginPriceRange: roundPrice(beginPrice),
+			endPriceRange:   roundPrice(endPrice),
+			buyIntervalPct:  roundPct(intervalPct),
+			profitMarginPct: roundPct(max(intervalPct, 3*c.feePct)),
+			buySize:         c.buySize,
+			cancelOffsetPct: c.cancelOffsetPct,
+		}
+	}
+	wantFills := c.fillsPerDay * float64(len(days))
+	lo, hi := 0.01, min(widthPct, 50)
+	for range 30 {
+		mid := math.Sqrt(lo * hi)
+		spec := newSpec(mid)
+		if err := spec.Check(); err == nil && float64(simulatedFills(spec, prices)) > wantFills {
+			lo = mid
+		} else {
+			hi = mid
+		}
+	}
+	intervalPct := hi
+
+	spec := newSpec(intervalPct)
+	if err := spec.Check(); err != nil {
+		return fmt.Errorf("could not create a valid spec: %w", err)
+	}
+	fills := simulatedFills(spec, prices)
+
+	fmt.Printf("Product: %s\n", c.product)
+	fmt.Printf("Candles: %d (%d days from %s to %s)\n", len(candles), len(days), days[0].day.Format(time.DateOnly), days[len(days)-1].day.Format(time.DateOnly))
+	fmt.Printf("Last close: %.5f\n", last)
+
+	fmt.Println()
+	fmt.Printf("Daily volatility: %.3f%%\n", dailyVolPct)
+	fmt.Printf("Average true range (%d days): %.5f (%.3f%%)\n", c.atrDays, atr, atr/last*100)
+	fmt.Printf("Median daily range: %.3f%%\n", rangePct)
+
+	fmt.Println()
+	fmt.Printf("Price range: %.5f - %.5f (+/-%.3f%%)\n", spec.beginPriceRange, spec.endPriceRange, widthPct)
+	fmt.Printf("Buy interval: %.3f%%\n", spec.buyIntervalPct)
+	fmt.Printf("Profit margin: %.3f%%\n", spec.profitMarginPct)
+	if spec.profitMarginPct > spec.buyIntervalPct {
+		fmt.Printf("  (raised from the buy interval to cover the fees)\n")
+	}
+	fmt.Printf("Simulated fills per day: %.2f\n", float64(fills)/float64(len(days)))
+
+	fmt.Println()
+	fmt.Printf("Spec: %s\n", spec.flagArgs())
+
+	fmt.Println()
+	pairs := spec.BuySellPairs()
+	PrintAnalysis(waller.Analyze(pairs, spec.FeePct()))
+
+	if c.printPairs {
+		printPairs(pairs, spec.FeePct())
+	}
+	return nil
+}
+
+// simulatedFills returns the number of buy and sell fills for a spec over the
+// prices.
+func simulatedFills(spec *Spec, prices []float64) int {
+	s := newSimulator(spec.BuySellPairs(), spec.FeePct())
+	for _, p := range prices {
+		s.step(p)
+	}
+	return s.numBuys + s.numSells
+}
+
+// roundPrice rounds a price to three decimal places or five decimal places
+// for the prices below one.
+func roundPrice(v float64) float64 {
+	if v >= 1 {
+		return math.Round(v*1000) / 1000
+	}
+	return math.Round(v*100000) / 100000
+}
+
+func roundPct(v float64) float64 {
+	return math.Round(v*1000) / 1000
+}
@@ -9,6 +9,7 @@ import (
 	"os"
 	"text/tabwriter"
 
+	"github.com/bvk/tradebot/point"
 	"github.com/bvk/tradebot/waller"
 	"github.com/shopspring/decimal"
 	"github.com/visvasity/cli"
@@ -32,21 +33,24 @@ func (c *Query) run(ctx context.Context, args []string) error {
 	PrintAnalysis(a)
 
 	if c.printPairs {
-		d100 := decimal.NewFromInt(100)
-		feePctDec := decimal.NewFromFloat(feePct)
-		tw := tabwriter.NewWriter(os.Stdout, 0, 0, 1, ' ', tabwriter.AlignRight)
-		fmt.Fprintf(tw, "BuySize\tBuyPrice\tSellSize\tSellPrice\tPriceMargin\tProfit\t\n")
-		for _, p := range pairs {
-			bfee := p.Buy.Price.Mul(p.Buy.Size).Mul(feePctDec).Div(d100)
-			sfee := p.Sell.Price.Mul(p.Sell.Size).Mul(feePctDec).Div(d100)
-			profit := p.ValueMargin().Sub(bfee).Sub(sfee)
-			fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%s\t\n", p.Buy.Size.StringFixed(5), p.Buy.Price.StringFixed(5), p.Sell.Size.StringFixed(5), p.Sell.Price.StringFixed(5), p.Sell.Price.Sub(p.Buy.Price).StringFixed(5), profit.StringFixed(2))
-		}
-		tw.Flush()
+		printPairs(pairs, decimal.NewFromFloat(feePct))
 	}
 	return nil
 }
 
+func printPairs(pairs []*point.Pair, feePct decimal.Decimal) {
+	d100 := decimal.NewFromInt(100)
+	tw := tabwriter.NewWriter(os.Stdout, 0, 0, 1, ' ', tabwriter.AlignRight)
+	fmt.Fprintf(tw, "BuySize\tBuyPrice\tSellSize\tSellPrice\tPriceMargin\tProfit\t\n")
+	for _, p := range pairs {
+		bfee := p.Buy.Price.Mul(p.Buy.Size).Mul(feePct).Div(d100)
+		sfee := p.Sell.Price.Mul(p.Sell.Size).Mul(feePct).Div(d100)
+		profit := p.ValueMargin().Sub(bfee).Sub(sfee)
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%s\t\n", p.Buy.Size.StringFixed(5), p.Buy.Price.StringFixed(5), p.Sell.Size.StringFixed(5), p.Sell.Price.StringFixed(5), p.Sell.Price.Sub(p.Buy.Price).StringFixed(5), profit.StringFixed(2))
+	}
+	tw.Flush()
+}
+
 func (c *Query) Command() (string, *flag.FlagSet, cli.CmdFunc) {
 	fset := flag.NewFlagSet("query", flag.ContinueOnError)
 	c.spec.SetFlags(fset)
@@ -0,0 +1,182 @@
+// Copyright (c) 2025 BVK Chaitanya
+
+package waller
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/bvk/tradebot/gobs"
+	"github.com/bvk/tradebot/point"
+	"github.com/shopspring/decimal"
+)
+
+// simPair is a buy-sell pair in floating point form for fast simulations.
+type simPair struct {
+	buy, sell, size float64
+
+	// cancel is the cancel price for the buy order.
+	cancel float64
+
+	// fee is the total fee for a buy-and-sell loop.
+	fee float64
+}
+
+// simulator replays a ticker price sequence over the buy-sell pairs of a
+// waller in floating point and with binary search for the price crossings, so
+// that it can be used for large parameter sweeps.
+//
+// Buy orders are placed and canceled like the limiter jobs do: a buy order is
+// on the book only when the last ticker price is at or above the buy price and
+// below the buy cancel price. So, a buy is executed only when the price falls
+// through the buy price from a ticker price below the cancel price. Sell
+// orders are placed above their cancel price, which is below the sell price,
+// so sells are executed whenever the price rises through the sell price.
+type simulator struct {
+	pairs []simPair
+
+	// sellOrder holds pair indices sorted by the sell price.
+	sellOrder []int
+
+	holding []bool
+
+	numHeld   int
+	heldSize  float64
+	heldValue float64
+
+	numBuys  int
+	numSells int
+	profit   float64
+
+	last    float64
+	started bool
+}
+
+func newSimulator(pairs []*point.Pair, feePct decimal.Decimal) *simulator {
+	fpct, _ := feePct.Float64()
+	s := &simulator{
+		pairs:   make([]simPair, 0, len(pairs)),
+		holding: make([]bool, len(pairs)),
+	}
+	for _, p := range pairs {
+		buy, _ := p.Buy.Price.Float64()
+		sell, _ := p.Sell.Price.Float64()
+		size, _ := p.Buy.Size.Float64()
+		cancel, _ := p.Buy.Cancel.Float64()
+		s.pairs = append(s.pairs, simPair{
+			buy:    buy,
+			sell:   sell,
+			size:   size,
+			cancel: cancel,
+			fee:    (buy + sell) * size * fpct / 100,
+		})
+	}
+	sort.Slice(s.pairs, func(i, j int) bool {
+		return s.pairs[i].buy < s.pairs[j].buy
+	})
+	s.sellOrder = make([]int, len(s.pairs))
+	for i := range s.sellOrder {
+		s.sellOrder[i] = i
+	}
+	sort.Slice(s.sellOrder, func(i, j int) bool {
+		return s.pairs[s.sellOrder[i]].sell < s.pairs[s.sellOrder[j]].sell
+	})
+	return s
+}
+
+// unrealized returns the profit or loss of the held inventory at a price.
+func (s *simulator) unrealized(price float64) float64 {
+	return price*s.heldSize - s.heldValue
+}
+
+// fullyDeployed returns true if all buy points are holding the inventory.
+func (s *simulator) fullyDeployed() bool {
+	return s.numHeld == len(s.pairs)
+}
+
+// step moves the ticker to a new price and executes the buys and sells
+// crossed by the move.
+func (s *simulator) step(price float64) {
+	if !s.started {
+		s.last, s.started = price, true
+		return
+	}
+	last := s.last
+	lo, hi := min(s.last, price), max(s.last, price)
+	s.last = price
+	if lo == hi {
+		return
+	}
+
+	// Buys are executed only when the price is decreasing and only if the buy
+	// order was on the book at the last ticker price. Buy orders are always
+	// canceled above their cancel price, so a price jump from above the cancel
+	// price misses the buy.
+	if price == lo {
+		for i := sort.Search(len(s.pairs), func(i int) bool { return s.pairs[i].buy > lo }); i < len(s.pairs) && s.pairs[i].buy < hi; i++ {
+			if p := &s.pairs[i]; !s.holding[i] && last < p.cancel {
+				s.holding[i] = true
+				s.numBuys++
+				s.numHeld++
+				s.heldSize += p.size
+				s.heldValue += p.buy * p.size
+			}
+		}
+		return
+	}
+
+	// Sells are executed only when the price is increasing.
+	for j := sort.Search(len(s.sellOrder), func(j int) bool { return s.pairs[s.sellOrder[j]].sell > lo }); j < len(s.sellOrder); j++ {
+		i := s.sellOrder[j]
+		p := &s.pairs[i]
+		if p.sell >= hi {
+			break
+		}
+		if s.holding[i] {
+			s.holding[i] = false
+			s.numHeld--
+			s.heldSize -= p.size
+			s.heldValue -= p.buy * p.size
+			s.numSells++
+			s.profit += (p.sell-p.buy)*p.size - p.fee
+		}
+	}
+}
+
+// readPricesFile reads ticker prices from a file with floating point values
+// separated by spaces or newlines.
+func readPricesFile(fpath string) ([]float64, error) {
+	data, err := os.ReadFile(fpath)
+	if err != nil {
+		return nil, err
+	}
+	values := strings.Fields(string(data))
+	prices := make([]float64, len(values))
+	for i, value := range values {
+		p, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return nil, fmt.Errorf("could not parse %q as a float: %w", value, err)
+		}
+		prices[i] = p
+	}
+	return prices, nil
+}
+
+// candlePath returns a ticker price sequence from the candles where each
+// candle contributes it's low and high prices in the likely order.
+func candlePath(candles []*gobs.Candle) []float64 {
+	prices := make([]float64, 0, 2*len(candles))
+	for _, c := range candles {
+		low, _ := c.Low.Float64()
+		high, _ := c.High.Float64()
+		if c.Close.LessThan(c.Open) {
+			prices = append(prices, high, low)
+		} else {
+			prices = append(prices, low, high)
+		}
+	}
+	return prices
+}
@@ -7,6 +7,8 @@ import (
 	"fmt"
 	"log"
 	"sort"
+	"strconv"
+	"strings"
 
 	"github.com/bvk/tradebot/point"
 	"github.com/shopspring/decimal"
@@ -56,6 +58,26 @@ func (s *Spec) FeePct() decimal.Decimal {
 	return decimal.NewFromFloat(s.feePercentage)
 }
 
+// flagArgs returns the command-line flags that recreate the spec.
+func (s *Spec) flagArgs() string {
+	var args []string
+	add := func(name string, v float64) {
+		if v != 0 {
+			args = append(args, fmt.Sprintf("-%s=%s", name, strconv.FormatFloat(v, 'f', -1, 64)))
+		}
+	}
+	add("begin-price", s.beginPriceRange)
+	add("end-price", s.endPriceRange)
+	add("buy-interval", s.buyInterval)
+	add("buy-interval-pct", s.buyIntervalPct)
+	add("profit-margin", s.profitMargin)
+	add("profit-margin-pct", s.profitMarginPct)
+	add("buy-size", s.buySize)
+	add("cancel-offset-pct", s.cancelOffsetPct)
+	add("fee-pct", s.feePercentage)
+	return strings.Join(args, " ")
+}
+
 func (s *Spec) setDefaults() {
 	if s.sellSize == 0 {
 		s.sellSize = s.buySize
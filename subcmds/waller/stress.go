@@ -0,0 +1,254 @@
+// Copyright (c) 2025 BVK Chaitanya
+
+package waller
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"math/rand/v2"
+	"os"
+	"runtime"
+	"sync"
+	"text/tabwriter"
+	"time"
+
+	"github.com/bvk/tradebot/subcmds/cmdutil"
+	"github.com/bvk/tradebot/waller"
+	"github.com/visvasity/cli"
+)
+
+type Stress struct {
+	cmdutil.DBFlags
+
+	spec Spec
+
+	product string
+
+	beginDate, endDate string
+
+	model string
+	paths int
+	days  int
+	step  time.Duration
+	seed  uint64
+
+	startPrice float64
+}
+
+func (c *Stress) Command() (string, *flag.FlagSet, cli.CmdFunc) {
+	fset := flag.NewFlagSet("stress", flag.ContinueOnError)
+	c.DBFlags.SetFlags(fset)
+	c.spec.SetFlags(fset)
+	fset.StringVar(&c.product, "product", "", "product id with the candles data")
+	fset.StringVar(&c.beginDate, "begin-date", "", "date of start day for the candles in YYYY-MM-DD format (default 365 days ago)")
+	fset.StringVar(&c.endDate, "end-date", "", "date of stop day for the candles in YYYY-MM-DD format (default today)")
+	fset.StringVar(&c.model, "model", "bootstrap", "one of gbm|bootstrap|regime")
+	fset.IntVar(&c.paths, "paths", 1000, "number of synthetic price paths")
+	fset.IntVar(&c.days, "days", 90, "number of days in each price path")
+	fset.DurationVar(&c.step, "step", 15*time.Minute, "time interval between successive prices in the paths")
+	fset.Uint64Var(&c.seed, "seed", 0, "random seed for the price paths (default is random)")
+	fset.Float64Var(&c.startPrice, "start-price", 0, "starting price for the paths (default is the last close)")
+	return "stress", fset, cli.CmdFunc(c.run)
+}
+
+func (c *Stress) Purpose() string {
+	return "Runs Monte Carlo stress tests for a waller spec"
+}
+
+func (c *Stress) Description() string {
+	return `
+
+Command "stress" simulates a waller spec over many synthetic price paths
+generated from the historical candles data of a product and reports the
+distributions of profit, drawdown, days with fully deployed budget and the
+stranded inventory left at the end of the paths.
+
+Price paths are generated with one of the following models:
+
+  - gbm: Geometric brownian motion with the historical drift and volatility
+  - bootstrap: Historical returns sampled in one day blocks
+  - regime: Calm and volatile regimes switching as observed in the history
+
+Drawdown is measured over the realized profit plus the unrealized profit or
+loss of the held inventory. Candles data must be synced into the database
+beforehand with the "coinbase sync -data-type=candles" command.
+
+`
+}
+
+type stressResult struct {
+	profit        float64
+	equity        float64
+	drawdown      float64
+	deployedDays  float64
+	strandedValue float64
+	strandedLoss  float64
+}
+
+func (c *Stress) run(ctx context.Context, args []string) error {
+	if len(args) != 0 {
+		return fmt.Errorf("this command takes no arguments")
+	}
+	if err := c.spec.Check(); err != nil {
+		return err
+	}
+	if c.product == "" {
+		return fmt.Errorf("product id cannot be empty")
+	}
+	if c.paths <= 0 || c.days <= 0 || c.step < time.Minute || c.step > 24*time.Hour {
+		return fmt.Errorf("paths and days must be positive and step must be in between 1m-24h")
+	}
+
+	end := time.Now()
+	if c.endDate != "" {
+		v, err := time.Parse("2006-01-02", c.endDate)
+		if err != nil {
+			return fmt.Errorf("could not parse end date argument: %w", err)
+		}
+		end = v
+	}
+	begin := end.AddDate(0, 0, -365)
+	if c.beginDate != "" {
+		v, err := time.Parse("2006-01-02", c.beginDate)
+		if err != nil {
+			return fmt.Errorf("could not parse begin date argument: %w", err)
+		}
+		begin = v
+	}
+
+	db, closer, err := c.DBFlags.GetDatabase(ctx)
+	if err != nil {
+		return fmt.Errorf("could not create database client: %w", err)
+	}
+	defer closer()
+
+	candles, err := loadCandles(ctx, db, c.product, begin, end)
+	if err != nil {
+		return err
+	}
+	closes := resampleCloses(candles, c.step)
+	rets := logReturns(closes)
+	stepsPerDay := int(24 * time.Hour / c.step)
+	if len(rets) < 2*stepsPerDay {
+		return fmt.Errorf("need candles data for at least two days (found %d returns)", len(rets))
+	}
+
+	var model pathModel
+	switch c.model {
+	case "gbm":
+		model = newGBMModel(rets)
+	case "bootstrap":
+		model = newBootstrapModel(rets, stepsPerDay)
+	case "regime":
+		m, err := newRegimeModel(rets, stepsPerDay)
+		if err != nil {
+			return err
+		}
+		model = m
+	default:
+		return fmt.Errorf("invalid model name %q", c.model)
+	}
+
+	start := c.startPrice
+	if start <= 0 {
+		start = closes[len(closes)-1]
+	}
+	seed := c.seed
+	if seed == 0 {
+		seed = rand.Uint64()
+	}
+
+	pairs := c.spec.BuySellPairs()
+	analysis := waller.Analyze(pairs, c.spec.FeePct())
+	nsteps := c.days * stepsPerDay
+
+	results := make([]*stressResult, c.paths)
+	indexCh := make(chan int)
+	var wg sync.WaitGroup
+	for range runtime.NumCPU() {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			prices := make([]float64, nsteps)
+			for i := range indexCh {
+				r := rand.New(rand.NewPCG(seed, uint64(i)))
+				model.path(r, start, prices)
+
+				s := newSimulator(pairs, c.spec.FeePct())
+				s.step(start)
+				res := new(stressResult)
+				var peak float64
+				deployed := false
+				for j, price := range prices {
+					s.step(price)
+					equity := s.profit + s.unrealized(price)
+					peak = max(peak, equity)
+					res.drawdown = max(res.drawdown, peak-equity)
+					deployed = deployed || s.fullyDeployed()
+					if (j+1)%stepsPerDay == 0 {
+						if deployed {
+							res.deployedDays++
+						}
+						deployed = false
+					}
+				}
+				last := prices[len(prices)-1]
+				res.profit = s.profit
+				res.equity = s.profit + s.unrealized(last)
+				res.strandedValue = s.heldSize * last
+				res.strandedLoss = max(0, -s.unrealized(last))
+				results[i] = res
+			}
+		}()
+	}
+	for i := range c.paths {
+		if ctx.Err() != nil {
+			break
+		}
+		indexCh <- i
+	}
+	close(indexCh)
+	wg.Wait()
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	metrics := []struct {
+		name  string
+		value func(*stressResult) float64
+	}{
+		{"Realized Profit", func(r *stressResult) float64 { return r.profit }},
+		{"Profit With Unrealized", func(r *stressResult) float64 { return r.equity }},
+		{"Max Drawdown", func(r *stressResult) float64 { return r.drawdown }},
+		{"Fully Deployed Days", func(r *stressResult) float64 { return r.deployedDays }},
+		{"Stranded Inventory Value", func(r *stressResult) float64 { return r.strandedValue }},
+		{"Stranded Inventory Loss", func(r *stressResult) float64 { return r.strandedLoss }},
+	}
+
+	var losses int
+	for _, r := range results {
+		if r.equity < 0 {
+			losses++
+		}
+	}
+
+	fmt.Printf("Budget: %s\n", analysis.Budget().StringFixed(3))
+	fmt.Printf("Model: %s (%d paths of %d days from %.5f, seed %d)\n", c.model, c.paths, c.days, start, seed)
+	fmt.Printf("Historical returns: %d at %s interval\n", len(rets), c.step)
+	fmt.Printf("Probability of loss: %.2f%%\n", float64(losses)/float64(len(results))*100)
+	fmt.Println()
+
+	tw := tabwriter.NewWriter(os.Stdout, 0, 0, 1, ' ', tabwriter.AlignRight)
+	fmt.Fprintf(tw, "Metric\tMean\tP5\tP25\tP50\tP75\tP95\t\n")
+	for _, m := range metrics {
+		vs := make([]float64, len(results))
+		for i, r := range results {
+			vs[i] = m.value(r)
+		}
+		d := newDistribution(vs)
+		fmt.Fprintf(tw, "%s\t%.3f\t%.3f\t%.3f\t%.3f\t%.3f\t%.3f\t\n", m.name, d.mean, d.p5, d.p25, d.p50, d.p75, d.p95)
+	}
+	tw.Flush()
+	return nil
+}
@@ -8,10 +8,16 @@ import (
 	"flag"
 	"fmt"
 	"log"
+	"slices"
+	"strings"
+	"time"
 
 	"github.com/bvk/tradebot/api"
+	"github.com/bvk/tradebot/gobs"
 	"github.com/bvk/tradebot/subcmds/cmdutil"
 	"github.com/bvk/tradebot/subcmds/waller"
+	"github.com/bvk/tradebot/watcher"
+	"github.com/shopspring/decimal"
 	"github.com/visvasity/cli"
 )
 
@@ -25,6 +31,12 @@ type Add struct {
 	name     string
 
 	spec waller.Spec
+
+	fillModel      string
+	crossTicks     int
+	tickSize       float64
+	minTimeAtPrice time.Duration
+	simulateCancel bool
 }
 
 func (c *Add) check() error {
@@ -37,6 +49,12 @@ func (c *Add) check() error {
 	if err := c.spec.Check(); err != nil {
 		return err
 	}
+	if !slices.Contains(watcher.FillModes, c.fillModel) {
+		return fmt.Errorf("fill-model must be one of %s", strings.Join(watcher.FillModes, ", "))
+	}
+	if c.fillModel == "legacy" && (c.crossTicks != 0 || c.minTimeAtPrice != 0 || c.simulateCancel) {
+		return fmt.Errorf("fill model parameters require touch or cross fill-model")
+	}
 	return nil
 }
 
@@ -69,6 +87,15 @@ func (c *Add) Run(ctx context.Context, args []string) error {
 		Pairs:        pairs,
 		FeePct:       c.spec.FeePct(),
 	}
+	if c.fillModel != "legacy" {
+		req1.FillModel = &gobs.WatcherFillModel{
+			Mode:           c.fillModel,
+			CrossTicks:     c.crossTicks,
+			TickSize:       decimal.NewFromFloat(c.tickSize),
+			MinTimeAtPrice: c.minTimeAtPrice,
+			SimulateCancel: c.simulateCancel,
+		}
+	}
 	resp1, err := cmdutil.Post[api.WatchResponse](ctx, &c.ClientFlags, api.WatchPath, req1)
 	if err != nil {
 		return err
@@ -95,6 +122,11 @@ func (c *Add) Command() (string, *flag.FlagSet, cli.CmdFunc) {
 	fset.StringVar(&c.name, "name", "", "a name for the trader job")
 	fset.StringVar(&c.product, "product", "", "product id for the trader")
 	fset.StringVar(&c.exchange, "exchange", "coinbase", "exchange name for the product")
+	fset.StringVar(&c.fillModel, "fill-model", "legacy", "one of legacy|touch|cross conditions for simulated fills")
+	fset.IntVar(&c.crossTicks, "cross-ticks", 0, "number of ticks price must move beyond the order price in cross fill-model")
+	fset.Float64Var(&c.tickSize, "tick-size", 0, "price increment for the product (default is derived from the order prices)")
+	fset.DurationVar(&c.minTimeAtPrice, "min-time-at-price", 0, "minimum duration price must stay at or beyond the order price for a fill")
+	fset.BoolVar(&c.simulateCancel, "simulate-cancel", false, "when true, orders are open only when ticker is within the cancel offset as in limiters")
 	return "add", fset, cli.CmdFunc(c.Run)
 }
 
@@ -109,5 +141,24 @@ Command "add" creates a watcher job that simulates a waller job over real-time
 price movements from an exchange. See waller job's documentation for more
 details.
 
+By default, a buy is recorded when consecutive ticks cross the buy price and a
+sell is recorded when ticker is above the sell price, which may overstate the
+profits. More realistic fill conditions can be selected with the fill-model
+flag and it's parameters:
+
+  - touch: Orders are filled when price reaches the order price
+  - cross: Orders are filled when price moves beyond the order price by more
+    than cross-ticks ticks
+
+Price movement in between two ticks is treated as a candle with the lower and
+higher prices as it's low and high. With min-time-at-price, price must stay at
+or beyond the order price for the duration, which models the queue of other
+orders at the same price. With simulate-cancel, orders are considered open
+only after ticker comes within the cancel offset and are closed when ticker
+crosses the cancel price, just like the limiter jobs of a live waller.
+
+Simulated orders are always filled completely. Partial fills are not modeled,
+so results for the products with thin order books may still be optimistic.
+
 `
 }
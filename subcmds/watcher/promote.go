@@ -0,0 +1,113 @@
+// Copyright (c) 2025 BVK Chaitanya
+
+package watcher
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"text/tabwriter"
+
+	"github.com/bvk/tradebot/api"
+	"github.com/bvk/tradebot/namer"
+	"github.com/bvk/tradebot/subcmds/cmdutil"
+	"github.com/shopspring/decimal"
+	"github.com/visvasity/cli"
+)
+
+type Promote struct {
+	cmdutil.DBFlags
+
+	name string
+
+	minPrice float64
+	maxPrice float64
+
+	force bool
+}
+
+func (c *Promote) Run(ctx context.Context, args []string) error {
+	if len(args) != 1 {
+		return fmt.Errorf("this command takes one watcher argument")
+	}
+	arg := args[0]
+	if c.minPrice < 0 || c.maxPrice < 0 {
+		return fmt.Errorf("price range cannot be negative")
+	}
+
+	db, closer, err := c.DBFlags.GetDatabase(ctx)
+	if err != nil {
+		return fmt.Errorf("could not create database client: %w", err)
+	}
+	defer closer()
+
+	_, uid, _, err := namer.ResolveDB(ctx, db, arg)
+	if err != nil {
+		if !errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("could not resolve watcher argument %q: %w", arg, err)
+		}
+		uid = arg
+	}
+
+	req1 := &api.WatchPromoteRequest{
+		UID:      uid,
+		MinPrice: decimal.NewFromFloat(c.minPrice),
+		MaxPrice: decimal.NewFromFloat(c.maxPrice),
+		Force:    c.force,
+	}
+	resp1, err := cmdutil.Post[api.WatchPromoteResponse](ctx, &c.ClientFlags, api.WatchPromotePath, req1)
+	if err != nil {
+		return err
+	}
+
+	if c.name != "" {
+		req2 := &api.SetJobNameRequest{
+			UID:     resp1.UID,
+			JobName: c.name,
+		}
+		if _, err := cmdutil.Post[api.SetJobNameResponse](ctx, &c.ClientFlags, api.SetJobNamePath, req2); err != nil {
+			log.Printf("job is created, but could not set the job name (ignored): %v", err)
+		}
+	}
+
+	s := resp1.WatcherSummary
+	tw := tabwriter.NewWriter(os.Stdout, 0, 0, 1, ' ', tabwriter.AlignRight)
+	fmt.Fprintf(tw, "Job\tType\tPairs\tBudget\tDays\tBuys\tSells\tProfit\tReturn\tAnnual Return\t\n")
+	fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s%%\t%s%%\t\n", uid, "watcher", "-", s.Budget.StringFixed(3), s.NumDays().StringFixed(2), s.NumBuys.StringFixed(1), s.NumSells.StringFixed(1), s.Profit().StringFixed(3), s.ReturnPct().StringFixed(3), s.AnnualPct().StringFixed(3))
+	fmt.Fprintf(tw, "%s\t%s\t%d\t%s\t%s\t%s\t%s\t%s\t%s\t%s\t\n", resp1.UID, "waller", resp1.NumPairs, "-", "0.00", "0.0", "0.0", "0.000", "-", "-")
+	tw.Flush()
+	return nil
+}
+
+func (c *Promote) Command() (string, *flag.FlagSet, cli.CmdFunc) {
+	fset := flag.NewFlagSet("promote", flag.ContinueOnError)
+	c.DBFlags.SetFlags(fset)
+	fset.StringVar(&c.name, "name", "", "a name for the new waller job")
+	fset.Float64Var(&c.minPrice, "min-price", 0, "when non-zero, only pairs with buy price at or above this value are promoted")
+	fset.Float64Var(&c.maxPrice, "max-price", 0, "when non-zero, only pairs with buy price at or below this value are promoted")
+	fset.BoolVar(&c.force, "force", false, "when true, promotes an already superseded watcher again")
+	return "promote", fset, cli.CmdFunc(c.Run)
+}
+
+func (c *Promote) Purpose() string {
+	return "Creates a live waller job from a watcher"
+}
+
+func (c *Promote) Description() string {
+	return `
+
+Command "promote" creates a waller job with the buy/sell pairs of a watcher
+job, so that a promising simulation can be turned into real trading. Only the
+pairs with buy prices in the min-price and max-price range are used when the
+range is specified.
+
+Watcher is marked as superseded by the new waller, but continues to simulate
+the trades, so that simulated and real results can be compared later. The
+watcher's simulated summary at the time of promotion is printed next to the
+new job.
+
+`
+}
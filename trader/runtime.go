@@ -14,9 +14,28 @@ type Messenger interface {
 	SendMessage(context.Context, time.Time, string, ...interface{})
 }
 
+// CircuitBreaker tells the traders when new exchange orders must not be
+// created for a product, for example, during a market crash.
+type CircuitBreaker interface {
+	IsTripped() bool
+}
+
 type Runtime struct {
 	Exchange  exchange.Exchange
 	Database  kv.Database
 	Product   exchange.Product
 	Messenger Messenger
+
+	// Breaker is optional and can be nil.
+	Breaker CircuitBreaker
+
+	// RuntimeFor is optional and can be nil. When non-nil, it returns the
+	// runtime for another product, which is required by the jobs trading in
+	// multiple products or exchanges.
+	RuntimeFor func(ctx context.Context, exchangeName, productID string) (*Runtime, error)
+}
+
+// IsTripped returns true if new orders must not be created for the product.
+func (rt *Runtime) IsTripped() bool {
+	return rt.Breaker != nil && rt.Breaker.IsTripped()
 }
@@ -4,6 +4,7 @@ package trader
 
 import (
 	"context"
+	"errors"
 
 	"github.com/bvk/tradebot/gobs"
 	"github.com/bvk/tradebot/timerange"
@@ -11,6 +12,10 @@ import (
 	"github.com/shopspring/decimal"
 )
 
+// ErrPaused is returned by the trader Run methods when the trader stops by
+// itself and must remain paused till it is resumed by the user.
+var ErrPaused = errors.New("trader is paused")
+
 type Trader interface {
 	UID() string
 	ProductID() string
@@ -0,0 +1,100 @@
+// Copyright (c) 2025 BVK Chaitanya
+
+// Package trigger implements price conditions that must be satisfied before a
+// job begins trading. Jobs waiting for a trigger are said to be armed.
+package trigger
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/bvk/tradebot/gobs"
+	"github.com/shopspring/decimal"
+)
+
+var kinds = []string{"above", "below", "stay-above", "stay-below", "ma-above", "ma-below"}
+
+// Trigger is a price condition. Kinds "above" and "below" fire when the ticker
+// price crosses the price level. Kinds "stay-above" and "stay-below" fire when
+// ticker price stays above/below the price level for the window duration.
+// Kinds "ma-above" and "ma-below" fire when the moving average of ticker price
+// over the window duration crosses the price level.
+type Trigger struct {
+	Kind   string
+	Price  decimal.Decimal
+	Window time.Duration
+}
+
+// Parse parses a trigger in "kind:price" or "kind:price:window" format, for
+// example, "below:25000", "stay-above:30000:15m" or "ma-above:30000:1h".
+func Parse(s string) (*Trigger, error) {
+	fs := strings.Split(strings.ToLower(s), ":")
+	if len(fs) != 2 && len(fs) != 3 {
+		return nil, fmt.Errorf("trigger %q must be in kind:price[:window] format", s)
+	}
+	price, err := decimal.NewFromString(fs[1])
+	if err != nil {
+		return nil, fmt.Errorf("could not parse trigger price %q: %w", fs[1], err)
+	}
+	t := &Trigger{Kind: fs[0], Price: price}
+	if len(fs) == 3 {
+		d, err := time.ParseDuration(fs[2])
+		if err != nil {
+			return nil, fmt.Errorf("could not parse trigger window %q: %w", fs[2], err)
+		}
+		t.Window = d
+	}
+	if err := t.Check(); err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+func (t *Trigger) Check() error {
+	switch t.Kind {
+	case "above", "below":
+		if t.Window != 0 {
+			return fmt.Errorf("trigger kind %q doesn't take a window duration", t.Kind)
+		}
+	case "stay-above", "stay-below", "ma-above", "ma-below":
+		if t.Window <= 0 {
+			return fmt.Errorf("trigger kind %q requires a positive window duration", t.Kind)
+		}
+	default:
+		return fmt.Errorf("trigger kind %q is invalid (must be one of %s)", t.Kind, strings.Join(kinds, ", "))
+	}
+	if !t.Price.IsPositive() {
+		return fmt.Errorf("trigger price must be positive")
+	}
+	return nil
+}
+
+func (t *Trigger) String() string {
+	if t.Window == 0 {
+		return fmt.Sprintf("%s:%s", t.Kind, t.Price)
+	}
+	return fmt.Sprintf("%s:%s:%s", t.Kind, t.Price, t.Window)
+}
+
+func (t *Trigger) isAbove() bool {
+	return strings.HasSuffix(t.Kind, "above")
+}
+
+// ToGob returns the trigger in persistent form.
+func (t *Trigger) ToGob() *gobs.JobTrigger {
+	return &gobs.JobTrigger{
+		Kind:   t.Kind,
+		Price:  t.Price,
+		Window: t.Window,
+	}
+}
+
+// FromGob returns the trigger from it's persistent form.
+func FromGob(v *gobs.JobTrigger) *Trigger {
+	return &Trigger{
+		Kind:   v.Kind,
+		Price:  v.Price,
+		Window: v.Window,
+	}
+}
@@ -0,0 +1,120 @@
+// Copyright (c) 2025 BVK Chaitanya
+
+package trigger
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+type sample struct {
+	at    time.Time
+	price decimal.Decimal
+}
+
+// Watcher evaluates a trigger against a sequence of ticker prices. It is safe
+// for concurrent use.
+type Watcher struct {
+	mu sync.Mutex
+
+	trigger Trigger
+
+	// since is the time when ticker price has crossed to the firing side of the
+	// price level for the stay-* kinds and the first sample time for the ma-*
+	// kinds.
+	since time.Time
+
+	// samples hold the ticker prices in the moving average window for the ma-*
+	// kinds.
+	samples []sample
+
+	// last is the most recent value compared against the price level, which is
+	// either the ticker price or the moving average.
+	last   decimal.Decimal
+	lastAt time.Time
+
+	fired bool
+}
+
+func NewWatcher(t *Trigger) *Watcher {
+	return &Watcher{trigger: *t}
+}
+
+// Update evaluates the trigger with a ticker price and returns true if the
+// trigger has fired.
+func (w *Watcher) Update(price decimal.Decimal, at time.Time) bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.fired {
+		return true
+	}
+
+	t := &w.trigger
+	value := price
+	switch t.Kind {
+	case "above", "below":
+		w.fired = w.crossed(value)
+
+	case "stay-above", "stay-below":
+		if !w.crossed(value) {
+			w.since = time.Time{}
+			break
+		}
+		if w.since.IsZero() {
+			w.since = at
+		}
+		w.fired = at.Sub(w.since) >= t.Window
+
+	case "ma-above", "ma-below":
+		if w.since.IsZero() {
+			w.since = at
+		}
+		w.samples = append(w.samples, sample{at: at, price: price})
+		for len(w.samples) > 1 && at.Sub(w.samples[0].at) > t.Window {
+			w.samples = w.samples[1:]
+		}
+		var sum decimal.Decimal
+		for _, s := range w.samples {
+			sum = sum.Add(s.price)
+		}
+		value = sum.Div(decimal.NewFromInt(int64(len(w.samples))))
+		// Moving average is valid only after watching for the whole window.
+		w.fired = at.Sub(w.since) >= t.Window && w.crossed(value)
+	}
+
+	w.last, w.lastAt = value, at
+	return w.fired
+}
+
+func (w *Watcher) crossed(value decimal.Decimal) bool {
+	if w.trigger.isAbove() {
+		return value.GreaterThanOrEqual(w.trigger.Price)
+	}
+	return value.LessThanOrEqual(w.trigger.Price)
+}
+
+// Distance returns a human readable description of how far the trigger is
+// from firing.
+func (w *Watcher) Distance() string {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.fired {
+		return "fired"
+	}
+	if w.lastAt.IsZero() || w.last.IsZero() {
+		return "waiting for ticker"
+	}
+
+	t := &w.trigger
+	if (t.Kind == "stay-above" || t.Kind == "stay-below") && !w.since.IsZero() {
+		return fmt.Sprintf("held %s of %s", w.lastAt.Sub(w.since).Round(time.Second), t.Window)
+	}
+
+	pct := t.Price.Sub(w.last).Div(w.last).Mul(decimal.NewFromInt(100))
+	return fmt.Sprintf("%s%% (at %s)", pct.StringFixed(2), w.last.StringFixed(3))
+}
@@ -0,0 +1,58 @@
+// Copyright (c) 2025 BVK Chaitanya
+
+package trigger
+
+import (
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+func TestWatcher(t *testing.T) {
+	now := time.Now()
+	d := decimal.NewFromInt
+
+	type update struct {
+		offset time.Duration
+		price  int64
+		fired  bool
+	}
+
+	tests := []struct {
+		trigger string
+		updates []update
+	}{
+		{
+			trigger: "below:100",
+			updates: []update{{0, 110, false}, {time.Second, 101, false}, {2 * time.Second, 100, true}},
+		},
+		{
+			trigger: "stay-above:100:10m",
+			updates: []update{{0, 101, false}, {5 * time.Minute, 99, false}, {6 * time.Minute, 105, false}, {15 * time.Minute, 103, false}, {16 * time.Minute, 102, true}},
+		},
+		{
+			trigger: "ma-above:100:10m",
+			updates: []update{{0, 200, false}, {5 * time.Minute, 90, false}, {11 * time.Minute, 95, false}, {12 * time.Minute, 120, true}},
+		},
+	}
+
+	for _, test := range tests {
+		v, err := Parse(test.trigger)
+		if err != nil {
+			t.Fatal(err)
+		}
+		w := NewWatcher(v)
+		for i, u := range test.updates {
+			if fired := w.Update(d(u.price), now.Add(u.offset)); fired != u.fired {
+				t.Fatalf("%s: update %d: wanted fired=%v, got %v (distance %s)", test.trigger, i, u.fired, fired, w.Distance())
+			}
+		}
+	}
+
+	for _, s := range []string{"above", "above:100:1m", "stay-below:100", "sideways:100", "below:-1"} {
+		if _, err := Parse(s); err == nil {
+			t.Fatalf("trigger %q: wanted parse error, got nil", s)
+		}
+	}
+}
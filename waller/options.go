@@ -13,8 +13,8 @@ func (w *Waller) SetOption(opt, val string) (_ string, status error) {
 	switch key := strings.ToLower(opt); key {
 	case "retire":
 		return w.setRetireOption(key, val)
-	case "freeze":
-		return w.setFreezeOption(key, val)
+	case "freeze", "stop-loss", "stop-loss-slippage-pct", "stop-loss-action", "max-cycles", "profit-target", "end-date":
+		return w.setLooperOption(key, val)
 	default:
 		return "", fmt.Errorf("waller option %q is invalid", key)
 	}
@@ -53,20 +53,51 @@ func (w *Waller) setRetireOption(opt, val string) (_ string, status error) {
 	return undo, nil
 }
 
-func (w *Waller) setFreezeOption(opt, val string) (_ string, status error) {
-	for _, loop := range w.loopers {
+// setLooperOption sets an option on all child loopers. Undo value is the
+// common undo value of the loopers when they all report the same value.
+// Otherwise, undo value lists the undo values of every looper, so that each
+// looper is restored to it's own previous value.
+func (w *Waller) setLooperOption(opt, val string) (_ string, status error) {
+	vals := make([]string, len(w.loopers))
+	for i := range vals {
+		vals[i] = val
+	}
+	if each, ok := strings.CutPrefix(val, "undo:"); ok && strings.Contains(each, ",") {
+		undos := strings.Split(each, ",")
+		if len(undos) != len(w.loopers) {
+			return "", fmt.Errorf("undo value %q has %d looper values (want %d)", val, len(undos), len(w.loopers))
+		}
+		for i, u := range undos {
+			vals[i] = "undo:" + u
+		}
+	}
+
+	undos := make([]string, len(w.loopers))
+	for i, loop := range w.loopers {
 		loop := loop
-		undoValue, err := loop.SetOption(opt, val)
+		undoValue, err := loop.SetOption(opt, vals[i])
 		if err != nil {
 			return "", err
 		}
 		defer func() {
 			if status != nil {
 				if _, err := loop.SetOption(opt, undoValue); err != nil {
-					slog.Error("could not undo set-option on looper (needs manual fix)", "looper", loop, "opt", opt, "val", val, "err", err)
+					slog.Error("could not undo set-option on looper (needs manual fix)", "looper", loop, "opt", opt, "val", vals[i], "err", err)
 				}
 			}
 		}()
+		undos[i] = undoValue
+	}
+
+	if len(undos) == 0 {
+		return "", nil
+	}
+	if !slices.ContainsFunc(undos[1:], func(u string) bool { return u != undos[0] }) {
+		return undos[0], nil
+	}
+	// Empty undo value from a looper is the same as an empty undo: value.
+	for i, u := range undos {
+		undos[i] = strings.TrimPrefix(u, "undo:")
 	}
-	return "", nil
+	return "undo:" + strings.Join(undos, ","), nil
 }
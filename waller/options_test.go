@@ -0,0 +1,111 @@
+// Copyright (c) 2025 BVK Chaitanya
+
+package waller
+
+import (
+	"testing"
+
+	"github.com/bvk/tradebot/point"
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+)
+
+func newTestWaller(t *testing.T, n int) *Waller {
+	d := decimal.NewFromInt
+	var pairs []*point.Pair
+	for i := range n {
+		base := int64(100 + 10*i)
+		pairs = append(pairs, &point.Pair{
+			Buy:  point.Point{Size: d(1), Price: d(base), Cancel: d(base + 5)},
+			Sell: point.Point{Size: d(1), Price: d(base + 10), Cancel: d(base + 5)},
+		})
+	}
+	w, err := New(uuid.New().String(), "test", "BTC-USD", pairs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return w
+}
+
+func freezeValues(w *Waller) []string {
+	var vs []string
+	for _, l := range w.loopers {
+		v, ok := l.Options()["freeze"]
+		if !ok {
+			v = "none"
+		}
+		vs = append(vs, v)
+	}
+	return vs
+}
+
+func TestFreezeUndo(t *testing.T) {
+	w := newTestWaller(t, 3)
+
+	// Freeze sells on one looper and buys on another before freezing the whole
+	// waller.
+	if _, err := w.loopers[0].SetOption("freeze", "buys"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := w.loopers[2].SetOption("freeze", "sells"); err != nil {
+		t.Fatal(err)
+	}
+	before := freezeValues(w)
+
+	undo, err := w.SetOption("freeze", "buys")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if undo == "" {
+		t.Fatalf("wanted a non-empty undo value when loopers are frozen differently")
+	}
+	if got := freezeValues(w); got[0] != "buys" || got[1] != "buys" || got[2] != "both" {
+		t.Fatalf("wanted buys to be frozen on all loopers, got %v", got)
+	}
+
+	if _, err := w.SetOption("freeze", undo); err != nil {
+		t.Fatal(err)
+	}
+	after := freezeValues(w)
+	for i := range before {
+		if before[i] != after[i] {
+			t.Fatalf("looper %d: wanted freeze value %q after undo, got %q (undo %q)", i, before[i], after[i], undo)
+		}
+	}
+
+	// Undo values with wrong number of loopers must be rejected.
+	if _, err := w.SetOption("freeze", "undo:none,none"); err == nil {
+		t.Fatalf("wanted an error for undo value with wrong number of loopers")
+	}
+}
+
+func TestFreezeUndoSame(t *testing.T) {
+	w := newTestWaller(t, 2)
+
+	undo, err := w.SetOption("freeze", "buys")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if undo != "undo:none" {
+		t.Fatalf("wanted common undo value undo:none, got %q", undo)
+	}
+	// Freezing again changes nothing, so undo of the second freeze must not
+	// unfreeze the loopers.
+	again, err := w.SetOption("freeze", "buys")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := w.SetOption("freeze", again); err != nil {
+		t.Fatal(err)
+	}
+	if got := freezeValues(w); got[0] != "buys" || got[1] != "buys" {
+		t.Fatalf("wanted loopers to remain frozen, got %v", got)
+	}
+
+	if _, err := w.SetOption("freeze", undo); err != nil {
+		t.Fatal(err)
+	}
+	if got := freezeValues(w); got[0] != "none" || got[1] != "none" {
+		t.Fatalf("wanted loopers to be unfrozen, got %v", got)
+	}
+}
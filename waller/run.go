@@ -4,6 +4,8 @@ package waller
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"log"
 	"log/slog"
 	"runtime/debug"
@@ -47,7 +49,7 @@ func (w *Waller) Run(ctx context.Context, rt *trader.Runtime) (status error) {
 	jobUpdatesCh := make(chan string, len(w.loopers))
 	ctx = trader.WithJobUpdateChannel(ctx, jobUpdatesCh)
 
-	var nloopers atomic.Int64
+	var nloopers, npaused atomic.Int64
 	loopMap := make(map[string]*looper.Looper)
 	for _, loop := range w.loopers {
 		loop := loop
@@ -70,6 +72,11 @@ func (w *Waller) Run(ctx context.Context, rt *trader.Runtime) (status error) {
 
 			for ctx.Err() == nil {
 				if err := loop.Run(ctx, rt); err != nil {
+					if ctx.Err() == nil && errors.Is(err, trader.ErrPaused) {
+						slog.Info("wall-looper is paused by itself", "waller", w, "looper", loop, "err", err)
+						npaused.Add(1)
+						return
+					}
 					if ctx.Err() == nil {
 						log.Printf("wall-looper %v has failed (fix manually): %v", loop, err)
 						return
@@ -94,5 +101,8 @@ func (w *Waller) Run(ctx context.Context, rt *trader.Runtime) (status error) {
 		}
 	}
 
+	if ctx.Err() == nil && npaused.Load() > 0 {
+		return fmt.Errorf("%d wall-loopers are paused: %w", npaused.Load(), trader.ErrPaused)
+	}
 	return context.Cause(ctx)
 }
@@ -132,6 +132,17 @@ func (w *Waller) BudgetAt(feePct decimal.Decimal) decimal.Decimal {
 	return sum
 }
 
+// HeldPositions returns the number of loopers holding unsold inventory.
+func (w *Waller) HeldPositions() int {
+	n := 0
+	for _, l := range w.loopers {
+		if l.GetSummary(nil).UnsoldSize.IsPositive() {
+			n++
+		}
+	}
+	return n
+}
+
 func (w *Waller) Pairs() []*point.Pair {
 	var ps []*point.Pair
 	for _, l := range w.loopers {
@@ -140,6 +151,16 @@ func (w *Waller) Pairs() []*point.Pair {
 	return ps
 }
 
+// Options returns the options set on the waller in the format accepted by
+// SetOption. Options are set on all child loopers together, so options of the
+// first looper are returned.
+func (w *Waller) Options() map[string]string {
+	if len(w.loopers) == 0 {
+		return nil
+	}
+	return w.loopers[0].Options()
+}
+
 func (w *Waller) Actions() []*gobs.Action {
 	var actions []*gobs.Action
 	for _, l := range w.loopers {
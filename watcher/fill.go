@@ -0,0 +1,210 @@
+// Copyright (c) 2025 BVK Chaitanya
+
+package watcher
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/bvk/tradebot/gobs"
+	"github.com/bvk/tradebot/limiter"
+	"github.com/bvk/tradebot/point"
+	"github.com/shopspring/decimal"
+)
+
+// FillModes are the supported values for the fill-model option.
+var FillModes = []string{"legacy", "touch", "cross"}
+
+// orderState holds the simulated state of the pending buy or sell order of a
+// trade loop. It is not persisted.
+type orderState struct {
+	// openAt is the time when a simulated limiter would have created the
+	// exchange order. It is zero if order is not open.
+	openAt time.Time
+
+	// atPriceSince is the time since which price is at or beyond the order
+	// price.
+	atPriceSince time.Time
+}
+
+// CheckFillModel returns a non-nil error if fill model parameters are invalid.
+func CheckFillModel(m *gobs.WatcherFillModel) error {
+	if m.Mode != "touch" && m.Mode != "cross" {
+		return fmt.Errorf("fill model mode %q is invalid", m.Mode)
+	}
+	if m.CrossTicks < 0 {
+		return fmt.Errorf("cross ticks cannot be negative")
+	}
+	if m.TickSize.IsNegative() {
+		return fmt.Errorf("tick size cannot be negative")
+	}
+	if m.MinTimeAtPrice < 0 {
+		return fmt.Errorf("min time at price cannot be negative")
+	}
+	return nil
+}
+
+// tickSize returns the price increment to use for the order price.
+func tickSize(m *gobs.WatcherFillModel, price decimal.Decimal) decimal.Decimal {
+	if m.TickSize.IsPositive() {
+		return m.TickSize
+	}
+	return decimal.New(1, min(price.Exponent(), 0))
+}
+
+// reached returns true if a price range [low, high] reaches the order price
+// as per the fill model.
+func reached(m *gobs.WatcherFillModel, p *point.Point, low, high decimal.Decimal) bool {
+	offset := decimal.Zero
+	if m.Mode == "cross" {
+		offset = tickSize(m, p.Price).Mul(decimal.NewFromInt(int64(m.CrossTicks)))
+	}
+	if p.Side() == "BUY" {
+		level := p.Price.Sub(offset)
+		if m.Mode == "touch" {
+			return low.LessThanOrEqual(level)
+		}
+		return low.LessThan(level)
+	}
+	level := p.Price.Add(offset)
+	if m.Mode == "touch" {
+		return high.GreaterThanOrEqual(level)
+	}
+	return high.GreaterThan(level)
+}
+
+// breached returns true if price has crossed the cancel price of the order.
+func breached(p *point.Point, price decimal.Decimal) bool {
+	if p.Side() == "BUY" {
+		return price.GreaterThanOrEqual(p.Cancel)
+	}
+	return price.LessThanOrEqual(p.Cancel)
+}
+
+// isFilled updates the simulated order state with a price movement from last
+// to current price and returns true if the order is filled. Price range in
+// between the last and current prices is used as the candle low and high.
+func isFilled(m *gobs.WatcherFillModel, p *point.Point, st *orderState, last, current decimal.Decimal, now time.Time) bool {
+	filled := false
+	if !m.SimulateCancel || !st.openAt.IsZero() {
+		low, high := decimal.Min(last, current), decimal.Max(last, current)
+		if !reached(m, p, low, high) {
+			st.atPriceSince = time.Time{}
+		} else {
+			if st.atPriceSince.IsZero() {
+				st.atPriceSince = now
+			}
+			filled = now.Sub(st.atPriceSince) >= m.MinTimeAtPrice
+		}
+	}
+	if filled {
+		*st = orderState{}
+		return true
+	}
+
+	if m.SimulateCancel {
+		// Limiter creates the order when ticker is in the activation range and
+		// cancels it when ticker crosses the cancel price after a timeout.
+		if st.openAt.IsZero() {
+			if p.InRange(current) {
+				st.openAt = now
+			}
+		} else if breached(p, current) && st.openAt.Add(limiter.CancelOffsetTimeout).Before(now) {
+			*st = orderState{}
+		}
+	}
+	return false
+}
+
+func (w *Watcher) fillModel() *gobs.WatcherFillModel {
+	if w.state.FillModel == nil {
+		return &gobs.WatcherFillModel{Mode: "legacy"}
+	}
+	return w.state.FillModel
+}
+
+func (w *Watcher) SetOption(opt, val string) (string, error) {
+	key := strings.ToLower(opt)
+	value := strings.TrimPrefix(val, "undo:")
+	m := *w.fillModel()
+
+	var current string
+	switch key {
+	case "fill-model":
+		current = m.Mode
+		if value == "" {
+			value = "legacy"
+		}
+		if !slices.Contains(FillModes, value) {
+			return "", fmt.Errorf("invalid value %q for the %s option (must be one of %s)", val, key, strings.Join(FillModes, ", "))
+		}
+		m.Mode = value
+
+	case "cross-ticks":
+		current = strconv.Itoa(m.CrossTicks)
+		n, err := strconv.Atoi(value)
+		if err != nil {
+			return "", fmt.Errorf("could not parse %s option value %q: %w", key, val, err)
+		}
+		m.CrossTicks = n
+
+	case "tick-size":
+		current = m.TickSize.String()
+		d, err := decimal.NewFromString(value)
+		if err != nil {
+			return "", fmt.Errorf("could not parse %s option value %q: %w", key, val, err)
+		}
+		m.TickSize = d
+
+	case "min-time-at-price":
+		current = m.MinTimeAtPrice.String()
+		d, err := time.ParseDuration(value)
+		if err != nil {
+			return "", fmt.Errorf("could not parse %s option value %q: %w", key, val, err)
+		}
+		m.MinTimeAtPrice = d
+
+	case "simulate-cancel":
+		current = strconv.FormatBool(m.SimulateCancel)
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return "", fmt.Errorf("could not parse %s option value %q: %w", key, val, err)
+		}
+		m.SimulateCancel = b
+
+	default:
+		return "", fmt.Errorf("watcher job doesn't support option %q", opt)
+	}
+
+	if m.Mode == "legacy" {
+		if key != "fill-model" {
+			return "", fmt.Errorf("option %q requires touch or cross fill-model", key)
+		}
+		w.state.FillModel = nil
+		w.orderStates = nil
+		return "undo:" + current, nil
+	}
+	if err := CheckFillModel(&m); err != nil {
+		return "", err
+	}
+	w.state.FillModel = &m
+	w.orderStates = nil
+	return "undo:" + current, nil
+}
+
+// SetFillModel sets the fill model parameters for the simulated orders.
+func (w *Watcher) SetFillModel(m *gobs.WatcherFillModel) error {
+	if m == nil || m.Mode == "" || m.Mode == "legacy" {
+		w.state.FillModel = nil
+		return nil
+	}
+	if err := CheckFillModel(m); err != nil {
+		return err
+	}
+	v := *m
+	w.state.FillModel = &v
+	return nil
+}
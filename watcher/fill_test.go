@@ -0,0 +1,235 @@
+// Copyright (c) 2025 BVK Chaitanya
+
+package watcher
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/bvk/tradebot/exchange"
+	"github.com/bvk/tradebot/gobs"
+	"github.com/bvk/tradebot/limiter"
+	"github.com/bvk/tradebot/point"
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+)
+
+var (
+	testBuy  = point.Point{Size: decimal.NewFromInt(1), Price: decimal.NewFromInt(100), Cancel: decimal.NewFromInt(105)}
+	testSell = point.Point{Size: decimal.NewFromInt(1), Price: decimal.NewFromInt(110), Cancel: decimal.NewFromInt(105)}
+)
+
+func TestReached(t *testing.T) {
+	d := decimal.RequireFromString
+	tests := []struct {
+		model     gobs.WatcherFillModel
+		point     point.Point
+		low, high string
+		want      bool
+	}{
+		{gobs.WatcherFillModel{Mode: "touch"}, testBuy, "100", "101", true},
+		{gobs.WatcherFillModel{Mode: "touch"}, testBuy, "100.01", "101", false},
+		{gobs.WatcherFillModel{Mode: "touch"}, testSell, "109", "110", true},
+		{gobs.WatcherFillModel{Mode: "touch"}, testSell, "109", "109.99", false},
+
+		{gobs.WatcherFillModel{Mode: "cross"}, testBuy, "100", "101", false},
+		{gobs.WatcherFillModel{Mode: "cross"}, testBuy, "99.99", "101", true},
+		{gobs.WatcherFillModel{Mode: "cross"}, testSell, "109", "110", false},
+		{gobs.WatcherFillModel{Mode: "cross"}, testSell, "109", "110.01", true},
+
+		// Tick size is derived from the order price when it is zero.
+		{gobs.WatcherFillModel{Mode: "cross", CrossTicks: 2}, testBuy, "98", "101", false},
+		{gobs.WatcherFillModel{Mode: "cross", CrossTicks: 2}, testBuy, "97.99", "101", true},
+		{gobs.WatcherFillModel{Mode: "cross", CrossTicks: 2, TickSize: d("0.5")}, testBuy, "99", "101", false},
+		{gobs.WatcherFillModel{Mode: "cross", CrossTicks: 2, TickSize: d("0.5")}, testBuy, "98.99", "101", true},
+		{gobs.WatcherFillModel{Mode: "cross", CrossTicks: 1, TickSize: d("0.5")}, testSell, "109", "110.5", false},
+		{gobs.WatcherFillModel{Mode: "cross", CrossTicks: 1, TickSize: d("0.5")}, testSell, "109", "110.51", true},
+	}
+	for i, test := range tests {
+		if got := reached(&test.model, &test.point, d(test.low), d(test.high)); got != test.want {
+			t.Fatalf("test %d: %s %s in [%s, %s]: wanted %v, got %v", i, test.model.Mode, test.point.Side(), test.low, test.high, test.want, got)
+		}
+	}
+}
+
+func TestIsFilled(t *testing.T) {
+	type tick struct {
+		price string
+		at    time.Duration
+	}
+	tests := []struct {
+		name  string
+		model gobs.WatcherFillModel
+		point point.Point
+		ticks []tick
+		// want is the index of the tick that fills the order or -1.
+		want int
+	}{
+		{
+			name:  "touch",
+			model: gobs.WatcherFillModel{Mode: "touch"},
+			point: testBuy,
+			ticks: []tick{{"101", 0}, {"100.5", time.Second}, {"100", 2 * time.Second}},
+			want:  2,
+		},
+		{
+			name:  "min-time-at-price",
+			model: gobs.WatcherFillModel{Mode: "touch", MinTimeAtPrice: 10 * time.Second},
+			point: testBuy,
+			ticks: []tick{{"101", 0}, {"100", time.Second}, {"99", 5 * time.Second}, {"99", 11 * time.Second}},
+			want:  3,
+		},
+		{
+			name:  "min-time-at-price-reset",
+			model: gobs.WatcherFillModel{Mode: "touch", MinTimeAtPrice: 10 * time.Second},
+			point: testBuy,
+			ticks: []tick{{"101", 0}, {"100", time.Second}, {"101", 2 * time.Second}, {"102", 3 * time.Second}, {"100", 5 * time.Second}, {"100", 12 * time.Second}, {"100", 15 * time.Second}},
+			want:  6,
+		},
+		{
+			name:  "simulate-cancel-gap",
+			model: gobs.WatcherFillModel{Mode: "touch", SimulateCancel: true},
+			point: testBuy,
+			// Order is never opened when price gaps from above the cancel price.
+			ticks: []tick{{"110", 0}, {"99", time.Second}, {"98", 2 * time.Second}},
+			want:  -1,
+		},
+		{
+			name:  "simulate-cancel-open",
+			model: gobs.WatcherFillModel{Mode: "touch", SimulateCancel: true},
+			point: testBuy,
+			ticks: []tick{{"110", 0}, {"102", time.Second}, {"99", 2 * time.Second}},
+			want:  2,
+		},
+		{
+			name:  "simulate-cancel-timeout",
+			model: gobs.WatcherFillModel{Mode: "touch", SimulateCancel: true},
+			point: testBuy,
+			// Order is canceled only after it is open for the cancel timeout.
+			ticks: []tick{{"110", 0}, {"102", time.Second}, {"106", 2 * time.Second}, {"106", limiter.CancelOffsetTimeout + 2*time.Second}, {"99", limiter.CancelOffsetTimeout + 3*time.Second}},
+			want:  -1,
+		},
+		{
+			name:  "cross-sell",
+			model: gobs.WatcherFillModel{Mode: "cross", CrossTicks: 1, TickSize: decimal.NewFromInt(1)},
+			point: testSell,
+			ticks: []tick{{"108", 0}, {"110", time.Second}, {"111", 2 * time.Second}, {"111.5", 3 * time.Second}},
+			want:  3,
+		},
+	}
+
+	start := time.Now()
+	for _, test := range tests {
+		var st orderState
+		got := -1
+		for i := 1; i < len(test.ticks); i++ {
+			last, current := decimal.RequireFromString(test.ticks[i-1].price), decimal.RequireFromString(test.ticks[i].price)
+			if isFilled(&test.model, &test.point, &st, last, current, start.Add(test.ticks[i].at)) {
+				got = i
+				break
+			}
+		}
+		if got != test.want {
+			t.Fatalf("%s: wanted fill at tick %d, got %d", test.name, test.want, got)
+		}
+	}
+}
+
+func newTestWatcher(t *testing.T) *Watcher {
+	w, err := New(uuid.New().String(), "test", "BTC-USD", decimal.Zero, []*point.Pair{{Buy: testBuy, Sell: testSell}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return w
+}
+
+func TestHandlePriceUpdate(t *testing.T) {
+	tests := []struct {
+		name   string
+		model  *gobs.WatcherFillModel
+		prices []string
+		// nbuys and nsells are the expected number of buys and sells.
+		nbuys, nsells int
+	}{
+		// Legacy buys need a tick below the buy price.
+		{"legacy", nil, []string{"101", "100", "99.9", "110", "110.1"}, 1, 1},
+		{"touch", &gobs.WatcherFillModel{Mode: "touch"}, []string{"101", "100", "110"}, 1, 1},
+		{"cross", &gobs.WatcherFillModel{Mode: "cross"}, []string{"101", "100", "99.9", "110", "110.1"}, 1, 1},
+		{"cross-no-fills", &gobs.WatcherFillModel{Mode: "cross"}, []string{"101", "100", "101"}, 0, 0},
+	}
+	for _, test := range tests {
+		w := newTestWatcher(t)
+		if err := w.SetFillModel(test.model); err != nil {
+			t.Fatal(err)
+		}
+		var last exchange.PriceUpdate
+		now := time.Now()
+		for i, p := range test.prices {
+			current := &exchange.SimpleTicker{Price: decimal.RequireFromString(p)}
+			current.ServerTime.Time = now.Add(time.Duration(i) * time.Second)
+			w.handlePriceUpdate(context.Background(), 0, last, current)
+			last = current
+		}
+		loop := w.state.TradeLoops[0]
+		if len(loop.Buys) != test.nbuys || len(loop.Sells) != test.nsells {
+			t.Fatalf("%s: wanted %d buys and %d sells, got %d and %d", test.name, test.nbuys, test.nsells, len(loop.Buys), len(loop.Sells))
+		}
+	}
+}
+
+func TestSetOption(t *testing.T) {
+	w := newTestWatcher(t)
+
+	// Model parameters require touch or cross fill model.
+	if _, err := w.SetOption("cross-ticks", "2"); err == nil {
+		t.Fatalf("wanted non-nil error for cross-ticks in legacy fill model")
+	}
+	if _, err := w.SetOption("fill-model", "queue"); err == nil {
+		t.Fatalf("wanted non-nil error for invalid fill model")
+	}
+
+	undoModel, err := w.SetOption("fill-model", "cross")
+	if err != nil {
+		t.Fatal(err)
+	}
+	undoTicks, err := w.SetOption("cross-ticks", "2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	undoCancel, err := w.SetOption("simulate-cancel", "true")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m := w.fillModel(); m.Mode != "cross" || m.CrossTicks != 2 || !m.SimulateCancel {
+		t.Fatalf("unexpected fill model %+v", m)
+	}
+	if _, err := w.SetOption("cross-ticks", "-1"); err == nil {
+		t.Fatalf("wanted non-nil error for negative cross-ticks")
+	}
+	if m := w.fillModel(); m.CrossTicks != 2 {
+		t.Fatalf("wanted failed option to keep the fill model, got %+v", m)
+	}
+
+	// Undo values must restore the options in the reverse order.
+	for _, undo := range []struct{ opt, val string }{
+		{"simulate-cancel", undoCancel},
+		{"cross-ticks", undoTicks},
+	} {
+		if _, err := w.SetOption(undo.opt, undo.val); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if m := w.fillModel(); m.Mode != "cross" || m.CrossTicks != 0 || m.SimulateCancel {
+		t.Fatalf("wanted undo to restore the options, got %+v", m)
+	}
+	if undoModel != "undo:legacy" {
+		t.Fatalf("wanted undo:legacy, got %q", undoModel)
+	}
+	if _, err := w.SetOption("fill-model", undoModel); err != nil {
+		t.Fatal(err)
+	}
+	if w.state.FillModel != nil {
+		t.Fatalf("wanted legacy fill model after undo, got %+v", w.state.FillModel)
+	}
+}
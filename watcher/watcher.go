@@ -36,6 +36,10 @@ type Watcher struct {
 	uid string
 
 	state *gobs.WatcherState
+
+	// orderStates holds the simulated order states for the trade loops when a
+	// fill model is configured.
+	orderStates []orderState
 }
 
 var _ trader.Trader = &Watcher{}
@@ -146,8 +150,47 @@ func (w *Watcher) ExchangeName() string {
 	return w.state.ExchangeName
 }
 
-func (w *Watcher) SetOption(key, value string) (string, error) {
-	return "", fmt.Errorf("watcher job doesn't support option %q", key)
+func (w *Watcher) FeePct() decimal.Decimal {
+	return w.state.FeePct
+}
+
+// Pairs returns the buy/sell pairs with buy prices in the [low, high] range.
+// Zero values for low and high are treated as unbounded.
+func (w *Watcher) Pairs(low, high decimal.Decimal) []*point.Pair {
+	var pairs []*point.Pair
+	for _, loop := range w.state.TradeLoops {
+		buy := loop.Pair.Buy.Price
+		if !low.IsZero() && buy.LessThan(low) {
+			continue
+		}
+		if !high.IsZero() && buy.GreaterThan(high) {
+			continue
+		}
+		pairs = append(pairs, &point.Pair{
+			Buy:  point.Point(loop.Pair.Buy),
+			Sell: point.Point(loop.Pair.Sell),
+		})
+	}
+	return pairs
+}
+
+// SupersededBy returns the uid of the waller job created from this watcher
+// or an empty string.
+func (w *Watcher) SupersededBy() string {
+	return w.state.SupersededBy
+}
+
+// SupersededAt returns the time when the watcher is superseded by a waller.
+func (w *Watcher) SupersededAt() time.Time {
+	return w.state.SupersededAt
+}
+
+// Supersede marks the watcher as superseded by a live waller job. Watcher
+// continues to simulate the trades, so that it can be compared with the
+// waller.
+func (w *Watcher) Supersede(uid string, at time.Time) {
+	w.state.SupersededBy = uid
+	w.state.SupersededAt = at
 }
 
 func (w *Watcher) Actions() []*gobs.Action {
@@ -298,6 +341,26 @@ func (w *Watcher) handlePriceUpdate(_ context.Context, index int, last, current
 		action = "SELL"
 	}
 
+	if m := w.state.FillModel; m != nil && (action == "BUY" || action == "SELL") {
+		if len(w.orderStates) != len(w.state.TradeLoops) {
+			w.orderStates = make([]orderState, len(w.state.TradeLoops))
+		}
+		p := point.Point(buy)
+		if action == "SELL" {
+			p = point.Point(sell)
+		}
+		if !isFilled(m, &p, &w.orderStates[index], lastPrice, price, tickerTime.Time) {
+			return false
+		}
+		if action == "BUY" {
+			loop.Buys = append(loop.Buys, tickerTime.Time)
+		} else {
+			loop.Sells = append(loop.Sells, tickerTime.Time)
+		}
+		slog.Info("watcher: a new order is filled", "watcher", w, "side", action, "price", p.Price, "timestamp", tickerTime.Time, "size", p.Size, "fill-model", m.Mode)
+		return true
+	}
+
 	switch action {
 	case "BUY":
 		if lastPrice.GreaterThanOrEqual(buy.Price) && price.LessThan(buy.Price) {